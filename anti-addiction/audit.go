@@ -0,0 +1,145 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// AuditKind 被拒绝的操作类型
+type AuditKind string
+
+const (
+	// AuditKindPlay 游戏登录或在线
+	AuditKindPlay AuditKind = "play"
+	// AuditKindPurchase 充值
+	AuditKindPurchase AuditKind = "purchase"
+)
+
+// AuditEntry 一次被拒绝的操作
+type AuditEntry struct {
+	// 玩家或账号ID
+	PlayerID int64 `json:"player_id"`
+	// 年龄段标签，同 Metrics 的 bracket
+	AgeBracket string `json:"age_bracket"`
+	// 操作类型
+	Kind AuditKind `json:"kind"`
+	// 拒绝原因
+	Reason DenyReason `json:"reason"`
+	// 充值金额，限额币种的最小单位，仅充值时有效
+	Amount int64 `json:"amount,omitempty"`
+	// 发生时间戳（毫秒）
+	Timestamp int64 `json:"timestamp"`
+}
+
+func (entry *AuditEntry) MarshalBinary() ([]byte, error) {
+	return json.Marshal(entry)
+}
+
+func (entry *AuditEntry) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, entry)
+}
+
+// Score 以发生时间作为有序集合分值
+func (entry *AuditEntry) Score() float64 {
+	return float64(entry.Timestamp)
+}
+
+func (entry *AuditEntry) SetScore(score float64) {
+	entry.Timestamp = int64(score)
+}
+
+// AuditSink 被拒绝操作的审计记录，实现需并发安全
+type AuditSink interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// recordAudit 写入审计记录，sink 为 nil 时忽略；写入失败只记录日志，不影响检查结果
+func recordAudit(ctx context.Context, sink AuditSink, entry AuditEntry) {
+	if sink == nil {
+		return
+	}
+	if err := sink.Record(ctx, entry); err != nil {
+		zaplogger.DefaultLogger().Error("anti-addiction audit record failed", field.WithError(err),
+			field.WithPlayerId(entry.PlayerID), field.String("kind", string(entry.Kind)))
+	}
+}
+
+// MemoryAuditSink 基于内存的审计记录，适用于单机或测试
+type MemoryAuditSink struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+}
+
+// NewMemoryAuditSink 创建基于内存的审计记录
+func NewMemoryAuditSink() *MemoryAuditSink {
+	return &MemoryAuditSink{}
+}
+
+func (sink *MemoryAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.entries = append(sink.entries, entry)
+	return nil
+}
+
+// Entries 按写入顺序返回全部审计记录
+func (sink *MemoryAuditSink) Entries() []AuditEntry {
+	sink.mu.RLock()
+	defer sink.mu.RUnlock()
+	entries := make([]AuditEntry, len(sink.entries))
+	copy(entries, sink.entries)
+	return entries
+}
+
+// StorageAuditSink 基于 global-storage sorted set 的审计记录，以发生时间作为分值，
+// 同一毫秒内内容完全相同的记录会合并为一条
+type StorageAuditSink struct {
+	zset       storage.SortedSetTransactional
+	maxEntries int64
+}
+
+// NewStorageAuditSink 创建基于 global-storage 的审计记录
+// zset: 需以 NewAuditEntryFactory 作为数据工厂注册的 sorted set 存储
+// maxEntries: 最多保留的记录数，超出时删除最早的记录，<=0 表示不限制
+func NewStorageAuditSink(zset storage.SortedSetTransactional, maxEntries int64) *StorageAuditSink {
+	return &StorageAuditSink{zset: zset, maxEntries: maxEntries}
+}
+
+// NewAuditEntryFactory 返回审计记录的数据工厂，用于注册 sorted set 存储
+func NewAuditEntryFactory() storage.SortedSetData {
+	return &AuditEntry{}
+}
+
+func (sink *StorageAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	if err := sink.zset.ZAdd(ctx, &entry); err != nil {
+		return err
+	}
+	if sink.maxEntries > 0 {
+		return sink.zset.ZRevTrimByTopN(ctx, sink.maxEntries)
+	}
+	return nil
+}
+
+// Query 按发生时间倒序查询 [from, to] 内的审计记录
+func (sink *StorageAuditSink) Query(ctx context.Context, from, to time.Time, offset, count int) ([]AuditEntry, error) {
+	elements, err := sink.zset.ZRevRangeByScore(ctx, float64(to.UnixMilli()), float64(from.UnixMilli()), offset, count)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, 0, len(elements))
+	for _, element := range elements {
+		entry, ok := element.(*AuditEntry)
+		if !ok {
+			return nil, errors.New("anti-addiction: unexpected audit entry type")
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,191 @@
+package anti_addiction
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// fakeZSet 基于内存实现的 storage.SortedSetTransactional，不支持事务
+type fakeZSet struct {
+	mu      sync.Mutex
+	members map[string]float64
+	factory storage.SortedSetDataFactory
+}
+
+func newFakeZSet(factory storage.SortedSetDataFactory) *fakeZSet {
+	return &fakeZSet{members: make(map[string]float64), factory: factory}
+}
+
+func (z *fakeZSet) ZAdd(ctx context.Context, element storage.SortedSetData) error {
+	data, err := element.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.members[string(data)] = element.Score()
+	return nil
+}
+
+func (z *fakeZSet) ZRem(ctx context.Context, element storage.StorageData) error {
+	data, err := element.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	delete(z.members, string(data))
+	return nil
+}
+
+// sorted 按分值升序返回全部元素
+func (z *fakeZSet) sorted() ([]storage.SortedSetData, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	elements := make([]storage.SortedSetData, 0, len(z.members))
+	for member, score := range z.members {
+		element := z.factory()
+		if err := element.UnmarshalBinary([]byte(member)); err != nil {
+			return nil, err
+		}
+		element.SetScore(score)
+		elements = append(elements, element)
+	}
+	sort.Slice(elements, func(i, j int) bool { return elements[i].Score() < elements[j].Score() })
+	return elements, nil
+}
+
+func (z *fakeZSet) ZRange(ctx context.Context, start, stop int64) ([]storage.SortedSetData, error) {
+	elements, err := z.sorted()
+	if err != nil {
+		return nil, err
+	}
+	if stop < 0 || stop >= int64(len(elements)) {
+		stop = int64(len(elements)) - 1
+	}
+	if start > stop {
+		return nil, nil
+	}
+	return elements[start : stop+1], nil
+}
+
+func (z *fakeZSet) ZRevRangeByScore(ctx context.Context, max, min float64, offset, count int) ([]storage.SortedSetData, error) {
+	elements, err := z.sorted()
+	if err != nil {
+		return nil, err
+	}
+	var result []storage.SortedSetData
+	for i := len(elements) - 1; i >= 0; i-- {
+		if score := elements[i].Score(); score >= min && score <= max {
+			result = append(result, elements[i])
+		}
+	}
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if count >= 0 && count < len(result) {
+		result = result[:count]
+	}
+	return result, nil
+}
+
+func (z *fakeZSet) ZRevTrimByTopN(ctx context.Context, n int64) error {
+	elements, err := z.sorted()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(elements)-int(n); i++ {
+		if err = z.ZRem(ctx, elements[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *fakeZSet) ZTrimByTopN(ctx context.Context, n int64) error {
+	elements, err := z.sorted()
+	if err != nil {
+		return err
+	}
+	for i := int(n); i < len(elements); i++ {
+		if err = z.ZRem(ctx, elements[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (z *fakeZSet) BeginTx(ctx context.Context) (storage.SortedSetTransaction, error) {
+	return nil, errors.New("fakeZSet: transaction not supported")
+}
+
+func TestStorageAuditSink(t *testing.T) {
+	ctx := context.Background()
+	sink := NewStorageAuditSink(newFakeZSet(NewAuditEntryFactory), 2)
+	base := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+
+	for i := int64(0); i < 3; i++ {
+		entry := AuditEntry{
+			PlayerID:   i,
+			AgeBracket: "8-16",
+			Kind:       AuditKindPurchase,
+			Reason:     DenyReasonMonthlyLimit,
+			Amount:     100,
+			Timestamp:  base.Add(time.Duration(i) * time.Minute).UnixMilli(),
+		}
+		if err := sink.Record(ctx, entry); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	entries, err := sink.Query(ctx, base, base.Add(time.Hour), 0, 10)
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	// 只保留最新的 2 条，按时间倒序
+	if len(entries) != 2 || entries[0].PlayerID != 2 || entries[1].PlayerID != 1 {
+		t.Fatalf("Query() = %+v, want players [2 1]", entries)
+	}
+	if entries[0].Reason != DenyReasonMonthlyLimit || entries[0].Kind != AuditKindPurchase {
+		t.Errorf("Query()[0] = %+v, want monthly_limit purchase", entries[0])
+	}
+}
+
+func TestPurchaseRecorder_Audit(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewPurchaseRecorder(mustNewPurchaseChecker(t, getDefaultPurchaseConfig()), newFakeHash(NewPurchaseRecordFactory))
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	recorder.SetTimeNow(func() time.Time { return now })
+	sink := NewMemoryAuditSink()
+	recorder.SetAuditSink(sink)
+
+	if _, err := recorder.CheckAndRecordPurchase(ctx, 1, 100, 6); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := recorder.CheckAndRecordPurchase(ctx, 2, 5000, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []AuditEntry{
+		{PlayerID: 1, AgeBracket: "0-8", Kind: AuditKindPurchase, Reason: DenyReasonAgeBanned, Timestamp: now.UnixMilli()},
+		{PlayerID: 2, AgeBracket: "8-16", Kind: AuditKindPurchase, Reason: DenyReasonMonthlyLimit, Amount: 5000, Timestamp: now.UnixMilli()},
+	}
+	entries := sink.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("Entries() = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Entries()[%d] = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
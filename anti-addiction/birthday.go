@@ -0,0 +1,56 @@
+package anti_addiction
+
+import "time"
+
+// AgeAt 计算出生日期为 birthday 的玩家在 t 时刻的周岁年龄：出生日期按其自身记录的年月日，
+// 与 t 在其所在时区的年月日比较，不做时区转换，避免以 UTC 零点保存的生日在东八区等时区提前或推后一天。
+// 2月29日出生的玩家在平年的3月1日长一岁
+func AgeAt(birthday time.Time, t time.Time) int32 {
+	age := int32(t.Year() - birthday.Year())
+	if t.Month() < birthday.Month() || (t.Month() == birthday.Month() && t.Day() < birthday.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// IsInPlayTimeForBirthday 按当前时刻的实际年龄检查是否在允许游戏时间内，
+// 玩家在游戏过程中达到成年年龄会立即按成年人处理。可直接传入 idcard_sdk.IdInfo 的 Birthday
+func (checker *AntiAddictionTimeChecker) IsInPlayTimeForBirthday(birthday time.Time) bool {
+	now := checker.timeNow()
+	return checker.IsInPlayTimeAt(AgeAt(birthday, now), now)
+}
+
+// GetPlayEndTimeForBirthday 按当前时刻的实际年龄获取今天的可游玩结束时间戳（毫秒）
+// 返回值：-1表示不可游玩，0表示无限制，其他值表示具体的结束时间戳
+func (checker *AntiAddictionTimeChecker) GetPlayEndTimeForBirthday(birthday time.Time) int64 {
+	now := checker.timeNow()
+	return checker.GetPlayEndTimeAt(AgeAt(birthday, now), now)
+}
+
+func (checker *antiAddictionChecker) IsInPlayTimeForBirthday(birthday time.Time) bool {
+	now := checker.timeNow()
+	return checker.IsInPlayTimeAt(AgeAt(birthday, now), now)
+}
+
+func (checker *antiAddictionChecker) GetPlayEndTimeForBirthday(birthday time.Time) int64 {
+	return checker.state.Load().TimeChecker.GetPlayEndTimeForBirthday(birthday)
+}
+
+func (checker *antiAddictionChecker) CheckSinglePurchaseForBirthday(amount int64, birthday time.Time, opts ...PurchaseOption) bool {
+	state := checker.state.Load()
+	age := AgeAt(birthday, state.TimeChecker.timeNow())
+	allowed, reason := state.PurchaseChecker.CheckSinglePurchaseWithReason(amount, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, allowed, reason, opts...)
+	return allowed
+}
+
+func (checker *antiAddictionChecker) CheckMonthlyPurchaseForBirthday(amount int64, monthlyTotal int64, birthday time.Time, opts ...PurchaseOption) bool {
+	state := checker.state.Load()
+	age := AgeAt(birthday, state.TimeChecker.timeNow())
+	allowed, reason := state.PurchaseChecker.CheckMonthlyPurchaseWithReason(amount, monthlyTotal, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, allowed, reason, opts...)
+	return allowed
+}
@@ -0,0 +1,81 @@
+package anti_addiction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		birthday time.Time
+		at       time.Time
+		want     int32
+	}{
+		{
+			name:     "生日前一天",
+			birthday: time.Date(2007, 3, 28, 0, 0, 0, 0, time.Local),
+			at:       time.Date(2025, 3, 27, 23, 59, 59, 0, time.Local),
+			want:     17,
+		},
+		{
+			name:     "生日当天",
+			birthday: time.Date(2007, 3, 28, 0, 0, 0, 0, time.Local),
+			at:       time.Date(2025, 3, 28, 0, 0, 0, 0, time.Local),
+			want:     18,
+		},
+		{
+			name:     "2月29日出生-平年2月28日",
+			birthday: time.Date(2008, 2, 29, 0, 0, 0, 0, time.Local),
+			at:       time.Date(2026, 2, 28, 12, 0, 0, 0, time.Local),
+			want:     17,
+		},
+		{
+			name:     "2月29日出生-平年3月1日",
+			birthday: time.Date(2008, 2, 29, 0, 0, 0, 0, time.Local),
+			at:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+			want:     18,
+		},
+		{
+			name:     "UTC保存的生日-东八区生日当天零点",
+			birthday: time.Date(2007, 3, 28, 0, 0, 0, 0, time.UTC),
+			at:       time.Date(2025, 3, 28, 0, 0, 0, 0, time.FixedZone("CST", 8*3600)),
+			want:     18,
+		},
+		{
+			name:     "UTC保存的生日-西五区生日前一天深夜",
+			birthday: time.Date(2007, 3, 28, 0, 0, 0, 0, time.UTC),
+			at:       time.Date(2025, 3, 27, 23, 0, 0, 0, time.FixedZone("EST", -5*3600)),
+			want:     17,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeAt(tt.birthday, tt.at); got != tt.want {
+				t.Errorf("AgeAt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAntiAddictionTimeChecker_IsInPlayTimeForBirthday(t *testing.T) {
+	checker := NewAntiAddictionTimeChecker(getTestTimeConfig())
+	birthday := time.Date(2007, 3, 26, 0, 0, 0, 0, time.Local)
+
+	// 星期二，满18周岁前一刻不可游玩
+	now := time.Date(2025, 3, 25, 23, 59, 59, 0, time.Local)
+	checker.SetTimeNow(func() time.Time { return now })
+	if checker.IsInPlayTimeForBirthday(birthday) {
+		t.Errorf("IsInPlayTimeForBirthday() before 18 = true, want false")
+	}
+
+	// 跨过零点满18周岁，立即按成年人处理
+	now = now.Add(time.Second)
+	if !checker.IsInPlayTimeForBirthday(birthday) {
+		t.Errorf("IsInPlayTimeForBirthday() at 18 = false, want true")
+	}
+	if got := checker.GetPlayEndTimeForBirthday(birthday); got != 0 {
+		t.Errorf("GetPlayEndTimeForBirthday() at 18 = %d, want 0", got)
+	}
+}
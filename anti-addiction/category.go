@@ -0,0 +1,60 @@
+package anti_addiction
+
+// PurchaseTotals 已充值累计，单位与币种同充值选项
+type PurchaseTotals struct {
+	// 当日全部品类已充值总额
+	DailyTotal int64
+	// 当月全部品类已充值总额
+	MonthlyTotal int64
+	// 当日本品类已充值总额
+	CategoryDailyTotal int64
+	// 当月本品类已充值总额
+	CategoryMonthlyTotal int64
+}
+
+// GetCategoryPurchaseLimit 获取指定品类、年龄的充值限制，品类未配置时 ok 为 false
+func (checker *PurchaseChecker) GetCategoryPurchaseLimit(category string, age int32) (limit PurchaseLimit, ok bool) {
+	limits, ok := checker.config.CategoryLimits[category]
+	if !ok {
+		return PurchaseLimit{}, false
+	}
+	return limits.limitFor(age), true
+}
+
+// CheckCategoryPurchase 先按总限额检查，再叠加品类限额检查，返回详细结果，
+// MaxAmount 为两者中较小的可充值金额；品类未配置时等同于 CheckPurchaseDetailed
+// category: 商品品类，如 "gacha"
+// totals: 全部品类及本品类的已充值累计
+// opts: 可选参数，不传则使用默认选项，使用分作为单位
+func (checker *PurchaseChecker) CheckCategoryPurchase(category string, amount int64, age int32, totals PurchaseTotals, opts ...PurchaseOption) PurchaseDecision {
+	decision := checker.CheckPurchaseDetailed(amount, totals.DailyTotal, totals.MonthlyTotal, age, opts...)
+	limit, ok := checker.GetCategoryPurchaseLimit(category, age)
+	if !ok || decision.Reason == DenyReasonCurrencyUnsupported || decision.Reason == DenyReasonAgeBanned {
+		return decision
+	}
+
+	// 币种已在总限额检查中验证可转换
+	amountInFen, _ := checker.ConvertAmount(amount, opts...)
+	dailyTotalInFen, _ := checker.ConvertAmount(totals.CategoryDailyTotal, opts...)
+	monthlyTotalInFen, _ := checker.ConvertAmount(totals.CategoryMonthlyTotal, opts...)
+	categoryDecision := evaluatePurchaseLimit(limit, amountInFen, dailyTotalInFen, monthlyTotalInFen)
+	categoryDecision.Category = category
+	return mergePurchaseDecision(decision, categoryDecision)
+}
+
+// mergePurchaseDecision 叠加两层限额的检查结果：extra 禁充时以 extra 为准，
+// 否则优先保留 base 的拒绝原因，MaxAmount 取两者中较小值
+func mergePurchaseDecision(base, extra PurchaseDecision) PurchaseDecision {
+	if extra.Reason == DenyReasonAgeBanned {
+		return extra
+	}
+	maxAmount := base.MaxAmount
+	if maxAmount == -1 || (extra.MaxAmount != -1 && extra.MaxAmount < maxAmount) {
+		maxAmount = extra.MaxAmount
+	}
+	if base.Allowed && !extra.Allowed {
+		base = extra
+	}
+	base.MaxAmount = maxAmount
+	return base
+}
@@ -1,19 +1,55 @@
 package anti_addiction
 
 import (
+	"context"
 	"errors"
 	"github.com/NumberMan1/numbox/utils"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Config 防沉迷总配置
 type Config struct {
-	TimeConfig     TimeConfig
-	PurchaseConfig PurchaseConfig
+	// 策略预设名称，LoadConfig 以该预设为基础合并文件中的配置，为空时以 ProfileDefault 为基础；
+	// 非空时 NewAntiAddictionChecker 与 Reload 中值为零的各项配置也取该预设中的配置
+	Profile string `json:"profile" yaml:"profile"`
+	// 成年年龄，达到该年龄不受时间限制，0 表示使用 DefaultAdultAge
+	AdultAge       int32          `json:"adult_age" yaml:"adult-age"`
+	TimeConfig     TimeConfig     `json:"time_config" yaml:"time-config"`
+	PurchaseConfig PurchaseConfig `json:"purchase_config" yaml:"purchase-config"`
+	GuestConfig    GuestConfig    `json:"guest_config" yaml:"guest-config"`
+	GraceConfig    GraceConfig    `json:"grace_config" yaml:"grace-config"`
+	DurationConfig DurationConfig `json:"duration_config" yaml:"duration-config"`
 }
 
 type AntiAddictionChecker interface {
 	IsInPlayTime(age int32) bool
 	GetPlayEndTime(age int32) int64
+	// IsInPlayTimeForBirthday 按当前时刻的实际年龄检查是否在允许游戏时间内
+	IsInPlayTimeForBirthday(birthday time.Time) bool
+	// GetPlayEndTimeForBirthday 按当前时刻的实际年龄获取今天的可游玩结束时间戳（毫秒）
+	GetPlayEndTimeForBirthday(birthday time.Time) int64
+	// CheckSinglePurchaseForBirthday 按当前时刻的实际年龄检查单笔充值是否超限
+	CheckSinglePurchaseForBirthday(amount int64, birthday time.Time, opts ...PurchaseOption) bool
+	// CheckMonthlyPurchaseForBirthday 按当前时刻的实际年龄检查月度充值是否超限
+	CheckMonthlyPurchaseForBirthday(amount int64, monthlyTotal int64, birthday time.Time, opts ...PurchaseOption) bool
+	// IsInPlayTimeForAccount 检查账号是否在允许游戏时间内，豁免名单内的账号总是可游玩，其余按 IsInPlayTimeWithReason 判断；
+	// age 为 AgePending 表示实名认证进行中，此时仅在等待期内按未成年人的可游玩时间判断
+	IsInPlayTimeForAccount(ctx context.Context, accountID int64, age int32) (bool, error)
+	// SetGraceChecker 设置实名认证等待期检查器，不随 Reload 替换；等待期配置以 Config.GraceConfig 为准并随 Reload 更新
+	SetGraceChecker(grace *GraceChecker)
+	// SetGuestChecker 设置游客模式检查器，不随 Reload 替换；游客配置以 Config.GuestConfig 为准并随 Reload 更新
+	SetGuestChecker(guest *GuestChecker)
+	// IsInPlayTimeAt 检查指定时刻是否在允许游戏时间内
+	IsInPlayTimeAt(age int32, t time.Time) bool
+	// GetPlayEndTimeAt 获取指定时刻当天的可游玩结束时间戳（毫秒），-1表示不可游玩，0表示无限制
+	GetPlayEndTimeAt(age int32, t time.Time) int64
+	// ExplainDecision 判定指定时刻、年龄是否可游玩，并返回命中的覆盖日期、节假日或星期规则
+	ExplainDecision(t time.Time, age int32) Decision
+	// GetNextPlayWindow 从 from 开始向后查找指定年龄的下一个可游玩时间段，已扣除宵禁
+	// 没有适用宵禁的成年人返回 start 为 from、end 为零值；ok 为 false 表示一年内没有可游玩时间段
+	GetNextPlayWindow(age int32, from time.Time) (start, end time.Time, ok bool)
 	// CheckSinglePurchase 检查单笔充值是否超限
 	// amount: 充值金额
 	// age: 玩家年龄
@@ -27,20 +63,138 @@ type AntiAddictionChecker interface {
 	// opts: 可选参数，不传则使用默认选项，使用分作为单位
 	// 返回值：是否允许充值
 	CheckMonthlyPurchase(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) bool
+	// CheckDailyPurchase 检查当日充值是否超限
+	// amount: 本次充值金额
+	// dailyTotal: 当日已充值总额
+	// age: 玩家年龄
+	// opts: 可选参数，不传则使用默认选项，使用分作为单位
+	// 返回值：是否允许充值
+	CheckDailyPurchase(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) bool
+	// CheckPurchaseDetailed 依次检查年龄段禁充、单笔、当日、月度限额，返回详细结果
+	// amount: 本次充值金额
+	// dailyTotal: 当日已充值总额
+	// monthlyTotal: 当月已充值总额
+	// age: 玩家年龄
+	// opts: 可选参数，不传则使用默认选项，使用分作为单位
+	CheckPurchaseDetailed(amount int64, dailyTotal int64, monthlyTotal int64, age int32, opts ...PurchaseOption) PurchaseDecision
+	// CheckCategoryPurchase 先按总限额检查，再叠加品类限额检查，返回详细结果
+	// category: 商品品类，未配置品类限额时等同于 CheckPurchaseDetailed
+	// totals: 全部品类及本品类的已充值累计，单位与币种同 opts
+	CheckCategoryPurchase(category string, amount int64, age int32, totals PurchaseTotals, opts ...PurchaseOption) PurchaseDecision
+	// GetMaxSingleAmount 获取指定年龄单笔最多可充值的金额，-1 表示无限制
+	// opts: 可选参数，指定返回值的单位与币种，不传则使用分作为单位
+	GetMaxSingleAmount(age int32, opts ...PurchaseOption) int64
+	// GetRemainingMonthlyQuota 获取指定年龄当月剩余可充值额度，-1 表示无限制
+	// monthlyTotal: 当月已充值总额，单位与币种同 opts
+	// opts: 可选参数，指定入参与返回值的单位与币种，不传则使用分作为单位
+	GetRemainingMonthlyQuota(age int32, monthlyTotal int64, opts ...PurchaseOption) int64
+	// ConvertAmount 将指定单位、币种的金额转换为限额币种的最小单位（人民币即为分）
+	ConvertAmount(amount int64, opts ...PurchaseOption) (int64, error)
+	// ComputeKickList 一次性计算在 now 时刻不可游玩、需要踢下线的玩家ID，豁免名单内的玩家不会被踢下线
+	ComputeKickList(players []PlayerAge, now time.Time) []PlayerID
+	// GetDailyDurationLimit 获取指定年龄的每日累计游戏时长上限（秒），-1 表示不限制
+	GetDailyDurationLimit(age int32) int64
+	// SchedulePlayEnd 在指定年龄的可游玩结束时刻触发通道，重载配置导致结束时刻变化时自动重新计时
+	// 当前不可游玩时立即触发，成年人等无限制时仅在重载后变为受限才会触发，豁免名单内的账号不会触发；调用 cancel 停止调度
+	SchedulePlayEnd(accountID int64, age int32) (<-chan time.Time, func())
+	// IsInPlayTimeWithReason 检查是否在允许游戏时间内，不可游玩时同时返回原因，可游玩时原因为 DenyReasonNone；
+	// age 为 AgePending 时不可游玩，原因为 DenyReasonVerificationPending，等待期由 IsInPlayTimeForAccount 判断
+	IsInPlayTimeWithReason(age int32) (bool, DenyReason)
+	// IsInPlayTimeAtWithReason 检查指定时刻是否在允许游戏时间内，不可游玩时同时返回原因
+	IsInPlayTimeAtWithReason(age int32, t time.Time) (bool, DenyReason)
+	// CheckSinglePurchaseWithReason 检查单笔充值是否超限，拒绝时同时返回原因，允许时原因为 DenyReasonNone
+	CheckSinglePurchaseWithReason(amount int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	// CheckMonthlyPurchaseWithReason 检查月度充值是否超限，拒绝时同时返回原因
+	CheckMonthlyPurchaseWithReason(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	// CheckDailyPurchaseWithReason 检查当日充值是否超限，拒绝时同时返回原因
+	CheckDailyPurchaseWithReason(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	// AgeBracket 返回年龄段标签，用于指标与审计
+	AgeBracket(age int32) string
+	// AdultAge 返回当前配置的成年年龄
+	AdultAge() int32
+	// SetAuditSink 设置被拒绝操作的审计记录，IsInPlayTimeForAccount 拒绝时写入，不随 Reload 替换
+	SetAuditSink(sink AuditSink)
+	// SetExemptions 设置豁免名单，名单内账号在 IsInPlayTimeForAccount 中总是可游玩，不随 Reload 替换
+	SetExemptions(exemptions *Exemptions)
+	// IsExempt 账号是否在豁免名单内
+	IsExempt(accountID int64) bool
+	// SetMetrics 设置可游玩与充值检查的指标上报实现，传入 nil 时关闭上报，不随 Reload 替换
+	SetMetrics(metrics Metrics)
+	// Reload 使用新配置重建检查器并原子替换，正在进行的检查不受影响；配置非法时保留旧配置并返回错误
+	Reload(config Config) error
 }
 
-type antiAddictionChecker struct {
+// checkerState 同一份配置构建出的检查器集合，重载时整体替换
+type checkerState struct {
 	TimeChecker     *AntiAddictionTimeChecker
 	PurchaseChecker *PurchaseChecker
+	DurationConfig  DurationConfig
+	GraceConfig     GraceConfig
+	GuestConfig     GuestConfig
+}
+
+type antiAddictionChecker struct {
+	state   atomic.Pointer[checkerState]
+	grace   atomic.Pointer[GraceChecker]
+	guest   atomic.Pointer[GuestChecker]
+	metrics atomic.Pointer[metricsHolder]
+	audit   atomic.Pointer[auditHolder]
+	exempt  atomic.Pointer[Exemptions]
+	timeNow func() time.Time
+
+	// reloaded 每次重载时关闭并替换，用于通知等待中的调度器；reloadMu 同时保证等待期、游客配置与 state 一致
+	reloadMu sync.Mutex
+	reloaded chan struct{}
 }
 
 var antiAddictionCheckerInstance AntiAddictionChecker
 
-func InitAntiAddictionChecker(config Config) {
-	antiAddictionCheckerInstance = &antiAddictionChecker{
-		TimeChecker:     NewAntiAddictionTimeChecker(config.TimeConfig),
-		PurchaseChecker: NewPurchaseChecker(config.PurchaseConfig),
+func newCheckerState(config Config) (*checkerState, error) {
+	config, err := config.applyProfile()
+	if err != nil {
+		return nil, err
+	}
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	purchaseChecker, err := NewPurchaseChecker(config.PurchaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	purchaseChecker.SetAdultAge(config.adultAge())
+	timeChecker := NewAntiAddictionTimeChecker(config.TimeConfig)
+	timeChecker.SetAdultAge(config.adultAge())
+	return &checkerState{
+		TimeChecker:     timeChecker,
+		PurchaseChecker: purchaseChecker,
+		DurationConfig:  config.DurationConfig,
+		GraceConfig:     config.GraceConfig,
+		GuestConfig:     config.GuestConfig,
+	}, nil
+}
+
+// NewAntiAddictionChecker 创建防沉迷检查器，配置非法时返回错误
+func NewAntiAddictionChecker(config Config) (AntiAddictionChecker, error) {
+	state, err := newCheckerState(config)
+	if err != nil {
+		return nil, err
 	}
+	checker := &antiAddictionChecker{
+		timeNow:  time.Now,
+		reloaded: make(chan struct{}),
+	}
+	checker.state.Store(state)
+	return checker, nil
+}
+
+// InitAntiAddictionChecker 初始化全局检查器，配置非法时返回错误且不替换已有实例
+func InitAntiAddictionChecker(config Config) error {
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		return err
+	}
+	antiAddictionCheckerInstance = checker
+	return nil
 }
 
 func GetAddictionChecker() AntiAddictionChecker {
@@ -48,18 +202,229 @@ func GetAddictionChecker() AntiAddictionChecker {
 	return antiAddictionCheckerInstance
 }
 
+// ReloadAntiAddictionChecker 热更新全局检查器的配置，未初始化时等同于初始化
+func ReloadAntiAddictionChecker(config Config) error {
+	if antiAddictionCheckerInstance == nil {
+		return InitAntiAddictionChecker(config)
+	}
+	return antiAddictionCheckerInstance.Reload(config)
+}
+
+func (checker *antiAddictionChecker) Reload(config Config) error {
+	state, err := newCheckerState(config)
+	if err != nil {
+		return err
+	}
+	state.TimeChecker.SetTimeNow(checker.timeNow)
+
+	checker.reloadMu.Lock()
+	checker.state.Store(state)
+	if grace := checker.grace.Load(); grace != nil {
+		grace.SetConfig(state.GraceConfig)
+	}
+	if guest := checker.guest.Load(); guest != nil {
+		guest.SetConfig(state.GuestConfig)
+	}
+	close(checker.reloaded)
+	checker.reloaded = make(chan struct{})
+	checker.reloadMu.Unlock()
+	return nil
+}
+
+// reloadSignal 返回在下一次重载时关闭的通道
+func (checker *antiAddictionChecker) reloadSignal() <-chan struct{} {
+	checker.reloadMu.Lock()
+	defer checker.reloadMu.Unlock()
+	return checker.reloaded
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法，重载后依然生效
+func (checker *antiAddictionChecker) SetTimeNow(timeNow func() time.Time) {
+	checker.timeNow = timeNow
+	checker.state.Load().TimeChecker.SetTimeNow(timeNow)
+}
+
 func (checker *antiAddictionChecker) IsInPlayTime(age int32) bool {
-	return checker.TimeChecker.IsInPlayTime(age)
+	return checker.IsInPlayTimeAt(age, checker.timeNow())
 }
 
 func (checker *antiAddictionChecker) GetPlayEndTime(age int32) int64 {
-	return checker.TimeChecker.GetPlayEndTime(age)
+	return checker.state.Load().TimeChecker.GetPlayEndTime(age)
+}
+
+func (checker *antiAddictionChecker) IsInPlayTimeForAccount(ctx context.Context, accountID int64, age int32) (bool, error) {
+	if checker.IsExempt(accountID) {
+		return true, nil
+	}
+	inGrace := false
+	if grace := checker.grace.Load(); grace != nil && age == AgePending {
+		var err error
+		if inGrace, err = grace.IsInGracePeriod(ctx, accountID); err != nil {
+			return false, err
+		}
+	}
+	allowed, reason := checker.playTimeAt(age, checker.timeNow(), inGrace)
+	if !allowed {
+		checker.recordPlayDenial(ctx, accountID, age, reason)
+	}
+	return allowed, nil
+}
+
+func (checker *antiAddictionChecker) SetExemptions(exemptions *Exemptions) {
+	checker.exempt.Store(exemptions)
+}
+
+func (checker *antiAddictionChecker) IsExempt(accountID int64) bool {
+	return checker.exempt.Load().IsExempt(accountID)
+}
+
+func (checker *antiAddictionChecker) AdultAge() int32 {
+	return checker.state.Load().TimeChecker.AdultAge()
+}
+
+func (checker *antiAddictionChecker) AgeBracket(age int32) string {
+	return checker.state.Load().PurchaseChecker.AgeBracket(age)
+}
+
+// auditHolder 包装 AuditSink 以便原子替换
+type auditHolder struct {
+	sink AuditSink
+}
+
+func (checker *antiAddictionChecker) SetAuditSink(sink AuditSink) {
+	checker.audit.Store(&auditHolder{sink: sink})
+}
+
+func (checker *antiAddictionChecker) recordPlayDenial(ctx context.Context, accountID int64, age int32, reason DenyReason) {
+	holder := checker.audit.Load()
+	if holder == nil {
+		return
+	}
+	recordAudit(ctx, holder.sink, AuditEntry{
+		PlayerID:   accountID,
+		AgeBracket: checker.AgeBracket(age),
+		Kind:       AuditKindPlay,
+		Reason:     reason,
+		Timestamp:  checker.timeNow().UnixMilli(),
+	})
+}
+
+func (checker *antiAddictionChecker) SetGraceChecker(grace *GraceChecker) {
+	checker.reloadMu.Lock()
+	defer checker.reloadMu.Unlock()
+	if grace != nil {
+		grace.SetConfig(checker.state.Load().GraceConfig)
+	}
+	checker.grace.Store(grace)
+}
+
+func (checker *antiAddictionChecker) SetGuestChecker(guest *GuestChecker) {
+	checker.reloadMu.Lock()
+	defer checker.reloadMu.Unlock()
+	if guest != nil {
+		guest.SetConfig(checker.state.Load().GuestConfig)
+	}
+	checker.guest.Store(guest)
+}
+
+func (checker *antiAddictionChecker) GetDailyDurationLimit(age int32) int64 {
+	return checker.state.Load().DurationConfig.GetDailyDurationLimit(age)
+}
+
+func (checker *antiAddictionChecker) IsInPlayTimeAt(age int32, t time.Time) bool {
+	allowed, _ := checker.IsInPlayTimeAtWithReason(age, t)
+	return allowed
+}
+
+func (checker *antiAddictionChecker) IsInPlayTimeWithReason(age int32) (bool, DenyReason) {
+	return checker.IsInPlayTimeAtWithReason(age, checker.timeNow())
+}
+
+func (checker *antiAddictionChecker) IsInPlayTimeAtWithReason(age int32, t time.Time) (bool, DenyReason) {
+	return checker.playTimeAt(age, t, false)
+}
+
+// playTimeAt 检查指定时刻是否可游玩；认证中的账号仅在等待期内按未成年人的可游玩时间判断
+func (checker *antiAddictionChecker) playTimeAt(age int32, t time.Time, inGrace bool) (bool, DenyReason) {
+	state := checker.state.Load()
+	allowed, reason := false, DenyReasonVerificationPending
+	if age != AgePending || inGrace {
+		allowed, reason = state.TimeChecker.IsInPlayTimeAtWithReason(age, t)
+	}
+	checker.recordPlayCheck(state, age, allowed, reason)
+	return allowed, reason
+}
+
+func (checker *antiAddictionChecker) GetPlayEndTimeAt(age int32, t time.Time) int64 {
+	return checker.state.Load().TimeChecker.GetPlayEndTimeAt(age, t)
+}
+
+func (checker *antiAddictionChecker) ExplainDecision(t time.Time, age int32) Decision {
+	return checker.state.Load().TimeChecker.ExplainDecision(t, age)
+}
+
+func (checker *antiAddictionChecker) GetNextPlayWindow(age int32, from time.Time) (start, end time.Time, ok bool) {
+	return checker.state.Load().TimeChecker.GetNextPlayWindow(age, from)
 }
 
 func (checker *antiAddictionChecker) CheckSinglePurchase(amount int64, age int32, opts ...PurchaseOption) bool {
-	return checker.PurchaseChecker.CheckSinglePurchase(amount, age, opts...)
+	allowed, _ := checker.CheckSinglePurchaseWithReason(amount, age, opts...)
+	return allowed
+}
+
+func (checker *antiAddictionChecker) CheckSinglePurchaseWithReason(amount int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	state := checker.state.Load()
+	allowed, reason := state.PurchaseChecker.CheckSinglePurchaseWithReason(amount, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, allowed, reason, opts...)
+	return allowed, reason
 }
 
 func (checker *antiAddictionChecker) CheckMonthlyPurchase(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) bool {
-	return checker.PurchaseChecker.CheckMonthlyPurchase(amount, monthlyTotal, age, opts...)
+	allowed, _ := checker.CheckMonthlyPurchaseWithReason(amount, monthlyTotal, age, opts...)
+	return allowed
+}
+
+func (checker *antiAddictionChecker) CheckMonthlyPurchaseWithReason(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	state := checker.state.Load()
+	allowed, reason := state.PurchaseChecker.CheckMonthlyPurchaseWithReason(amount, monthlyTotal, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, allowed, reason, opts...)
+	return allowed, reason
+}
+
+func (checker *antiAddictionChecker) CheckDailyPurchase(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) bool {
+	allowed, _ := checker.CheckDailyPurchaseWithReason(amount, dailyTotal, age, opts...)
+	return allowed
+}
+
+func (checker *antiAddictionChecker) CheckDailyPurchaseWithReason(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	state := checker.state.Load()
+	allowed, reason := state.PurchaseChecker.CheckDailyPurchaseWithReason(amount, dailyTotal, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, allowed, reason, opts...)
+	return allowed, reason
+}
+
+func (checker *antiAddictionChecker) ConvertAmount(amount int64, opts ...PurchaseOption) (int64, error) {
+	return checker.state.Load().PurchaseChecker.ConvertAmount(amount, opts...)
+}
+
+func (checker *antiAddictionChecker) CheckPurchaseDetailed(amount int64, dailyTotal int64, monthlyTotal int64, age int32, opts ...PurchaseOption) PurchaseDecision {
+	state := checker.state.Load()
+	decision := state.PurchaseChecker.CheckPurchaseDetailed(amount, dailyTotal, monthlyTotal, age, opts...)
+	checker.recordPurchaseCheck(state, age, amount, decision.Allowed, decision.Reason, opts...)
+	return decision
+}
+
+func (checker *antiAddictionChecker) CheckCategoryPurchase(category string, amount int64, age int32, totals PurchaseTotals, opts ...PurchaseOption) PurchaseDecision {
+	state := checker.state.Load()
+	decision := state.PurchaseChecker.CheckCategoryPurchase(category, amount, age, totals, opts...)
+	checker.recordPurchaseCheck(state, age, amount, decision.Allowed, decision.Reason, opts...)
+	return decision
+}
+
+func (checker *antiAddictionChecker) GetMaxSingleAmount(age int32, opts ...PurchaseOption) int64 {
+	return checker.state.Load().PurchaseChecker.GetMaxSingleAmount(age, opts...)
+}
+
+func (checker *antiAddictionChecker) GetRemainingMonthlyQuota(age int32, monthlyTotal int64, opts ...PurchaseOption) int64 {
+	return checker.state.Load().PurchaseChecker.GetRemainingMonthlyQuota(age, monthlyTotal, opts...)
 }
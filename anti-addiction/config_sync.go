@@ -0,0 +1,133 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// syncedConfig 存储在 global-storage 中的配置及其版本号
+type syncedConfig struct {
+	// 每次发布递增
+	Version int64  `json:"version"`
+	Config  Config `json:"config"`
+}
+
+func (synced *syncedConfig) MarshalBinary() ([]byte, error) {
+	return json.Marshal(synced)
+}
+
+func (synced *syncedConfig) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, synced)
+}
+
+// ConfigSync 通过 global-storage 的 KV 在集群内同步防沉迷配置：
+// 任一节点 Publish 新配置，所有节点 Watch 轮询到版本变化后调用 checker.Reload，保证各节点执行相同的规则
+type ConfigSync struct {
+	kv         storage.KVTransactional
+	checker    AntiAddictionChecker
+	retryTimes int
+
+	mu        sync.Mutex
+	version   int64
+	converter CurrencyConverter
+}
+
+// NewConfigSync 创建配置同步器
+// kv: 存放配置的 KV 存储，所有节点需使用同一个 key
+func NewConfigSync(kv storage.KVTransactional, checker AntiAddictionChecker) *ConfigSync {
+	return &ConfigSync{
+		kv:         kv,
+		checker:    checker,
+		retryTimes: defaultRecordRetryTimes,
+	}
+}
+
+// SetConverter 设置本节点的币种转换钩子，币种转换钩子无法序列化，同步到的配置会使用该钩子
+func (configSync *ConfigSync) SetConverter(converter CurrencyConverter) {
+	configSync.mu.Lock()
+	defer configSync.mu.Unlock()
+	configSync.converter = converter
+}
+
+// Version 返回本节点已应用的配置版本，0 表示尚未同步
+func (configSync *ConfigSync) Version() int64 {
+	configSync.mu.Lock()
+	defer configSync.mu.Unlock()
+	return configSync.version
+}
+
+// Publish 校验并发布新配置，版本号在存储中的版本上递增，返回发布后的版本号；
+// 发布后本节点同样需经 Sync 或 Watch 应用
+func (configSync *ConfigSync) Publish(ctx context.Context, config Config) (int64, error) {
+	if err := config.Validate(); err != nil {
+		return 0, err
+	}
+	var err error
+	for i := 0; i <= configSync.retryTimes; i++ {
+		var version int64
+		version, err = configSync.tryPublish(ctx, config)
+		if !errors.Is(err, storage.ErrTransactionConflict) {
+			return version, err
+		}
+	}
+	return 0, err
+}
+
+func (configSync *ConfigSync) tryPublish(ctx context.Context, config Config) (int64, error) {
+	var version int64
+	current := &syncedConfig{}
+	err := configSync.kv.Update(ctx, current, func(found bool) (storage.StorageData, error) {
+		version = current.Version + 1
+		return &syncedConfig{Version: version, Config: config}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Sync 读取存储中的配置，版本比已应用的新时调用 checker.Reload
+// 返回值：是否应用了新配置；存储中没有配置时返回 false
+func (configSync *ConfigSync) Sync(ctx context.Context) (bool, error) {
+	synced := &syncedConfig{}
+	if err := configSync.kv.Get(ctx, synced); err != nil {
+		if errors.Is(err, storage.ErrFieldNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	configSync.mu.Lock()
+	defer configSync.mu.Unlock()
+	if synced.Version <= configSync.version {
+		return false, nil
+	}
+	synced.Config.PurchaseConfig.Converter = configSync.converter
+	if err := configSync.checker.Reload(synced.Config); err != nil {
+		return false, err
+	}
+	configSync.version = synced.Version
+	return true, nil
+}
+
+// Watch 立即同步一次，之后按 interval 轮询存储中的配置版本，变化时重载 checker；
+// 同步失败时保留旧配置并通过 onError 回调通知（可为 nil）。ctx 结束时停止监听
+func (configSync *ConfigSync) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := configSync.Sync(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package anti_addiction
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// fakeKV 基于内存实现的 storage.KVTransactional，Update 与提交时按版本号检测冲突
+type fakeKV struct {
+	mu      sync.Mutex
+	data    []byte
+	version int
+}
+
+func (kv *fakeKV) Set(ctx context.Context, value storage.StorageData) error {
+	data, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.data = data
+	kv.version++
+	return nil
+}
+
+func (kv *fakeKV) Get(ctx context.Context, dest storage.StorageData) error {
+	kv.mu.Lock()
+	data := kv.data
+	kv.mu.Unlock()
+	if data == nil {
+		return storage.ErrFieldNotFound
+	}
+	return dest.UnmarshalBinary(data)
+}
+
+func (kv *fakeKV) Update(ctx context.Context, dest storage.StorageData, fn func(found bool) (storage.StorageData, error)) error {
+	kv.mu.Lock()
+	data, version := kv.data, kv.version
+	kv.mu.Unlock()
+	if data != nil {
+		if err := dest.UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+	next, err := fn(data != nil)
+	if err != nil || next == nil {
+		return err
+	}
+	value, err := next.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if kv.version != version {
+		return storage.ErrTransactionConflict
+	}
+	kv.data = value
+	kv.version++
+	return nil
+}
+
+func (kv *fakeKV) BeginTx(ctx context.Context) (storage.KVTransaction, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	return &fakeKVTx{kv: kv, snapshot: kv.data, version: kv.version}, nil
+}
+
+type fakeKVTx struct {
+	kv       *fakeKV
+	snapshot []byte
+	write    []byte
+	version  int
+}
+
+func (tx *fakeKVTx) Set(value storage.StorageData) error {
+	data, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tx.write = data
+	return nil
+}
+
+func (tx *fakeKVTx) Get(dest storage.StorageData) error {
+	data := tx.snapshot
+	if tx.write != nil {
+		data = tx.write
+	}
+	if data == nil {
+		return storage.ErrFieldNotFound
+	}
+	return dest.UnmarshalBinary(data)
+}
+
+func (tx *fakeKVTx) Commit(ctx context.Context) error {
+	tx.kv.mu.Lock()
+	defer tx.kv.mu.Unlock()
+	if tx.kv.version != tx.version {
+		return storage.ErrTransactionConflict
+	}
+	if tx.write != nil {
+		tx.kv.data = tx.write
+		tx.kv.version++
+	}
+	return nil
+}
+
+func (tx *fakeKVTx) Rollback() {}
+
+func TestConfigSync(t *testing.T) {
+	ctx := context.Background()
+	kv := &fakeKV{}
+
+	nodes := make([]AntiAddictionChecker, 2)
+	syncs := make([]*ConfigSync, 2)
+	for i := range nodes {
+		checker, err := NewAntiAddictionChecker(DefaultConfig())
+		if err != nil {
+			t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+		}
+		nodes[i] = checker
+		syncs[i] = NewConfigSync(kv, checker)
+	}
+
+	// 存储中没有配置时不重载
+	if applied, err := syncs[0].Sync(ctx); err != nil || applied {
+		t.Fatalf("Sync() empty = %v, %v, want false", applied, err)
+	}
+
+	// 星期三 20:30
+	wednesday := time.Date(2025, 4, 2, 20, 30, 0, 0, time.Local)
+	config := DefaultConfig()
+	config.TimeConfig.Holidays = append(config.TimeConfig.Holidays, Holiday{Month: 4, Day: 2})
+	version, err := syncs[0].Publish(ctx, config)
+	if err != nil || version != 1 {
+		t.Fatalf("Publish() = %d, %v, want 1", version, err)
+	}
+
+	for i, configSync := range syncs {
+		if applied, err := configSync.Sync(ctx); err != nil || !applied {
+			t.Errorf("node %d Sync() = %v, %v, want true", i, applied, err)
+		}
+		if !nodes[i].IsInPlayTimeAt(14, wednesday) {
+			t.Errorf("node %d IsInPlayTimeAt() = false, want true after sync", i)
+		}
+		if applied, err := configSync.Sync(ctx); err != nil || applied {
+			t.Errorf("node %d Sync() unchanged = %v, %v, want false", i, applied, err)
+		}
+	}
+
+	config.TimeConfig.StartHour = 25
+	if _, err = syncs[1].Publish(ctx, config); err == nil {
+		t.Errorf("Publish() invalid config error = nil, want error")
+	}
+	if version, err = syncs[1].Publish(ctx, DefaultConfig()); err != nil || version != 2 {
+		t.Fatalf("Publish() = %d, %v, want 2", version, err)
+	}
+	if _, err = syncs[0].Sync(ctx); err != nil || syncs[0].Version() != 2 {
+		t.Errorf("Sync() version = %d, %v, want 2", syncs[0].Version(), err)
+	}
+	if nodes[0].IsInPlayTimeAt(14, wednesday) {
+		t.Errorf("IsInPlayTimeAt() = true, want false after holiday removed")
+	}
+}
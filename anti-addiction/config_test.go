@@ -0,0 +1,303 @@
+package anti_addiction
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAntiAddictionChecker_Reload(t *testing.T) {
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+
+	if checker.CheckSinglePurchase(6000, 10) {
+		t.Fatalf("CheckSinglePurchase() before reload = true, want false")
+	}
+
+	newPurchaseConfig := getDefaultPurchaseConfig()
+	newPurchaseConfig.AgeLimits[1].Limit.SingleLimit = 8000
+	err = checker.Reload(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: newPurchaseConfig,
+	})
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if !checker.CheckSinglePurchase(6000, 10) {
+		t.Errorf("CheckSinglePurchase() after reload = false, want true")
+	}
+
+	// 非法配置不生效，保留旧配置
+	invalidPurchaseConfig := getDefaultPurchaseConfig()
+	invalidPurchaseConfig.AgeLimits[1].MinAge = 6
+	err = checker.Reload(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: invalidPurchaseConfig,
+	})
+	if err == nil {
+		t.Fatalf("Reload() with invalid config error = nil, want error")
+	}
+	if !checker.CheckSinglePurchase(6000, 10) {
+		t.Errorf("CheckSinglePurchase() after invalid reload = false, want true")
+	}
+}
+
+func TestAntiAddictionChecker_ReloadConcurrent(t *testing.T) {
+	config := Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	}
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = checker.Reload(config)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				checker.CheckMonthlyPurchase(100, 100, 10)
+				checker.IsInPlayTime(10)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "anti_addiction.yaml")
+	yamlContent := `
+time-config:
+  start-hour: 19
+  end-hour: 21
+  allowed-week-days: [0, 6]
+purchase-config:
+  age-limits:
+    - min-age: 0
+      max-age: 18
+      limit:
+        single-limit: 100
+        monthly-limit: 1000
+`
+	if err := os.WriteFile(yamlPath, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadConfig(yaml) error = %v", err)
+	}
+	if config.TimeConfig.StartHour != 19 || len(config.TimeConfig.AllowedWeekDays) != 2 {
+		t.Errorf("LoadConfig(yaml) time config = %+v", config.TimeConfig)
+	}
+	if len(config.TimeConfig.Holidays) != len(DefaultConfig().TimeConfig.Holidays) {
+		t.Errorf("LoadConfig(yaml) holidays = %v, want defaults", config.TimeConfig.Holidays)
+	}
+	if len(config.PurchaseConfig.AgeLimits) != 1 || config.PurchaseConfig.AgeLimits[0].Limit.MonthlyLimit != 1000 {
+		t.Errorf("LoadConfig(yaml) purchase config = %+v", config.PurchaseConfig)
+	}
+
+	jsonPath := filepath.Join(dir, "anti_addiction.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"time_config":{"start_hour":20,"end_hour":22}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err = LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("LoadConfig(json) error = %v", err)
+	}
+	if config.TimeConfig.EndHour != 22 {
+		t.Errorf("LoadConfig(json) EndHour = %d, want 22", config.TimeConfig.EndHour)
+	}
+
+	badPath := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badPath, []byte(`{"time_config":{"start_hour":24}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadConfig(badPath); err == nil {
+		t.Errorf("LoadConfig(bad) error = nil, want error")
+	}
+}
+
+func TestLoadConfig_Profile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "korea.yaml")
+	if err := os.WriteFile(path, []byte("profile: korea-shutdown-repealed\nguest-config:\n  trial-seconds: 600\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Profile != ProfileKoreaShutdownRepealed || config.PurchaseConfig.Currency != "KRW" || config.GuestConfig.TrialSeconds != 600 {
+		t.Errorf("LoadConfig() = %+v", config)
+	}
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	// 星期一凌晨 3 点
+	monday := time.Date(2025, 3, 31, 3, 0, 0, 0, time.Local)
+	if !checker.IsInPlayTimeAt(10, monday) {
+		t.Errorf("IsInPlayTimeAt(10, %v) = false, want true", monday)
+	}
+	if !checker.CheckSinglePurchase(1000000, 10) {
+		t.Errorf("CheckSinglePurchase(1000000, 10) = false, want true")
+	}
+
+	unknownPath := filepath.Join(dir, "unknown.yaml")
+	if err := os.WriteFile(unknownPath, []byte("profile: mars\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadConfig(unknownPath); err == nil {
+		t.Errorf("LoadConfig(unknown profile) error = nil, want error")
+	}
+
+	if err = RegisterProfile("test-custom", func() Config {
+		config := DefaultConfig()
+		config.TimeConfig.StartHour = 19
+		return config
+	}); err != nil {
+		t.Fatalf("RegisterProfile() error = %v", err)
+	}
+	config, err = ProfileConfig("test-custom")
+	if err != nil || config.TimeConfig.StartHour != 19 || config.Profile != "test-custom" {
+		t.Errorf("ProfileConfig(test-custom) = %+v, %v", config, err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(config *Config)
+		wantErr bool
+	}{
+		{
+			name:    "默认配置",
+			modify:  func(config *Config) {},
+			wantErr: false,
+		},
+		{
+			name:    "小时越界",
+			modify:  func(config *Config) { config.TimeConfig.EndHour = 24 },
+			wantErr: true,
+		},
+		{
+			name:    "开始晚于结束",
+			modify:  func(config *Config) { config.TimeConfig.StartHour = 22 },
+			wantErr: true,
+		},
+		{
+			name:    "非法星期",
+			modify:  func(config *Config) { config.TimeConfig.AllowedWeekDays = []time.Weekday{7} },
+			wantErr: true,
+		},
+		{
+			name:    "非法节假日",
+			modify:  func(config *Config) { config.TimeConfig.Holidays = Holidays{{Month: 2, Day: 30}} },
+			wantErr: true,
+		},
+		{
+			name: "年龄段重叠",
+			modify: func(config *Config) {
+				config.PurchaseConfig.AgeLimits[1].MinAge = 7
+			},
+			wantErr: true,
+		},
+		{
+			name:    "成年年龄为负",
+			modify:  func(config *Config) { config.AdultAge = -1 },
+			wantErr: true,
+		},
+		{
+			name: "年龄段上下限颠倒",
+			modify: func(config *Config) {
+				config.PurchaseConfig.AgeLimits[2].MaxAge = 16
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAntiAddictionChecker_AdultAge(t *testing.T) {
+	config := DefaultConfig()
+	config.AdultAge = 19
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	// 星期一 10:00，未成年人不可游玩
+	now := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+
+	if checker.AdultAge() != 19 {
+		t.Errorf("AdultAge() = %d, want 19", checker.AdultAge())
+	}
+	if checker.IsInPlayTimeAt(18, now) || checker.GetPlayEndTimeAt(18, now) != -1 {
+		t.Errorf("IsInPlayTime(18) with adult age 19 = true, want false")
+	}
+	if !checker.IsInPlayTimeAt(19, now) || checker.GetPlayEndTimeAt(19, now) != 0 {
+		t.Errorf("IsInPlayTime(19) with adult age 19 = false, want true")
+	}
+	if got := checker.AgeBracket(18); got != AgeBracketUnknown {
+		t.Errorf("AgeBracket(18) = %q, want %q", got, AgeBracketUnknown)
+	}
+
+	// 重载为默认配置后恢复 18 岁
+	if err = checker.Reload(DefaultConfig()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if !checker.IsInPlayTimeAt(18, now) || checker.AdultAge() != DefaultAdultAge {
+		t.Errorf("IsInPlayTime(18) after reload = false, want true")
+	}
+}
+
+func TestNewAntiAddictionChecker_Profile(t *testing.T) {
+	// 未加载文件，直接传入预设名称与部分配置
+	checker, err := NewAntiAddictionChecker(Config{Profile: ProfileKoreaShutdownRepealed, GuestConfig: GuestConfig{TrialSeconds: 600}})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	monday := time.Date(2025, 3, 31, 3, 0, 0, 0, time.Local)
+	if !checker.IsInPlayTimeAt(10, monday) || !checker.CheckSinglePurchase(1000000, 10) {
+		t.Errorf("profile %s not applied", ProfileKoreaShutdownRepealed)
+	}
+
+	// Reload 同样应用预设
+	if err = checker.Reload(Config{Profile: ProfileChinaNPPA}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if checker.IsInPlayTimeAt(10, monday) || checker.CheckSinglePurchase(1000000, 10) {
+		t.Errorf("profile %s not applied on Reload", ProfileChinaNPPA)
+	}
+	if err = checker.Reload(Config{Profile: "mars"}); err == nil {
+		t.Errorf("Reload(unknown profile) error = nil, want error")
+	}
+}
@@ -0,0 +1,102 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"time"
+)
+
+// Curfew 宵禁规则：指定年龄段在该时间段内一律不可游玩，叠加在可游玩时间段检查之上。
+// 结束时间早于开始时间表示跨越零点，如 22:00-08:00
+type Curfew struct {
+	// 年龄下限（包含）
+	MinAge int32 `json:"min_age" yaml:"min-age"`
+	// 年龄上限（不包含）
+	MaxAge      int32 `json:"max_age" yaml:"max-age"`
+	StartHour   int   `json:"start_hour" yaml:"start-hour"`
+	StartMinute int   `json:"start_minute" yaml:"start-minute"`
+	EndHour     int   `json:"end_hour" yaml:"end-hour"`
+	EndMinute   int   `json:"end_minute" yaml:"end-minute"`
+}
+
+func (curfew Curfew) appliesTo(age int32) bool {
+	return age >= curfew.MinAge && age < curfew.MaxAge
+}
+
+func (curfew Curfew) startSeconds() int {
+	return curfew.StartHour*3600 + curfew.StartMinute*60
+}
+
+func (curfew Curfew) endSeconds() int {
+	return curfew.EndHour*3600 + curfew.EndMinute*60
+}
+
+// contains 检查指定时刻是否处于宵禁时间段内，开始时间包含、结束时间不包含
+func (curfew Curfew) contains(t time.Time) bool {
+	current := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	start, end := curfew.startSeconds(), curfew.endSeconds()
+	if start < end {
+		return current >= start && current < end
+	}
+	return current >= start || current < end
+}
+
+// nextStart 返回 t 之后（包含 t）最近一次宵禁开始的时刻
+func (curfew Curfew) nextStart(t time.Time) time.Time {
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := today.Add(time.Duration(curfew.startSeconds()) * time.Second)
+	if start.Before(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// Validate 校验宵禁规则
+func (curfew Curfew) Validate() error {
+	if curfew.MinAge < 0 || curfew.MinAge >= curfew.MaxAge {
+		return fmt.Errorf("anti-addiction: invalid curfew age bracket [%d, %d)", curfew.MinAge, curfew.MaxAge)
+	}
+	if err := validateClock("curfew start", curfew.StartHour, curfew.StartMinute, 0); err != nil {
+		return err
+	}
+	if err := validateClock("curfew end", curfew.EndHour, curfew.EndMinute, 0); err != nil {
+		return err
+	}
+	if curfew.startSeconds() == curfew.endSeconds() {
+		return fmt.Errorf("anti-addiction: curfew start and end are both %02d:%02d", curfew.StartHour, curfew.StartMinute)
+	}
+	return nil
+}
+
+// IsInCurfew 检查指定年龄在指定时刻是否处于宵禁时间段内
+func (checker *AntiAddictionTimeChecker) IsInCurfew(age int32, t time.Time) bool {
+	for _, curfew := range checker.timeRange.Curfews {
+		if curfew.appliesTo(age) && curfew.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCurfew 是否有适用于指定年龄的宵禁规则
+func (checker *AntiAddictionTimeChecker) hasCurfew(age int32) bool {
+	for _, curfew := range checker.timeRange.Curfews {
+		if curfew.appliesTo(age) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextCurfewStart 返回指定年龄在 t 之后最近一次宵禁开始的时刻，没有适用的宵禁规则时 ok 为 false
+func (checker *AntiAddictionTimeChecker) nextCurfewStart(age int32, t time.Time) (next time.Time, ok bool) {
+	for _, curfew := range checker.timeRange.Curfews {
+		if !curfew.appliesTo(age) {
+			continue
+		}
+		start := curfew.nextStart(t)
+		if !ok || start.Before(next) {
+			next, ok = start, true
+		}
+	}
+	return
+}
@@ -0,0 +1,86 @@
+package anti_addiction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAntiAddictionTimeChecker_Curfew(t *testing.T) {
+	config := getTestTimeConfig()
+	config.EndHour = 23
+	config.Curfews = []Curfew{
+		// 16岁以下 22:00-08:00 宵禁
+		{MinAge: 0, MaxAge: 16, StartHour: 22, EndHour: 8},
+		// 所有人 03:00-05:00 维护宵禁
+		{MinAge: 0, MaxAge: 200, StartHour: 3, EndHour: 5},
+	}
+	checker := NewAntiAddictionTimeChecker(config)
+	saturday := time.Date(2025, 3, 29, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		age        int32
+		at         time.Time
+		wantInPlay bool
+		wantEnd    int64
+	}{
+		{
+			name:       "15岁-宵禁前-结束时间提前到宵禁开始",
+			age:        15,
+			at:         saturday.Add(21 * time.Hour),
+			wantInPlay: true,
+			wantEnd:    saturday.Add(22 * time.Hour).UnixMilli(),
+		},
+		{
+			name:       "15岁-宵禁中",
+			age:        15,
+			at:         saturday.Add(22*time.Hour + 30*time.Minute),
+			wantInPlay: false,
+			wantEnd:    -1,
+		},
+		{
+			name:       "16岁-不受22点宵禁影响",
+			age:        16,
+			at:         saturday.Add(22*time.Hour + 30*time.Minute),
+			wantInPlay: true,
+			wantEnd:    saturday.Add(23 * time.Hour).UnixMilli(),
+		},
+		{
+			name:       "成年人-结束时间为下一次宵禁开始",
+			age:        20,
+			at:         saturday.Add(12 * time.Hour),
+			wantInPlay: true,
+			wantEnd:    saturday.AddDate(0, 0, 1).Add(3 * time.Hour).UnixMilli(),
+		},
+		{
+			name:       "成年人-宵禁中",
+			age:        20,
+			at:         saturday.Add(4 * time.Hour),
+			wantInPlay: false,
+			wantEnd:    -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.IsInPlayTimeAt(tt.age, tt.at); got != tt.wantInPlay {
+				t.Errorf("IsInPlayTimeAt() = %v, want %v", got, tt.wantInPlay)
+			}
+			if got := checker.GetPlayEndTimeAt(tt.age, tt.at); got != tt.wantEnd {
+				t.Errorf("GetPlayEndTimeAt() = %d, want %d", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCurfew_Validate(t *testing.T) {
+	if err := (Curfew{MinAge: 0, MaxAge: 18, StartHour: 22, EndHour: 8}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := (Curfew{MinAge: 0, MaxAge: 18, StartHour: 22, EndHour: 22}).Validate(); err == nil {
+		t.Errorf("Validate() empty curfew error = nil, want error")
+	}
+	if err := (Curfew{MinAge: 0, MaxAge: 18, StartHour: 25, EndHour: 8}).Validate(); err == nil {
+		t.Errorf("Validate() invalid hour error = nil, want error")
+	}
+}
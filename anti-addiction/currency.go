@@ -0,0 +1,57 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"sync"
+)
+
+// CurrencyCodeCNY 人民币币种代码，未配置币种时的默认值
+const CurrencyCodeCNY = "CNY"
+
+// Currency 币种定义
+type Currency struct {
+	// Code ISO 4217 币种代码
+	Code string
+	// MinorUnit 1个主单位等于多少个最小单位，如人民币 1元=100分、韩元无辅币为 1
+	MinorUnit int64
+}
+
+// MajorUnit 返回该币种主单位对应的 MoneyUnit，用于以元、美元等主单位传入金额
+func (currency Currency) MajorUnit() MoneyUnit {
+	return MoneyUnit(currency.MinorUnit)
+}
+
+// CurrencyConverter 币种转换钩子，将 from 币种的最小单位金额转换为 to 币种的最小单位金额
+type CurrencyConverter func(amount int64, from, to Currency) (int64, error)
+
+var (
+	currencyMu sync.RWMutex
+	currencies = map[string]Currency{
+		"CNY": {Code: "CNY", MinorUnit: 100},
+		"USD": {Code: "USD", MinorUnit: 100},
+		"EUR": {Code: "EUR", MinorUnit: 100},
+		"HKD": {Code: "HKD", MinorUnit: 100},
+		"TWD": {Code: "TWD", MinorUnit: 100},
+		"JPY": {Code: "JPY", MinorUnit: 1},
+		"KRW": {Code: "KRW", MinorUnit: 1},
+	}
+)
+
+// RegisterCurrency 注册或覆盖币种定义
+func RegisterCurrency(currency Currency) error {
+	if currency.Code == "" || currency.MinorUnit <= 0 {
+		return fmt.Errorf("anti-addiction: invalid currency %+v", currency)
+	}
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	currencies[currency.Code] = currency
+	return nil
+}
+
+// LookupCurrency 根据币种代码查找币种定义
+func LookupCurrency(code string) (Currency, bool) {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	currency, ok := currencies[code]
+	return currency, ok
+}
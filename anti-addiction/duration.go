@@ -0,0 +1,174 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// DurationLimit 定义年龄段的每日累计游戏时长上限
+type DurationLimit struct {
+	// 年龄下限（包含）
+	MinAge int32 `json:"min_age" yaml:"min-age"`
+	// 年龄上限（不包含）
+	MaxAge int32 `json:"max_age" yaml:"max-age"`
+	// 每日累计游戏时长上限（秒），0 或 -1 表示不限制
+	DailySeconds int64 `json:"daily_seconds" yaml:"daily-seconds"`
+}
+
+// DurationConfig 累计游戏时长配置，未配置的年龄段不限制时长
+type DurationConfig struct {
+	Limits []DurationLimit `json:"limits" yaml:"limits"`
+}
+
+// Validate 校验累计时长配置
+func (config DurationConfig) Validate() error {
+	for _, limit := range config.Limits {
+		if limit.MinAge < 0 || limit.MinAge >= limit.MaxAge {
+			return fmt.Errorf("anti-addiction: invalid duration age bracket [%d, %d)", limit.MinAge, limit.MaxAge)
+		}
+		if limit.DailySeconds < -1 {
+			return fmt.Errorf("anti-addiction: invalid daily seconds %d for age bracket [%d, %d)",
+				limit.DailySeconds, limit.MinAge, limit.MaxAge)
+		}
+	}
+	return nil
+}
+
+// GetDailyDurationLimit 获取指定年龄的每日累计游戏时长上限（秒），-1 表示不限制
+func (config DurationConfig) GetDailyDurationLimit(age int32) int64 {
+	for _, limit := range config.Limits {
+		if age >= limit.MinAge && age < limit.MaxAge && limit.DailySeconds > 0 {
+			return limit.DailySeconds
+		}
+	}
+	return -1
+}
+
+// DurationTracker 玩家每日累计游戏时长记录
+type DurationTracker interface {
+	// GetDailyPlayed 获取玩家在 day 所在自然日的累计游戏时长
+	GetDailyPlayed(ctx context.Context, playerID int64, day time.Time) (time.Duration, error)
+	// AddDailyPlayed 累加玩家在 day 所在自然日的游戏时长
+	AddDailyPlayed(ctx context.Context, playerID int64, day time.Time, played time.Duration) error
+}
+
+// durationRecord 玩家某一自然日的累计游戏时长
+type durationRecord struct {
+	// 记录所属日期，格式 20060102
+	Day string `json:"day"`
+	// 累计游戏时长（毫秒）
+	PlayedMs int64 `json:"played_ms"`
+}
+
+func (record *durationRecord) MarshalBinary() ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (record *durationRecord) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, record)
+}
+
+// memoryDurationTracker 基于内存的累计时长记录，适用于单机或测试
+type memoryDurationTracker struct {
+	mu      sync.Mutex
+	records map[int64]durationRecord
+}
+
+// NewMemoryDurationTracker 创建基于内存的累计时长记录
+func NewMemoryDurationTracker() DurationTracker {
+	return &memoryDurationTracker{records: make(map[int64]durationRecord)}
+}
+
+func (tracker *memoryDurationTracker) GetDailyPlayed(ctx context.Context, playerID int64, day time.Time) (time.Duration, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	record := tracker.records[playerID]
+	if record.Day != day.Format("20060102") {
+		return 0, nil
+	}
+	return time.Duration(record.PlayedMs) * time.Millisecond, nil
+}
+
+func (tracker *memoryDurationTracker) AddDailyPlayed(ctx context.Context, playerID int64, day time.Time, played time.Duration) error {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	record := tracker.records[playerID]
+	if dayStr := day.Format("20060102"); record.Day != dayStr {
+		record = durationRecord{Day: dayStr}
+	}
+	record.PlayedMs += played.Milliseconds()
+	tracker.records[playerID] = record
+	return nil
+}
+
+// storageDurationTracker 基于 global-storage hash 的累计时长记录，以玩家ID为field
+type storageDurationTracker struct {
+	hash       storage.HashTransactional
+	retryTimes int
+}
+
+// NewStorageDurationTracker 创建基于 global-storage 的累计时长记录
+// hash: 需以 NewDurationRecordFactory 作为数据工厂注册的 hash 存储
+func NewStorageDurationTracker(hash storage.HashTransactional) DurationTracker {
+	return &storageDurationTracker{hash: hash, retryTimes: defaultRecordRetryTimes}
+}
+
+// NewDurationRecordFactory 返回累计时长记录的数据工厂，用于注册 hash 存储
+func NewDurationRecordFactory() storage.StorageData {
+	return &durationRecord{}
+}
+
+func (tracker *storageDurationTracker) GetDailyPlayed(ctx context.Context, playerID int64, day time.Time) (time.Duration, error) {
+	data, err := tracker.hash.HGet(ctx, strconv.FormatInt(playerID, 10))
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	record, ok := data.(*durationRecord)
+	if !ok {
+		return 0, errors.New("anti-addiction: unexpected duration record type")
+	}
+	if record.Day != day.Format("20060102") {
+		return 0, nil
+	}
+	return time.Duration(record.PlayedMs) * time.Millisecond, nil
+}
+
+// AddDailyPlayed 通过 HUpdate 比对该玩家的记录在读取后未被修改再写回，并发累加时按 retryTimes 重试，不会丢失其他节点的累加
+func (tracker *storageDurationTracker) AddDailyPlayed(ctx context.Context, playerID int64, day time.Time, played time.Duration) error {
+	field := strconv.FormatInt(playerID, 10)
+	var err error
+	for i := 0; i <= tracker.retryTimes; i++ {
+		err = tracker.tryAdd(ctx, field, day.Format("20060102"), played)
+		if !errors.Is(err, storage.ErrTransactionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func (tracker *storageDurationTracker) tryAdd(ctx context.Context, field string, day string, played time.Duration) error {
+	return tracker.hash.HUpdate(ctx, field, func(current storage.StorageData) (storage.StorageData, error) {
+		record := &durationRecord{}
+		if current != nil {
+			var ok bool
+			if record, ok = current.(*durationRecord); !ok {
+				return nil, errors.New("anti-addiction: unexpected duration record type")
+			}
+		}
+		if record.Day != day {
+			record = &durationRecord{Day: day}
+		}
+		record.PlayedMs += played.Milliseconds()
+		return record, nil
+	})
+}
@@ -0,0 +1,71 @@
+package anti_addiction
+
+import (
+	"sync"
+)
+
+// Exemptions 豁免名单，名单内的账号（如测试、GM账号）不受时间与充值限制，可在运行时更新，并发安全；
+// 零值为空名单，可直接使用
+type Exemptions struct {
+	mu        sync.RWMutex
+	ids       map[int64]struct{}
+	predicate func(accountID int64) bool
+}
+
+// NewExemptions 创建豁免名单
+func NewExemptions(accountIDs ...int64) *Exemptions {
+	exemptions := &Exemptions{}
+	exemptions.Replace(accountIDs)
+	return exemptions
+}
+
+// Replace 整体替换名单中的账号，用于从配置中心等重新加载
+func (exemptions *Exemptions) Replace(accountIDs []int64) {
+	ids := make(map[int64]struct{}, len(accountIDs))
+	for _, accountID := range accountIDs {
+		ids[accountID] = struct{}{}
+	}
+	exemptions.mu.Lock()
+	defer exemptions.mu.Unlock()
+	exemptions.ids = ids
+}
+
+// Add 添加豁免账号
+func (exemptions *Exemptions) Add(accountIDs ...int64) {
+	exemptions.mu.Lock()
+	defer exemptions.mu.Unlock()
+	if exemptions.ids == nil {
+		exemptions.ids = make(map[int64]struct{}, len(accountIDs))
+	}
+	for _, accountID := range accountIDs {
+		exemptions.ids[accountID] = struct{}{}
+	}
+}
+
+// Remove 移除豁免账号
+func (exemptions *Exemptions) Remove(accountIDs ...int64) {
+	exemptions.mu.Lock()
+	defer exemptions.mu.Unlock()
+	for _, accountID := range accountIDs {
+		delete(exemptions.ids, accountID)
+	}
+}
+
+// SetPredicate 设置额外的豁免判断，如按账号号段识别测试账号，为 nil 时仅使用名单
+func (exemptions *Exemptions) SetPredicate(predicate func(accountID int64) bool) {
+	exemptions.mu.Lock()
+	defer exemptions.mu.Unlock()
+	exemptions.predicate = predicate
+}
+
+// IsExempt 账号是否豁免，名单为 nil 时返回 false
+func (exemptions *Exemptions) IsExempt(accountID int64) bool {
+	if exemptions == nil {
+		return false
+	}
+	exemptions.mu.RLock()
+	_, ok := exemptions.ids[accountID]
+	predicate := exemptions.predicate
+	exemptions.mu.RUnlock()
+	return ok || (predicate != nil && predicate(accountID))
+}
@@ -0,0 +1,86 @@
+package anti_addiction
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExemptions(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultConfig()
+	config.TimeConfig = getTestTimeConfig()
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	exemptions := NewExemptions(1)
+	exemptions.SetPredicate(func(accountID int64) bool { return accountID >= 9000 })
+	checker.SetExemptions(exemptions)
+
+	// 星期一 10:00
+	monday := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+	checker.(*antiAddictionChecker).SetTimeNow(func() time.Time { return monday })
+
+	tests := []struct {
+		name       string
+		accountID  int64
+		wantResult bool
+	}{
+		{name: "名单内", accountID: 1, wantResult: true},
+		{name: "号段豁免", accountID: 9001, wantResult: true},
+		{name: "普通账号", accountID: 2, wantResult: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := checker.IsInPlayTimeForAccount(ctx, tt.accountID, 14); err != nil || got != tt.wantResult {
+				t.Errorf("IsInPlayTimeForAccount() = %v, %v, want %v", got, err, tt.wantResult)
+			}
+		})
+	}
+
+	// 运行时重新加载名单
+	exemptions.Replace([]int64{2})
+	if got, _ := checker.IsInPlayTimeForAccount(ctx, 1, 14); got {
+		t.Errorf("IsInPlayTimeForAccount(1) after replace = true, want false")
+	}
+	if got, _ := checker.IsInPlayTimeForAccount(ctx, 2, 14); !got {
+		t.Errorf("IsInPlayTimeForAccount(2) after replace = false, want true")
+	}
+
+	players := []PlayerAge{{PlayerID: 1, Age: 14}, {PlayerID: 2, Age: 14}}
+	if kickList := checker.ComputeKickList(players, monday); !slices.Equal(kickList, []PlayerID{1}) {
+		t.Errorf("ComputeKickList() = %v, want [1]", kickList)
+	}
+
+	manager := NewSessionManager(checker, NewMemoryDurationTracker())
+	manager.SetTimeNow(func() time.Time { return monday })
+	if remaining, err := manager.StartSession(ctx, 2, 14); err != nil || remaining != -1 {
+		t.Errorf("StartSession() exempt = %d, %v, want -1", remaining, err)
+	}
+	if _, err = manager.StartSession(ctx, 3, 14); !errors.Is(err, ErrPlayNotAllowed) {
+		t.Errorf("StartSession() error = %v, want ErrPlayNotAllowed", err)
+	}
+
+	recorder := NewPurchaseRecorder(checker, newFakeHash(NewPurchaseRecordFactory))
+	recorder.SetExemptions(exemptions)
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 2, 100000, 6); err != nil || !allowed {
+		t.Errorf("CheckAndRecordPurchase() exempt = %v, %v, want true", allowed, err)
+	}
+	if total, _ := recorder.GetMonthlyTotal(ctx, 2); total != 100000 {
+		t.Errorf("GetMonthlyTotal() exempt = %d, want 100000", total)
+	}
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 3, 100, 6); err != nil || allowed {
+		t.Errorf("CheckAndRecordPurchase() = %v, %v, want false", allowed, err)
+	}
+}
+
+func TestExemptions_ZeroValue(t *testing.T) {
+	exemptions := &Exemptions{}
+	exemptions.Add(1)
+	if !exemptions.IsExempt(1) || exemptions.IsExempt(2) {
+		t.Errorf("IsExempt() after Add on zero value = %v, %v, want true, false", exemptions.IsExempt(1), exemptions.IsExempt(2))
+	}
+}
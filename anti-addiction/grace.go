@@ -0,0 +1,209 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// AgePending 实名认证进行中、年龄未知时传入的年龄
+const AgePending int32 = -1
+
+// GraceConfig 实名认证等待期配置
+type GraceConfig struct {
+	// 等待期可游玩时长（秒），0 表示不开启等待期
+	DurationSeconds int64 `json:"duration_seconds" yaml:"duration-seconds"`
+}
+
+// GraceRecord 账号的等待期记录，每个账号只能开启一次
+type GraceRecord struct {
+	// 等待期开始时间戳（毫秒），0 表示尚未开启
+	StartAt int64 `json:"start_at"`
+}
+
+func (record *GraceRecord) MarshalBinary() ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (record *GraceRecord) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, record)
+}
+
+// GraceStore 等待期记录存储，记录不存在时返回零值记录
+type GraceStore interface {
+	Load(ctx context.Context, accountID int64) (GraceRecord, error)
+	// Start 账号尚未开启等待期时原子地写入开始时间戳（毫秒）并返回 true，已开启时不修改并返回 false
+	Start(ctx context.Context, accountID int64, startAt int64) (bool, error)
+}
+
+// GraceChecker 实名认证等待期检查器，认证提交后到结果返回前给予一次性的临时游玩时长
+type GraceChecker struct {
+	config  atomic.Pointer[GraceConfig]
+	store   GraceStore
+	timeNow func() time.Time
+}
+
+// NewGraceChecker 创建实名认证等待期检查器；通过 AntiAddictionChecker.SetGraceChecker 设置后，
+// 等待期配置以 Config.GraceConfig 为准并随 Reload 更新
+func NewGraceChecker(config GraceConfig, store GraceStore) *GraceChecker {
+	checker := &GraceChecker{
+		store:   store,
+		timeNow: time.Now,
+	}
+	checker.SetConfig(config)
+	return checker
+}
+
+// SetConfig 替换等待期配置，已开启的等待期按新的时长判断
+func (checker *GraceChecker) SetConfig(config GraceConfig) {
+	checker.config.Store(&config)
+}
+
+// duration 等待期可游玩时长，0 表示不开启等待期
+func (checker *GraceChecker) duration() time.Duration {
+	return time.Duration(max(checker.config.Load().DurationSeconds, 0)) * time.Second
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (checker *GraceChecker) SetTimeNow(timeNow func() time.Time) {
+	checker.timeNow = timeNow
+}
+
+// StartGracePeriod 为账号开启等待期，每个账号只能开启一次，并发开启时只有一方成功
+// 返回值：是否成功开启，已开启过或未配置等待期时返回 false
+func (checker *GraceChecker) StartGracePeriod(ctx context.Context, accountID int64) (bool, error) {
+	if checker.duration() <= 0 {
+		return false, nil
+	}
+	return checker.store.Start(ctx, accountID, checker.timeNow().UnixMilli())
+}
+
+// IsInGracePeriod 检查账号是否处于等待期内
+func (checker *GraceChecker) IsInGracePeriod(ctx context.Context, accountID int64) (bool, error) {
+	duration := checker.duration()
+	if duration <= 0 {
+		return false, nil
+	}
+	record, err := checker.store.Load(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+	if record.StartAt == 0 {
+		return false, nil
+	}
+	end := time.UnixMilli(record.StartAt).Add(duration)
+	return checker.timeNow().Before(end), nil
+}
+
+// GracePeriodEnd 账号等待期的结束时间戳（毫秒），未开启等待期或未配置等待期时返回 0
+func (checker *GraceChecker) GracePeriodEnd(ctx context.Context, accountID int64) (int64, error) {
+	duration := checker.duration()
+	if duration <= 0 {
+		return 0, nil
+	}
+	record, err := checker.store.Load(ctx, accountID)
+	if err != nil || record.StartAt == 0 {
+		return 0, err
+	}
+	return time.UnixMilli(record.StartAt).Add(duration).UnixMilli(), nil
+}
+
+// memoryGraceStore 基于内存的等待期记录存储，适用于单机或测试
+type memoryGraceStore struct {
+	mu      sync.RWMutex
+	records map[int64]GraceRecord
+}
+
+// NewMemoryGraceStore 创建基于内存的等待期记录存储
+func NewMemoryGraceStore() GraceStore {
+	return &memoryGraceStore{records: make(map[int64]GraceRecord)}
+}
+
+func (store *memoryGraceStore) Load(ctx context.Context, accountID int64) (GraceRecord, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.records[accountID], nil
+}
+
+func (store *memoryGraceStore) Start(ctx context.Context, accountID int64, startAt int64) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.records[accountID].StartAt > 0 {
+		return false, nil
+	}
+	store.records[accountID] = GraceRecord{StartAt: startAt}
+	return true, nil
+}
+
+// storageGraceStore 基于 global-storage hash 的等待期记录存储，以账号ID为field
+type storageGraceStore struct {
+	hash       storage.HashTransactional
+	retryTimes int
+}
+
+// NewStorageGraceStore 创建基于 global-storage 的等待期记录存储
+// hash: 需以 NewGraceRecordFactory 作为数据工厂注册的 hash 存储
+func NewStorageGraceStore(hash storage.HashTransactional) GraceStore {
+	return &storageGraceStore{hash: hash, retryTimes: defaultRecordRetryTimes}
+}
+
+// NewGraceRecordFactory 返回等待期记录的数据工厂，用于注册 hash 存储
+func NewGraceRecordFactory() storage.StorageData {
+	return &GraceRecord{}
+}
+
+func (store *storageGraceStore) Load(ctx context.Context, accountID int64) (GraceRecord, error) {
+	data, err := store.hash.HGet(ctx, strconv.FormatInt(accountID, 10))
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return GraceRecord{}, nil
+	}
+	if err != nil {
+		return GraceRecord{}, err
+	}
+	record, ok := data.(*GraceRecord)
+	if !ok {
+		return GraceRecord{}, errors.New("anti-addiction: unexpected grace record type")
+	}
+	return *record, nil
+}
+
+// Start 通过 HUpdate 比对记录在读取后未被修改，已开启时不写回；并发开启冲突时按 retryTimes 重新读取
+func (store *storageGraceStore) Start(ctx context.Context, accountID int64, startAt int64) (bool, error) {
+	field := strconv.FormatInt(accountID, 10)
+	var started bool
+	var err error
+	for i := 0; i <= store.retryTimes; i++ {
+		started, err = store.tryStart(ctx, field, startAt)
+		if !errors.Is(err, storage.ErrTransactionConflict) {
+			return started, err
+		}
+	}
+	return false, err
+}
+
+func (store *storageGraceStore) tryStart(ctx context.Context, field string, startAt int64) (bool, error) {
+	started := false
+	err := store.hash.HUpdate(ctx, field, func(current storage.StorageData) (storage.StorageData, error) {
+		if current != nil {
+			record, ok := current.(*GraceRecord)
+			if !ok {
+				return nil, errors.New("anti-addiction: unexpected grace record type")
+			}
+			if record.StartAt > 0 {
+				return nil, nil
+			}
+		}
+		started = true
+		return &GraceRecord{StartAt: startAt}, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return started, nil
+}
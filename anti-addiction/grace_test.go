@@ -0,0 +1,107 @@
+package anti_addiction
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGraceChecker(t *testing.T) {
+	ctx := context.Background()
+	grace := NewGraceChecker(GraceConfig{DurationSeconds: 600}, NewMemoryGraceStore())
+	// 周五 20:10，处于未成年人可游玩时间段内
+	now := time.Date(2025, 3, 28, 20, 10, 0, 0, time.Local)
+	grace.SetTimeNow(func() time.Time { return now })
+
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+		GraceConfig:    GraceConfig{DurationSeconds: 600},
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	checker.(*antiAddictionChecker).SetTimeNow(func() time.Time { return now })
+	checker.SetGraceChecker(grace)
+
+	if allowed, _ := checker.IsInPlayTimeForAccount(ctx, 1, AgePending); allowed {
+		t.Errorf("IsInPlayTimeForAccount() before grace = true, want false")
+	}
+	if _, reason := checker.IsInPlayTimeWithReason(AgePending); reason != DenyReasonVerificationPending {
+		t.Errorf("IsInPlayTimeWithReason(AgePending) reason = %v, want %v", reason, DenyReasonVerificationPending)
+	}
+
+	if started, err := grace.StartGracePeriod(ctx, 1); err != nil || !started {
+		t.Fatalf("StartGracePeriod() = %v, %v, want true", started, err)
+	}
+	if allowed, _ := checker.IsInPlayTimeForAccount(ctx, 1, AgePending); !allowed {
+		t.Errorf("IsInPlayTimeForAccount() in grace = false, want true")
+	}
+
+	// 等待期时长随 Reload 更新
+	if err = checker.Reload(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+		GraceConfig:    GraceConfig{DurationSeconds: 60},
+	}); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if allowed, _ := checker.IsInPlayTimeForAccount(ctx, 1, AgePending); allowed {
+		t.Errorf("IsInPlayTimeForAccount() after shortened grace = true, want false")
+	}
+
+	// 等待期结束后不可再次开启
+	now = now.Add(8 * time.Minute)
+	if inGrace, _ := grace.IsInGracePeriod(ctx, 1); inGrace {
+		t.Errorf("IsInGracePeriod() after expiry = true, want false")
+	}
+	if started, _ := grace.StartGracePeriod(ctx, 1); started {
+		t.Errorf("StartGracePeriod() twice = true, want false")
+	}
+
+	// 等待期内按未成年人的可游玩时间判断
+	now = time.Date(2025, 3, 28, 12, 0, 0, 0, time.Local)
+	if started, _ := grace.StartGracePeriod(ctx, 3); !started {
+		t.Fatalf("StartGracePeriod(3) = false, want true")
+	}
+	if allowed, _ := checker.IsInPlayTimeForAccount(ctx, 3, AgePending); allowed {
+		t.Errorf("IsInPlayTimeForAccount() in grace outside window = true, want false")
+	}
+
+	// 已实名账号按年龄判断
+	if allowed, _ := checker.IsInPlayTimeForAccount(ctx, 2, 20); !allowed {
+		t.Errorf("IsInPlayTimeForAccount() adult = false, want true")
+	}
+}
+
+func TestGraceStore_StartConcurrent(t *testing.T) {
+	stores := map[string]GraceStore{
+		"memory":  NewMemoryGraceStore(),
+		"storage": NewStorageGraceStore(newFakeHash(NewGraceRecordFactory)),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			grace := NewGraceChecker(GraceConfig{DurationSeconds: 600}, store)
+			var started atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if ok, err := grace.StartGracePeriod(ctx, 1); err == nil && ok {
+						started.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			// 并发开启只有一方成功
+			if started.Load() != 1 {
+				t.Errorf("started = %d, want 1", started.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// GuestConfig 游客模式配置：未实名账号在一个周期内按设备累计的试玩时长
+type GuestConfig struct {
+	// 周期内累计可试玩时长（秒），默认 3600 秒
+	TrialSeconds int64 `json:"trial_seconds" yaml:"trial-seconds"`
+	// 周期天数，从设备首次试玩开始计算，默认 15 天，周期结束后重新计算
+	PeriodDays int `json:"period_days" yaml:"period-days"`
+}
+
+// GuestRecord 设备的游客试玩记录
+type GuestRecord struct {
+	// 本周期首次试玩时间戳（毫秒）
+	FirstPlayAt int64 `json:"first_play_at"`
+	// 本周期已试玩时长（毫秒），按毫秒累计以免频繁的短时心跳被舍去
+	PlayedMs int64 `json:"played_ms"`
+}
+
+func (record *GuestRecord) MarshalBinary() ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (record *GuestRecord) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, record)
+}
+
+// GuestStore 游客试玩记录存储，记录不存在时返回零值记录
+type GuestStore interface {
+	Load(ctx context.Context, deviceID string) (GuestRecord, error)
+	// Update 原子地读取并修改设备记录，并发修改同一设备时不会丢失其他调用方的写入
+	Update(ctx context.Context, deviceID string, fn func(record GuestRecord) GuestRecord) error
+}
+
+// GuestChecker 游客模式试玩时长检查器
+type GuestChecker struct {
+	config  atomic.Pointer[GuestConfig]
+	store   GuestStore
+	timeNow func() time.Time
+}
+
+// NewGuestChecker 创建游客模式检查器，未配置的字段使用默认值；通过 AntiAddictionChecker.SetGuestChecker 设置后，
+// 游客配置以 Config.GuestConfig 为准并随 Reload 更新
+func NewGuestChecker(config GuestConfig, store GuestStore) *GuestChecker {
+	checker := &GuestChecker{
+		store:   store,
+		timeNow: time.Now,
+	}
+	checker.SetConfig(config)
+	return checker
+}
+
+// SetConfig 替换游客配置，未配置的字段使用默认值，已累计的试玩时长按新配置判断
+func (checker *GuestChecker) SetConfig(config GuestConfig) {
+	if config.TrialSeconds <= 0 {
+		config.TrialSeconds = 3600
+	}
+	if config.PeriodDays <= 0 {
+		config.PeriodDays = 15
+	}
+	checker.config.Store(&config)
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (checker *GuestChecker) SetTimeNow(timeNow func() time.Time) {
+	checker.timeNow = timeNow
+}
+
+// inPeriod 周期已结束时返回新周期的空记录
+func (checker *GuestChecker) inPeriod(record GuestRecord, now time.Time) GuestRecord {
+	period := time.Duration(checker.config.Load().PeriodDays) * 24 * time.Hour
+	if record.FirstPlayAt > 0 && now.Sub(time.UnixMilli(record.FirstPlayAt)) >= period {
+		return GuestRecord{}
+	}
+	return record
+}
+
+// CheckGuestPlay 检查设备是否还有游客试玩时长
+// 返回值：是否允许试玩、本周期剩余试玩时长
+func (checker *GuestChecker) CheckGuestPlay(ctx context.Context, deviceID string) (bool, time.Duration, error) {
+	record, err := checker.store.Load(ctx, deviceID)
+	if err != nil {
+		return false, 0, err
+	}
+	record = checker.inPeriod(record, checker.timeNow())
+	trial := time.Duration(checker.config.Load().TrialSeconds) * time.Second
+	remaining := max(trial-time.Duration(record.PlayedMs)*time.Millisecond, 0)
+	return remaining > 0, remaining, nil
+}
+
+// RecordGuestPlay 原子地累加设备的游客试玩时长（按毫秒累计），首次试玩时开始计算周期
+func (checker *GuestChecker) RecordGuestPlay(ctx context.Context, deviceID string, played time.Duration) error {
+	now := checker.timeNow()
+	return checker.store.Update(ctx, deviceID, func(record GuestRecord) GuestRecord {
+		record = checker.inPeriod(record, now)
+		if record.FirstPlayAt == 0 {
+			record.FirstPlayAt = now.UnixMilli()
+		}
+		record.PlayedMs += played.Milliseconds()
+		return record
+	})
+}
+
+// memoryGuestStore 基于内存的游客记录存储，适用于单机或测试
+type memoryGuestStore struct {
+	mu      sync.RWMutex
+	records map[string]GuestRecord
+}
+
+// NewMemoryGuestStore 创建基于内存的游客记录存储
+func NewMemoryGuestStore() GuestStore {
+	return &memoryGuestStore{records: make(map[string]GuestRecord)}
+}
+
+func (store *memoryGuestStore) Load(ctx context.Context, deviceID string) (GuestRecord, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.records[deviceID], nil
+}
+
+func (store *memoryGuestStore) Update(ctx context.Context, deviceID string, fn func(record GuestRecord) GuestRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.records[deviceID] = fn(store.records[deviceID])
+	return nil
+}
+
+// storageGuestStore 基于 global-storage hash 的游客记录存储，以设备ID为field
+type storageGuestStore struct {
+	hash       storage.HashTransactional
+	retryTimes int
+}
+
+// NewStorageGuestStore 创建基于 global-storage 的游客记录存储
+// hash: 需以 NewGuestRecordFactory 作为数据工厂注册的 hash 存储
+func NewStorageGuestStore(hash storage.HashTransactional) GuestStore {
+	return &storageGuestStore{hash: hash, retryTimes: defaultRecordRetryTimes}
+}
+
+// NewGuestRecordFactory 返回游客记录的数据工厂，用于注册 hash 存储
+func NewGuestRecordFactory() storage.StorageData {
+	return &GuestRecord{}
+}
+
+func (store *storageGuestStore) Load(ctx context.Context, deviceID string) (GuestRecord, error) {
+	data, err := store.hash.HGet(ctx, deviceID)
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return GuestRecord{}, nil
+	}
+	if err != nil {
+		return GuestRecord{}, err
+	}
+	record, ok := data.(*GuestRecord)
+	if !ok {
+		return GuestRecord{}, errors.New("anti-addiction: unexpected guest record type")
+	}
+	return *record, nil
+}
+
+// Update 通过 HUpdate 比对该设备的记录在读取后未被修改再写回，并发修改时按 retryTimes 重新读取并调用 fn
+func (store *storageGuestStore) Update(ctx context.Context, deviceID string, fn func(record GuestRecord) GuestRecord) error {
+	var err error
+	for i := 0; i <= store.retryTimes; i++ {
+		err = store.hash.HUpdate(ctx, deviceID, func(current storage.StorageData) (storage.StorageData, error) {
+			var record GuestRecord
+			if current != nil {
+				stored, ok := current.(*GuestRecord)
+				if !ok {
+					return nil, errors.New("anti-addiction: unexpected guest record type")
+				}
+				record = *stored
+			}
+			record = fn(record)
+			return &record, nil
+		})
+		if !errors.Is(err, storage.ErrTransactionConflict) {
+			return err
+		}
+	}
+	return err
+}
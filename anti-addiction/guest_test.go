@@ -0,0 +1,108 @@
+package anti_addiction
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGuestChecker(t *testing.T) {
+	ctx := context.Background()
+	checker := NewGuestChecker(GuestConfig{}, NewMemoryGuestStore())
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	checker.SetTimeNow(func() time.Time { return now })
+
+	allowed, remaining, err := checker.CheckGuestPlay(ctx, "device-1")
+	if err != nil || !allowed || remaining != time.Hour {
+		t.Fatalf("CheckGuestPlay() new device = %v, %v, %v, want true, 1h", allowed, remaining, err)
+	}
+
+	if err = checker.RecordGuestPlay(ctx, "device-1", 40*time.Minute); err != nil {
+		t.Fatalf("RecordGuestPlay() error = %v", err)
+	}
+	now = now.AddDate(0, 0, 3)
+	if err = checker.RecordGuestPlay(ctx, "device-1", 20*time.Minute); err != nil {
+		t.Fatalf("RecordGuestPlay() error = %v", err)
+	}
+	allowed, remaining, err = checker.CheckGuestPlay(ctx, "device-1")
+	if err != nil || allowed || remaining != 0 {
+		t.Errorf("CheckGuestPlay() exhausted = %v, %v, %v, want false, 0", allowed, remaining, err)
+	}
+
+	// 其他设备不受影响
+	if allowed, _, _ = checker.CheckGuestPlay(ctx, "device-2"); !allowed {
+		t.Errorf("CheckGuestPlay() other device = false, want true")
+	}
+
+	// 从首次试玩起满15天后重新计算
+	now = time.Date(2025, 3, 16, 12, 0, 0, 0, time.Local)
+	allowed, remaining, err = checker.CheckGuestPlay(ctx, "device-1")
+	if err != nil || !allowed || remaining != time.Hour {
+		t.Errorf("CheckGuestPlay() next period = %v, %v, %v, want true, 1h", allowed, remaining, err)
+	}
+}
+
+func TestGuestChecker_SubSecondHeartbeats(t *testing.T) {
+	ctx := context.Background()
+	checker := NewGuestChecker(GuestConfig{TrialSeconds: 1}, NewMemoryGuestStore())
+
+	// 短于 1 秒的心跳按毫秒累计，不会被舍去
+	for i := 0; i < 4; i++ {
+		if err := checker.RecordGuestPlay(ctx, "device-1", 250*time.Millisecond); err != nil {
+			t.Fatalf("RecordGuestPlay() error = %v", err)
+		}
+	}
+	if allowed, remaining, err := checker.CheckGuestPlay(ctx, "device-1"); err != nil || allowed || remaining != 0 {
+		t.Errorf("CheckGuestPlay() = %v, %v, %v, want false, 0", allowed, remaining, err)
+	}
+}
+
+func TestGuestChecker_ConcurrentRecord(t *testing.T) {
+	stores := map[string]GuestStore{
+		"memory":  NewMemoryGuestStore(),
+		"storage": NewStorageGuestStore(newFakeHash(NewGuestRecordFactory)),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			checker := NewGuestChecker(GuestConfig{}, store)
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_ = checker.RecordGuestPlay(ctx, "device-1", 10*time.Minute)
+				}()
+			}
+			wg.Wait()
+			record, err := store.Load(ctx, "device-1")
+			if err != nil || record.PlayedMs != time.Hour.Milliseconds() {
+				t.Errorf("PlayedMs = %d, %v, want %d", record.PlayedMs, err, time.Hour.Milliseconds())
+			}
+		})
+	}
+}
+
+func TestAntiAddictionChecker_GuestConfig(t *testing.T) {
+	ctx := context.Background()
+	config := Config{TimeConfig: getTestTimeConfig(), PurchaseConfig: getDefaultPurchaseConfig(), GuestConfig: GuestConfig{TrialSeconds: 600}}
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	guest := NewGuestChecker(GuestConfig{}, NewMemoryGuestStore())
+	checker.SetGuestChecker(guest)
+
+	// 试玩时长以 Config.GuestConfig 为准
+	if _, remaining, _ := guest.CheckGuestPlay(ctx, "device-1"); remaining != 10*time.Minute {
+		t.Errorf("remaining = %v, want 10m", remaining)
+	}
+	config.GuestConfig.TrialSeconds = 1200
+	if err = checker.Reload(config); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if _, remaining, _ := guest.CheckGuestPlay(ctx, "device-1"); remaining != 20*time.Minute {
+		t.Errorf("remaining after Reload = %v, want 20m", remaining)
+	}
+}
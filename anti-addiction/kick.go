@@ -0,0 +1,45 @@
+package anti_addiction
+
+import "time"
+
+// PlayerID 玩家ID
+type PlayerID int64
+
+// PlayerAge 在线玩家及其年龄
+type PlayerAge struct {
+	PlayerID PlayerID
+	Age      int32
+}
+
+// ComputeKickList 一次性计算在 now 时刻不可游玩、需要踢下线的玩家。
+// 同一时刻的判断结果只与年龄有关，按年龄缓存判断结果，数千名在线玩家只需判断少数几次
+func (checker *AntiAddictionTimeChecker) ComputeKickList(players []PlayerAge, now time.Time) []PlayerID {
+	allowedByAge := make(map[int32]bool)
+	kickList := make([]PlayerID, 0)
+	for _, player := range players {
+		allowed, ok := allowedByAge[player.Age]
+		if !ok {
+			allowed = checker.IsInPlayTimeAt(player.Age, now)
+			allowedByAge[player.Age] = allowed
+		}
+		if !allowed {
+			kickList = append(kickList, player.PlayerID)
+		}
+	}
+	return kickList
+}
+
+// ComputeKickList 同 AntiAddictionTimeChecker.ComputeKickList，豁免名单内的玩家不会被踢下线
+func (checker *antiAddictionChecker) ComputeKickList(players []PlayerAge, now time.Time) []PlayerID {
+	exemptions := checker.exempt.Load()
+	if exemptions == nil {
+		return checker.state.Load().TimeChecker.ComputeKickList(players, now)
+	}
+	candidates := make([]PlayerAge, 0, len(players))
+	for _, player := range players {
+		if !exemptions.IsExempt(int64(player.PlayerID)) {
+			candidates = append(candidates, player)
+		}
+	}
+	return checker.state.Load().TimeChecker.ComputeKickList(candidates, now)
+}
@@ -0,0 +1,37 @@
+package anti_addiction
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAntiAddictionTimeChecker_ComputeKickList(t *testing.T) {
+	config := getTestTimeConfig()
+	config.Curfews = []Curfew{{MinAge: 0, MaxAge: 12, StartHour: 20, StartMinute: 30, EndHour: 8}}
+	checker := NewAntiAddictionTimeChecker(config)
+
+	players := make([]PlayerAge, 0)
+	for i := PlayerID(0); i < 3000; i++ {
+		players = append(players, PlayerAge{PlayerID: i, Age: int32(i % 30)})
+	}
+
+	// 星期六 20:45，12岁以下处于宵禁
+	saturday := time.Date(2025, 3, 29, 20, 45, 0, 0, time.Local)
+	kickList := checker.ComputeKickList(players, saturday)
+	for _, player := range players {
+		want := !checker.IsInPlayTimeAt(player.Age, saturday)
+		if got := slices.Contains(kickList, player.PlayerID); got != want {
+			t.Fatalf("player %d age %d kicked = %v, want %v", player.PlayerID, player.Age, got, want)
+		}
+	}
+	if len(kickList) != 1200 {
+		t.Errorf("len(kickList) = %d, want 1200", len(kickList))
+	}
+
+	// 星期三所有未成年人都需要下线
+	wednesday := time.Date(2025, 3, 26, 20, 15, 0, 0, time.Local)
+	if got := len(checker.ComputeKickList(players, wednesday)); got != 1800 {
+		t.Errorf("len(kickList) on wednesday = %d, want 1800", got)
+	}
+}
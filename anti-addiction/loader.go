@@ -0,0 +1,280 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NumberMan1/component/filewatch"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfig 返回符合现行国家新闻出版署规定的默认配置：
+// 未成年人仅可在周五、周六、周日和法定节假日的 20:00-21:00 游戏；
+// 未满8周岁不得充值，8-16周岁单笔不超过50元、每月不超过200元，
+// 16-18周岁单笔不超过100元、每月不超过400元；
+// 游客模式同一设备15天内累计试玩不超过1小时。
+// 春节、中秋等农历节假日及调休每年不同，需要按年度自行配置 Holidays。
+func DefaultConfig() Config {
+	return Config{
+		TimeConfig: TimeConfig{
+			StartHour:       20,
+			EndHour:         21,
+			AllowedWeekDays: []time.Weekday{time.Friday, time.Saturday, time.Sunday},
+			Holidays: Holidays{
+				{Month: 1, Day: 1},
+				{Month: 5, Day: 1},
+				{Month: 5, Day: 2},
+				{Month: 10, Day: 1},
+				{Month: 10, Day: 2},
+				{Month: 10, Day: 3},
+			},
+		},
+		PurchaseConfig: PurchaseConfig{
+			AgeLimits: AgePurchaseLimits{
+				{MinAge: 0, MaxAge: 8, Limit: PurchaseLimit{SingleLimit: 0, MonthlyLimit: 0}},
+				{MinAge: 8, MaxAge: 16, Limit: PurchaseLimit{SingleLimit: 5000, MonthlyLimit: 20000}},
+				{MinAge: 16, MaxAge: 18, Limit: PurchaseLimit{SingleLimit: 10000, MonthlyLimit: 40000}},
+			},
+		},
+		GuestConfig: GuestConfig{
+			TrialSeconds: 3600,
+			PeriodDays:   15,
+		},
+	}
+}
+
+// LoadConfig 从 YAML 或 JSON 文件加载配置，文件中未出现的字段沿用 profile 指定的策略预设的值，
+// 未指定 profile 时沿用 DefaultConfig 的值；
+// 加载后会执行 Validate，配置非法时返回描述具体问题的错误
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("anti-addiction: read config %s: %w", path, err)
+	}
+
+	var unmarshal func(data []byte, v any) error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		unmarshal = yaml.Unmarshal
+	case ".json":
+		unmarshal = json.Unmarshal
+	default:
+		return Config{}, fmt.Errorf("anti-addiction: unsupported config format %q", ext)
+	}
+
+	// 先读取策略预设名称，再以预设为基础合并完整配置
+	var header struct {
+		Profile string `json:"profile" yaml:"profile"`
+	}
+	if err = unmarshal(data, &header); err != nil {
+		return Config{}, fmt.Errorf("anti-addiction: parse config %s: %w", path, err)
+	}
+	config := DefaultConfig()
+	if header.Profile != "" {
+		if config, err = ProfileConfig(header.Profile); err != nil {
+			return Config{}, err
+		}
+	}
+	if err = unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("anti-addiction: parse config %s: %w", path, err)
+	}
+
+	if err = config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Validate 校验配置是否合法
+func (config Config) Validate() error {
+	if config.Profile != "" {
+		if _, ok := LookupProfile(config.Profile); !ok {
+			return fmt.Errorf("anti-addiction: unknown policy profile %q", config.Profile)
+		}
+	}
+	if config.AdultAge < 0 {
+		return fmt.Errorf("anti-addiction: invalid adult age %d", config.AdultAge)
+	}
+	if err := config.TimeConfig.Validate(); err != nil {
+		return err
+	}
+	if err := config.PurchaseConfig.Validate(); err != nil {
+		return err
+	}
+	if err := config.GuestConfig.Validate(); err != nil {
+		return err
+	}
+	if config.GraceConfig.DurationSeconds < 0 {
+		return fmt.Errorf("anti-addiction: invalid grace duration seconds %d", config.GraceConfig.DurationSeconds)
+	}
+	return config.DurationConfig.Validate()
+}
+
+// adultAge 返回生效的成年年龄，未配置时为 DefaultAdultAge
+func (config Config) adultAge() int32 {
+	if config.AdultAge == 0 {
+		return DefaultAdultAge
+	}
+	return config.AdultAge
+}
+
+// Validate 校验游客模式配置，0 表示使用默认值
+func (config GuestConfig) Validate() error {
+	if config.TrialSeconds < 0 || config.PeriodDays < 0 {
+		return fmt.Errorf("anti-addiction: invalid guest config trial seconds %d period days %d",
+			config.TrialSeconds, config.PeriodDays)
+	}
+	return nil
+}
+
+// Validate 校验时间配置：时分秒取值范围、开始时间早于结束时间、星期与节假日合法
+func (config TimeConfig) Validate() error {
+	if err := validateClock("start", config.StartHour, config.StartMinute, config.StartSecond); err != nil {
+		return err
+	}
+	if err := validateClock("end", config.EndHour, config.EndMinute, config.EndSecond); err != nil {
+		return err
+	}
+	start := config.StartHour*3600 + config.StartMinute*60 + config.StartSecond
+	end := config.EndHour*3600 + config.EndMinute*60 + config.EndSecond
+	if start >= end {
+		return fmt.Errorf("anti-addiction: start time %02d:%02d:%02d must be before end time %02d:%02d:%02d",
+			config.StartHour, config.StartMinute, config.StartSecond,
+			config.EndHour, config.EndMinute, config.EndSecond)
+	}
+	for _, weekday := range config.AllowedWeekDays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("anti-addiction: invalid allowed weekday %d, want 0-6", weekday)
+		}
+	}
+	for _, curfew := range config.Curfews {
+		if err := curfew.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, override := range config.Overrides {
+		if err := override.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateDayRulePrecedence(config.Precedence); err != nil {
+		return err
+	}
+	for _, holiday := range config.Holidays {
+		// 使用闰年校验，保证 2 月 29 日可以配置
+		date := time.Date(2024, time.Month(holiday.Month), holiday.Day, 0, 0, 0, 0, time.UTC)
+		if holiday.Month < 1 || holiday.Month > 12 || date.Day() != holiday.Day {
+			return fmt.Errorf("anti-addiction: invalid holiday %02d-%02d", holiday.Month, holiday.Day)
+		}
+	}
+	return nil
+}
+
+func validateClock(name string, hour, minute, second int) error {
+	if hour < 0 || hour > 23 {
+		return fmt.Errorf("anti-addiction: %s hour %d out of range 0-23", name, hour)
+	}
+	if minute < 0 || minute > 59 {
+		return fmt.Errorf("anti-addiction: %s minute %d out of range 0-59", name, minute)
+	}
+	if second < 0 || second > 59 {
+		return fmt.Errorf("anti-addiction: %s second %d out of range 0-59", name, second)
+	}
+	return nil
+}
+
+// Validate 校验充值配置：币种已注册，限额不小于 -1，年龄段合法且连续不重叠
+func (config PurchaseConfig) Validate() error {
+	if config.Currency != "" {
+		if _, ok := LookupCurrency(config.Currency); !ok {
+			return fmt.Errorf("anti-addiction: unknown currency %q", config.Currency)
+		}
+	}
+	if err := validateAgePurchaseLimits(config.AgeLimits); err != nil {
+		return err
+	}
+	for category, limits := range config.CategoryLimits {
+		if category == "" {
+			return fmt.Errorf("anti-addiction: empty purchase category")
+		}
+		if err := validateAgePurchaseLimits(limits); err != nil {
+			return fmt.Errorf("anti-addiction: purchase category %q: %w", category, err)
+		}
+	}
+	return nil
+}
+
+// validateAgePurchaseLimits 校验限额取值不小于 -1，且年龄段连续不重叠
+func validateAgePurchaseLimits(limits AgePurchaseLimits) error {
+	for _, ageLimit := range limits {
+		if ageLimit.Limit.SingleLimit < -1 || ageLimit.Limit.MonthlyLimit < -1 || ageLimit.Limit.DailyLimit < -1 {
+			return fmt.Errorf("anti-addiction: invalid limit for age bracket [%d, %d), want -1 or non-negative",
+				ageLimit.MinAge, ageLimit.MaxAge)
+		}
+	}
+	return limits.Validate()
+}
+
+// AgeBracketError 年龄段配置错误，列出所有存在问题的年龄段
+type AgeBracketError struct {
+	Problems []string
+}
+
+func (err *AgeBracketError) Error() string {
+	return "anti-addiction: invalid age brackets: " + strings.Join(err.Problems, "; ")
+}
+
+// Validate 校验年龄段：上下限合法，从 0 岁开始连续且互不重叠。
+// 年龄段之间的空隙会导致该年龄落入"无限制"，因此同样视为错误；最后一个年龄段之后视为成年人不受限制
+func (limits AgePurchaseLimits) Validate() error {
+	sorted := slices.Clone(limits)
+	slices.SortFunc(sorted, func(a, b AgePurchaseLimit) int {
+		return int(a.MinAge - b.MinAge)
+	})
+
+	var problems []string
+	for i, ageLimit := range sorted {
+		if ageLimit.MinAge < 0 || ageLimit.MinAge >= ageLimit.MaxAge {
+			problems = append(problems, fmt.Sprintf("invalid bracket [%d, %d)", ageLimit.MinAge, ageLimit.MaxAge))
+			continue
+		}
+		if i == 0 {
+			if ageLimit.MinAge > 0 {
+				problems = append(problems, fmt.Sprintf("gap [0, %d) before bracket [%d, %d)",
+					ageLimit.MinAge, ageLimit.MinAge, ageLimit.MaxAge))
+			}
+			continue
+		}
+		prev := sorted[i-1]
+		switch {
+		case prev.MaxAge > ageLimit.MinAge:
+			problems = append(problems, fmt.Sprintf("bracket [%d, %d) overlaps [%d, %d)",
+				prev.MinAge, prev.MaxAge, ageLimit.MinAge, ageLimit.MaxAge))
+		case prev.MaxAge < ageLimit.MinAge:
+			problems = append(problems, fmt.Sprintf("gap [%d, %d) between [%d, %d) and [%d, %d)",
+				prev.MaxAge, ageLimit.MinAge, prev.MinAge, prev.MaxAge, ageLimit.MinAge, ageLimit.MaxAge))
+		}
+	}
+	if len(problems) > 0 {
+		return &AgeBracketError{Problems: problems}
+	}
+	return nil
+}
+
+// WatchConfigFile 按 interval 轮询配置文件的修改时间，文件变更且校验通过后调用 checker.Reload，
+// 加载失败时保留旧配置并通过 onError 回调通知（可为 nil）。ctx 结束时停止监听
+func WatchConfigFile(ctx context.Context, path string, interval time.Duration, checker AntiAddictionChecker, onError func(error)) {
+	filewatch.Watch(ctx, path, interval, func() error {
+		config, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		return checker.Reload(config)
+	}, onError)
+}
@@ -0,0 +1,61 @@
+package anti_addiction
+
+const (
+	// AgeBracketAdult 未命中充值年龄段配置的成年人
+	AgeBracketAdult = "adult"
+	// AgeBracketPending 实名认证进行中
+	AgeBracketPending = "pending"
+	// AgeBracketUnknown 未命中充值年龄段配置的未成年人
+	AgeBracketUnknown = "unknown"
+)
+
+// Metrics 防沉迷检查的指标上报接口，由接入方适配到 Prometheus 等监控系统，实现需并发安全
+// bracket: 年龄段标签，见 PurchaseChecker.AgeBracket
+type Metrics interface {
+	// IncPlayCheck 可游玩检查计数，允许时 reason 为 DenyReasonNone
+	IncPlayCheck(bracket string, allowed bool, reason DenyReason)
+	// IncPurchaseCheck 充值检查计数，允许时 reason 为 DenyReasonNone
+	IncPurchaseCheck(bracket string, allowed bool, reason DenyReason)
+	// ObservePurchaseAmount 充值检查金额分布，金额为限额币种的最小单位，币种无法转换时不上报
+	ObservePurchaseAmount(bracket string, amount int64)
+}
+
+// nopMetrics 未设置指标上报时的默认实现
+type nopMetrics struct{}
+
+func (nopMetrics) IncPlayCheck(string, bool, DenyReason)     {}
+func (nopMetrics) IncPurchaseCheck(string, bool, DenyReason) {}
+func (nopMetrics) ObservePurchaseAmount(string, int64)       {}
+
+// metricsHolder 包装 Metrics 以便原子替换
+type metricsHolder struct {
+	metrics Metrics
+}
+
+// SetMetrics 设置指标上报实现，传入 nil 时关闭上报，不随 Reload 替换
+func (checker *antiAddictionChecker) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	checker.metrics.Store(&metricsHolder{metrics: metrics})
+}
+
+func (checker *antiAddictionChecker) getMetrics() Metrics {
+	if holder := checker.metrics.Load(); holder != nil {
+		return holder.metrics
+	}
+	return nopMetrics{}
+}
+
+func (checker *antiAddictionChecker) recordPlayCheck(state *checkerState, age int32, allowed bool, reason DenyReason) {
+	checker.getMetrics().IncPlayCheck(state.PurchaseChecker.AgeBracket(age), allowed, reason)
+}
+
+func (checker *antiAddictionChecker) recordPurchaseCheck(state *checkerState, age int32, amount int64, allowed bool, reason DenyReason, opts ...PurchaseOption) {
+	metrics := checker.getMetrics()
+	bracket := state.PurchaseChecker.AgeBracket(age)
+	if amountInMinor, err := state.PurchaseChecker.ConvertAmount(amount, opts...); err == nil {
+		metrics.ObservePurchaseAmount(bracket, amountInMinor)
+	}
+	metrics.IncPurchaseCheck(bracket, allowed, reason)
+}
@@ -0,0 +1,100 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeMetrics struct {
+	mu       sync.Mutex
+	play     map[string]int
+	purchase map[string]int
+	amounts  map[string][]int64
+}
+
+func newFakeMetrics() *fakeMetrics {
+	return &fakeMetrics{
+		play:     make(map[string]int),
+		purchase: make(map[string]int),
+		amounts:  make(map[string][]int64),
+	}
+}
+
+func (metrics *fakeMetrics) IncPlayCheck(bracket string, allowed bool, reason DenyReason) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	metrics.play[fmt.Sprintf("%s/%v/%s", bracket, allowed, reason)]++
+}
+
+func (metrics *fakeMetrics) IncPurchaseCheck(bracket string, allowed bool, reason DenyReason) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	metrics.purchase[fmt.Sprintf("%s/%v/%s", bracket, allowed, reason)]++
+}
+
+func (metrics *fakeMetrics) ObservePurchaseAmount(bracket string, amount int64) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	metrics.amounts[bracket] = append(metrics.amounts[bracket], amount)
+}
+
+func TestAntiAddictionChecker_Metrics(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeConfig = getTestTimeConfig()
+	config.TimeConfig.Curfews = []Curfew{{MinAge: 0, MaxAge: 12, StartHour: 20, StartMinute: 30, EndHour: 8}}
+	c, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	metrics := newFakeMetrics()
+	c.SetMetrics(metrics)
+
+	// 星期六 20:45
+	saturday := time.Date(2025, 3, 29, 20, 45, 0, 0, time.Local)
+	// 星期一 20:30
+	monday := time.Date(2025, 3, 31, 20, 30, 0, 0, time.Local)
+	c.IsInPlayTimeAt(14, saturday)
+	c.IsInPlayTimeAt(10, saturday)
+	c.IsInPlayTimeAt(14, monday)
+	c.IsInPlayTimeAt(14, saturday.Add(time.Hour))
+	c.IsInPlayTimeAt(30, monday)
+
+	c.CheckSinglePurchase(5000, 10)
+	c.CheckSinglePurchase(6000, 10)
+	c.CheckSinglePurchase(100, 5)
+	c.CheckMonthlyPurchase(100, 39950, 17)
+	c.CheckPurchaseDetailed(100, 0, 0, 17, PurchaseOption{Currency: "USD"})
+
+	wantPlay := map[string]int{
+		"8-16/true/none":                 1,
+		"8-16/false/curfew":              1,
+		"8-16/false/not_allowed_weekday": 1,
+		"8-16/false/outside_window":      1,
+		"adult/true/none":                1,
+	}
+	wantPurchase := map[string]int{
+		"8-16/true/none":                   1,
+		"8-16/false/single_limit":          1,
+		"0-8/false/age_banned":             1,
+		"16-18/false/monthly_limit":        1,
+		"16-18/false/currency_unsupported": 1,
+	}
+	for key, want := range wantPlay {
+		if got := metrics.play[key]; got != want {
+			t.Errorf("play[%s] = %d, want %d", key, got, want)
+		}
+	}
+	for key, want := range wantPurchase {
+		if got := metrics.purchase[key]; got != want {
+			t.Errorf("purchase[%s] = %d, want %d", key, got, want)
+		}
+	}
+	if got := metrics.amounts["8-16"]; len(got) != 2 || got[0] != 5000 || got[1] != 6000 {
+		t.Errorf("amounts[8-16] = %v, want [5000 6000]", got)
+	}
+	if got := metrics.amounts["16-18"]; len(got) != 1 {
+		t.Errorf("amounts[16-18] = %v, want 1 observation", got)
+	}
+}
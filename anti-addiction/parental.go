@@ -0,0 +1,278 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// ParentalControl 家长为账号设置的自定义规则，只能在全局配置的基础上进一步收紧，未设置的项沿用全局配置
+type ParentalControl struct {
+	// 自定义可游玩时间，为 nil 时沿用全局配置；仅对未成年人生效
+	TimeConfig *TimeConfig `json:"time_config,omitempty" yaml:"time-config,omitempty"`
+	// 自定义充值限制（单位：限额币种的最小单位），为 nil 时沿用全局配置；
+	// 各项为 -1 时该项沿用全局配置，为正数时与全局配置取较小值，不允许为 0，禁止充值请使用 BanPurchase
+	PurchaseLimit *PurchaseLimit `json:"purchase_limit,omitempty" yaml:"purchase-limit,omitempty"`
+	// 禁止充值，拒绝原因为 DenyReasonAgeBanned
+	BanPurchase bool `json:"ban_purchase,omitempty" yaml:"ban-purchase,omitempty"`
+	// 每日累计游戏时长上限（秒），<=0 时沿用全局配置
+	DailySeconds int64 `json:"daily_seconds,omitempty" yaml:"daily-seconds,omitempty"`
+	// 最后修改时间戳（毫秒），由 ParentalChecker 写入
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func (control *ParentalControl) MarshalBinary() ([]byte, error) {
+	return json.Marshal(control)
+}
+
+func (control *ParentalControl) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, control)
+}
+
+// Validate 校验自定义规则
+func (control ParentalControl) Validate() error {
+	if control.TimeConfig != nil {
+		if err := control.TimeConfig.Validate(); err != nil {
+			return err
+		}
+	}
+	// 0 既可能表示未设置也可能表示禁止充值，为避免歧义不允许出现
+	if limit := control.PurchaseLimit; limit != nil &&
+		(!validParentalLimit(limit.SingleLimit) || !validParentalLimit(limit.MonthlyLimit) || !validParentalLimit(limit.DailyLimit)) {
+		return fmt.Errorf("anti-addiction: invalid parental purchase limit %+v, want -1 or positive", *limit)
+	}
+	return nil
+}
+
+func validParentalLimit(limit int64) bool {
+	return limit == -1 || limit > 0
+}
+
+// purchaseLimit 返回自定义充值限制，与全局检查结果合并后生效；禁止充值时各项为 0，未设置时不额外限制
+func (control ParentalControl) purchaseLimit() PurchaseLimit {
+	if control.BanPurchase {
+		return PurchaseLimit{}
+	}
+	if control.PurchaseLimit == nil {
+		return PurchaseLimit{SingleLimit: -1, MonthlyLimit: -1, DailyLimit: -1}
+	}
+	return *control.PurchaseLimit
+}
+
+// ParentalStore 家长控制规则存储
+type ParentalStore interface {
+	// Get 获取账号的自定义规则，未设置时 ok 为 false
+	Get(ctx context.Context, accountID int64) (control ParentalControl, ok bool, err error)
+	Set(ctx context.Context, accountID int64, control ParentalControl) error
+	Delete(ctx context.Context, accountID int64) error
+}
+
+// ParentalChecker 在全局检查器之前查询账号的家长控制规则，账号需同时满足自定义规则与全局配置
+type ParentalChecker struct {
+	checker AntiAddictionChecker
+	store   ParentalStore
+	timeNow func() time.Time
+
+	// 按账号缓存由自定义时间规则构建的时间检查器，规则或成年年龄变化时重建
+	mu           sync.Mutex
+	timeCheckers map[int64]parentalTimeChecker
+}
+
+// parentalTimeChecker 缓存的自定义时间检查器及构建时的规则
+type parentalTimeChecker struct {
+	config   TimeConfig
+	adultAge int32
+	checker  *AntiAddictionTimeChecker
+}
+
+// NewParentalChecker 创建家长控制检查器
+func NewParentalChecker(checker AntiAddictionChecker, store ParentalStore) *ParentalChecker {
+	return &ParentalChecker{
+		checker:      checker,
+		store:        store,
+		timeNow:      time.Now,
+		timeCheckers: make(map[int64]parentalTimeChecker),
+	}
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (checker *ParentalChecker) SetTimeNow(timeNow func() time.Time) {
+	checker.timeNow = timeNow
+}
+
+// SetControl 校验并保存账号的自定义规则
+func (checker *ParentalChecker) SetControl(ctx context.Context, accountID int64, control ParentalControl) error {
+	if err := control.Validate(); err != nil {
+		return err
+	}
+	control.UpdatedAt = checker.timeNow().UnixMilli()
+	return checker.store.Set(ctx, accountID, control)
+}
+
+// GetControl 获取账号的自定义规则，未设置时 ok 为 false
+func (checker *ParentalChecker) GetControl(ctx context.Context, accountID int64) (ParentalControl, bool, error) {
+	return checker.store.Get(ctx, accountID)
+}
+
+// DeleteControl 删除账号的自定义规则，之后沿用全局配置
+func (checker *ParentalChecker) DeleteControl(ctx context.Context, accountID int64) error {
+	return checker.store.Delete(ctx, accountID)
+}
+
+// IsInPlayTime 检查账号当前是否可游玩，不可游玩时同时返回原因
+func (checker *ParentalChecker) IsInPlayTime(ctx context.Context, accountID int64, age int32) (bool, DenyReason, error) {
+	return checker.IsInPlayTimeAt(ctx, accountID, age, checker.timeNow())
+}
+
+// IsInPlayTimeAt 检查账号在指定时刻是否可游玩：先按自定义时间规则检查，再按全局配置检查，豁免账号总是可游玩
+func (checker *ParentalChecker) IsInPlayTimeAt(ctx context.Context, accountID int64, age int32, t time.Time) (bool, DenyReason, error) {
+	if checker.checker.IsExempt(accountID) {
+		return true, DenyReasonNone, nil
+	}
+	control, ok, err := checker.store.Get(ctx, accountID)
+	if err != nil {
+		return false, DenyReasonNone, err
+	}
+	if timeChecker := checker.timeChecker(accountID, control, ok); timeChecker != nil {
+		if allowed, reason := timeChecker.IsInPlayTimeAtWithReason(age, t); !allowed {
+			return false, reason, nil
+		}
+	}
+	allowed, reason := checker.checker.IsInPlayTimeAtWithReason(age, t)
+	return allowed, reason, nil
+}
+
+// timeChecker 返回账号自定义时间规则的检查器，规则未变化时复用缓存，没有自定义时间规则时返回 nil
+func (checker *ParentalChecker) timeChecker(accountID int64, control ParentalControl, ok bool) *AntiAddictionTimeChecker {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	if !ok || control.TimeConfig == nil {
+		delete(checker.timeCheckers, accountID)
+		return nil
+	}
+	adultAge := checker.checker.AdultAge()
+	cached, hit := checker.timeCheckers[accountID]
+	if hit && cached.adultAge == adultAge && reflect.DeepEqual(cached.config, *control.TimeConfig) {
+		return cached.checker
+	}
+	timeChecker := NewAntiAddictionTimeChecker(*control.TimeConfig)
+	timeChecker.SetAdultAge(adultAge)
+	checker.timeCheckers[accountID] = parentalTimeChecker{config: *control.TimeConfig, adultAge: adultAge, checker: timeChecker}
+	return timeChecker
+}
+
+// CheckPurchase 按全局配置与自定义充值限制依次检查，返回详细结果，MaxAmount 取两者中较小值，豁免账号不受限制
+// dailyTotal: 当日已充值总额
+// monthlyTotal: 当月已充值总额
+// opts: 可选参数，不传则使用默认选项，使用分作为单位
+func (checker *ParentalChecker) CheckPurchase(ctx context.Context, accountID int64, amount int64, dailyTotal int64, monthlyTotal int64, age int32, opts ...PurchaseOption) (PurchaseDecision, error) {
+	if checker.checker.IsExempt(accountID) {
+		return PurchaseDecision{Allowed: true, MaxAmount: -1}, nil
+	}
+	control, ok, err := checker.store.Get(ctx, accountID)
+	if err != nil {
+		return PurchaseDecision{}, err
+	}
+	decision := checker.checker.CheckPurchaseDetailed(amount, dailyTotal, monthlyTotal, age, opts...)
+	if !ok || (control.PurchaseLimit == nil && !control.BanPurchase) ||
+		decision.Reason == DenyReasonCurrencyUnsupported || decision.Reason == DenyReasonAgeBanned {
+		return decision, nil
+	}
+
+	// 币种已在全局检查中验证可转换
+	amountInFen, _ := checker.checker.ConvertAmount(amount, opts...)
+	dailyTotalInFen, _ := checker.checker.ConvertAmount(dailyTotal, opts...)
+	monthlyTotalInFen, _ := checker.checker.ConvertAmount(monthlyTotal, opts...)
+	return mergePurchaseDecision(decision, evaluatePurchaseLimit(control.purchaseLimit(), amountInFen, dailyTotalInFen, monthlyTotalInFen)), nil
+}
+
+// GetDailyDurationLimit 获取账号的每日累计游戏时长上限（秒），自定义上限与全局上限取较小值，-1 表示不限制
+func (checker *ParentalChecker) GetDailyDurationLimit(ctx context.Context, accountID int64, age int32) (int64, error) {
+	limit := checker.checker.GetDailyDurationLimit(age)
+	control, ok, err := checker.store.Get(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	if ok && control.DailySeconds > 0 && (limit == -1 || control.DailySeconds < limit) {
+		limit = control.DailySeconds
+	}
+	return limit, nil
+}
+
+// memoryParentalStore 基于内存的家长控制规则存储，适用于单机或测试
+type memoryParentalStore struct {
+	mu       sync.RWMutex
+	controls map[int64]ParentalControl
+}
+
+// NewMemoryParentalStore 创建基于内存的家长控制规则存储
+func NewMemoryParentalStore() ParentalStore {
+	return &memoryParentalStore{controls: make(map[int64]ParentalControl)}
+}
+
+func (store *memoryParentalStore) Get(ctx context.Context, accountID int64) (ParentalControl, bool, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	control, ok := store.controls[accountID]
+	return control, ok, nil
+}
+
+func (store *memoryParentalStore) Set(ctx context.Context, accountID int64, control ParentalControl) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.controls[accountID] = control
+	return nil
+}
+
+func (store *memoryParentalStore) Delete(ctx context.Context, accountID int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.controls, accountID)
+	return nil
+}
+
+// storageParentalStore 基于 global-storage hash 的家长控制规则存储，以账号ID为field
+type storageParentalStore struct {
+	hash storage.HashTransactional
+}
+
+// NewStorageParentalStore 创建基于 global-storage 的家长控制规则存储
+// hash: 需以 NewParentalControlFactory 作为数据工厂注册的 hash 存储
+func NewStorageParentalStore(hash storage.HashTransactional) ParentalStore {
+	return &storageParentalStore{hash: hash}
+}
+
+// NewParentalControlFactory 返回家长控制规则的数据工厂，用于注册 hash 存储
+func NewParentalControlFactory() storage.StorageData {
+	return &ParentalControl{}
+}
+
+func (store *storageParentalStore) Get(ctx context.Context, accountID int64) (ParentalControl, bool, error) {
+	data, err := store.hash.HGet(ctx, strconv.FormatInt(accountID, 10))
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return ParentalControl{}, false, nil
+	}
+	if err != nil {
+		return ParentalControl{}, false, err
+	}
+	control, ok := data.(*ParentalControl)
+	if !ok {
+		return ParentalControl{}, false, errors.New("anti-addiction: unexpected parental control type")
+	}
+	return *control, true, nil
+}
+
+func (store *storageParentalStore) Set(ctx context.Context, accountID int64, control ParentalControl) error {
+	return store.hash.HSet(ctx, strconv.FormatInt(accountID, 10), &control)
+}
+
+func (store *storageParentalStore) Delete(ctx context.Context, accountID int64) error {
+	return store.hash.HDel(ctx, strconv.FormatInt(accountID, 10))
+}
@@ -0,0 +1,103 @@
+package anti_addiction
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParentalChecker(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultConfig()
+	config.TimeConfig = getTestTimeConfig()
+	globalChecker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	checker := NewParentalChecker(globalChecker, NewStorageParentalStore(newFakeHash(NewParentalControlFactory)))
+
+	// 仅允许星期六 20:00-20:30，单笔 10 元，每日 20 分钟
+	timeConfig := getTestTimeConfig()
+	timeConfig.EndHour, timeConfig.EndMinute = 20, 30
+	timeConfig.AllowedWeekDays = []time.Weekday{time.Saturday}
+	control := ParentalControl{
+		TimeConfig:    &timeConfig,
+		PurchaseLimit: &PurchaseLimit{SingleLimit: 1000, MonthlyLimit: -1, DailyLimit: -1},
+		DailySeconds:  1200,
+	}
+	if err = checker.SetControl(ctx, 1, control); err != nil {
+		t.Fatalf("SetControl() error = %v", err)
+	}
+	if _, ok, err := checker.GetControl(ctx, 1); err != nil || !ok {
+		t.Fatalf("GetControl() = %v, %v, want true", ok, err)
+	}
+
+	playTests := []struct {
+		name       string
+		accountID  int64
+		inputTime  time.Time
+		wantResult bool
+		wantReason DenyReason
+	}{
+		{name: "自定义时间段内", accountID: 1, inputTime: time.Date(2025, 3, 29, 20, 15, 0, 0, time.Local), wantResult: true},
+		{name: "自定义时间段外", accountID: 1, inputTime: time.Date(2025, 3, 29, 20, 45, 0, 0, time.Local), wantReason: DenyReasonOutsideWindow},
+		{name: "自定义星期外", accountID: 1, inputTime: time.Date(2025, 3, 28, 20, 15, 0, 0, time.Local), wantReason: DenyReasonNotAllowedWeekday},
+		{name: "未设置沿用全局", accountID: 2, inputTime: time.Date(2025, 3, 28, 20, 45, 0, 0, time.Local), wantResult: true},
+	}
+	for _, tt := range playTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason, err := checker.IsInPlayTimeAt(ctx, tt.accountID, 14, tt.inputTime)
+			if err != nil || got != tt.wantResult || reason != tt.wantReason {
+				t.Errorf("IsInPlayTimeAt() = %v, %v, %v, want %v, %v", got, reason, err, tt.wantResult, tt.wantReason)
+			}
+		})
+	}
+
+	// 修改自定义时间规则后按新规则检查
+	extended := timeConfig
+	extended.EndMinute = 50
+	control.TimeConfig = &extended
+	if err = checker.SetControl(ctx, 1, control); err != nil {
+		t.Fatalf("SetControl() error = %v", err)
+	}
+	if got, _, err := checker.IsInPlayTimeAt(ctx, 1, 14, time.Date(2025, 3, 29, 20, 45, 0, 0, time.Local)); err != nil || !got {
+		t.Errorf("IsInPlayTimeAt() after update = %v, %v, want true", got, err)
+	}
+
+	decision, err := checker.CheckPurchase(ctx, 1, 2000, 0, 0, 14)
+	if err != nil || decision.Allowed || decision.Reason != DenyReasonSingleLimit || decision.Threshold != 1000 {
+		t.Errorf("CheckPurchase() = %+v, %v, want single limit 1000", decision, err)
+	}
+	if decision, err = checker.CheckPurchase(ctx, 2, 2000, 0, 0, 14); err != nil || !decision.Allowed {
+		t.Errorf("CheckPurchase() without control = %+v, %v, want allowed", decision, err)
+	}
+	// 月度限额沿用全局的 200 元
+	if decision, err = checker.CheckPurchase(ctx, 1, 1000, 0, 19500, 14); err != nil || decision.Reason != DenyReasonMonthlyLimit || decision.Threshold != 20000 {
+		t.Errorf("CheckPurchase() inherited monthly = %+v, %v, want monthly limit 20000", decision, err)
+	}
+	if err = checker.SetControl(ctx, 3, ParentalControl{BanPurchase: true}); err != nil {
+		t.Fatalf("SetControl() ban error = %v", err)
+	}
+	if decision, err = checker.CheckPurchase(ctx, 3, 100, 0, 0, 14); err != nil || decision.Reason != DenyReasonAgeBanned {
+		t.Errorf("CheckPurchase() banned = %+v, %v, want %v", decision, err, DenyReasonAgeBanned)
+	}
+	if limit, err := checker.GetDailyDurationLimit(ctx, 1, 14); err != nil || limit != 1200 {
+		t.Errorf("GetDailyDurationLimit() = %d, %v, want 1200", limit, err)
+	}
+
+	if err = checker.DeleteControl(ctx, 1); err != nil {
+		t.Fatalf("DeleteControl() error = %v", err)
+	}
+	if _, ok, _ := checker.GetControl(ctx, 1); ok {
+		t.Errorf("GetControl() after delete ok = true, want false")
+	}
+
+	for _, limit := range []PurchaseLimit{
+		{SingleLimit: -2, MonthlyLimit: -1, DailyLimit: -1},
+		{SingleLimit: 1000, MonthlyLimit: 0, DailyLimit: -1},
+	} {
+		if err = checker.SetControl(ctx, 1, ParentalControl{PurchaseLimit: &limit}); err == nil {
+			t.Errorf("SetControl(%+v) error = nil, want error", limit)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayRule 判定某天是否可游玩的规则
+type DayRule string
+
+const (
+	// DayRuleNone 未经日期规则判定，如成年人、宵禁或没有规则命中
+	DayRuleNone DayRule = ""
+	// DayRuleOverride 指定日期的覆盖规则，用于调休
+	DayRuleOverride DayRule = "override"
+	// DayRuleHoliday 节假日
+	DayRuleHoliday DayRule = "holiday"
+	// DayRuleWeekday 允许的星期
+	DayRuleWeekday DayRule = "weekday"
+)
+
+// overrideDateLayout 覆盖日期的格式
+const overrideDateLayout = "2006-01-02"
+
+// DefaultDayRulePrecedence 默认的规则优先级：覆盖日期 > 节假日 > 星期
+var DefaultDayRulePrecedence = []DayRule{DayRuleOverride, DayRuleHoliday, DayRuleWeekday}
+
+// DayOverride 指定日期的覆盖规则，如调休上班的周日配置为不可游玩，调休放假的周三配置为可游玩
+type DayOverride struct {
+	// 日期，格式 2006-01-02
+	Date string `json:"date" yaml:"date"`
+	// 当天是否可游玩，可游玩时仍需在可游玩时间段内
+	Allowed bool `json:"allowed" yaml:"allowed"`
+}
+
+// Validate 校验覆盖日期格式
+func (override DayOverride) Validate() error {
+	if _, err := time.Parse(overrideDateLayout, override.Date); err != nil {
+		return fmt.Errorf("anti-addiction: invalid override date %q, want 2006-01-02", override.Date)
+	}
+	return nil
+}
+
+// validateDayRulePrecedence 校验规则优先级：规则合法且不重复
+func validateDayRulePrecedence(precedence []DayRule) error {
+	seen := make(map[DayRule]bool, len(precedence))
+	for _, rule := range precedence {
+		switch rule {
+		case DayRuleOverride, DayRuleHoliday, DayRuleWeekday:
+		default:
+			return fmt.Errorf("anti-addiction: invalid day rule %q", rule)
+		}
+		if seen[rule] {
+			return fmt.Errorf("anti-addiction: duplicate day rule %q", rule)
+		}
+		seen[rule] = true
+	}
+	return nil
+}
+
+// Decision 可游玩判定的详细结果
+type Decision struct {
+	// 是否可游玩
+	Allowed bool
+	// 不可游玩的原因，可游玩时为 DenyReasonNone
+	Reason DenyReason
+	// 决定当天是否可游玩的规则，成年人或处于宵禁时为 DayRuleNone
+	Rule DayRule
+}
+
+// resolveDay 按规则优先级依次判定指定日期是否可游玩，返回命中的规则：
+// 覆盖规则在日期有配置时命中；节假日规则仅在当天为节假日时命中并允许游玩；星期规则总是命中
+func (checker *AntiAddictionTimeChecker) resolveDay(day time.Time) (bool, DayRule) {
+	for _, rule := range checker.timeRange.Precedence {
+		switch rule {
+		case DayRuleOverride:
+			if allowed, ok := checker.timeRange.Overrides[day.Format(overrideDateLayout)]; ok {
+				return allowed, DayRuleOverride
+			}
+		case DayRuleHoliday:
+			if checker.IsHoliday(day) {
+				return true, DayRuleHoliday
+			}
+		case DayRuleWeekday:
+			return checker.IsWeekAllowedDay(day), DayRuleWeekday
+		}
+	}
+	return false, DayRuleNone
+}
+
+// ExplainDecision 判定指定时刻、年龄是否可游玩，并返回命中的规则，用于排查调休与星期配置冲突等问题
+func (checker *AntiAddictionTimeChecker) ExplainDecision(t time.Time, age int32) Decision {
+	if checker.IsInCurfew(age, t) {
+		return Decision{Reason: DenyReasonCurfew}
+	}
+	if checker.isAdult(age) {
+		return Decision{Allowed: true}
+	}
+	allowed, rule := checker.resolveDay(t)
+	switch {
+	case !allowed:
+		return Decision{Reason: DenyReasonNotAllowedWeekday, Rule: rule}
+	case !checker.IsInHourTimeRange(t):
+		return Decision{Reason: DenyReasonOutsideWindow, Rule: rule}
+	}
+	return Decision{Allowed: true, Rule: rule}
+}
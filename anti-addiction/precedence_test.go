@@ -0,0 +1,115 @@
+package anti_addiction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAntiAddictionTimeChecker_ExplainDecision(t *testing.T) {
+	config := getTestTimeConfig()
+	// 2025-03-30 星期日调休上班，2025-04-02 星期三调休放假，2025-01-01 节假日但配置为不可游玩
+	config.Overrides = []DayOverride{
+		{Date: "2025-03-30", Allowed: false},
+		{Date: "2025-04-02", Allowed: true},
+		{Date: "2025-01-01", Allowed: false},
+	}
+
+	tests := []struct {
+		name       string
+		precedence []DayRule
+		inputTime  time.Time
+		age        int32
+		want       Decision
+	}{
+		{
+			name:      "覆盖日期-调休上班",
+			inputTime: time.Date(2025, 3, 30, 20, 30, 0, 0, time.Local),
+			age:       14,
+			want:      Decision{Reason: DenyReasonNotAllowedWeekday, Rule: DayRuleOverride},
+		},
+		{
+			name:      "覆盖日期-调休放假",
+			inputTime: time.Date(2025, 4, 2, 20, 30, 0, 0, time.Local),
+			age:       14,
+			want:      Decision{Allowed: true, Rule: DayRuleOverride},
+		},
+		{
+			name:      "覆盖日期优先于节假日",
+			inputTime: time.Date(2025, 1, 1, 20, 30, 0, 0, time.Local),
+			age:       14,
+			want:      Decision{Reason: DenyReasonNotAllowedWeekday, Rule: DayRuleOverride},
+		},
+		{
+			name:       "节假日优先于覆盖日期",
+			precedence: []DayRule{DayRuleHoliday, DayRuleOverride, DayRuleWeekday},
+			inputTime:  time.Date(2025, 1, 1, 20, 30, 0, 0, time.Local),
+			age:        14,
+			want:       Decision{Allowed: true, Rule: DayRuleHoliday},
+		},
+		{
+			name:      "星期规则-时间段外",
+			inputTime: time.Date(2025, 3, 29, 19, 0, 0, 0, time.Local),
+			age:       14,
+			want:      Decision{Reason: DenyReasonOutsideWindow, Rule: DayRuleWeekday},
+		},
+		{
+			name:       "未配置星期规则",
+			precedence: []DayRule{DayRuleOverride},
+			inputTime:  time.Date(2025, 3, 29, 20, 30, 0, 0, time.Local),
+			age:        14,
+			want:       Decision{Reason: DenyReasonNotAllowedWeekday, Rule: DayRuleNone},
+		},
+		{
+			name:      "成年人",
+			inputTime: time.Date(2025, 3, 30, 20, 30, 0, 0, time.Local),
+			age:       18,
+			want:      Decision{Allowed: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.Precedence = tt.precedence
+			if err := config.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			checker := NewAntiAddictionTimeChecker(config)
+			if got := checker.ExplainDecision(tt.inputTime, tt.age); got != tt.want {
+				t.Errorf("ExplainDecision() = %+v, want %+v", got, tt.want)
+			}
+			if got := checker.IsInPlayTimeAt(tt.age, tt.inputTime); got != tt.want.Allowed {
+				t.Errorf("IsInPlayTimeAt() = %v, want %v", got, tt.want.Allowed)
+			}
+		})
+	}
+}
+
+func TestTimeConfig_ValidatePrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(config *TimeConfig)
+		wantErr bool
+	}{
+		{name: "默认", modify: func(config *TimeConfig) {}},
+		{name: "非法日期", modify: func(config *TimeConfig) {
+			config.Overrides = []DayOverride{{Date: "2025-02-30"}}
+		}, wantErr: true},
+		{name: "非法规则", modify: func(config *TimeConfig) {
+			config.Precedence = []DayRule{"lunar"}
+		}, wantErr: true},
+		{name: "重复规则", modify: func(config *TimeConfig) {
+			config.Precedence = []DayRule{DayRuleHoliday, DayRuleHoliday}
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := getTestTimeConfig()
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
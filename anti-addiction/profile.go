@@ -0,0 +1,107 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// ProfileDefault 默认策略，与 DefaultConfig 一致
+	ProfileDefault = "default"
+	// ProfileChinaNPPA 中国大陆国家新闻出版署防沉迷规定
+	ProfileChinaNPPA = "china-nppa"
+	// ProfileKoreaShutdownRepealed 韩国废除游戏宵禁制度后的策略：不限制游戏时间与充值金额
+	ProfileKoreaShutdownRepealed = "korea-shutdown-repealed"
+)
+
+// PolicyProfile 地区策略预设，返回打包了时间与充值规则的完整配置，每次调用需返回新的实例
+type PolicyProfile func() Config
+
+var (
+	profileMu sync.RWMutex
+	profiles  = map[string]PolicyProfile{
+		ProfileDefault:               DefaultConfig,
+		ProfileChinaNPPA:             chinaNPPAConfig,
+		ProfileKoreaShutdownRepealed: koreaShutdownRepealedConfig,
+	}
+)
+
+// chinaNPPAConfig 《关于进一步严格管理 切实防止未成年人沉迷网络游戏的通知》，即 DefaultConfig 的规则
+func chinaNPPAConfig() Config {
+	return DefaultConfig()
+}
+
+// koreaShutdownRepealedConfig 韩国 2021 年废除游戏宵禁制度后不再有法定的游戏时间与充值限制，
+// 全天均可游玩，限额以韩元计
+func koreaShutdownRepealedConfig() Config {
+	return Config{
+		TimeConfig: TimeConfig{
+			StartHour:       0,
+			EndHour:         23,
+			EndMinute:       59,
+			EndSecond:       59,
+			AllowedWeekDays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+		},
+		PurchaseConfig: PurchaseConfig{
+			Currency: "KRW",
+		},
+	}
+}
+
+// RegisterProfile 注册或覆盖策略预设
+func RegisterProfile(name string, profile PolicyProfile) error {
+	if name == "" || profile == nil {
+		return fmt.Errorf("anti-addiction: invalid policy profile %q", name)
+	}
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profiles[name] = profile
+	return nil
+}
+
+// LookupProfile 根据名称查找策略预设
+func LookupProfile(name string) (PolicyProfile, bool) {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	profile, ok := profiles[name]
+	return profile, ok
+}
+
+// ProfileConfig 返回指定策略预设的配置，Profile 字段设置为预设名称，可在其基础上修改
+func ProfileConfig(name string) (Config, error) {
+	profile, ok := LookupProfile(name)
+	if !ok {
+		return Config{}, fmt.Errorf("anti-addiction: unknown policy profile %q", name)
+	}
+	config := profile()
+	config.Profile = name
+	return config, nil
+}
+
+// applyProfile Profile 非空时以预设为基础：值为零的成年年龄与各项配置取预设中的值，
+// 使 NewAntiAddictionChecker 与 Reload 直接传入的配置与 LoadConfig 一样应用预设
+func (config Config) applyProfile() (Config, error) {
+	if config.Profile == "" {
+		return config, nil
+	}
+	base, err := ProfileConfig(config.Profile)
+	if err != nil {
+		return Config{}, err
+	}
+	inheritZero(&config.AdultAge, base.AdultAge)
+	inheritZero(&config.TimeConfig, base.TimeConfig)
+	inheritZero(&config.PurchaseConfig, base.PurchaseConfig)
+	inheritZero(&config.GuestConfig, base.GuestConfig)
+	inheritZero(&config.GraceConfig, base.GraceConfig)
+	inheritZero(&config.DurationConfig, base.DurationConfig)
+	return config, nil
+}
+
+// inheritZero value 为零值时取 base
+func inheritZero[T any](value *T, base T) {
+	if reflect.ValueOf(value).Elem().IsZero() {
+		*value = base
+	}
+}
@@ -1,32 +1,39 @@
 package anti_addiction
 
-import "slices"
+import (
+	"fmt"
+	"slices"
+)
 
-// MoneyUnit 定义金额单位
+// MoneyUnit 定义金额单位，数值为该单位对应的最小货币单位数量
 type MoneyUnit int
 
 const (
 	UnitFen  MoneyUnit = 1   // 分
 	UnitJiao MoneyUnit = 10  // 角
 	UnitYuan MoneyUnit = 100 // 元
+	// UnitMinor 任意币种的最小单位，如美分、韩元
+	UnitMinor MoneyUnit = 1
 )
 
 // PurchaseLimit 定义不同年龄段的充值限制
 type PurchaseLimit struct {
-	// 单笔充值限制（单位：分）
-	SingleLimit int64
-	// 月度充值限制（单位：分）
-	MonthlyLimit int64
+	// 单笔充值限制（单位：分），0 表示禁止充值，-1 表示无限制
+	SingleLimit int64 `json:"single_limit" yaml:"single-limit"`
+	// 月度充值限制（单位：分），0 表示禁止充值，-1 表示无限制
+	MonthlyLimit int64 `json:"monthly_limit" yaml:"monthly-limit"`
+	// 日充值限制（单位：分），0 或 -1 表示无限制，不配置时不限制当日充值；禁止充值请将单笔或月度限制设为 0
+	DailyLimit int64 `json:"daily_limit" yaml:"daily-limit"`
 }
 
 // AgePurchaseLimit 定义年龄段的充值限制
 type AgePurchaseLimit struct {
 	// 年龄下限（包含）
-	MinAge int32
+	MinAge int32 `json:"min_age" yaml:"min-age"`
 	// 年龄上限（不包含）
-	MaxAge int32
+	MaxAge int32 `json:"max_age" yaml:"max-age"`
 	// 充值限制
-	Limit PurchaseLimit
+	Limit PurchaseLimit `json:"limit" yaml:"limit"`
 }
 
 type AgePurchaseLimits []AgePurchaseLimit
@@ -34,13 +41,21 @@ type AgePurchaseLimits []AgePurchaseLimit
 // PurchaseConfig 充值限制配置
 type PurchaseConfig struct {
 	// 按年龄段配置的充值限制列表
-	AgeLimits AgePurchaseLimits
+	AgeLimits AgePurchaseLimits `json:"age_limits" yaml:"age-limits"`
+	// 限额所使用的币种代码，限额以该币种的最小单位表示，为空时为人民币（分）
+	Currency string `json:"currency" yaml:"currency"`
+	// 币种转换钩子，充值币种与限额币种不一致时使用，未设置时拒绝跨币种充值
+	Converter CurrencyConverter `json:"-" yaml:"-"`
+	// 按商品品类（如抽奖、通行证）额外配置的充值限制，在总限额之外叠加生效，可为空
+	CategoryLimits map[string]AgePurchaseLimits `json:"category_limits" yaml:"category-limits"`
 }
 
 // PurchaseOption 充值检查选项
 type PurchaseOption struct {
-	// 金额单位，默认为分
+	// 金额单位，默认为分（最小单位）
 	Unit MoneyUnit
+	// 充值金额的币种代码，为空时与限额币种一致
+	Currency string
 }
 
 // DefaultPurchaseOption 默认选项，使用分作为单位
@@ -51,13 +66,24 @@ var DefaultPurchaseOption = PurchaseOption{
 // PurchaseChecker 充值检查器
 type PurchaseChecker struct {
 	config PurchaseConfig
+	// 成年年龄，用于年龄段标签
+	adultAge int32
 }
 
-// NewPurchaseChecker 创建充值检查器
-func NewPurchaseChecker(config PurchaseConfig) *PurchaseChecker {
+// NewPurchaseChecker 创建充值检查器，年龄段重叠或存在空隙时返回 *AgeBracketError
+func NewPurchaseChecker(config PurchaseConfig) (*PurchaseChecker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	// 创建配置的副本，避免修改原始配置
 	sortedConfig := PurchaseConfig{
 		AgeLimits: make(AgePurchaseLimits, len(config.AgeLimits)),
+		Currency:  config.Currency,
+		Converter: config.Converter,
+	}
+	if sortedConfig.Currency == "" {
+		sortedConfig.Currency = CurrencyCodeCNY
 	}
 	copy(sortedConfig.AgeLimits, config.AgeLimits)
 
@@ -65,16 +91,40 @@ func NewPurchaseChecker(config PurchaseConfig) *PurchaseChecker {
 	slices.SortFunc(sortedConfig.AgeLimits, func(a, b AgePurchaseLimit) int {
 		return int(a.MinAge - b.MinAge)
 	})
+	if len(config.CategoryLimits) > 0 {
+		sortedConfig.CategoryLimits = make(map[string]AgePurchaseLimits, len(config.CategoryLimits))
+		for category, limits := range config.CategoryLimits {
+			sorted := slices.Clone(limits)
+			slices.SortFunc(sorted, func(a, b AgePurchaseLimit) int {
+				return int(a.MinAge - b.MinAge)
+			})
+			sortedConfig.CategoryLimits[category] = sorted
+		}
+	}
 
 	return &PurchaseChecker{
-		config: sortedConfig,
+		config:   sortedConfig,
+		adultAge: DefaultAdultAge,
+	}, nil
+}
+
+// SetAdultAge 设置成年年龄，<=0 时使用 DefaultAdultAge
+func (checker *PurchaseChecker) SetAdultAge(age int32) {
+	if age <= 0 {
+		age = DefaultAdultAge
 	}
+	checker.adultAge = age
 }
 
 // GetPurchaseLimit 获取指定年龄的充值限制
 func (checker *PurchaseChecker) GetPurchaseLimit(age int32) PurchaseLimit {
+	return checker.config.AgeLimits.limitFor(age)
+}
+
+// limitFor 获取指定年龄的充值限制，没有匹配的年龄段时返回无限制
+func (limits AgePurchaseLimits) limitFor(age int32) PurchaseLimit {
 	// 遍历配置的年龄段，找到匹配的限制
-	for _, ageLimit := range checker.config.AgeLimits {
+	for _, ageLimit := range limits {
 		if age >= ageLimit.MinAge && age < ageLimit.MaxAge {
 			return ageLimit.Limit
 		}
@@ -84,28 +134,72 @@ func (checker *PurchaseChecker) GetPurchaseLimit(age int32) PurchaseLimit {
 	return PurchaseLimit{
 		SingleLimit:  -1,
 		MonthlyLimit: -1,
+		DailyLimit:   -1,
+	}
+}
+
+// AgeBracket 返回年龄段标签，用于指标与审计：命中充值年龄段配置时为 "最小年龄-最大年龄"，如 "8-16"，
+// 否则为 AgeBracketAdult、AgeBracketPending 或 AgeBracketUnknown
+func (checker *PurchaseChecker) AgeBracket(age int32) string {
+	if age == AgePending {
+		return AgeBracketPending
+	}
+	for _, ageLimit := range checker.config.AgeLimits {
+		if age >= ageLimit.MinAge && age < ageLimit.MaxAge {
+			return fmt.Sprintf("%d-%d", ageLimit.MinAge, ageLimit.MaxAge)
+		}
 	}
+	if age >= checker.adultAge {
+		return AgeBracketAdult
+	}
+	return AgeBracketUnknown
 }
 
-// convertAmount 根据单位转换金额为分
+// convertAmount 根据单位转换金额为最小单位
 func convertAmount(amount int64, unit MoneyUnit) int64 {
+	if unit <= 0 {
+		unit = UnitMinor
+	}
 	return amount * int64(unit)
 }
 
+// ConvertAmount 将指定单位、币种的金额转换为限额币种的最小单位（人民币即为分）
+func (checker *PurchaseChecker) ConvertAmount(amount int64, opts ...PurchaseOption) (int64, error) {
+	opt := DefaultPurchaseOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	amountInMinor := convertAmount(amount, opt.Unit)
+	if opt.Currency == "" || opt.Currency == checker.config.Currency {
+		return amountInMinor, nil
+	}
+
+	from, ok := LookupCurrency(opt.Currency)
+	if !ok {
+		return 0, fmt.Errorf("anti-addiction: unknown currency %q", opt.Currency)
+	}
+	to, ok := LookupCurrency(checker.config.Currency)
+	if !ok {
+		return 0, fmt.Errorf("anti-addiction: unknown currency %q", checker.config.Currency)
+	}
+	if checker.config.Converter == nil {
+		return 0, fmt.Errorf("anti-addiction: no converter from %s to %s", from.Code, to.Code)
+	}
+	return checker.config.Converter(amountInMinor, from, to)
+}
+
 // CheckSinglePurchase 检查单笔充值是否超限
 // amount: 充值金额
 // age: 玩家年龄
 // opts: 可选参数，不传则使用默认选项
 // 返回值：是否允许充值
 func (checker *PurchaseChecker) CheckSinglePurchase(amount int64, age int32, opts ...PurchaseOption) bool {
-	// 使用默认选项
-	opt := DefaultPurchaseOption
-	if len(opts) > 0 {
-		opt = opts[0]
+	// 转换金额为限额币种的最小单位，币种无法转换时拒绝充值
+	amountInFen, err := checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return false
 	}
-
-	// 转换金额为分
-	amountInFen := convertAmount(amount, opt.Unit)
 	limit := checker.GetPurchaseLimit(age)
 
 	if limit.SingleLimit == 0 {
@@ -126,15 +220,15 @@ func (checker *PurchaseChecker) CheckSinglePurchase(amount int64, age int32, opt
 // opts: 可选参数，不传则使用默认选项
 // 返回值：是否允许充值
 func (checker *PurchaseChecker) CheckMonthlyPurchase(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) bool {
-	// 使用默认选项
-	opt := DefaultPurchaseOption
-	if len(opts) > 0 {
-		opt = opts[0]
+	// 转换金额为限额币种的最小单位，币种无法转换时拒绝充值
+	amountInFen, err := checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return false
+	}
+	monthlyTotalInFen, err := checker.ConvertAmount(monthlyTotal, opts...)
+	if err != nil {
+		return false
 	}
-
-	// 转换金额为分
-	amountInFen := convertAmount(amount, opt.Unit)
-	monthlyTotalInFen := convertAmount(monthlyTotal, opt.Unit)
 	limit := checker.GetPurchaseLimit(age)
 
 	if limit.MonthlyLimit == 0 {
@@ -147,3 +241,209 @@ func (checker *PurchaseChecker) CheckMonthlyPurchase(amount int64, monthlyTotal
 
 	return monthlyTotalInFen+amountInFen <= limit.MonthlyLimit
 }
+
+// CheckDailyPurchase 检查当日充值是否超限
+// amount: 本次充值金额
+// dailyTotal: 当日已充值总额
+// age: 玩家年龄
+// opts: 可选参数，不传则使用默认选项
+// 返回值：是否允许充值
+func (checker *PurchaseChecker) CheckDailyPurchase(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) bool {
+	// 转换金额为限额币种的最小单位，币种无法转换时拒绝充值
+	amountInFen, err := checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return false
+	}
+	dailyTotalInFen, err := checker.ConvertAmount(dailyTotal, opts...)
+	if err != nil {
+		return false
+	}
+	limit := checker.GetPurchaseLimit(age)
+
+	if limit.DailyLimit <= 0 {
+		return true
+	}
+
+	return dailyTotalInFen+amountInFen <= limit.DailyLimit
+}
+
+// CheckSinglePurchaseWithReason 检查单笔充值是否超限，拒绝时同时返回原因，允许时原因为 DenyReasonNone
+func (checker *PurchaseChecker) CheckSinglePurchaseWithReason(amount int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	if checker.CheckSinglePurchase(amount, age, opts...) {
+		return true, DenyReasonNone
+	}
+	return false, checker.denyReason(age, DenyReasonSingleLimit, opts...)
+}
+
+// CheckMonthlyPurchaseWithReason 检查月度充值是否超限，拒绝时同时返回原因，允许时原因为 DenyReasonNone
+func (checker *PurchaseChecker) CheckMonthlyPurchaseWithReason(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	if checker.CheckMonthlyPurchase(amount, monthlyTotal, age, opts...) {
+		return true, DenyReasonNone
+	}
+	return false, checker.denyReason(age, DenyReasonMonthlyLimit, opts...)
+}
+
+// CheckDailyPurchaseWithReason 检查当日充值是否超限，拒绝时同时返回原因，允许时原因为 DenyReasonNone
+func (checker *PurchaseChecker) CheckDailyPurchaseWithReason(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason) {
+	if checker.CheckDailyPurchase(amount, dailyTotal, age, opts...) {
+		return true, DenyReasonNone
+	}
+	return false, checker.denyReason(age, DenyReasonDailyLimit, opts...)
+}
+
+// denyReason 推断充值检查被拒绝的原因：币种无法转换、年龄段禁充，否则为该检查对应的限额原因
+func (checker *PurchaseChecker) denyReason(age int32, limitReason DenyReason, opts ...PurchaseOption) DenyReason {
+	if _, err := checker.ConvertAmount(0, opts...); err != nil {
+		return DenyReasonCurrencyUnsupported
+	}
+	limit := checker.GetPurchaseLimit(age)
+	if limit.SingleLimit == 0 || limit.MonthlyLimit == 0 {
+		return DenyReasonAgeBanned
+	}
+	return limitReason
+}
+
+// PurchaseDecision 充值检查的详细结果，金额均为限额币种的最小单位（人民币即为分）
+type PurchaseDecision struct {
+	// 是否允许充值
+	Allowed bool
+	// 拒绝原因，允许时为 DenyReasonNone
+	Reason DenyReason
+	// 导致拒绝的限额，允许时为 0
+	Threshold int64
+	// 本次最多可充值的金额，-1 表示无限制
+	MaxAmount int64
+	// 由品类限额导致拒绝时为品类名称
+	Category string
+}
+
+// CheckPurchaseDetailed 依次检查年龄段禁充、单笔、当日、月度限额，返回详细结果
+// amount: 本次充值金额
+// dailyTotal: 当日已充值总额
+// monthlyTotal: 当月已充值总额
+// age: 玩家年龄
+// opts: 可选参数，不传则使用默认选项
+func (checker *PurchaseChecker) CheckPurchaseDetailed(amount int64, dailyTotal int64, monthlyTotal int64, age int32, opts ...PurchaseOption) PurchaseDecision {
+	amountInFen, err := checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return PurchaseDecision{Reason: DenyReasonCurrencyUnsupported}
+	}
+	dailyTotalInFen, err := checker.ConvertAmount(dailyTotal, opts...)
+	if err != nil {
+		return PurchaseDecision{Reason: DenyReasonCurrencyUnsupported}
+	}
+	monthlyTotalInFen, err := checker.ConvertAmount(monthlyTotal, opts...)
+	if err != nil {
+		return PurchaseDecision{Reason: DenyReasonCurrencyUnsupported}
+	}
+
+	return evaluatePurchaseLimit(checker.GetPurchaseLimit(age), amountInFen, dailyTotalInFen, monthlyTotalInFen)
+}
+
+// evaluatePurchaseLimit 依次检查禁充、单笔、当日、月度限额，金额均为限额币种的最小单位
+func evaluatePurchaseLimit(limit PurchaseLimit, amountInFen, dailyTotalInFen, monthlyTotalInFen int64) PurchaseDecision {
+	if limit.SingleLimit == 0 || limit.MonthlyLimit == 0 {
+		return PurchaseDecision{Reason: DenyReasonAgeBanned}
+	}
+
+	maxAmount := int64(-1)
+	lowerMax := func(remaining int64) {
+		remaining = max(remaining, 0)
+		if maxAmount == -1 || remaining < maxAmount {
+			maxAmount = remaining
+		}
+	}
+	if limit.SingleLimit > 0 {
+		lowerMax(limit.SingleLimit)
+	}
+	if limit.DailyLimit > 0 {
+		lowerMax(limit.DailyLimit - dailyTotalInFen)
+	}
+	if limit.MonthlyLimit > 0 {
+		lowerMax(limit.MonthlyLimit - monthlyTotalInFen)
+	}
+
+	decision := PurchaseDecision{Allowed: true, MaxAmount: maxAmount}
+	switch {
+	case limit.SingleLimit > 0 && amountInFen > limit.SingleLimit:
+		decision.Reason, decision.Threshold = DenyReasonSingleLimit, limit.SingleLimit
+	case limit.DailyLimit > 0 && dailyTotalInFen+amountInFen > limit.DailyLimit:
+		decision.Reason, decision.Threshold = DenyReasonDailyLimit, limit.DailyLimit
+	case limit.MonthlyLimit > 0 && monthlyTotalInFen+amountInFen > limit.MonthlyLimit:
+		decision.Reason, decision.Threshold = DenyReasonMonthlyLimit, limit.MonthlyLimit
+	}
+	decision.Allowed = decision.Reason == DenyReasonNone
+	return decision
+}
+
+// convertFromLimit 将限额币种的最小单位金额转换回指定单位、币种，不足一个单位的部分舍去
+func (checker *PurchaseChecker) convertFromLimit(amountInMinor int64, opt PurchaseOption) (int64, error) {
+	if opt.Currency != "" && opt.Currency != checker.config.Currency {
+		from, ok := LookupCurrency(checker.config.Currency)
+		if !ok {
+			return 0, fmt.Errorf("anti-addiction: unknown currency %q", checker.config.Currency)
+		}
+		to, ok := LookupCurrency(opt.Currency)
+		if !ok {
+			return 0, fmt.Errorf("anti-addiction: unknown currency %q", opt.Currency)
+		}
+		if checker.config.Converter == nil {
+			return 0, fmt.Errorf("anti-addiction: no converter from %s to %s", from.Code, to.Code)
+		}
+		converted, err := checker.config.Converter(amountInMinor, from, to)
+		if err != nil {
+			return 0, err
+		}
+		amountInMinor = converted
+	}
+	unit := opt.Unit
+	if unit <= 0 {
+		unit = UnitMinor
+	}
+	return amountInMinor / int64(unit), nil
+}
+
+// GetMaxSingleAmount 获取指定年龄单笔最多可充值的金额
+// opts: 可选参数，指定返回值的单位与币种，不传则使用分作为单位
+// 返回值：-1 表示无限制，0 表示禁止充值
+func (checker *PurchaseChecker) GetMaxSingleAmount(age int32, opts ...PurchaseOption) int64 {
+	opt := DefaultPurchaseOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	limit := checker.GetPurchaseLimit(age)
+	if limit.SingleLimit == -1 {
+		return -1
+	}
+	amount, err := checker.convertFromLimit(limit.SingleLimit, opt)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+// GetRemainingMonthlyQuota 获取指定年龄当月剩余可充值额度
+// monthlyTotal: 当月已充值总额，单位与币种同 opts
+// opts: 可选参数，指定入参与返回值的单位与币种，不传则使用分作为单位
+// 返回值：-1 表示无限制，0 表示已无额度
+func (checker *PurchaseChecker) GetRemainingMonthlyQuota(age int32, monthlyTotal int64, opts ...PurchaseOption) int64 {
+	opt := DefaultPurchaseOption
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	limit := checker.GetPurchaseLimit(age)
+	if limit.MonthlyLimit == -1 {
+		return -1
+	}
+	monthlyTotalInFen, err := checker.ConvertAmount(monthlyTotal, opt)
+	if err != nil {
+		return 0
+	}
+	remaining, err := checker.convertFromLimit(max(limit.MonthlyLimit-monthlyTotalInFen, 0), opt)
+	if err != nil {
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,404 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+const (
+	// defaultRecordRetryTimes 乐观锁冲突时的默认重试次数
+	defaultRecordRetryTimes = 3
+	// defaultReserveTTL 充值预占的默认有效期
+	defaultReserveTTL = 15 * time.Minute
+	// committedRetention 已确认订单号的保留时长，期间重复确认或补录同一订单不会重复累计
+	committedRetention = 72 * time.Hour
+)
+
+var (
+	ErrReservationNotFound = errors.New("anti-addiction: purchase reservation not found or expired")
+	ErrReservationExists   = errors.New("anti-addiction: purchase reservation already exists")
+)
+
+// PurchaseLimitChecker 充值限额检查，*PurchaseChecker 与 AntiAddictionChecker 均满足该接口
+type PurchaseLimitChecker interface {
+	CheckSinglePurchase(amount int64, age int32, opts ...PurchaseOption) bool
+	CheckMonthlyPurchase(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) bool
+	CheckDailyPurchase(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) bool
+	CheckSinglePurchaseWithReason(amount int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	CheckMonthlyPurchaseWithReason(amount int64, monthlyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	CheckDailyPurchaseWithReason(amount int64, dailyTotal int64, age int32, opts ...PurchaseOption) (bool, DenyReason)
+	ConvertAmount(amount int64, opts ...PurchaseOption) (int64, error)
+	AgeBracket(age int32) string
+}
+
+// purchaseRecord 玩家当月及当日的充值累计，存储在以玩家ID为field的hash中
+type purchaseRecord struct {
+	// 记录所属月份，格式 200601，与当前月份不一致时视为已跨月
+	Month string `json:"month"`
+	// 当月已充值总额（单位：分）
+	MonthlyTotal int64 `json:"monthly_total"`
+	// 记录所属日期，格式 20060102，与当前日期不一致时视为已跨天
+	Day string `json:"day"`
+	// 当日已充值总额（单位：分）
+	DailyTotal int64 `json:"daily_total"`
+	// 尚未确认的充值预占，key 为订单号
+	Reservations map[string]purchaseReservation `json:"reservations,omitempty"`
+	// 已确认的订单号及确认时间戳（毫秒），用于 CommitPurchase 与 RecordPurchase 的幂等
+	Committed map[string]int64 `json:"committed,omitempty"`
+}
+
+// purchaseReservation 充值预占
+type purchaseReservation struct {
+	// 预占金额（单位：分）
+	Amount int64 `json:"amount"`
+	// 过期时间戳（毫秒）
+	ExpireAt int64 `json:"expire_at"`
+}
+
+func (record *purchaseRecord) MarshalBinary() ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (record *purchaseRecord) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, record)
+}
+
+// rollover 跨月、跨天时清空对应累计
+func (record *purchaseRecord) rollover(now time.Time) {
+	if month := now.Format("200601"); record.Month != month {
+		record.Month = month
+		record.MonthlyTotal = 0
+	}
+	if day := now.Format("20060102"); record.Day != day {
+		record.Day = day
+		record.DailyTotal = 0
+	}
+}
+
+// reservedAmount 返回所有预占的金额之和
+func (record *purchaseRecord) reservedAmount() int64 {
+	var total int64
+	for _, reservation := range record.Reservations {
+		total += reservation.Amount
+	}
+	return total
+}
+
+// purgeExpired 清除已过期的预占与超过保留时长的已确认订单号
+func (record *purchaseRecord) purgeExpired(now time.Time) {
+	for orderID, reservation := range record.Reservations {
+		if reservation.ExpireAt <= now.UnixMilli() {
+			delete(record.Reservations, orderID)
+		}
+	}
+	for orderID, committedAt := range record.Committed {
+		if committedAt+committedRetention.Milliseconds() <= now.UnixMilli() {
+			delete(record.Committed, orderID)
+		}
+	}
+}
+
+// commit 将金额计入当日及当月累计，并记录订单已确认
+func (record *purchaseRecord) commit(orderID string, amountInFen int64, now time.Time) {
+	delete(record.Reservations, orderID)
+	record.MonthlyTotal += amountInFen
+	record.DailyTotal += amountInFen
+	if record.Committed == nil {
+		record.Committed = make(map[string]int64)
+	}
+	record.Committed[orderID] = now.UnixMilli()
+}
+
+// PurchaseRecorder 基于 global-storage 的充值累计记录器，
+// 每个玩家在 hash 中占用一个 field，跨月、跨天后对应累计自动从 0 开始
+type PurchaseRecorder struct {
+	checker    PurchaseLimitChecker
+	hash       storage.HashTransactional
+	retryTimes int
+	reserveTTL time.Duration
+	audit      AuditSink
+	exempt     *Exemptions
+	timeNow    func() time.Time
+}
+
+// NewPurchaseRecorder 创建充值累计记录器
+// hash: 需以 NewPurchaseRecordFactory 作为数据工厂注册的 hash 存储
+func NewPurchaseRecorder(checker PurchaseLimitChecker, hash storage.HashTransactional) *PurchaseRecorder {
+	return &PurchaseRecorder{
+		checker:    checker,
+		hash:       hash,
+		retryTimes: defaultRecordRetryTimes,
+		reserveTTL: defaultReserveTTL,
+		timeNow:    time.Now,
+	}
+}
+
+// NewPurchaseRecordFactory 返回充值记录的数据工厂，用于注册 hash 存储
+func NewPurchaseRecordFactory() storage.StorageData {
+	return &purchaseRecord{}
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (recorder *PurchaseRecorder) SetTimeNow(timeNow func() time.Time) {
+	recorder.timeNow = timeNow
+}
+
+// SetRetryTimes 设置事务冲突时的重试次数
+func (recorder *PurchaseRecorder) SetRetryTimes(retryTimes int) {
+	recorder.retryTimes = retryTimes
+}
+
+// SetReserveTTL 设置充值预占的有效期
+func (recorder *PurchaseRecorder) SetReserveTTL(ttl time.Duration) {
+	recorder.reserveTTL = ttl
+}
+
+// SetAuditSink 设置审计记录，充值被限额拒绝时写入
+func (recorder *PurchaseRecorder) SetAuditSink(sink AuditSink) {
+	recorder.audit = sink
+}
+
+// SetExemptions 设置豁免名单，名单内玩家的充值不受限额限制，但仍计入累计
+func (recorder *PurchaseRecorder) SetExemptions(exemptions *Exemptions) {
+	recorder.exempt = exemptions
+}
+
+// GetReservedAmount 获取玩家尚未确认且未过期的预占总额（单位：分）
+func (recorder *PurchaseRecorder) GetReservedAmount(ctx context.Context, playerID int64) (int64, error) {
+	record, err := recorder.getRecord(ctx, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return record.reservedAmount(), nil
+}
+
+// GetMonthlyTotal 获取玩家当月已充值总额（单位：分）
+func (recorder *PurchaseRecorder) GetMonthlyTotal(ctx context.Context, playerID int64) (int64, error) {
+	record, err := recorder.getRecord(ctx, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return record.MonthlyTotal, nil
+}
+
+// GetDailyTotal 获取玩家当日已充值总额（单位：分）
+func (recorder *PurchaseRecorder) GetDailyTotal(ctx context.Context, playerID int64) (int64, error) {
+	record, err := recorder.getRecord(ctx, playerID)
+	if err != nil {
+		return 0, err
+	}
+	return record.DailyTotal, nil
+}
+
+func (recorder *PurchaseRecorder) getRecord(ctx context.Context, playerID int64) (*purchaseRecord, error) {
+	record := &purchaseRecord{}
+	data, err := recorder.hash.HGet(ctx, strconv.FormatInt(playerID, 10))
+	if err != nil && !errors.Is(err, storage.ErrFieldNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		var ok bool
+		if record, ok = data.(*purchaseRecord); !ok {
+			return nil, errors.New("anti-addiction: unexpected purchase record type")
+		}
+	}
+	now := recorder.timeNow()
+	record.rollover(now)
+	record.purgeExpired(now)
+	return record, nil
+}
+
+// CheckAndRecordPurchase 校验单笔、当日与月度限额，通过后原子地累加当日及当月充值总额（检查时读取的记录被其他节点修改则重试），
+// 尚未确认的预占额度同样计入限额
+// amount: 充值金额
+// opts: 可选参数，不传则使用默认选项，使用分作为单位
+// 返回值：是否允许充值；事务多次冲突或存储异常时返回错误
+func (recorder *PurchaseRecorder) CheckAndRecordPurchase(ctx context.Context, playerID int64, amount int64, age int32, opts ...PurchaseOption) (bool, error) {
+	exempt := recorder.exempt.IsExempt(playerID)
+	if allowed, reason := recorder.checker.CheckSinglePurchaseWithReason(amount, age, opts...); !allowed && !exempt {
+		recorder.recordDenial(ctx, playerID, age, 0, reason)
+		return false, nil
+	}
+
+	// 累计统一使用限额币种的最小单位
+	amountInFen, err := recorder.checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	denied := DenyReasonNone
+	allowed, err := recorder.updateRecord(ctx, playerID, func(record *purchaseRecord) (bool, error) {
+		if denied = recorder.checkRecord(record, amountInFen, age); denied != DenyReasonNone && !exempt {
+			return false, nil
+		}
+		denied = DenyReasonNone
+		record.MonthlyTotal += amountInFen
+		record.DailyTotal += amountInFen
+		return true, nil
+	})
+	if err == nil && denied != DenyReasonNone {
+		recorder.recordDenial(ctx, playerID, age, amountInFen, denied)
+	}
+	return allowed, err
+}
+
+// ReservePurchase 校验限额后为订单预占额度，预占额度在 TTL 内计入限额，避免支付处理期间额度被重复使用。
+// 支付成功后调用 CommitPurchase 计入累计，支付失败调用 CancelPurchase 释放，超时未处理的预占自动失效
+// orderID: 订单号，同一玩家下唯一
+// 返回值：是否允许充值；订单已存在预占时返回 ErrReservationExists
+func (recorder *PurchaseRecorder) ReservePurchase(ctx context.Context, playerID int64, orderID string, amount int64, age int32, opts ...PurchaseOption) (bool, error) {
+	exempt := recorder.exempt.IsExempt(playerID)
+	if allowed, reason := recorder.checker.CheckSinglePurchaseWithReason(amount, age, opts...); !allowed && !exempt {
+		recorder.recordDenial(ctx, playerID, age, 0, reason)
+		return false, nil
+	}
+
+	amountInFen, err := recorder.checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	denied := DenyReasonNone
+	allowed, err := recorder.updateRecord(ctx, playerID, func(record *purchaseRecord) (bool, error) {
+		if _, exists := record.Reservations[orderID]; exists {
+			return false, ErrReservationExists
+		}
+		if denied = recorder.checkRecord(record, amountInFen, age); denied != DenyReasonNone && !exempt {
+			return false, nil
+		}
+		denied = DenyReasonNone
+		if record.Reservations == nil {
+			record.Reservations = make(map[string]purchaseReservation)
+		}
+		record.Reservations[orderID] = purchaseReservation{
+			Amount:   amountInFen,
+			ExpireAt: recorder.timeNow().Add(recorder.reserveTTL).UnixMilli(),
+		}
+		return true, nil
+	})
+	if err == nil && denied != DenyReasonNone {
+		recorder.recordDenial(ctx, playerID, age, amountInFen, denied)
+	}
+	return allowed, err
+}
+
+// CommitPurchase 确认订单预占的额度，将其计入当日及当月累计；同一订单重复确认时直接返回
+// 预占不存在或已过期时返回 ErrReservationNotFound，已支付成功的订单应改用 RecordPurchase 按实际金额补录
+func (recorder *PurchaseRecorder) CommitPurchase(ctx context.Context, playerID int64, orderID string) error {
+	_, err := recorder.updateRecord(ctx, playerID, func(record *purchaseRecord) (bool, error) {
+		if _, committed := record.Committed[orderID]; committed {
+			return false, nil
+		}
+		reservation, exists := record.Reservations[orderID]
+		if !exists {
+			return false, ErrReservationNotFound
+		}
+		record.commit(orderID, reservation.Amount, recorder.timeNow())
+		return true, nil
+	})
+	return err
+}
+
+// RecordPurchase 不检查限额，将已支付成功的订单金额计入当日及当月累计，订单存在预占时一并释放；
+// 以订单号保证幂等，同一订单重复调用或已通过 CommitPurchase 确认时不会重复累计。
+// 用于预占过期后才收到支付结果等必须入账的场景
+// amount: 实际支付金额
+// opts: 可选参数，不传则使用默认选项，使用分作为单位
+func (recorder *PurchaseRecorder) RecordPurchase(ctx context.Context, playerID int64, orderID string, amount int64, opts ...PurchaseOption) error {
+	amountInFen, err := recorder.checker.ConvertAmount(amount, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = recorder.updateRecord(ctx, playerID, func(record *purchaseRecord) (bool, error) {
+		if _, committed := record.Committed[orderID]; committed {
+			return false, nil
+		}
+		record.commit(orderID, amountInFen, recorder.timeNow())
+		return true, nil
+	})
+	return err
+}
+
+// CancelPurchase 释放订单预占的额度，预占不存在或已过期时返回 ErrReservationNotFound
+func (recorder *PurchaseRecorder) CancelPurchase(ctx context.Context, playerID int64, orderID string) error {
+	_, err := recorder.updateRecord(ctx, playerID, func(record *purchaseRecord) (bool, error) {
+		if _, exists := record.Reservations[orderID]; !exists {
+			return false, ErrReservationNotFound
+		}
+		delete(record.Reservations, orderID)
+		return true, nil
+	})
+	return err
+}
+
+// checkRecord 将已累计金额与未过期的预占金额一并计入，检查当日与月度限额，返回拒绝原因
+func (recorder *PurchaseRecorder) checkRecord(record *purchaseRecord, amountInFen int64, age int32) DenyReason {
+	reserved := record.reservedAmount()
+	opt := PurchaseOption{Unit: UnitMinor}
+	if allowed, reason := recorder.checker.CheckDailyPurchaseWithReason(amountInFen, record.DailyTotal+reserved, age, opt); !allowed {
+		return reason
+	}
+	_, reason := recorder.checker.CheckMonthlyPurchaseWithReason(amountInFen, record.MonthlyTotal+reserved, age, opt)
+	return reason
+}
+
+// recordDenial 记录被拒绝的充值，amountInFen 为 0 表示金额未能转换
+func (recorder *PurchaseRecorder) recordDenial(ctx context.Context, playerID int64, age int32, amountInFen int64, reason DenyReason) {
+	if recorder.audit == nil {
+		return
+	}
+	recordAudit(ctx, recorder.audit, AuditEntry{
+		PlayerID:   playerID,
+		AgeBracket: recorder.checker.AgeBracket(age),
+		Kind:       AuditKindPurchase,
+		Reason:     reason,
+		Amount:     amountInFen,
+		Timestamp:  recorder.timeNow().UnixMilli(),
+	})
+}
+
+// updateRecord 读取并修改玩家记录，fn 返回 true 时写回；写回通过 HUpdate 比对该玩家的记录在读取后未被修改，
+// 并发修改时按 retryTimes 重新读取并调用 fn，保证限额检查与累加之间不会丢失其他节点的写入
+func (recorder *PurchaseRecorder) updateRecord(ctx context.Context, playerID int64, fn func(record *purchaseRecord) (bool, error)) (bool, error) {
+	field := strconv.FormatInt(playerID, 10)
+	var err error
+	for i := 0; i <= recorder.retryTimes; i++ {
+		var updated bool
+		updated, err = recorder.tryUpdateRecord(ctx, field, fn)
+		if !errors.Is(err, storage.ErrTransactionConflict) {
+			return updated, err
+		}
+	}
+	return false, err
+}
+
+func (recorder *PurchaseRecorder) tryUpdateRecord(ctx context.Context, field string, fn func(record *purchaseRecord) (bool, error)) (bool, error) {
+	updated := false
+	err := recorder.hash.HUpdate(ctx, field, func(current storage.StorageData) (storage.StorageData, error) {
+		record := &purchaseRecord{}
+		if current != nil {
+			var ok bool
+			if record, ok = current.(*purchaseRecord); !ok {
+				return nil, errors.New("anti-addiction: unexpected purchase record type")
+			}
+		}
+		now := recorder.timeNow()
+		record.rollover(now)
+		record.purgeExpired(now)
+
+		var err error
+		if updated, err = fn(record); err != nil || !updated {
+			return nil, err
+		}
+		return record, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return updated, nil
+}
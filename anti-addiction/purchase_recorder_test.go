@@ -0,0 +1,360 @@
+package anti_addiction
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// fakeHash 基于内存实现的 storage.HashTransactional：HUpdate 与 redisHash 一致，只在该字段读取后被修改时冲突；
+// 事务在 BeginTx 之后 key 有任何写入即冲突
+type fakeHash struct {
+	mu          sync.Mutex
+	data        map[string][]byte
+	version     int
+	dataFactory storage.StorageDataFactory
+}
+
+func newFakeHash(dataFactory storage.StorageDataFactory) *fakeHash {
+	return &fakeHash{data: make(map[string][]byte), dataFactory: dataFactory}
+}
+
+func (h *fakeHash) HGetAll(ctx context.Context) (map[string]storage.StorageData, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	res := make(map[string]storage.StorageData, len(h.data))
+	for f, v := range h.data {
+		data := h.dataFactory()
+		if err := data.UnmarshalBinary(v); err != nil {
+			return nil, err
+		}
+		res[f] = data
+	}
+	return res, nil
+}
+
+func (h *fakeHash) HSet(ctx context.Context, field string, value storage.StorageData) error {
+	b, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.data[field] = b
+	h.version++
+	return nil
+}
+
+func (h *fakeHash) HGet(ctx context.Context, field string) (storage.StorageData, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.data[field]
+	if !ok {
+		return nil, storage.ErrFieldNotFound
+	}
+	data := h.dataFactory()
+	return data, data.UnmarshalBinary(b)
+}
+
+func (h *fakeHash) HDel(ctx context.Context, fields ...string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, f := range fields {
+		delete(h.data, f)
+	}
+	h.version++
+	return nil
+}
+
+func (h *fakeHash) HUpdate(ctx context.Context, field string, fn func(current storage.StorageData) (storage.StorageData, error)) error {
+	h.mu.Lock()
+	b, exists := h.data[field]
+	h.mu.Unlock()
+
+	var current storage.StorageData
+	if exists {
+		current = h.dataFactory()
+		if err := current.UnmarshalBinary(b); err != nil {
+			return err
+		}
+	}
+	next, err := fn(current)
+	if err != nil || next == nil {
+		return err
+	}
+	value, err := next.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if now, ok := h.data[field]; ok != exists || string(now) != string(b) {
+		return storage.ErrTransactionConflict
+	}
+	h.data[field] = value
+	h.version++
+	return nil
+}
+
+func (h *fakeHash) BeginTx(ctx context.Context) (storage.HashTransaction, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot := make(map[string][]byte, len(h.data))
+	for f, v := range h.data {
+		snapshot[f] = v
+	}
+	return &fakeHashTx{base: h, version: h.version, snapshot: snapshot, writes: make(map[string][]byte)}, nil
+}
+
+type fakeHashTx struct {
+	base     *fakeHash
+	version  int
+	snapshot map[string][]byte
+	writes   map[string][]byte
+}
+
+func (tx *fakeHashTx) HGetAll(newDataFn func() storage.StorageData) (map[string]storage.StorageData, error) {
+	res := make(map[string]storage.StorageData)
+	for f := range tx.snapshot {
+		data := newDataFn()
+		if err := tx.HGet(f, data); err == nil {
+			res[f] = data
+		}
+	}
+	for f := range tx.writes {
+		data := newDataFn()
+		if err := tx.HGet(f, data); err == nil {
+			res[f] = data
+		}
+	}
+	return res, nil
+}
+
+func (tx *fakeHashTx) HSet(field string, value storage.StorageData) error {
+	b, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tx.writes[field] = b
+	return nil
+}
+
+func (tx *fakeHashTx) HGet(field string, dest storage.StorageData) error {
+	b, ok := tx.writes[field]
+	if !ok {
+		b, ok = tx.snapshot[field]
+	}
+	if !ok || b == nil {
+		return storage.ErrFieldNotFound
+	}
+	return dest.UnmarshalBinary(b)
+}
+
+func (tx *fakeHashTx) HDel(fields ...string) error {
+	for _, f := range fields {
+		tx.writes[f] = nil
+	}
+	return nil
+}
+
+func (tx *fakeHashTx) Commit(ctx context.Context) error {
+	tx.base.mu.Lock()
+	defer tx.base.mu.Unlock()
+	if tx.base.version != tx.version {
+		return storage.ErrTransactionConflict
+	}
+	for f, v := range tx.writes {
+		if v == nil {
+			delete(tx.base.data, f)
+		} else {
+			tx.base.data[f] = v
+		}
+	}
+	tx.base.version++
+	return nil
+}
+
+func (tx *fakeHashTx) Rollback() {}
+
+func TestPurchaseRecorder_CheckAndRecordPurchase(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewPurchaseRecorder(mustNewPurchaseChecker(t, getDefaultPurchaseConfig()), newFakeHash(NewPurchaseRecordFactory))
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	recorder.SetTimeNow(func() time.Time { return now })
+
+	steps := []struct {
+		name      string
+		amount    int64
+		opt       PurchaseOption
+		wantAllow bool
+		wantTotal int64
+	}{
+		{name: "首笔充值", amount: 5000, opt: DefaultPurchaseOption, wantAllow: true, wantTotal: 5000},
+		{name: "超过单笔限额", amount: 5001, opt: DefaultPurchaseOption, wantAllow: false, wantTotal: 5000},
+		{name: "以元为单位充值", amount: 50, opt: PurchaseOption{Unit: UnitYuan}, wantAllow: true, wantTotal: 10000},
+		{name: "达到月度限额", amount: 5000, opt: DefaultPurchaseOption, wantAllow: true, wantTotal: 15000},
+		{name: "达到月度限额", amount: 5000, opt: DefaultPurchaseOption, wantAllow: true, wantTotal: 20000},
+		{name: "超过月度限额", amount: 1, opt: DefaultPurchaseOption, wantAllow: false, wantTotal: 20000},
+	}
+
+	for _, step := range steps {
+		allowed, err := recorder.CheckAndRecordPurchase(ctx, 1001, step.amount, 10, step.opt)
+		if err != nil {
+			t.Fatalf("%s: CheckAndRecordPurchase() error = %v", step.name, err)
+		}
+		if allowed != step.wantAllow {
+			t.Errorf("%s: CheckAndRecordPurchase() = %v, want %v", step.name, allowed, step.wantAllow)
+		}
+		total, err := recorder.GetMonthlyTotal(ctx, 1001)
+		if err != nil {
+			t.Fatalf("%s: GetMonthlyTotal() error = %v", step.name, err)
+		}
+		if total != step.wantTotal {
+			t.Errorf("%s: GetMonthlyTotal() = %d, want %d", step.name, total, step.wantTotal)
+		}
+	}
+
+	// 跨月后累计清零
+	now = now.AddDate(0, 1, 0)
+	total, err := recorder.GetMonthlyTotal(ctx, 1001)
+	if err != nil || total != 0 {
+		t.Errorf("GetMonthlyTotal() after rollover = %d, %v, want 0", total, err)
+	}
+	allowed, err := recorder.CheckAndRecordPurchase(ctx, 1001, 5000, 10)
+	if err != nil || !allowed {
+		t.Errorf("CheckAndRecordPurchase() after rollover = %v, %v, want true", allowed, err)
+	}
+}
+
+func TestPurchaseRecorder_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewPurchaseRecorder(mustNewPurchaseChecker(t, getDefaultPurchaseConfig()), newFakeHash(NewPurchaseRecordFactory))
+	recorder.SetRetryTimes(100)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowedCount := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, err := recorder.CheckAndRecordPurchase(ctx, 1002, 5000, 10)
+			if err != nil {
+				t.Errorf("CheckAndRecordPurchase() error = %v", err)
+				return
+			}
+			if allowed {
+				mu.Lock()
+				allowedCount++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 月度限额 200 元，每笔 50 元，只允许 4 笔
+	if allowedCount != 4 {
+		t.Errorf("allowed purchases = %d, want 4", allowedCount)
+	}
+}
+
+func TestPurchaseRecorder_DailyLimit(t *testing.T) {
+	ctx := context.Background()
+	config := getDefaultPurchaseConfig()
+	config.AgeLimits[1].Limit.DailyLimit = 8000
+	recorder := NewPurchaseRecorder(mustNewPurchaseChecker(t, config), newFakeHash(NewPurchaseRecordFactory))
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	recorder.SetTimeNow(func() time.Time { return now })
+
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 1003, 5000, 10); err != nil || !allowed {
+		t.Fatalf("CheckAndRecordPurchase() = %v, %v, want true", allowed, err)
+	}
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 1003, 5000, 10); err != nil || allowed {
+		t.Errorf("CheckAndRecordPurchase() over daily limit = %v, %v, want false", allowed, err)
+	}
+
+	// 跨天后日累计清零，月累计保留
+	now = now.AddDate(0, 0, 1)
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 1003, 5000, 10); err != nil || !allowed {
+		t.Errorf("CheckAndRecordPurchase() next day = %v, %v, want true", allowed, err)
+	}
+	if total, _ := recorder.GetMonthlyTotal(ctx, 1003); total != 10000 {
+		t.Errorf("GetMonthlyTotal() = %d, want 10000", total)
+	}
+	if total, _ := recorder.GetDailyTotal(ctx, 1003); total != 5000 {
+		t.Errorf("GetDailyTotal() = %d, want 5000", total)
+	}
+}
+
+func TestPurchaseRecorder_ReserveCommitCancel(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewPurchaseRecorder(mustNewPurchaseChecker(t, getDefaultPurchaseConfig()), newFakeHash(NewPurchaseRecordFactory))
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	recorder.SetTimeNow(func() time.Time { return now })
+	recorder.SetReserveTTL(10 * time.Minute)
+
+	// 月度限额 200 元，预占 3 笔 50 元
+	for _, orderID := range []string{"order-1", "order-2", "order-3"} {
+		if allowed, err := recorder.ReservePurchase(ctx, 1004, orderID, 5000, 10); err != nil || !allowed {
+			t.Fatalf("ReservePurchase(%s) = %v, %v, want true", orderID, allowed, err)
+		}
+	}
+	if _, err := recorder.ReservePurchase(ctx, 1004, "order-1", 100, 10); !errors.Is(err, ErrReservationExists) {
+		t.Errorf("ReservePurchase() duplicate order error = %v, want ErrReservationExists", err)
+	}
+
+	// 预占额度计入限额
+	if allowed, err := recorder.CheckAndRecordPurchase(ctx, 1004, 5000, 10); err != nil || !allowed {
+		t.Fatalf("CheckAndRecordPurchase() = %v, %v, want true", allowed, err)
+	}
+	if allowed, err := recorder.ReservePurchase(ctx, 1004, "order-4", 1, 10); err != nil || allowed {
+		t.Errorf("ReservePurchase() over quota = %v, %v, want false", allowed, err)
+	}
+
+	if err := recorder.CommitPurchase(ctx, 1004, "order-1"); err != nil {
+		t.Fatalf("CommitPurchase() error = %v", err)
+	}
+	if err := recorder.CommitPurchase(ctx, 1004, "order-1"); err != nil {
+		t.Errorf("CommitPurchase() twice error = %v, want nil", err)
+	}
+	if err := recorder.CancelPurchase(ctx, 1004, "order-2"); err != nil {
+		t.Fatalf("CancelPurchase() error = %v", err)
+	}
+
+	if total, _ := recorder.GetMonthlyTotal(ctx, 1004); total != 10000 {
+		t.Errorf("GetMonthlyTotal() = %d, want 10000", total)
+	}
+	if reserved, _ := recorder.GetReservedAmount(ctx, 1004); reserved != 5000 {
+		t.Errorf("GetReservedAmount() = %d, want 5000", reserved)
+	}
+
+	// 预占过期后自动释放
+	now = now.Add(11 * time.Minute)
+	if reserved, _ := recorder.GetReservedAmount(ctx, 1004); reserved != 0 {
+		t.Errorf("GetReservedAmount() after expiry = %d, want 0", reserved)
+	}
+	if err := recorder.CommitPurchase(ctx, 1004, "order-3"); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("CommitPurchase() expired error = %v, want ErrReservationNotFound", err)
+	}
+
+	// 预占过期后补录已支付的订单，重复补录不重复累计
+	for i := 0; i < 2; i++ {
+		if err := recorder.RecordPurchase(ctx, 1004, "order-3", 5000); err != nil {
+			t.Fatalf("RecordPurchase() error = %v", err)
+		}
+	}
+	if err := recorder.RecordPurchase(ctx, 1004, "order-1", 5000); err != nil {
+		t.Fatalf("RecordPurchase() committed order error = %v", err)
+	}
+	if total, _ := recorder.GetMonthlyTotal(ctx, 1004); total != 15000 {
+		t.Errorf("GetMonthlyTotal() after RecordPurchase = %d, want 15000", total)
+	}
+	if err := recorder.CommitPurchase(ctx, 1004, "order-3"); err != nil {
+		t.Errorf("CommitPurchase() recorded order error = %v, want nil", err)
+	}
+}
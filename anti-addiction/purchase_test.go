@@ -1,6 +1,7 @@
 package anti_addiction
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -35,8 +36,17 @@ func getDefaultPurchaseConfig() PurchaseConfig {
 	}
 }
 
+func mustNewPurchaseChecker(t *testing.T, config PurchaseConfig) *PurchaseChecker {
+	t.Helper()
+	checker, err := NewPurchaseChecker(config)
+	if err != nil {
+		t.Fatalf("NewPurchaseChecker() error = %v", err)
+	}
+	return checker
+}
+
 func TestPurchaseChecker_GetPurchaseLimit(t *testing.T) {
-	checker := NewPurchaseChecker(getDefaultPurchaseConfig())
+	checker := mustNewPurchaseChecker(t, getDefaultPurchaseConfig())
 
 	tests := []struct {
 		name string
@@ -71,7 +81,7 @@ func TestPurchaseChecker_GetPurchaseLimit(t *testing.T) {
 		{
 			name: "18岁-无限制",
 			age:  18,
-			want: PurchaseLimit{SingleLimit: -1, MonthlyLimit: -1},
+			want: PurchaseLimit{SingleLimit: -1, MonthlyLimit: -1, DailyLimit: -1},
 		},
 	}
 
@@ -86,7 +96,7 @@ func TestPurchaseChecker_GetPurchaseLimit(t *testing.T) {
 }
 
 func TestPurchaseChecker_CheckSinglePurchase(t *testing.T) {
-	checker := NewPurchaseChecker(getDefaultPurchaseConfig())
+	checker := mustNewPurchaseChecker(t, getDefaultPurchaseConfig())
 
 	tests := []struct {
 		name        string
@@ -155,7 +165,7 @@ func TestPurchaseChecker_CheckSinglePurchase(t *testing.T) {
 }
 
 func TestPurchaseChecker_CheckMonthlyPurchase(t *testing.T) {
-	checker := NewPurchaseChecker(getDefaultPurchaseConfig())
+	checker := mustNewPurchaseChecker(t, getDefaultPurchaseConfig())
 
 	tests := []struct {
 		name         string
@@ -262,7 +272,7 @@ func TestNewPurchaseChecker_ConfigSorting(t *testing.T) {
 	}
 
 	// 创建检查器
-	checker := NewPurchaseChecker(unsortedConfig)
+	checker := mustNewPurchaseChecker(t, unsortedConfig)
 
 	// 验证配置是否已排序
 	for i := 0; i < len(checker.config.AgeLimits)-1; i++ {
@@ -310,3 +320,408 @@ func TestNewPurchaseChecker_ConfigSorting(t *testing.T) {
 		t.Error("Original config was modified")
 	}
 }
+
+func TestPurchaseChecker_CheckDailyPurchase(t *testing.T) {
+	config := getDefaultPurchaseConfig()
+	config.AgeLimits[1].Limit.DailyLimit = 10000 // 100元
+	checker := mustNewPurchaseChecker(t, config)
+
+	tests := []struct {
+		name        string
+		amount      int64
+		dailyTotal  int64
+		age         int32
+		opt         *PurchaseOption
+		wantAllowed bool
+	}{
+		{
+			name:        "10岁-允许充值-默认单位(分)",
+			amount:      4000,
+			dailyTotal:  6000,
+			age:         10,
+			opt:         nil,
+			wantAllowed: true,
+		},
+		{
+			name:        "10岁-超额充值-元",
+			amount:      50,
+			dailyTotal:  60,
+			age:         10,
+			opt:         &PurchaseOption{Unit: UnitYuan},
+			wantAllowed: false,
+		},
+		{
+			name:        "16岁-未配置日限额",
+			amount:      10000,
+			dailyTotal:  100000,
+			age:         16,
+			opt:         nil,
+			wantAllowed: true,
+		},
+		{
+			name:        "18岁-无限制-默认单位(分)",
+			amount:      100000,
+			dailyTotal:  1000000,
+			age:         18,
+			opt:         nil,
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var allowed bool
+			if tt.opt != nil {
+				allowed = checker.CheckDailyPurchase(tt.amount, tt.dailyTotal, tt.age, *tt.opt)
+			} else {
+				allowed = checker.CheckDailyPurchase(tt.amount, tt.dailyTotal, tt.age)
+			}
+			if allowed != tt.wantAllowed {
+				t.Errorf("CheckDailyPurchase() = %v, want %v", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestPurchaseChecker_Currency(t *testing.T) {
+	usd, _ := LookupCurrency("USD")
+	krw, _ := LookupCurrency("KRW")
+
+	config := PurchaseConfig{
+		AgeLimits: []AgePurchaseLimit{
+			{
+				MinAge: 0,
+				MaxAge: 18,
+				Limit: PurchaseLimit{
+					SingleLimit:  1000,  // 10美元
+					MonthlyLimit: 10000, // 100美元
+				},
+			},
+		},
+		Currency: "USD",
+		Converter: func(amount int64, from, to Currency) (int64, error) {
+			// 测试汇率：1美元=1000韩元
+			if from.Code == "KRW" && to.Code == "USD" {
+				return amount / 10, nil
+			}
+			return 0, errors.New("unsupported")
+		},
+	}
+	checker := mustNewPurchaseChecker(t, config)
+
+	tests := []struct {
+		name        string
+		amount      int64
+		opt         PurchaseOption
+		wantAllowed bool
+	}{
+		{name: "美分", amount: 1000, opt: PurchaseOption{Unit: UnitMinor}, wantAllowed: true},
+		{name: "美元", amount: 11, opt: PurchaseOption{Unit: usd.MajorUnit()}, wantAllowed: false},
+		{name: "韩元-转换后未超限", amount: 10000, opt: PurchaseOption{Unit: krw.MajorUnit(), Currency: "KRW"}, wantAllowed: true},
+		{name: "韩元-转换后超限", amount: 11000, opt: PurchaseOption{Currency: "KRW"}, wantAllowed: false},
+		{name: "无法转换的币种", amount: 1, opt: PurchaseOption{Currency: "JPY"}, wantAllowed: false},
+		{name: "未知币种", amount: 1, opt: PurchaseOption{Currency: "XXX"}, wantAllowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.CheckSinglePurchase(tt.amount, 10, tt.opt); got != tt.wantAllowed {
+				t.Errorf("CheckSinglePurchase() = %v, want %v", got, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestPurchaseChecker_CheckPurchaseDetailed(t *testing.T) {
+	config := getDefaultPurchaseConfig()
+	config.AgeLimits[1].Limit.DailyLimit = 8000
+	checker := mustNewPurchaseChecker(t, config)
+
+	tests := []struct {
+		name         string
+		amount       int64
+		dailyTotal   int64
+		monthlyTotal int64
+		age          int32
+		want         PurchaseDecision
+	}{
+		{
+			name: "7岁-禁止充值",
+			age:  7,
+			want: PurchaseDecision{Reason: DenyReasonAgeBanned},
+		},
+		{
+			name:   "10岁-允许充值",
+			amount: 3000,
+			age:    10,
+			want:   PurchaseDecision{Allowed: true, MaxAmount: 5000},
+		},
+		{
+			name:   "10岁-超过单笔限额",
+			amount: 6000,
+			age:    10,
+			want:   PurchaseDecision{Reason: DenyReasonSingleLimit, Threshold: 5000, MaxAmount: 5000},
+		},
+		{
+			name:       "10岁-超过日限额",
+			amount:     3000,
+			dailyTotal: 6000,
+			age:        10,
+			want:       PurchaseDecision{Reason: DenyReasonDailyLimit, Threshold: 8000, MaxAmount: 2000},
+		},
+		{
+			name:         "16岁-超过月度限额",
+			amount:       5000,
+			monthlyTotal: 39000,
+			age:          16,
+			want:         PurchaseDecision{Reason: DenyReasonMonthlyLimit, Threshold: 40000, MaxAmount: 1000},
+		},
+		{
+			name:         "18岁-无限制",
+			amount:       100000,
+			monthlyTotal: 1000000,
+			age:          18,
+			want:         PurchaseDecision{Allowed: true, MaxAmount: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checker.CheckPurchaseDetailed(tt.amount, tt.dailyTotal, tt.monthlyTotal, tt.age)
+			if got != tt.want {
+				t.Errorf("CheckPurchaseDetailed() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseChecker_RemainingQuota(t *testing.T) {
+	checker := mustNewPurchaseChecker(t, getDefaultPurchaseConfig())
+
+	tests := []struct {
+		name          string
+		age           int32
+		monthlyTotal  int64
+		opt           PurchaseOption
+		wantMaxSingle int64
+		wantRemaining int64
+	}{
+		{name: "7岁-禁止充值", age: 7, opt: DefaultPurchaseOption, wantMaxSingle: 0, wantRemaining: 0},
+		{name: "10岁-分", age: 10, monthlyTotal: 15000, opt: DefaultPurchaseOption, wantMaxSingle: 5000, wantRemaining: 5000},
+		{name: "10岁-元", age: 10, monthlyTotal: 150, opt: PurchaseOption{Unit: UnitYuan}, wantMaxSingle: 50, wantRemaining: 50},
+		{name: "16岁-额度用尽", age: 16, monthlyTotal: 50000, opt: DefaultPurchaseOption, wantMaxSingle: 10000, wantRemaining: 0},
+		{name: "16岁-角", age: 16, monthlyTotal: 0, opt: PurchaseOption{Unit: UnitJiao}, wantMaxSingle: 1000, wantRemaining: 4000},
+		{name: "18岁-无限制", age: 18, monthlyTotal: 1000000, opt: DefaultPurchaseOption, wantMaxSingle: -1, wantRemaining: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.GetMaxSingleAmount(tt.age, tt.opt); got != tt.wantMaxSingle {
+				t.Errorf("GetMaxSingleAmount() = %d, want %d", got, tt.wantMaxSingle)
+			}
+			if got := checker.GetRemainingMonthlyQuota(tt.age, tt.monthlyTotal, tt.opt); got != tt.wantRemaining {
+				t.Errorf("GetRemainingMonthlyQuota() = %d, want %d", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestNewPurchaseChecker_AgeBracketValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		ageLimits    AgePurchaseLimits
+		wantProblems int
+	}{
+		{
+			name:         "连续不重叠",
+			ageLimits:    getDefaultPurchaseConfig().AgeLimits,
+			wantProblems: 0,
+		},
+		{
+			name: "年龄段重叠",
+			ageLimits: AgePurchaseLimits{
+				{MinAge: 0, MaxAge: 10},
+				{MinAge: 8, MaxAge: 18},
+			},
+			wantProblems: 1,
+		},
+		{
+			name: "年龄段存在空隙",
+			ageLimits: AgePurchaseLimits{
+				{MinAge: 0, MaxAge: 8},
+				{MinAge: 10, MaxAge: 18},
+			},
+			wantProblems: 1,
+		},
+		{
+			name: "未从0岁开始且重叠",
+			ageLimits: AgePurchaseLimits{
+				{MinAge: 8, MaxAge: 16},
+				{MinAge: 12, MaxAge: 18},
+			},
+			wantProblems: 2,
+		},
+		{
+			name: "上下限颠倒",
+			ageLimits: AgePurchaseLimits{
+				{MinAge: 0, MaxAge: 8},
+				{MinAge: 18, MaxAge: 8},
+			},
+			wantProblems: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPurchaseChecker(PurchaseConfig{AgeLimits: tt.ageLimits})
+			if tt.wantProblems == 0 {
+				if err != nil {
+					t.Errorf("NewPurchaseChecker() error = %v, want nil", err)
+				}
+				return
+			}
+			var bracketErr *AgeBracketError
+			if !errors.As(err, &bracketErr) {
+				t.Fatalf("NewPurchaseChecker() error = %v, want *AgeBracketError", err)
+			}
+			if len(bracketErr.Problems) != tt.wantProblems {
+				t.Errorf("problems = %v, want %d problems", bracketErr.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestPurchaseChecker_WithReason(t *testing.T) {
+	checker := mustNewPurchaseChecker(t, getDefaultPurchaseConfig())
+
+	tests := []struct {
+		name       string
+		check      func() (bool, DenyReason)
+		wantResult bool
+		wantReason DenyReason
+	}{
+		{
+			name:       "单笔允许",
+			check:      func() (bool, DenyReason) { return checker.CheckSinglePurchaseWithReason(5000, 10) },
+			wantResult: true,
+			wantReason: DenyReasonNone,
+		},
+		{
+			name:       "单笔超限",
+			check:      func() (bool, DenyReason) { return checker.CheckSinglePurchaseWithReason(5001, 10) },
+			wantReason: DenyReasonSingleLimit,
+		},
+		{
+			name:       "禁止充值年龄段",
+			check:      func() (bool, DenyReason) { return checker.CheckMonthlyPurchaseWithReason(100, 0, 6) },
+			wantReason: DenyReasonAgeBanned,
+		},
+		{
+			name:       "月度超限",
+			check:      func() (bool, DenyReason) { return checker.CheckMonthlyPurchaseWithReason(100, 19950, 10) },
+			wantReason: DenyReasonMonthlyLimit,
+		},
+		{
+			name: "币种不支持",
+			check: func() (bool, DenyReason) {
+				return checker.CheckDailyPurchaseWithReason(100, 0, 10, PurchaseOption{Currency: "USD"})
+			},
+			wantReason: DenyReasonCurrencyUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.check()
+			if got != tt.wantResult || reason != tt.wantReason {
+				t.Errorf("check = %v, %v, want %v, %v", got, reason, tt.wantResult, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestPurchaseChecker_CheckCategoryPurchase(t *testing.T) {
+	config := getDefaultPurchaseConfig()
+	config.CategoryLimits = map[string]AgePurchaseLimits{
+		"gacha": {
+			{MinAge: 0, MaxAge: 12, Limit: PurchaseLimit{SingleLimit: 0, MonthlyLimit: 0}},
+			{MinAge: 12, MaxAge: 18, Limit: PurchaseLimit{SingleLimit: 2000, MonthlyLimit: 5000}},
+		},
+	}
+	checker := mustNewPurchaseChecker(t, config)
+
+	tests := []struct {
+		name     string
+		category string
+		amount   int64
+		age      int32
+		totals   PurchaseTotals
+		want     PurchaseDecision
+	}{
+		{
+			name:     "未配置品类",
+			category: "pass",
+			amount:   5000,
+			age:      14,
+			want:     PurchaseDecision{Allowed: true, MaxAmount: 5000},
+		},
+		{
+			name:     "品类允许",
+			category: "gacha",
+			amount:   1000,
+			age:      14,
+			totals:   PurchaseTotals{MonthlyTotal: 10000, CategoryMonthlyTotal: 2000},
+			want:     PurchaseDecision{Allowed: true, MaxAmount: 2000},
+		},
+		{
+			name:     "品类单笔超限",
+			category: "gacha",
+			amount:   3000,
+			age:      14,
+			want:     PurchaseDecision{Reason: DenyReasonSingleLimit, Threshold: 2000, MaxAmount: 2000, Category: "gacha"},
+		},
+		{
+			name:     "品类月度超限",
+			category: "gacha",
+			amount:   1000,
+			age:      14,
+			totals:   PurchaseTotals{MonthlyTotal: 4500, CategoryMonthlyTotal: 4500},
+			want:     PurchaseDecision{Reason: DenyReasonMonthlyLimit, Threshold: 5000, MaxAmount: 500, Category: "gacha"},
+		},
+		{
+			name:     "品类禁止",
+			category: "gacha",
+			amount:   100,
+			age:      10,
+			want:     PurchaseDecision{Reason: DenyReasonAgeBanned, Category: "gacha"},
+		},
+		{
+			name:     "总限额优先",
+			category: "gacha",
+			amount:   1000,
+			age:      14,
+			totals:   PurchaseTotals{MonthlyTotal: 19500},
+			want:     PurchaseDecision{Reason: DenyReasonMonthlyLimit, Threshold: 20000, MaxAmount: 500},
+		},
+		{
+			name:     "成年人品类无限制",
+			category: "gacha",
+			amount:   100000,
+			age:      20,
+			want:     PurchaseDecision{Allowed: true, MaxAmount: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checker.CheckCategoryPurchase(tt.category, tt.amount, tt.age, tt.totals); got != tt.want {
+				t.Errorf("CheckCategoryPurchase() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	config.CategoryLimits["bad"] = AgePurchaseLimits{{MinAge: 5, MaxAge: 18}}
+	if _, err := NewPurchaseChecker(config); err == nil {
+		t.Errorf("NewPurchaseChecker() with category gap error = nil, want error")
+	}
+}
@@ -0,0 +1,67 @@
+package anti_addiction
+
+import "fmt"
+
+// DenyReason 防沉迷检查的拒绝原因
+type DenyReason int
+
+const (
+	// DenyReasonNone 未拒绝
+	DenyReasonNone DenyReason = iota
+	// DenyReasonAgeBanned 该年龄段禁止充值
+	DenyReasonAgeBanned
+	// DenyReasonSingleLimit 超过单笔充值限额
+	DenyReasonSingleLimit
+	// DenyReasonDailyLimit 超过当日充值限额
+	DenyReasonDailyLimit
+	// DenyReasonMonthlyLimit 超过月度充值限额
+	DenyReasonMonthlyLimit
+	// DenyReasonCurrencyUnsupported 充值币种无法转换为限额币种
+	DenyReasonCurrencyUnsupported
+	// DenyReasonNotAllowedWeekday 当天既不是允许游戏的星期也不是节假日
+	DenyReasonNotAllowedWeekday
+	// DenyReasonOutsideWindow 当天允许游戏，但不在可游玩时间段内
+	DenyReasonOutsideWindow
+	// DenyReasonCurfew 处于宵禁时段
+	DenyReasonCurfew
+	// DenyReasonDailyDurationExhausted 当日累计游戏时长已用完
+	DenyReasonDailyDurationExhausted
+	// DenyReasonVerificationPending 实名认证进行中且不在等待期内
+	DenyReasonVerificationPending
+)
+
+var denyReasonNames = map[DenyReason]string{
+	DenyReasonNone:                   "none",
+	DenyReasonAgeBanned:              "age_banned",
+	DenyReasonSingleLimit:            "single_limit",
+	DenyReasonDailyLimit:             "daily_limit",
+	DenyReasonMonthlyLimit:           "monthly_limit",
+	DenyReasonCurrencyUnsupported:    "currency_unsupported",
+	DenyReasonNotAllowedWeekday:      "not_allowed_weekday",
+	DenyReasonOutsideWindow:          "outside_window",
+	DenyReasonCurfew:                 "curfew",
+	DenyReasonDailyDurationExhausted: "daily_duration_exhausted",
+	DenyReasonVerificationPending:    "verification_pending",
+}
+
+func (reason DenyReason) String() string {
+	if name, ok := denyReasonNames[reason]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText 序列化为原因名称，便于审计记录等持久化数据直接阅读
+func (reason DenyReason) MarshalText() ([]byte, error) {
+	return []byte(reason.String()), nil
+}
+
+func (reason *DenyReason) UnmarshalText(text []byte) error {
+	for value, name := range denyReasonNames {
+		if name == string(text) {
+			*reason = value
+			return nil
+		}
+	}
+	return fmt.Errorf("anti-addiction: unknown deny reason %q", text)
+}
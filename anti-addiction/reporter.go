@@ -0,0 +1,252 @@
+package anti_addiction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// maxBehaviorBatchSize 国家新闻出版署行为数据上报接口单次最多上报条数
+const maxBehaviorBatchSize = 128
+
+// BehaviorType 游戏用户行为类型
+type BehaviorType int
+
+const (
+	BehaviorOffline BehaviorType = 0 // 下线
+	BehaviorOnline  BehaviorType = 1 // 上线
+)
+
+// ReportUserType 上报的用户类型
+type ReportUserType int
+
+const (
+	ReportUserCertified ReportUserType = 0 // 已认证用户
+	ReportUserGuest     ReportUserType = 2 // 游客用户
+)
+
+// BehaviorRecord 一条游戏用户上下线行为数据
+type BehaviorRecord struct {
+	// 批量模式中的序号，发送时自动填充
+	No int `json:"no"`
+	// 游戏内部会话标识
+	SessionID string `json:"si"`
+	// 用户行为类型
+	BehaviorType BehaviorType `json:"bt"`
+	// 行为发生时间戳（秒）
+	OccurTime int64 `json:"ot"`
+	// 用户类型
+	UserType ReportUserType `json:"ct"`
+	// 游客模式设备标识，游客用户必填
+	DeviceID string `json:"di,omitempty"`
+	// 已通过实名认证用户的唯一标识，已认证用户必填
+	PI string `json:"pi,omitempty"`
+}
+
+// behaviorBatch 一批行为数据，上报失败时整批落地到存储
+type behaviorBatch struct {
+	Records []BehaviorRecord `json:"collections"`
+}
+
+func (batch *behaviorBatch) MarshalBinary() ([]byte, error) {
+	return json.Marshal(batch)
+}
+
+func (batch *behaviorBatch) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, batch)
+}
+
+// NewBehaviorBatchFactory 返回行为数据批次的数据工厂，用于注册落地存储的 hash
+func NewBehaviorBatchFactory() storage.StorageData {
+	return &behaviorBatch{}
+}
+
+// ReporterConfig 行为数据上报配置
+type ReporterConfig struct {
+	AppID string `json:"app_id" yaml:"app-id"`
+	BizID string `json:"biz_id" yaml:"biz-id"`
+	// 16进制编码的密钥，用于 AES-128-GCM 加密与签名
+	SecretKey string `json:"secret_key" yaml:"secret-key"`
+	// 上报接口地址，为空时使用官方地址
+	Url string `json:"url" yaml:"url"`
+	// 每批上报条数，最大 128
+	BatchSize int `json:"batch_size" yaml:"batch-size"`
+	// 定时上报间隔（毫秒）
+	FlushIntervalMs int64 `json:"flush_interval_ms" yaml:"flush-interval-ms"`
+	// 单批上报失败后的重试次数
+	RetryTimes int `json:"retry_times" yaml:"retry-times"`
+	// 重试间隔（毫秒），每次重试翻倍
+	RetryIntervalMs int64 `json:"retry_interval_ms" yaml:"retry-interval-ms"`
+	// 单次请求超时（毫秒）
+	TimeoutMs int64 `json:"timeout_ms" yaml:"timeout-ms"`
+}
+
+// BehaviorReporter 行为数据上报客户端，缓存上下线行为并按批次通过 idcard_sdk.NPPAIdCardSDK 的 LoginOut 签名加密后上报，
+// 重试仍失败的批次落地到 global-storage，可通过 ResendSpilled 补报
+type BehaviorReporter struct {
+	config ReporterConfig
+	sdk    *idcard_sdk.NPPAIdCardSDK
+	spill  storage.HashTransactional
+
+	mu      sync.Mutex
+	pending []BehaviorRecord
+	flushCh chan struct{}
+	timeNow func() time.Time
+}
+
+// NewBehaviorReporter 创建行为数据上报客户端，密钥不是合法的16进制 AES 密钥时返回错误
+// spill: 上报失败时的落地存储，需以 NewBehaviorBatchFactory 作为数据工厂注册，可为 nil
+func NewBehaviorReporter(config ReporterConfig, spill storage.HashTransactional) (*BehaviorReporter, error) {
+	if config.BatchSize <= 0 || config.BatchSize > maxBehaviorBatchSize {
+		config.BatchSize = maxBehaviorBatchSize
+	}
+	if config.FlushIntervalMs <= 0 {
+		config.FlushIntervalMs = 5000
+	}
+	if config.RetryIntervalMs <= 0 {
+		config.RetryIntervalMs = 500
+	}
+	if config.TimeoutMs <= 0 {
+		config.TimeoutMs = 5000
+	}
+	sdk, err := idcard_sdk.NewNPPAIdCardSDK(idcard_sdk.NPPAConfig{
+		AppId:       config.AppID,
+		BizId:       config.BizID,
+		SecretKey:   config.SecretKey,
+		LoginOutUrl: config.Url,
+		HTTP:        idcard_sdk.HTTPConfig{TimeoutMillis: config.TimeoutMs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BehaviorReporter{
+		config:  config,
+		sdk:     sdk,
+		spill:   spill,
+		flushCh: make(chan struct{}, 1),
+		timeNow: time.Now,
+	}, nil
+}
+
+// Report 缓存一条行为数据，缓存达到批次大小时通知 Run 立即上报
+func (reporter *BehaviorReporter) Report(record BehaviorRecord) {
+	reporter.mu.Lock()
+	reporter.pending = append(reporter.pending, record)
+	full := len(reporter.pending) >= reporter.config.BatchSize
+	reporter.mu.Unlock()
+
+	if full {
+		select {
+		case reporter.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run 定时或在缓存满批时上报，ctx 结束时上报剩余数据后返回，需在独立 goroutine 中调用
+func (reporter *BehaviorReporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(reporter.config.FlushIntervalMs) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			reporter.Flush(context.Background())
+			return
+		case <-ticker.C:
+		case <-reporter.flushCh:
+		}
+		reporter.Flush(ctx)
+	}
+}
+
+// Flush 立即上报所有缓存的行为数据，失败的批次落地到存储
+func (reporter *BehaviorReporter) Flush(ctx context.Context) {
+	reporter.mu.Lock()
+	pending := reporter.pending
+	reporter.pending = nil
+	reporter.mu.Unlock()
+
+	for start := 0; start < len(pending); start += reporter.config.BatchSize {
+		end := min(start+reporter.config.BatchSize, len(pending))
+		batch := &behaviorBatch{Records: pending[start:end]}
+		if err := reporter.sendWithRetry(ctx, batch); err != nil {
+			reporter.spillBatch(ctx, batch, err)
+		}
+	}
+}
+
+// ResendSpilled 补报落地存储中的批次，补报成功的批次从存储中删除
+func (reporter *BehaviorReporter) ResendSpilled(ctx context.Context) error {
+	if reporter.spill == nil {
+		return nil
+	}
+	batches, err := reporter.spill.HGetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for id, data := range batches {
+		batch, ok := data.(*behaviorBatch)
+		if !ok {
+			return errors.New("anti-addiction: unexpected behavior batch type")
+		}
+		if err = reporter.sendWithRetry(ctx, batch); err != nil {
+			return err
+		}
+		if err = reporter.spill.HDel(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (reporter *BehaviorReporter) spillBatch(ctx context.Context, batch *behaviorBatch, cause error) {
+	zaplogger.DefaultLogger().Error("BehaviorReporter report failed", field.WithError(cause),
+		field.Int("records", len(batch.Records)))
+	if reporter.spill == nil {
+		return
+	}
+	id := strconv.FormatInt(reporter.timeNow().UnixNano(), 10)
+	if err := reporter.spill.HSet(ctx, id, batch); err != nil {
+		zaplogger.DefaultLogger().Error("BehaviorReporter spill failed", field.WithError(err))
+	}
+}
+
+func (reporter *BehaviorReporter) sendWithRetry(ctx context.Context, batch *behaviorBatch) error {
+	interval := time.Duration(reporter.config.RetryIntervalMs) * time.Millisecond
+	err := reporter.send(ctx, batch)
+	for i := 0; err != nil && i < reporter.config.RetryTimes; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+		err = reporter.send(ctx, batch)
+	}
+	return err
+}
+
+func (reporter *BehaviorReporter) send(ctx context.Context, batch *behaviorBatch) error {
+	behaviors := make([]idcard_sdk.NPPABehavior, len(batch.Records))
+	for i, record := range batch.Records {
+		batch.Records[i].No = i + 1
+		behaviors[i] = idcard_sdk.NPPABehavior{
+			No:         i + 1,
+			SessionId:  record.SessionID,
+			Behavior:   idcard_sdk.NPPABehaviorType(record.BehaviorType),
+			OccurredAt: record.OccurTime,
+			UserType:   idcard_sdk.NPPAUserType(record.UserType),
+			DeviceId:   record.DeviceID,
+			PI:         record.PI,
+		}
+	}
+	return reporter.sdk.LoginOut(ctx, behaviors)
+}
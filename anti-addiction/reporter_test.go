@@ -0,0 +1,141 @@
+package anti_addiction
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+)
+
+const testSecretKey = "2836e95fcd10e04b0069bb1ee659955b"
+
+// newTestReportServer 模拟上报接口，校验签名并解密记录，fail 为 true 时返回错误码
+func newTestReportServer(t *testing.T, fail *bool) (*httptest.Server, func() []BehaviorRecord) {
+	signer, err := idcard_sdk.NewNPPASigner(testSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var received []BehaviorRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		params := r.URL.Query()
+		for _, name := range []string{"appId", "bizId", "timestamps"} {
+			params.Set(name, r.Header.Get(name))
+		}
+		if r.Header.Get("sign") != signer.Sign(params, body) {
+			t.Errorf("sign mismatch")
+		}
+
+		var wrapper struct {
+			Data string `json:"data"`
+		}
+		if err := json.Unmarshal(body, &wrapper); err != nil {
+			t.Errorf("unmarshal body error = %v", err)
+		}
+		raw, _ := base64.StdEncoding.DecodeString(wrapper.Data)
+		key, _ := hex.DecodeString(testSecretKey)
+		block, _ := aes.NewCipher(key)
+		gcm, _ := cipher.NewGCM(block)
+		plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+		if err != nil {
+			t.Errorf("decrypt error = %v", err)
+		}
+		var batch behaviorBatch
+		_ = json.Unmarshal(plain, &batch)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if *fail {
+			_, _ = w.Write([]byte(`{"errcode":1001,"errmsg":"SYS ERROR"}`))
+			return
+		}
+		received = append(received, batch.Records...)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"OK"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []BehaviorRecord {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func TestBehaviorReporter_Flush(t *testing.T) {
+	fail := false
+	server, received := newTestReportServer(t, &fail)
+	reporter, err := NewBehaviorReporter(ReporterConfig{
+		AppID:     "app",
+		BizID:     "biz",
+		SecretKey: testSecretKey,
+		Url:       server.URL,
+		BatchSize: 2,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewBehaviorReporter() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		reporter.Report(BehaviorRecord{SessionID: "s", BehaviorType: BehaviorOnline, OccurTime: 1700000000, PI: "pi"})
+	}
+	reporter.Flush(context.Background())
+
+	records := received()
+	if len(records) != 3 {
+		t.Fatalf("received %d records, want 3", len(records))
+	}
+	// 每批序号从1开始
+	if records[0].No != 1 || records[1].No != 2 || records[2].No != 1 {
+		t.Errorf("record numbers = %d,%d,%d, want 1,2,1", records[0].No, records[1].No, records[2].No)
+	}
+}
+
+func TestBehaviorReporter_SpillAndResend(t *testing.T) {
+	ctx := context.Background()
+	fail := true
+	server, received := newTestReportServer(t, &fail)
+	spill := newFakeHash(NewBehaviorBatchFactory)
+	reporter, err := NewBehaviorReporter(ReporterConfig{
+		SecretKey:       testSecretKey,
+		Url:             server.URL,
+		RetryTimes:      1,
+		RetryIntervalMs: 1,
+	}, spill)
+	if err != nil {
+		t.Fatalf("NewBehaviorReporter() error = %v", err)
+	}
+
+	reporter.Report(BehaviorRecord{SessionID: "s", BehaviorType: BehaviorOffline, OccurTime: 1700000000, UserType: ReportUserGuest, DeviceID: "d"})
+	reporter.Flush(ctx)
+
+	spilled, _ := spill.HGetAll(ctx)
+	if len(spilled) != 1 {
+		t.Fatalf("spilled batches = %d, want 1", len(spilled))
+	}
+
+	fail = false
+	if err = reporter.ResendSpilled(ctx); err != nil {
+		t.Fatalf("ResendSpilled() error = %v", err)
+	}
+	if len(received()) != 1 {
+		t.Errorf("received %d records, want 1", len(received()))
+	}
+	if spilled, _ = spill.HGetAll(ctx); len(spilled) != 0 {
+		t.Errorf("spilled batches after resend = %d, want 0", len(spilled))
+	}
+}
+
+func TestNewBehaviorReporter_InvalidKey(t *testing.T) {
+	if _, err := NewBehaviorReporter(ReporterConfig{SecretKey: "invalid"}, nil); err == nil {
+		t.Errorf("NewBehaviorReporter(invalid key) error = nil")
+	}
+}
@@ -0,0 +1,64 @@
+package anti_addiction
+
+import (
+	"sync"
+	"time"
+)
+
+func (checker *antiAddictionChecker) SchedulePlayEnd(accountID int64, age int32) (<-chan time.Time, func()) {
+	fired := make(chan time.Time, 1)
+	done := make(chan struct{})
+	go checker.runPlayEndSchedule(accountID, age, fired, done)
+
+	var once sync.Once
+	return fired, func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// runPlayEndSchedule 计算结束时刻并等待，期间发生重载则重新计算；账号豁免时按无限制处理，
+// 到达结束时刻时账号已被加入豁免名单则不触发，继续等待重载或取消
+func (checker *antiAddictionChecker) runPlayEndSchedule(accountID int64, age int32, fired chan<- time.Time, done <-chan struct{}) {
+	for {
+		reloaded := checker.reloadSignal()
+		endTime := int64(0)
+		if !checker.IsExempt(accountID) {
+			endTime = checker.GetPlayEndTime(age)
+		}
+		if endTime == -1 {
+			fired <- checker.timeNow()
+			return
+		}
+
+		// 无限制时不设置定时器，仅等待重载或取消
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if endTime > 0 {
+			timer = time.NewTimer(time.UnixMilli(endTime).Sub(checker.timeNow()))
+			timeout = timer.C
+		}
+
+		select {
+		case <-done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-reloaded:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-timeout:
+			if checker.IsExempt(accountID) {
+				select {
+				case <-done:
+					return
+				case <-reloaded:
+				}
+				continue
+			}
+			fired <- time.UnixMilli(endTime)
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package anti_addiction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAntiAddictionChecker_SchedulePlayEnd(t *testing.T) {
+	config := Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	}
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+
+	// 星期六 20:59:59.9，距离 21:00 结束还有 100ms
+	fakeStart := time.Date(2025, 3, 29, 20, 59, 59, 900*int(time.Millisecond), time.Local)
+	realStart := time.Now()
+	checker.(*antiAddictionChecker).SetTimeNow(func() time.Time {
+		return fakeStart.Add(time.Since(realStart))
+	})
+
+	fired, cancel := checker.SchedulePlayEnd(1, 10)
+	defer cancel()
+
+	// 重载为 21:00:01 结束，调度应顺延
+	config.TimeConfig.EndSecond = 1
+	if err = checker.Reload(config); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	select {
+	case at := <-fired:
+		want := time.Date(2025, 3, 29, 21, 0, 1, 0, time.Local)
+		if !at.Equal(want) {
+			t.Errorf("SchedulePlayEnd() fired at %v, want %v", at, want)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("SchedulePlayEnd() did not fire")
+	}
+
+	// 不可游玩时立即触发
+	firedNow, cancelNow := checker.SchedulePlayEnd(1, 10)
+	defer cancelNow()
+	select {
+	case <-firedNow:
+	case <-time.After(3 * time.Second):
+		t.Fatal("SchedulePlayEnd() outside play time did not fire immediately")
+	}
+
+	// 豁免账号不会触发
+	checker.SetExemptions(NewExemptions(2))
+	firedExempt, cancelExempt := checker.SchedulePlayEnd(2, 10)
+	defer cancelExempt()
+	select {
+	case <-firedExempt:
+		t.Error("SchedulePlayEnd() for exempt account fired, want no fire")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 成年人不会触发，取消后调度结束
+	firedAdult, cancelAdult := checker.SchedulePlayEnd(1, 18)
+	cancelAdult()
+	cancelAdult()
+	select {
+	case <-firedAdult:
+		t.Error("SchedulePlayEnd() for adult fired, want no fire")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,338 @@
+package anti_addiction
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPlayNotAllowed  = errors.New("anti-addiction: play not allowed")
+	ErrSessionNotFound = errors.New("anti-addiction: session not found")
+)
+
+// playSession 一个在线会话
+type playSession struct {
+	age      int32
+	startAt  time.Time
+	lastBeat time.Time
+	// 已发送的休息提醒次数
+	reminded int64
+}
+
+// RestReminder 休息提醒事件
+type RestReminder struct {
+	PlayerID int64
+	// 本次会话已连续游戏时长
+	ContinuousPlay time.Duration
+	// 第几次提醒，从 1 开始
+	Count int64
+	// 触发提醒的心跳时间
+	At time.Time
+}
+
+// SessionManager 在线会话管理，结合可游玩时间段与每日累计时长，在每次心跳时返回剩余可游玩秒数
+type SessionManager struct {
+	checker  AntiAddictionChecker
+	tracker  DurationTracker
+	parental *ParentalChecker
+
+	mu       sync.Mutex
+	sessions map[int64]*playSession
+	audit    AuditSink
+	timeNow  func() time.Time
+
+	restInterval time.Duration
+	onRest       func(RestReminder)
+
+	idleTimeout time.Duration
+}
+
+// NewSessionManager 创建在线会话管理器
+// tracker: 每日累计时长记录，为 nil 时仅检查可游玩时间段
+func NewSessionManager(checker AntiAddictionChecker, tracker DurationTracker) *SessionManager {
+	return &SessionManager{
+		checker:  checker,
+		tracker:  tracker,
+		sessions: make(map[int64]*playSession),
+		timeNow:  time.Now,
+	}
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (manager *SessionManager) SetTimeNow(timeNow func() time.Time) {
+	manager.timeNow = timeNow
+}
+
+// SetAuditSink 设置审计记录，开始会话被拒绝时写入
+func (manager *SessionManager) SetAuditSink(sink AuditSink) {
+	manager.audit = sink
+}
+
+// SetRestReminder 设置休息提醒：会话每连续游戏 interval 时长，在心跳时调用一次 handler，成年人同样生效；
+// interval <= 0 或 handler 为 nil 时关闭提醒。需在开始会话前设置
+func (manager *SessionManager) SetRestReminder(interval time.Duration, handler func(RestReminder)) {
+	manager.restInterval = interval
+	manager.onRest = handler
+}
+
+// SetParentalChecker 设置家长控制检查器，设置后每日累计时长上限取家长自定义上限与全局上限中的较小值
+func (manager *SessionManager) SetParentalChecker(parental *ParentalChecker) {
+	manager.parental = parental
+}
+
+// SetIdleTimeout 设置会话空闲超时：距上次心跳超过 timeout 的会话视为已断开，
+// 之后的心跳与结束会话返回 ErrSessionNotFound，空闲期间不计入累计时长；timeout <= 0 时不超时
+func (manager *SessionManager) SetIdleTimeout(timeout time.Duration) {
+	manager.idleTimeout = timeout
+}
+
+// StartSession 开始会话，不可游玩或当日时长已用完时返回 ErrPlayNotAllowed
+// 返回值：剩余可游玩秒数，-1 表示无限制
+func (manager *SessionManager) StartSession(ctx context.Context, playerID int64, age int32) (int64, error) {
+	remaining, _, err := manager.StartSessionWithReason(ctx, playerID, age)
+	return remaining, err
+}
+
+// StartSessionWithReason 开始会话，不可游玩或当日时长已用完时返回 ErrPlayNotAllowed 及拒绝原因，
+// 豁免名单内的账号不受限制；玩家已有会话时先结算旧会话自上次心跳以来的时长再重新开始
+// 返回值：剩余可游玩秒数，-1 表示无限制；允许时原因为 DenyReasonNone
+func (manager *SessionManager) StartSessionWithReason(ctx context.Context, playerID int64, age int32) (int64, DenyReason, error) {
+	now := manager.timeNow()
+	if err := manager.EndSession(ctx, playerID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return 0, DenyReasonNone, err
+	}
+	remaining, reason, err := manager.remainingSeconds(ctx, playerID, age, now)
+	if err != nil {
+		return 0, DenyReasonNone, err
+	}
+	if remaining == 0 {
+		recordAudit(ctx, manager.audit, AuditEntry{
+			PlayerID:   playerID,
+			AgeBracket: manager.checker.AgeBracket(age),
+			Kind:       AuditKindPlay,
+			Reason:     reason,
+			Timestamp:  now.UnixMilli(),
+		})
+		return 0, reason, ErrPlayNotAllowed
+	}
+
+	manager.mu.Lock()
+	manager.sessions[playerID] = &playSession{age: age, startAt: now, lastBeat: now}
+	manager.mu.Unlock()
+	return remaining, DenyReasonNone, nil
+}
+
+// Heartbeat 记录会话心跳，累加自上次心跳以来的游戏时长
+// 返回值：剩余可游玩秒数，-1 表示无限制，0 表示应立即下线
+func (manager *SessionManager) Heartbeat(ctx context.Context, playerID int64) (int64, error) {
+	remaining, _, err := manager.HeartbeatWithReason(ctx, playerID)
+	return remaining, err
+}
+
+// HeartbeatWithReason 记录会话心跳，剩余可游玩秒数为 0 时同时返回应下线的原因
+func (manager *SessionManager) HeartbeatWithReason(ctx context.Context, playerID int64) (int64, DenyReason, error) {
+	now := manager.timeNow()
+	session, err := manager.touch(ctx, playerID, now)
+	if err != nil {
+		return 0, DenyReasonNone, err
+	}
+	manager.remindRest(playerID, now)
+	return manager.remainingSeconds(ctx, playerID, session.age, now)
+}
+
+// EndSession 结束会话，累加最后一段游戏时长
+func (manager *SessionManager) EndSession(ctx context.Context, playerID int64) error {
+	if _, err := manager.touch(ctx, playerID, manager.timeNow()); err != nil {
+		return err
+	}
+	manager.mu.Lock()
+	delete(manager.sessions, playerID)
+	manager.mu.Unlock()
+	return nil
+}
+
+// GetPlayEndTime 获取玩家今天的实际可游玩结束时间戳（毫秒）：配置了每日累计时长记录时，
+// 取可游玩时间段结束与当前时刻加每日剩余时长中的较早者，便于客户端倒计时到真实的下线时刻；
+// 豁免名单内的账号不受限制
+// 返回值：-1表示不可游玩，0表示无限制，其他值表示具体的结束时间戳
+func (manager *SessionManager) GetPlayEndTime(ctx context.Context, playerID int64, age int32) (int64, error) {
+	if manager.checker.IsExempt(playerID) {
+		return 0, nil
+	}
+	now := manager.timeNow()
+	endTime := manager.checker.GetPlayEndTimeAt(age, now)
+	if endTime == -1 || manager.tracker == nil {
+		return endTime, nil
+	}
+	dailyLimit, err := manager.dailyLimit(ctx, playerID, age)
+	if err != nil || dailyLimit == -1 {
+		return endTime, err
+	}
+	played, err := manager.tracker.GetDailyPlayed(ctx, playerID, now)
+	if err != nil {
+		return 0, err
+	}
+	dailyRemaining := time.Duration(dailyLimit)*time.Second - played
+	if dailyRemaining <= 0 {
+		return -1, nil
+	}
+	if dailyEnd := now.Add(dailyRemaining).UnixMilli(); endTime == 0 || dailyEnd < endTime {
+		return dailyEnd, nil
+	}
+	return endTime, nil
+}
+
+// ExpireIdleSessions 移除距上次心跳超过空闲超时的会话，返回移除的数量；
+// 用于清理未调用 EndSession 就断开的会话，应定期调用。未设置空闲超时时不做任何处理
+func (manager *SessionManager) ExpireIdleSessions() int {
+	if manager.idleTimeout <= 0 {
+		return 0
+	}
+	now := manager.timeNow()
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	expired := 0
+	for playerID, session := range manager.sessions {
+		if manager.isIdle(session, now) {
+			delete(manager.sessions, playerID)
+			expired++
+		}
+	}
+	return expired
+}
+
+// isIdle 会话距上次心跳是否已超过空闲超时，调用方需持有 mu
+func (manager *SessionManager) isIdle(session *playSession, now time.Time) bool {
+	return manager.idleTimeout > 0 && now.Sub(session.lastBeat) > manager.idleTimeout
+}
+
+// touch 将上次心跳到 now 之间的时长累加到对应自然日，跨越零点时拆分到两天；
+// 会话已空闲超时时移除会话并返回 ErrSessionNotFound，空闲期间不计时
+func (manager *SessionManager) touch(ctx context.Context, playerID int64, now time.Time) (playSession, error) {
+	manager.mu.Lock()
+	session, ok := manager.sessions[playerID]
+	if ok && manager.isIdle(session, now) {
+		delete(manager.sessions, playerID)
+		ok = false
+	}
+	if !ok {
+		manager.mu.Unlock()
+		return playSession{}, ErrSessionNotFound
+	}
+	last := session.lastBeat
+	session.lastBeat = now
+	snapshot := *session
+	manager.mu.Unlock()
+
+	if manager.tracker == nil || !now.After(last) {
+		return snapshot, nil
+	}
+	for last.Before(now) {
+		nextDay := time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, last.Location())
+		end := now
+		if nextDay.Before(now) {
+			end = nextDay
+		}
+		if err := manager.tracker.AddDailyPlayed(ctx, playerID, last, end.Sub(last)); err != nil {
+			return snapshot, err
+		}
+		last = end
+	}
+	return snapshot, nil
+}
+
+// remindRest 连续游戏时长每跨过一个提醒间隔时发送一次提醒，一次心跳跨过多个间隔时只提醒一次
+func (manager *SessionManager) remindRest(playerID int64, now time.Time) {
+	if manager.restInterval <= 0 || manager.onRest == nil {
+		return
+	}
+	manager.mu.Lock()
+	session, ok := manager.sessions[playerID]
+	if !ok {
+		manager.mu.Unlock()
+		return
+	}
+	continuous := now.Sub(session.startAt)
+	count := int64(continuous / manager.restInterval)
+	if count <= session.reminded {
+		manager.mu.Unlock()
+		return
+	}
+	session.reminded = count
+	manager.mu.Unlock()
+
+	manager.onRest(RestReminder{
+		PlayerID:       playerID,
+		ContinuousPlay: continuous,
+		Count:          count,
+		At:             now,
+	})
+}
+
+// remainingSeconds 计算剩余可游玩秒数：可游玩时间段剩余与每日累计时长剩余取较小值，-1 表示无限制
+// 剩余为 0 时同时返回原因
+func (manager *SessionManager) remainingSeconds(ctx context.Context, playerID int64, age int32, now time.Time) (int64, DenyReason, error) {
+	remaining := int64(-1)
+	if manager.checker.IsExempt(playerID) {
+		return remaining, DenyReasonNone, nil
+	}
+	endTime := manager.checker.GetPlayEndTimeAt(age, now)
+	switch {
+	case endTime == -1:
+		return 0, manager.windowDenyReason(age, now), nil
+	case endTime > 0:
+		remaining = max(time.UnixMilli(endTime).Sub(now).Milliseconds()/1000, 0)
+	}
+
+	reason := DenyReasonNone
+	if remaining == 0 {
+		reason = manager.windowDenyReason(age, now)
+	}
+	if manager.tracker == nil {
+		return remaining, reason, nil
+	}
+	dailyLimit, err := manager.dailyLimit(ctx, playerID, age)
+	if err != nil {
+		return 0, DenyReasonNone, err
+	}
+	if dailyLimit == -1 {
+		return remaining, reason, nil
+	}
+	played, err := manager.tracker.GetDailyPlayed(ctx, playerID, now)
+	if err != nil {
+		return 0, DenyReasonNone, err
+	}
+	dailyRemaining := max(dailyLimit-int64(played/time.Second), 0)
+	if remaining == -1 || dailyRemaining < remaining {
+		remaining = dailyRemaining
+		if remaining == 0 {
+			reason = DenyReasonDailyDurationExhausted
+		}
+	}
+	return remaining, reason, nil
+}
+
+// dailyLimit 获取玩家的每日累计游戏时长上限（秒），设置了家长控制检查器时按家长自定义上限收紧，-1 表示不限制
+func (manager *SessionManager) dailyLimit(ctx context.Context, playerID int64, age int32) (int64, error) {
+	if manager.parental != nil {
+		return manager.parental.GetDailyDurationLimit(ctx, playerID, age)
+	}
+	return manager.checker.GetDailyDurationLimit(age), nil
+}
+
+// windowDenyReason 返回可游玩时间段已结束的原因，检查时刻恰好仍在时间段边界内时视为时间段外
+func (manager *SessionManager) windowDenyReason(age int32, now time.Time) DenyReason {
+	if _, reason := manager.checker.IsInPlayTimeAtWithReason(age, now); reason != DenyReasonNone {
+		return reason
+	}
+	return DenyReasonOutsideWindow
+}
+
+// OnlineCount 返回当前在线会话数
+func (manager *SessionManager) OnlineCount() int {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	return len(manager.sessions)
+}
@@ -0,0 +1,347 @@
+package anti_addiction
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionManager(t *testing.T) {
+	ctx := context.Background()
+	config := Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+		DurationConfig: DurationConfig{
+			Limits: []DurationLimit{{MinAge: 0, MaxAge: 18, DailySeconds: 1800}},
+		},
+	}
+	config.TimeConfig.EndHour = 22
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	manager := NewSessionManager(checker, NewMemoryDurationTracker())
+	// 星期六 20:00
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	remaining, err := manager.StartSession(ctx, 1, 10)
+	if err != nil || remaining != 1800 {
+		t.Fatalf("StartSession() = %d, %v, want 1800", remaining, err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if remaining, err = manager.Heartbeat(ctx, 1); err != nil || remaining != 1200 {
+		t.Errorf("Heartbeat() = %d, %v, want 1200", remaining, err)
+	}
+
+	now = now.Add(20 * time.Minute)
+	if remaining, err = manager.Heartbeat(ctx, 1); err != nil || remaining != 0 {
+		t.Errorf("Heartbeat() exhausted = %d, %v, want 0", remaining, err)
+	}
+	if err = manager.EndSession(ctx, 1); err != nil {
+		t.Fatalf("EndSession() error = %v", err)
+	}
+	if _, reason, err := manager.StartSessionWithReason(ctx, 1, 10); !errors.Is(err, ErrPlayNotAllowed) || reason != DenyReasonDailyDurationExhausted {
+		t.Errorf("StartSessionWithReason() after exhausted = %v, %v, want ErrPlayNotAllowed, %v", reason, err, DenyReasonDailyDurationExhausted)
+	}
+	// 星期一不可游玩
+	now = time.Date(2025, 3, 31, 20, 30, 0, 0, time.Local)
+	if _, reason, err := manager.StartSessionWithReason(ctx, 4, 10); !errors.Is(err, ErrPlayNotAllowed) || reason != DenyReasonNotAllowedWeekday {
+		t.Errorf("StartSessionWithReason() monday = %v, %v, want ErrPlayNotAllowed, %v", reason, err, DenyReasonNotAllowedWeekday)
+	}
+
+	// 窗口剩余时长小于每日剩余时长时以窗口为准
+	now = time.Date(2025, 3, 29, 21, 50, 0, 0, time.Local)
+	if remaining, err = manager.StartSession(ctx, 2, 10); err != nil || remaining != 600 {
+		t.Errorf("StartSession() near window end = %d, %v, want 600", remaining, err)
+	}
+
+	// 成年人无限制
+	if remaining, err = manager.StartSession(ctx, 3, 20); err != nil || remaining != -1 {
+		t.Errorf("StartSession() adult = %d, %v, want -1", remaining, err)
+	}
+
+	if _, err = manager.Heartbeat(ctx, 404); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Heartbeat() unknown session error = %v, want ErrSessionNotFound", err)
+	}
+	if manager.OnlineCount() != 2 {
+		t.Errorf("OnlineCount() = %d, want 2", manager.OnlineCount())
+	}
+}
+
+func TestSessionManager_CrossMidnight(t *testing.T) {
+	ctx := context.Background()
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	tracker := NewMemoryDurationTracker()
+	manager := NewSessionManager(checker, tracker)
+	now := time.Date(2025, 3, 29, 23, 50, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	if _, err = manager.StartSession(ctx, 1, 20); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+	if _, err = manager.Heartbeat(ctx, 1); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+
+	played, _ := tracker.GetDailyPlayed(ctx, 1, now)
+	if played != 20*time.Minute {
+		t.Errorf("GetDailyPlayed() = %v, want 20m", played)
+	}
+}
+
+func TestSessionManager_Restart(t *testing.T) {
+	ctx := context.Background()
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	tracker := NewMemoryDurationTracker()
+	manager := NewSessionManager(checker, tracker)
+	now := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	if _, err = manager.StartSession(ctx, 1, 20); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	now = now.Add(10 * time.Minute)
+	// 未结束旧会话就重新开始，旧会话的时长应被结算
+	if _, err = manager.StartSession(ctx, 1, 20); err != nil {
+		t.Fatalf("StartSession() restart error = %v", err)
+	}
+	now = now.Add(5 * time.Minute)
+	if err = manager.EndSession(ctx, 1); err != nil {
+		t.Fatalf("EndSession() error = %v", err)
+	}
+	if played, _ := tracker.GetDailyPlayed(ctx, 1, now); played != 15*time.Minute {
+		t.Errorf("GetDailyPlayed() = %v, want 15m", played)
+	}
+	if manager.OnlineCount() != 0 {
+		t.Errorf("OnlineCount() = %d, want 0", manager.OnlineCount())
+	}
+}
+
+func TestSessionManager_IdleTimeout(t *testing.T) {
+	ctx := context.Background()
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	tracker := NewMemoryDurationTracker()
+	manager := NewSessionManager(checker, tracker)
+	manager.SetIdleTimeout(5 * time.Minute)
+	now := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	for _, playerID := range []int64{1, 2} {
+		if _, err = manager.StartSession(ctx, playerID, 20); err != nil {
+			t.Fatalf("StartSession(%d) error = %v", playerID, err)
+		}
+	}
+	now = now.Add(3 * time.Minute)
+	if _, err = manager.Heartbeat(ctx, 1); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+
+	// 玩家 2 已空闲超时，玩家 1 距上次心跳未超时
+	now = now.Add(3 * time.Minute)
+	if expired := manager.ExpireIdleSessions(); expired != 1 {
+		t.Errorf("ExpireIdleSessions() = %d, want 1", expired)
+	}
+	if manager.OnlineCount() != 1 {
+		t.Errorf("OnlineCount() = %d, want 1", manager.OnlineCount())
+	}
+
+	// 超时后的心跳视为会话已断开，空闲期间不计时
+	now = now.Add(time.Hour)
+	if _, err = manager.Heartbeat(ctx, 1); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Heartbeat() after idle error = %v, want ErrSessionNotFound", err)
+	}
+	if played, _ := tracker.GetDailyPlayed(ctx, 1, now); played != 3*time.Minute {
+		t.Errorf("GetDailyPlayed() = %v, want 3m", played)
+	}
+	if played, _ := tracker.GetDailyPlayed(ctx, 2, now); played != 0 {
+		t.Errorf("GetDailyPlayed() expired = %v, want 0", played)
+	}
+}
+
+func TestStorageDurationTracker_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	tracker := NewStorageDurationTracker(newFakeHash(NewDurationRecordFactory))
+	tracker.(*storageDurationTracker).retryTimes = 100
+	day := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := tracker.AddDailyPlayed(ctx, 1, day, time.Minute); err != nil {
+				t.Errorf("AddDailyPlayed() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if played, _ := tracker.GetDailyPlayed(ctx, 1, day); played != 20*time.Minute {
+		t.Errorf("GetDailyPlayed() = %v, want 20m", played)
+	}
+}
+
+func TestSessionManager_ParentalDailyLimit(t *testing.T) {
+	ctx := context.Background()
+	config := Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+		DurationConfig: DurationConfig{
+			Limits: []DurationLimit{{MinAge: 0, MaxAge: 18, DailySeconds: 1800}},
+		},
+	}
+	config.TimeConfig.EndHour = 22
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	parental := NewParentalChecker(checker, NewMemoryParentalStore())
+	if err = parental.SetControl(ctx, 1, ParentalControl{DailySeconds: 600}); err != nil {
+		t.Fatalf("SetControl() error = %v", err)
+	}
+	manager := NewSessionManager(checker, NewMemoryDurationTracker())
+	manager.SetParentalChecker(parental)
+	// 星期六 20:00
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	if remaining, err := manager.StartSession(ctx, 1, 10); err != nil || remaining != 600 {
+		t.Errorf("StartSession() with parental limit = %d, %v, want 600", remaining, err)
+	}
+	if remaining, err := manager.StartSession(ctx, 2, 10); err != nil || remaining != 1800 {
+		t.Errorf("StartSession() without parental limit = %d, %v, want 1800", remaining, err)
+	}
+	now = now.Add(10 * time.Minute)
+	if remaining, reason, err := manager.HeartbeatWithReason(ctx, 1); err != nil || remaining != 0 || reason != DenyReasonDailyDurationExhausted {
+		t.Errorf("HeartbeatWithReason() = %d, %v, %v, want 0, %v", remaining, reason, err, DenyReasonDailyDurationExhausted)
+	}
+}
+
+func TestSessionManager_RestReminder(t *testing.T) {
+	ctx := context.Background()
+	checker, err := NewAntiAddictionChecker(Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+	})
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	manager := NewSessionManager(checker, nil)
+	var reminders []RestReminder
+	manager.SetRestReminder(time.Hour, func(reminder RestReminder) {
+		reminders = append(reminders, reminder)
+	})
+	// 星期一，成年人同样提醒
+	now := time.Date(2025, 3, 31, 10, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+
+	if _, err = manager.StartSession(ctx, 1, 30); err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	steps := []struct {
+		elapsed   time.Duration
+		wantCount int
+	}{
+		{elapsed: 30 * time.Minute, wantCount: 0},
+		{elapsed: 61 * time.Minute, wantCount: 1},
+		{elapsed: 90 * time.Minute, wantCount: 1},
+		{elapsed: 200 * time.Minute, wantCount: 2},
+	}
+	start := now
+	for _, step := range steps {
+		now = start.Add(step.elapsed)
+		if _, err = manager.Heartbeat(ctx, 1); err != nil {
+			t.Fatalf("Heartbeat() error = %v", err)
+		}
+		if len(reminders) != step.wantCount {
+			t.Fatalf("after %v reminders = %d, want %d", step.elapsed, len(reminders), step.wantCount)
+		}
+	}
+	if last := reminders[1]; last.Count != 3 || last.ContinuousPlay != 200*time.Minute || last.PlayerID != 1 {
+		t.Errorf("reminders[1] = %+v, want count 3 after 200m", last)
+	}
+
+	// 重新开始会话后重新计算连续时长
+	if err = manager.EndSession(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.StartSession(ctx, 1, 30); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Minute)
+	if _, err = manager.Heartbeat(ctx, 1); err != nil || len(reminders) != 2 {
+		t.Errorf("Heartbeat() after restart reminders = %d, %v, want 2", len(reminders), err)
+	}
+}
+
+func TestSessionManager_GetPlayEndTime(t *testing.T) {
+	ctx := context.Background()
+	config := Config{
+		TimeConfig:     getTestTimeConfig(),
+		PurchaseConfig: getDefaultPurchaseConfig(),
+		DurationConfig: DurationConfig{
+			Limits: []DurationLimit{{MinAge: 0, MaxAge: 18, DailySeconds: 1800}},
+		},
+	}
+	config.TimeConfig.EndHour = 22
+	checker, err := NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatalf("NewAntiAddictionChecker() error = %v", err)
+	}
+	tracker := NewMemoryDurationTracker()
+	manager := NewSessionManager(checker, tracker)
+	// 星期六 20:00
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+	manager.SetTimeNow(func() time.Time { return now })
+	windowEnd := time.Date(2025, 3, 29, 22, 0, 0, 0, time.Local).UnixMilli()
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		playerID int64
+		age      int32
+		played   time.Duration
+		want     int64
+	}{
+		{name: "每日剩余时长先到", now: now, playerID: 1, age: 10, played: 10 * time.Minute, want: now.Add(20 * time.Minute).UnixMilli()},
+		{name: "时间段先结束", now: now.Add(110 * time.Minute), playerID: 2, age: 10, want: windowEnd},
+		{name: "每日时长已用完", now: now, playerID: 3, age: 10, played: 30 * time.Minute, want: -1},
+		{name: "成年人无限制", now: now, playerID: 4, age: 20, played: time.Hour, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = tt.now
+			if tt.played > 0 {
+				if err := tracker.AddDailyPlayed(ctx, tt.playerID, now, tt.played); err != nil {
+					t.Fatalf("AddDailyPlayed() error = %v", err)
+				}
+			}
+			if got, err := manager.GetPlayEndTime(ctx, tt.playerID, tt.age); err != nil || got != tt.want {
+				t.Errorf("GetPlayEndTime() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
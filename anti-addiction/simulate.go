@@ -0,0 +1,101 @@
+package anti_addiction
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window 一段可游玩时间，与 GetNextPlayWindow 一致，End 为可游玩时间段的结束时刻
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// SimulateSchedule 按配置枚举 [from, to] 内指定年龄的全部可游玩时间段，已扣除宵禁，用于在上线前核对节假日等配置；
+// 跨越零点且首尾相接的时间段会合并为一段
+// 返回值：按时间升序的时间段；配置非法或 to 早于 from 时返回错误
+func SimulateSchedule(config Config, from, to time.Time, age int32) ([]Window, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("anti-addiction: simulate range end %v before start %v", to, from)
+	}
+	checker := NewAntiAddictionTimeChecker(config.TimeConfig)
+	checker.SetAdultAge(config.adultAge())
+
+	var windows []Window
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for i := 0; ; i++ {
+		day := firstDay.AddDate(0, 0, i)
+		if day.After(to) {
+			break
+		}
+		for _, window := range checker.dayWindows(day, age) {
+			if window.Start.Before(from) {
+				window.Start = from
+			}
+			if window.End.After(to) {
+				window.End = to
+			}
+			if !window.End.After(window.Start) {
+				continue
+			}
+			if last := len(windows) - 1; last >= 0 && windows[last].End.Equal(window.Start) {
+				windows[last].End = window.End
+				continue
+			}
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// dayWindows 返回指定日期内指定年龄的可游玩时间段，已扣除宵禁
+func (checker *AntiAddictionTimeChecker) dayWindows(day time.Time, age int32) []Window {
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	var windows []Window
+	switch {
+	case checker.isAdult(age):
+		windows = []Window{{Start: dayStart, End: dayEnd}}
+	case checker.isPlayDay(dayStart):
+		start, end := checker.dayWindow(dayStart)
+		windows = []Window{{Start: start, End: end}}
+	}
+
+	for _, curfew := range checker.timeRange.Curfews {
+		if !curfew.appliesTo(age) {
+			continue
+		}
+		start := dayStart.Add(time.Duration(curfew.startSeconds()) * time.Second)
+		end := dayStart.Add(time.Duration(curfew.endSeconds()) * time.Second)
+		if start.Before(end) {
+			windows = subtractWindow(windows, Window{Start: start, End: end})
+			continue
+		}
+		// 跨越零点的宵禁在当天分为凌晨与夜间两段
+		windows = subtractWindow(windows, Window{Start: dayStart, End: end})
+		windows = subtractWindow(windows, Window{Start: start, End: dayEnd})
+	}
+	return windows
+}
+
+// subtractWindow 从时间段列表中扣除 cut
+func subtractWindow(windows []Window, cut Window) []Window {
+	result := make([]Window, 0, len(windows)+1)
+	for _, window := range windows {
+		if !cut.Start.Before(window.End) || !cut.End.After(window.Start) {
+			result = append(result, window)
+			continue
+		}
+		if window.Start.Before(cut.Start) {
+			result = append(result, Window{Start: window.Start, End: cut.Start})
+		}
+		if cut.End.Before(window.End) {
+			result = append(result, Window{Start: cut.End, End: window.End})
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package anti_addiction
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulateSchedule(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeConfig = getTestTimeConfig()
+	config.TimeConfig.Curfews = []Curfew{{MinAge: 0, MaxAge: 12, StartHour: 20, StartMinute: 30, EndHour: 8}}
+	config.TimeConfig.Overrides = []DayOverride{{Date: "2025-04-02", Allowed: true}}
+
+	// 2025-03-31 星期一 至 2025-04-06 星期日
+	from := time.Date(2025, 3, 31, 0, 0, 0, 0, time.Local)
+	to := time.Date(2025, 4, 6, 23, 59, 59, 0, time.Local)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 4, day, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name string
+		age  int32
+		want []Window
+	}{
+		{
+			name: "未成年人",
+			age:  14,
+			want: []Window{
+				{Start: at(2, 20, 0), End: at(2, 21, 0)},
+				{Start: at(4, 20, 0), End: at(4, 21, 0)},
+				{Start: at(5, 20, 0), End: at(5, 21, 0)},
+				{Start: at(6, 20, 0), End: at(6, 21, 0)},
+			},
+		},
+		{
+			name: "宵禁年龄段",
+			age:  10,
+			want: []Window{
+				{Start: at(2, 20, 0), End: at(2, 20, 30)},
+				{Start: at(4, 20, 0), End: at(4, 20, 30)},
+				{Start: at(5, 20, 0), End: at(5, 20, 30)},
+				{Start: at(6, 20, 0), End: at(6, 20, 30)},
+			},
+		},
+		{
+			name: "成年人合并为一段",
+			age:  30,
+			want: []Window{{Start: from, End: to}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SimulateSchedule(config, from, to, tt.age)
+			if err != nil {
+				t.Fatalf("SimulateSchedule() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SimulateSchedule() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("SimulateSchedule()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, err := SimulateSchedule(config, to, from, 14); err == nil {
+		t.Errorf("SimulateSchedule() reversed range error = nil, want error")
+	}
+}
@@ -6,8 +6,8 @@ import (
 )
 
 type Holiday struct {
-	Month int
-	Day   int
+	Month int `json:"month" yaml:"month"`
+	Day   int `json:"day" yaml:"day"`
 }
 
 type Holidays []Holiday
@@ -23,14 +23,20 @@ func convertSliceToSet[T comparable](slice []T) collection.Set[T] {
 
 // TimeConfig 防沉迷时间配置
 type TimeConfig struct {
-	StartHour       int
-	EndHour         int
-	StartMinute     int
-	EndMinute       int
-	StartSecond     int
-	EndSecond       int
-	AllowedWeekDays []time.Weekday
-	Holidays        Holidays
+	StartHour       int            `json:"start_hour" yaml:"start-hour"`
+	EndHour         int            `json:"end_hour" yaml:"end-hour"`
+	StartMinute     int            `json:"start_minute" yaml:"start-minute"`
+	EndMinute       int            `json:"end_minute" yaml:"end-minute"`
+	StartSecond     int            `json:"start_second" yaml:"start-second"`
+	EndSecond       int            `json:"end_second" yaml:"end-second"`
+	AllowedWeekDays []time.Weekday `json:"allowed_week_days" yaml:"allowed-week-days"`
+	Holidays        Holidays       `json:"holidays" yaml:"holidays"`
+	// 宵禁规则，可为空
+	Curfews []Curfew `json:"curfews" yaml:"curfews"`
+	// 指定日期的覆盖规则，用于调休，可为空
+	Overrides []DayOverride `json:"overrides" yaml:"overrides"`
+	// 判定当天是否可游玩的规则优先级，为空时使用 DefaultDayRulePrecedence（覆盖日期 > 节假日 > 星期）
+	Precedence []DayRule `json:"precedence" yaml:"precedence"`
 }
 
 // TimeRange 定义时间段结构
@@ -43,16 +49,33 @@ type TimeRange struct {
 	EndSecond       int
 	AllowedWeekDays collection.Set[time.Weekday]
 	HolidaySet      collection.Set[Holiday]
+	Curfews         []Curfew
+	// 覆盖日期，key 为 2006-01-02 格式的日期
+	Overrides  map[string]bool
+	Precedence []DayRule
 }
 
+// DefaultAdultAge 默认的成年年龄
+const DefaultAdultAge int32 = 18
+
 // AntiAddictionTimeChecker 防沉迷时间检查器
 type AntiAddictionTimeChecker struct {
 	timeRange TimeRange
 	timeNow   func() time.Time
+	// 成年年龄，达到该年龄不受时间限制
+	adultAge int32
 }
 
 // NewAntiAddictionTimeChecker 创建防沉迷时间检查器
 func NewAntiAddictionTimeChecker(config TimeConfig) *AntiAddictionTimeChecker {
+	overrides := make(map[string]bool, len(config.Overrides))
+	for _, override := range config.Overrides {
+		overrides[override.Date] = override.Allowed
+	}
+	precedence := config.Precedence
+	if len(precedence) == 0 {
+		precedence = DefaultDayRulePrecedence
+	}
 	return &AntiAddictionTimeChecker{
 		timeRange: TimeRange{
 			StartHour:       config.StartHour,
@@ -63,44 +86,69 @@ func NewAntiAddictionTimeChecker(config TimeConfig) *AntiAddictionTimeChecker {
 			EndSecond:       config.EndSecond,
 			AllowedWeekDays: convertSliceToSet(config.AllowedWeekDays),
 			HolidaySet:      convertSliceToSet(config.Holidays),
+			Curfews:         config.Curfews,
+			Overrides:       overrides,
+			Precedence:      precedence,
 		},
-		timeNow: time.Now,
+		timeNow:  time.Now,
+		adultAge: DefaultAdultAge,
+	}
+}
+
+// SetAdultAge 设置成年年龄，<=0 时使用 DefaultAdultAge
+func (checker *AntiAddictionTimeChecker) SetAdultAge(age int32) {
+	if age <= 0 {
+		age = DefaultAdultAge
 	}
+	checker.adultAge = age
+}
+
+// AdultAge 返回成年年龄
+func (checker *AntiAddictionTimeChecker) AdultAge() int32 {
+	return checker.adultAge
+}
+
+// isAdult 是否已成年，成年人不受时间限制
+func (checker *AntiAddictionTimeChecker) isAdult(age int32) bool {
+	return age >= checker.adultAge
 }
 
 // GetPlayEndTime 获取指定年龄在今天的可游玩结束时间戳（毫秒）
 // 返回值：-1表示不可游玩，0表示无限制，其他值表示具体的结束时间戳
 func (checker *AntiAddictionTimeChecker) GetPlayEndTime(age int32) int64 {
-	// 成年人无限制
-	if age >= 18 {
-		return 0
-	}
+	return checker.GetPlayEndTimeAt(age, checker.timeNow())
+}
 
-	now := checker.timeNow()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
-	// 检查是否是节假日
-	if checker.IsHoliday(now) {
-		if checker.IsInHourTimeRange(now) {
-			// 返回今天的结束时间点
-			endTime := today.Add(time.Duration(checker.timeRange.EndHour)*time.Hour +
-				time.Duration(checker.timeRange.EndMinute)*time.Minute +
-				time.Duration(checker.timeRange.EndSecond)*time.Second)
-			return endTime.UnixMilli()
-		}
+// GetPlayEndTimeAt 获取指定年龄在指定时刻当天的可游玩结束时间戳（毫秒），不依赖 SetTimeNow
+// 配置了宵禁时，结束时间不晚于下一次宵禁开始
+// 返回值：-1表示不可游玩，0表示无限制，其他值表示具体的结束时间戳
+func (checker *AntiAddictionTimeChecker) GetPlayEndTimeAt(age int32, now time.Time) int64 {
+	if checker.IsInCurfew(age, now) {
 		return -1
 	}
+	endTime := checker.getWindowEndTime(age, now)
+	curfewStart, ok := checker.nextCurfewStart(age, now)
+	if !ok || endTime == -1 {
+		return endTime
+	}
+	if endTime == 0 || curfewStart.UnixMilli() < endTime {
+		return curfewStart.UnixMilli()
+	}
+	return endTime
+}
+
+// getWindowEndTime 仅按覆盖日期、节假日与星期规则获取当天的可游玩结束时间戳（毫秒）
+func (checker *AntiAddictionTimeChecker) getWindowEndTime(age int32, now time.Time) int64 {
+	// 成年人无限制
+	if checker.isAdult(age) {
+		return 0
+	}
 
-	// 检查是否是允许的星期
-	if checker.IsWeekAllowedDay(now) {
-		if checker.IsInHourTimeRange(now) {
-			// 返回今天的结束时间点
-			endTime := today.Add(time.Duration(checker.timeRange.EndHour)*time.Hour +
-				time.Duration(checker.timeRange.EndMinute)*time.Minute +
-				time.Duration(checker.timeRange.EndSecond)*time.Second)
-			return endTime.UnixMilli()
-		}
-		return -1
+	// 按覆盖日期、节假日、星期规则判定当天可游玩且在时间段内
+	if checker.isPlayDay(now) && checker.IsInHourTimeRange(now) {
+		// 返回今天的结束时间点
+		_, endTime := checker.dayWindow(now)
+		return endTime.UnixMilli()
 	}
 
 	// 不在任何允许时间段内
@@ -170,20 +218,83 @@ func (checker *AntiAddictionTimeChecker) SetTimeNow(timeNow func() time.Time) {
 
 // IsInPlayTime 检查是否在允许游戏时间内
 func (checker *AntiAddictionTimeChecker) IsInPlayTime(age int32) bool {
-	if age >= 18 {
-		return true
-	}
+	return checker.IsInPlayTimeAt(age, checker.timeNow())
+}
+
+// IsInPlayTimeAt 检查指定时刻是否在允许游戏时间内，不依赖 SetTimeNow，便于离线任务评估任意时刻
+// 处于宵禁时间段内时一律不可游玩
+func (checker *AntiAddictionTimeChecker) IsInPlayTimeAt(age int32, now time.Time) bool {
+	allowed, _ := checker.IsInPlayTimeAtWithReason(age, now)
+	return allowed
+}
 
-	now := checker.timeNow()
+// IsInPlayTimeWithReason 检查是否在允许游戏时间内，不可游玩时同时返回原因
+func (checker *AntiAddictionTimeChecker) IsInPlayTimeWithReason(age int32) (bool, DenyReason) {
+	return checker.IsInPlayTimeAtWithReason(age, checker.timeNow())
+}
+
+// IsInPlayTimeAtWithReason 检查指定时刻是否在允许游戏时间内，不可游玩时同时返回原因，可游玩时原因为 DenyReasonNone
+func (checker *AntiAddictionTimeChecker) IsInPlayTimeAtWithReason(age int32, now time.Time) (bool, DenyReason) {
+	reason := checker.playDenyReason(age, now)
+	return reason == DenyReasonNone, reason
+}
 
-	// 判断是否在节假日游戏时间段内
-	if checker.IsInHolidayPlayTime(now) {
-		return true
+// playDenyReason 返回指定时刻不可游玩的原因，可游玩时返回 DenyReasonNone
+func (checker *AntiAddictionTimeChecker) playDenyReason(age int32, now time.Time) DenyReason {
+	return checker.ExplainDecision(now, age).Reason
+}
+
+// maxScanDays GetNextPlayWindow 向后查找的最大天数，节假日按年配置，一年多一些即可覆盖
+const maxScanDays = 400
+
+// isPlayDay 按规则优先级检查指定日期是否可游玩
+func (checker *AntiAddictionTimeChecker) isPlayDay(day time.Time) bool {
+	allowed, _ := checker.resolveDay(day)
+	return allowed
+}
+
+// dayWindow 返回指定日期当天的可游玩时间段
+func (checker *AntiAddictionTimeChecker) dayWindow(day time.Time) (start, end time.Time) {
+	today := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	start = today.Add(time.Duration(checker.timeRange.StartHour)*time.Hour +
+		time.Duration(checker.timeRange.StartMinute)*time.Minute +
+		time.Duration(checker.timeRange.StartSecond)*time.Second)
+	end = today.Add(time.Duration(checker.timeRange.EndHour)*time.Hour +
+		time.Duration(checker.timeRange.EndMinute)*time.Minute +
+		time.Duration(checker.timeRange.EndSecond)*time.Second)
+	return
+}
+
+// GetNextPlayWindow 从 from 开始向后查找指定年龄的下一个可游玩时间段，已扣除宵禁，与 SimulateSchedule 一致
+// 若 from 正处于可游玩时间段内，返回该时间段（start 早于 from）；跨越零点且首尾相接的时间段合并为一段
+// 成年人且没有适用的宵禁规则时不受限制，返回 start 为 from、end 为零值
+// 返回值：ok 为 false 表示在查找范围内没有可游玩时间段
+func (checker *AntiAddictionTimeChecker) GetNextPlayWindow(age int32, from time.Time) (start, end time.Time, ok bool) {
+	if checker.isAdult(age) && !checker.hasCurfew(age) {
+		return from, time.Time{}, true
+	}
+
+	firstDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for i := 0; i < maxScanDays; i++ {
+		for _, window := range checker.dayWindows(firstDay.AddDate(0, 0, i), age) {
+			if from.After(window.End) {
+				continue
+			}
+			return window.Start, checker.extendWindow(window.End, firstDay.AddDate(0, 0, i+1), age), true
+		}
 	}
+	return time.Time{}, time.Time{}, false
+}
 
-	// 判断是否在星期游戏时间段内
-	if checker.IsInWeekdayPlayTime(now) {
-		return true
+// extendWindow 时间段结束于零点且次日零点即可游玩时，将结束时刻顺延到次日对应时间段的结束
+func (checker *AntiAddictionTimeChecker) extendWindow(end time.Time, nextDay time.Time, age int32) time.Time {
+	for i := 0; i < maxScanDays && end.Equal(nextDay); i++ {
+		windows := checker.dayWindows(nextDay, age)
+		if len(windows) == 0 || !windows[0].Start.Equal(end) {
+			break
+		}
+		end = windows[0].End
+		nextDay = nextDay.AddDate(0, 0, 1)
 	}
-	return false
+	return end
 }
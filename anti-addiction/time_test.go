@@ -307,6 +307,55 @@ func TestAntiAddictionTimeChecker_IsInPlayTime(t *testing.T) {
 	}
 }
 
+func TestAntiAddictionTimeChecker_IsInPlayTimeWithReason(t *testing.T) {
+	config := getTestTimeConfig()
+	config.Curfews = []Curfew{{MinAge: 0, MaxAge: 12, StartHour: 20, StartMinute: 30, EndHour: 8}}
+	checker := NewAntiAddictionTimeChecker(config)
+
+	tests := []struct {
+		name       string
+		age        int32
+		inputTime  time.Time
+		wantResult bool
+		wantReason DenyReason
+	}{
+		{
+			name:       "成年人",
+			age:        18,
+			inputTime:  time.Date(2025, 3, 27, 20, 30, 0, 0, time.Local),
+			wantResult: true,
+			wantReason: DenyReasonNone,
+		},
+		{
+			name:       "未成年人-非允许星期",
+			age:        17,
+			inputTime:  time.Date(2025, 3, 27, 20, 30, 0, 0, time.Local), // 星期四
+			wantReason: DenyReasonNotAllowedWeekday,
+		},
+		{
+			name:       "未成年人-时间段外",
+			age:        17,
+			inputTime:  time.Date(2025, 3, 28, 19, 30, 0, 0, time.Local), // 星期五
+			wantReason: DenyReasonOutsideWindow,
+		},
+		{
+			name:       "未成年人-宵禁",
+			age:        10,
+			inputTime:  time.Date(2025, 3, 28, 20, 45, 0, 0, time.Local), // 星期五
+			wantReason: DenyReasonCurfew,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := checker.IsInPlayTimeAtWithReason(tt.age, tt.inputTime)
+			if got != tt.wantResult || reason != tt.wantReason {
+				t.Errorf("IsInPlayTimeAtWithReason() = %v, %v, want %v, %v", got, reason, tt.wantResult, tt.wantReason)
+			}
+		})
+	}
+}
+
 func TestAntiAddictionTimeChecker_SetTimeRange(t *testing.T) {
 	checker := NewAntiAddictionTimeChecker(getTestTimeConfig())
 
@@ -442,3 +491,117 @@ func TestAntiAddictionTimeChecker_GetPlayEndTime(t *testing.T) {
 		})
 	}
 }
+
+func TestAntiAddictionTimeChecker_GetNextPlayWindow(t *testing.T) {
+	checker := NewAntiAddictionTimeChecker(getTestTimeConfig())
+	// 2025-03-26 星期三
+	wednesday := time.Date(2025, 3, 26, 12, 0, 0, 0, time.Local)
+	friday := time.Date(2025, 3, 28, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		age       int32
+		from      time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "工作日-下一个窗口为周五",
+			age:       10,
+			from:      wednesday,
+			wantStart: friday.Add(20 * time.Hour),
+			wantEnd:   friday.Add(21 * time.Hour),
+		},
+		{
+			name:      "周五窗口内-返回当前窗口",
+			age:       10,
+			from:      friday.Add(20*time.Hour + 30*time.Minute),
+			wantStart: friday.Add(20 * time.Hour),
+			wantEnd:   friday.Add(21 * time.Hour),
+		},
+		{
+			name:      "周五窗口结束后-返回周六窗口",
+			age:       10,
+			from:      friday.Add(21*time.Hour + time.Second),
+			wantStart: friday.AddDate(0, 0, 1).Add(20 * time.Hour),
+			wantEnd:   friday.AddDate(0, 0, 1).Add(21 * time.Hour),
+		},
+		{
+			name:      "节假日-元旦",
+			age:       10,
+			from:      time.Date(2025, 12, 31, 22, 0, 0, 0, time.Local),
+			wantStart: time.Date(2026, 1, 1, 20, 0, 0, 0, time.Local),
+			wantEnd:   time.Date(2026, 1, 1, 21, 0, 0, 0, time.Local),
+		},
+		{
+			name:      "成年人-无限制",
+			age:       18,
+			from:      wednesday,
+			wantStart: wednesday,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := checker.GetNextPlayWindow(tt.age, tt.from)
+			if !ok {
+				t.Fatalf("GetNextPlayWindow() ok = false, want true")
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("GetNextPlayWindow() = [%v, %v], want [%v, %v]", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+
+	// 扣除宵禁
+	curfewConfig := getTestTimeConfig()
+	curfewConfig.Curfews = []Curfew{
+		{MinAge: 0, MaxAge: 12, StartHour: 20, StartMinute: 30, EndHour: 8},
+		{MinAge: 18, MaxAge: 200, StartHour: 1, EndHour: 6},
+	}
+	curfewChecker := NewAntiAddictionTimeChecker(curfewConfig)
+	if start, end, ok := curfewChecker.GetNextPlayWindow(10, wednesday); !ok ||
+		!start.Equal(friday.Add(20*time.Hour)) || !end.Equal(friday.Add(20*time.Hour+30*time.Minute)) {
+		t.Errorf("GetNextPlayWindow() with curfew = [%v, %v], %v, want friday 20:00-20:30", start, end, ok)
+	}
+	if start, end, ok := curfewChecker.GetNextPlayWindow(10, friday.Add(20*time.Hour+45*time.Minute)); !ok ||
+		!start.Equal(friday.AddDate(0, 0, 1).Add(20*time.Hour)) {
+		t.Errorf("GetNextPlayWindow() during curfew = [%v, %v], %v, want saturday 20:00", start, end, ok)
+	}
+	wednesdayMidnight := time.Date(2025, 3, 26, 0, 0, 0, 0, time.Local)
+	if start, end, ok := curfewChecker.GetNextPlayWindow(30, wednesday); !ok ||
+		!start.Equal(wednesdayMidnight.Add(6*time.Hour)) || !end.Equal(wednesdayMidnight.Add(25*time.Hour)) {
+		t.Errorf("GetNextPlayWindow() adult with curfew = [%v, %v], %v, want wednesday 06:00 to thursday 01:00", start, end, ok)
+	}
+
+	// 没有任何可游玩日期
+	noPlayConfig := getTestTimeConfig()
+	noPlayConfig.AllowedWeekDays = nil
+	noPlayConfig.Holidays = nil
+	if _, _, ok := NewAntiAddictionTimeChecker(noPlayConfig).GetNextPlayWindow(10, wednesday); ok {
+		t.Errorf("GetNextPlayWindow() without play days ok = true, want false")
+	}
+}
+
+func TestAntiAddictionTimeChecker_ExplicitTime(t *testing.T) {
+	checker := NewAntiAddictionTimeChecker(getTestTimeConfig())
+	// 固定当前时间为不可游玩的星期三，验证显式时间参数不受影响
+	checker.SetTimeNow(func() time.Time {
+		return time.Date(2025, 3, 26, 12, 0, 0, 0, time.Local)
+	})
+	saturday := time.Date(2025, 3, 29, 20, 30, 0, 0, time.Local)
+
+	if !checker.IsInPlayTimeAt(10, saturday) {
+		t.Errorf("IsInPlayTimeAt(saturday) = false, want true")
+	}
+	if checker.IsInPlayTime(10) {
+		t.Errorf("IsInPlayTime() = true, want false")
+	}
+	wantEnd := time.Date(2025, 3, 29, 21, 0, 0, 0, time.Local).UnixMilli()
+	if got := checker.GetPlayEndTimeAt(10, saturday); got != wantEnd {
+		t.Errorf("GetPlayEndTimeAt(saturday) = %d, want %d", got, wantEnd)
+	}
+	if got := checker.GetPlayEndTime(10); got != -1 {
+		t.Errorf("GetPlayEndTime() = %d, want -1", got)
+	}
+}
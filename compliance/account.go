@@ -0,0 +1,103 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+)
+
+// AccountRecord 账号的实名认证记录，姓名与身份证号已加密
+type AccountRecord struct {
+	Identity idcard_sdk.SealedIdentity `json:"identity"`
+	// 规范化姓名的加盐哈希，与 Identity.LookupHash 一起判断再次登录时提交的身份是否与记录一致
+	NameHash string `json:"name_hash"`
+	// 出版署分配的用户唯一标识，上报游戏行为时使用，未配置 PIResolver 时为空
+	PI       string    `json:"pi"`
+	Provider string    `json:"provider"`
+	Birthday time.Time `json:"birthday"`
+	// 验证时间戳（毫秒）
+	VerifiedAt int64 `json:"verified_at"`
+}
+
+func (record *AccountRecord) MarshalBinary() ([]byte, error) {
+	return json.Marshal(record)
+}
+
+func (record *AccountRecord) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, record)
+}
+
+// AccountStore 账号实名认证记录存储，记录不存在时返回 ErrAccountNotFound
+type AccountStore interface {
+	Load(ctx context.Context, accountID int64) (AccountRecord, error)
+	Save(ctx context.Context, accountID int64, record AccountRecord) error
+}
+
+// memoryAccountStore 基于内存的账号记录存储，适用于单机或测试
+type memoryAccountStore struct {
+	mu      sync.RWMutex
+	records map[int64]AccountRecord
+}
+
+// NewMemoryAccountStore 创建基于内存的账号记录存储
+func NewMemoryAccountStore() AccountStore {
+	return &memoryAccountStore{records: make(map[int64]AccountRecord)}
+}
+
+func (store *memoryAccountStore) Load(ctx context.Context, accountID int64) (AccountRecord, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	record, ok := store.records[accountID]
+	if !ok {
+		return AccountRecord{}, ErrAccountNotFound
+	}
+	return record, nil
+}
+
+func (store *memoryAccountStore) Save(ctx context.Context, accountID int64, record AccountRecord) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.records[accountID] = record
+	return nil
+}
+
+// storageAccountStore 基于 global-storage hash 的账号记录存储，以账号ID为field
+type storageAccountStore struct {
+	hash storage.HashTransactional
+}
+
+// NewStorageAccountStore 创建基于 global-storage 的账号记录存储
+// hash: 需以 NewAccountRecordFactory 作为数据工厂注册的 hash 存储
+func NewStorageAccountStore(hash storage.HashTransactional) AccountStore {
+	return &storageAccountStore{hash: hash}
+}
+
+// NewAccountRecordFactory 返回账号记录的数据工厂，用于注册 hash 存储
+func NewAccountRecordFactory() storage.StorageData {
+	return &AccountRecord{}
+}
+
+func (store *storageAccountStore) Load(ctx context.Context, accountID int64) (AccountRecord, error) {
+	data, err := store.hash.HGet(ctx, strconv.FormatInt(accountID, 10))
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return AccountRecord{}, ErrAccountNotFound
+	}
+	if err != nil {
+		return AccountRecord{}, err
+	}
+	record, ok := data.(*AccountRecord)
+	if !ok {
+		return AccountRecord{}, errors.New("compliance: unexpected account record type")
+	}
+	return *record, nil
+}
+
+func (store *storageAccountStore) Save(ctx context.Context, accountID int64, record AccountRecord) error {
+	return store.hash.HSet(ctx, strconv.FormatInt(accountID, 10), &record)
+}
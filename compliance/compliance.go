@@ -0,0 +1,423 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	anti_addiction "github.com/NumberMan1/component/anti-addiction"
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+var (
+	// ErrAccountNotFound 账号没有实名认证记录
+	ErrAccountNotFound = errors.New("compliance: account not found")
+	// ErrIdentityChanged 账号已实名，提交的姓名或身份证号与记录不一致
+	ErrIdentityChanged = errors.New("compliance: identity differs from verified record")
+	// ErrNotLoggedIn 账号未通过 OnLogin 登录
+	ErrNotLoggedIn = errors.New("compliance: account not logged in")
+)
+
+// PurchaseLimits 登录时的充值限额，单位为分
+type PurchaseLimits struct {
+	// 单笔最多可充值的金额，-1 表示无限制，0 表示禁止充值
+	MaxSingle int64 `json:"max_single"`
+	// 当月剩余可充值额度，-1 表示无限制，0 表示已无额度
+	RemainingMonthly int64 `json:"remaining_monthly"`
+}
+
+// LoginResult 登录的实名与防沉迷结果
+type LoginResult struct {
+	AccountID int64 `json:"account_id"`
+	// 登录时刻的年龄，认证中时为 anti_addiction.AgePending
+	Age        int32  `json:"age"`
+	AgeBracket string `json:"age_bracket"`
+	// 是否未成年，认证中时视为未成年
+	IsMinor bool   `json:"is_minor"`
+	PI      string `json:"pi,omitempty"`
+	// 实名认证结果来自账号记录，未调用服务商
+	Cached bool `json:"cached"`
+	// 服务商不可用，按实名认证等待期放行，不可充值，在等待期或可游玩时间段结束时下线
+	Pending bool `json:"pending"`
+	// 可游玩结束时间戳（毫秒），-1 表示不可游玩，0 表示无限制；配置了 SessionManager 时已考虑每日累计时长
+	PlayEndTime int64 `json:"play_end_time"`
+	// 不可游玩的原因，可游玩时为 DenyReasonNone
+	DenyReason anti_addiction.DenyReason `json:"deny_reason"`
+	Purchase   PurchaseLimits            `json:"purchase"`
+}
+
+// KickEvent 在线账号到达可游玩结束时刻，需要下线
+type KickEvent struct {
+	AccountID int64
+	Reason    anti_addiction.DenyReason
+	At        time.Time
+}
+
+// login 在线账号的状态
+type login struct {
+	pi        string
+	sessionID string
+	// stopKick 取消下线调度，可重复调用
+	stopKick func()
+}
+
+// Service 组合实名认证、防沉迷与 global-storage 的登录流程：实名认证（账号记录作为缓存）、计算年龄段、
+// 记录 PI、返回可游玩时间与充值限额，并在可游玩结束时刻触发下线事件
+type Service struct {
+	sdk      idcard_sdk.IdCardSDK
+	checker  anti_addiction.AntiAddictionChecker
+	accounts AccountStore
+	vault    *idcard_sdk.IdentityVault
+
+	sessions   *anti_addiction.SessionManager
+	recorder   *anti_addiction.PurchaseRecorder
+	reporter   *anti_addiction.BehaviorReporter
+	grace      *anti_addiction.GraceChecker
+	piResolver PIResolver
+	onKick     func(KickEvent)
+	timeNow    func() time.Time
+
+	mu     sync.Mutex
+	logins map[int64]*login
+}
+
+// NewService 创建登录合规服务
+// sdk: 实名认证，通常为 idcard_sdk.GetIdCardSDK()
+// accounts: 账号实名认证记录，同一账号再次以相同身份登录时不再调用服务商
+// vault: 加密账号记录中的姓名与身份证号
+func NewService(sdk idcard_sdk.IdCardSDK, checker anti_addiction.AntiAddictionChecker, accounts AccountStore, vault *idcard_sdk.IdentityVault) *Service {
+	return &Service{
+		sdk:      sdk,
+		checker:  checker,
+		accounts: accounts,
+		vault:    vault,
+		timeNow:  time.Now,
+		logins:   make(map[int64]*login),
+	}
+}
+
+// SetTimeNow 为了便于测试，添加设置时间函数的方法
+func (service *Service) SetTimeNow(timeNow func() time.Time) {
+	service.timeNow = timeNow
+}
+
+// SetSessionManager 设置在线会话管理，登录时开始会话并按每日累计时长计算结束时刻，登出时结束会话
+func (service *Service) SetSessionManager(sessions *anti_addiction.SessionManager) {
+	service.sessions = sessions
+}
+
+// SetPurchaseRecorder 设置充值累计记录，登录时按当月已充值总额计算剩余额度
+func (service *Service) SetPurchaseRecorder(recorder *anti_addiction.PurchaseRecorder) {
+	service.recorder = recorder
+}
+
+// SetBehaviorReporter 设置上下线行为上报，账号有 PI 时在登录与登出时上报
+func (service *Service) SetBehaviorReporter(reporter *anti_addiction.BehaviorReporter) {
+	service.reporter = reporter
+}
+
+// SetGraceChecker 设置实名认证等待期，服务商不可用时按等待期放行；同时设置到 AntiAddictionChecker，等待期时长以其 Config.GraceConfig 为准
+func (service *Service) SetGraceChecker(grace *anti_addiction.GraceChecker) {
+	service.grace = grace
+	service.checker.SetGraceChecker(grace)
+}
+
+// SetPIResolver 设置获取 PI 的方式，如 NPPAPIResolver；获取失败不影响登录，下次登录时重试
+func (service *Service) SetPIResolver(resolver PIResolver) {
+	service.piResolver = resolver
+}
+
+// SetKickHandler 设置下线事件的处理，在独立的 goroutine 中调用，需在登录前设置
+func (service *Service) SetKickHandler(handler func(KickEvent)) {
+	service.onKick = handler
+}
+
+// OnLogin 登录时完成实名认证与防沉迷检查：不可游玩时返回 anti_addiction.ErrPlayNotAllowed 及原因，
+// 姓名与身份证号不一致时返回 idcard_sdk.ErrMismatch，账号已实名但身份不一致时返回 ErrIdentityChanged；
+// 服务商不可用且未设置等待期或等待期已用完时返回服务商的错误。同一账号重复登录时替换之前的下线调度
+func (service *Service) OnLogin(ctx context.Context, accountID int64, name, idNo string) (LoginResult, error) {
+	now := service.timeNow()
+	result := LoginResult{AccountID: accountID, Age: anti_addiction.AgePending}
+	record, cached, verifyErr := service.verify(ctx, accountID, name, idNo, now)
+	switch {
+	case verifyErr == nil:
+		result.Age = anti_addiction.AgeAt(record.Birthday, now)
+		result.PI = record.PI
+		result.Cached = cached
+	case isPending(verifyErr) && service.grace != nil:
+		if _, err := service.grace.StartGracePeriod(ctx, accountID); err != nil {
+			return result, err
+		}
+		result.Pending = true
+	default:
+		return result, verifyErr
+	}
+	result.IsMinor = result.Pending || result.Age < service.checker.AdultAge()
+	result.AgeBracket = idcard_sdk.AgeBracketUnknown
+	if !result.Pending {
+		result.AgeBracket = service.checker.AgeBracket(result.Age)
+	}
+
+	allowed, err := service.checker.IsInPlayTimeForAccount(ctx, accountID, result.Age)
+	if err != nil {
+		return result, err
+	}
+	if !allowed && result.Pending {
+		// 等待期已用完，仍以服务商的错误拒绝
+		result.PlayEndTime = -1
+		return result, verifyErr
+	}
+	if !allowed {
+		result.PlayEndTime = -1
+		if _, result.DenyReason = service.checker.IsInPlayTimeWithReason(result.Age); result.DenyReason == anti_addiction.DenyReasonNone {
+			result.DenyReason = anti_addiction.DenyReasonOutsideWindow
+		}
+		return result, anti_addiction.ErrPlayNotAllowed
+	}
+	if result.Pending {
+		return result, service.loginPending(ctx, &result)
+	}
+
+	if err = service.startSession(ctx, &result); err != nil {
+		return result, err
+	}
+	if result.Purchase, err = service.purchaseLimits(ctx, accountID, result.Age); err != nil {
+		service.endSession(ctx, accountID)
+		return result, err
+	}
+	current := &login{pi: result.PI, sessionID: strconv.FormatInt(accountID, 10) + "-" + strconv.FormatInt(now.UnixMilli(), 10)}
+	current.stopKick = service.scheduleKick(accountID, result.Age, result.PlayEndTime, anti_addiction.DenyReasonDailyDurationExhausted)
+	service.addLogin(accountID, current)
+	service.report(current, anti_addiction.BehaviorOnline, now)
+	return result, nil
+}
+
+// OnLogout 登出时取消下线调度、结束会话并上报下线行为
+func (service *Service) OnLogout(ctx context.Context, accountID int64) error {
+	service.mu.Lock()
+	current, ok := service.logins[accountID]
+	delete(service.logins, accountID)
+	service.mu.Unlock()
+	if !ok {
+		return ErrNotLoggedIn
+	}
+	current.stopKick()
+	service.report(current, anti_addiction.BehaviorOffline, service.timeNow())
+	if service.sessions == nil || current.sessionID == "" {
+		return nil
+	}
+	if err := service.sessions.EndSession(ctx, accountID); err != nil && !errors.Is(err, anti_addiction.ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Close 登出全部在线账号，可通过 lifecycle.Closer 注册，需在 BehaviorReporter 停止前关闭
+func (service *Service) Close() error {
+	service.mu.Lock()
+	accountIDs := make([]int64, 0, len(service.logins))
+	for accountID := range service.logins {
+		accountIDs = append(accountIDs, accountID)
+	}
+	service.mu.Unlock()
+	var errs []error
+	for _, accountID := range accountIDs {
+		if err := service.OnLogout(context.Background(), accountID); err != nil && !errors.Is(err, ErrNotLoggedIn) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verify 按账号记录或服务商完成实名认证，返回的 cached 表示未调用服务商
+func (service *Service) verify(ctx context.Context, accountID int64, name, idNo string, now time.Time) (record AccountRecord, cached bool, err error) {
+	lookupHash := service.vault.LookupHash(idNo)
+	nameHash := service.vault.LookupHash(idcard_sdk.NormalizeName(name))
+	record, err = service.accounts.Load(ctx, accountID)
+	switch {
+	case err == nil && record.Identity.LookupHash == lookupHash && record.NameHash == nameHash:
+		cached = true
+	case err == nil:
+		return AccountRecord{}, false, ErrIdentityChanged
+	case errors.Is(err, ErrAccountNotFound):
+		if record, err = service.verifyProvider(ctx, name, idNo, now); err != nil {
+			return AccountRecord{}, false, err
+		}
+		record.NameHash = nameHash
+	default:
+		return AccountRecord{}, false, err
+	}
+
+	resolved := false
+	if record.PI == "" && service.piResolver != nil {
+		pi, err := service.piResolver(ctx, accountID, name, idNo)
+		if err != nil {
+			zaplogger.DefaultLogger().Named("compliance").Warn("resolve pi failed", field.Int64("account_id", accountID), field.WithError(err))
+		} else {
+			record.PI, resolved = pi, true
+		}
+	}
+	if !cached || resolved {
+		if err = service.accounts.Save(ctx, accountID, record); err != nil {
+			return AccountRecord{}, false, err
+		}
+	}
+	return record, cached, nil
+}
+
+// verifyProvider 调用服务商验证并加密身份信息，服务商未返回出生日期时由身份证号解析
+func (service *Service) verifyProvider(ctx context.Context, name, idNo string, now time.Time) (AccountRecord, error) {
+	result, err := idcard_sdk.Verify(ctx, service.sdk, name, idNo)
+	if err != nil {
+		return AccountRecord{}, err
+	}
+	birthday := result.Info.Birthday
+	if birthday.IsZero() {
+		if birthday, _, err = idcard_sdk.ExtractBirthdayAndAge(idNo, now); err != nil {
+			return AccountRecord{}, err
+		}
+	}
+	identity, err := service.vault.Seal(ctx, name, idNo)
+	if err != nil {
+		return AccountRecord{}, err
+	}
+	return AccountRecord{
+		Identity:   identity,
+		Provider:   result.Provider,
+		Birthday:   birthday,
+		VerifiedAt: now.UnixMilli(),
+	}, nil
+}
+
+// isPending 服务商暂时无法给出结果，如不可用、额度耗尽或认证中
+func isPending(err error) bool {
+	status := idcard_sdk.VerifyStatusOf(err)
+	return status == idcard_sdk.VerifyProviderError || status == idcard_sdk.VerifyQuotaExceeded
+}
+
+// loginPending 记录等待期内的登录，在等待期结束或未成年人可游玩时间段结束时触发下线事件
+func (service *Service) loginPending(ctx context.Context, result *LoginResult) error {
+	graceEnd, err := service.grace.GracePeriodEnd(ctx, result.AccountID)
+	if err != nil {
+		return err
+	}
+	result.PlayEndTime = graceEnd
+	if windowEnd := service.checker.GetPlayEndTime(result.Age); windowEnd > 0 && windowEnd < graceEnd {
+		result.PlayEndTime = windowEnd
+	}
+	current := &login{}
+	current.stopKick = service.scheduleKick(result.AccountID, result.Age, graceEnd, anti_addiction.DenyReasonVerificationPending)
+	service.addLogin(result.AccountID, current)
+	return nil
+}
+
+// endSession 登录失败时结束已开始的会话，结束失败仅记录日志
+func (service *Service) endSession(ctx context.Context, accountID int64) {
+	if service.sessions == nil {
+		return
+	}
+	if err := service.sessions.EndSession(ctx, accountID); err != nil && !errors.Is(err, anti_addiction.ErrSessionNotFound) {
+		zaplogger.DefaultLogger().Named("compliance").Warn("end session failed", field.Int64("account_id", accountID), field.WithError(err))
+	}
+}
+
+// startSession 开始会话并计算可游玩结束时刻，未设置 SessionManager 时仅按可游玩时间段计算
+func (service *Service) startSession(ctx context.Context, result *LoginResult) error {
+	if service.sessions == nil {
+		result.PlayEndTime = 0
+		if !service.checker.IsExempt(result.AccountID) {
+			result.PlayEndTime = service.checker.GetPlayEndTime(result.Age)
+		}
+		return nil
+	}
+	_, reason, err := service.sessions.StartSessionWithReason(ctx, result.AccountID, result.Age)
+	if err != nil {
+		if errors.Is(err, anti_addiction.ErrPlayNotAllowed) {
+			result.PlayEndTime, result.DenyReason = -1, reason
+		}
+		return err
+	}
+	result.PlayEndTime, err = service.sessions.GetPlayEndTime(ctx, result.AccountID, result.Age)
+	return err
+}
+
+func (service *Service) purchaseLimits(ctx context.Context, accountID int64, age int32) (PurchaseLimits, error) {
+	var monthlyTotal int64
+	if service.recorder != nil {
+		var err error
+		if monthlyTotal, err = service.recorder.GetMonthlyTotal(ctx, accountID); err != nil {
+			return PurchaseLimits{}, err
+		}
+	}
+	return PurchaseLimits{
+		MaxSingle:        service.checker.GetMaxSingleAmount(age),
+		RemainingMonthly: service.checker.GetRemainingMonthlyQuota(age, monthlyTotal),
+	}, nil
+}
+
+// scheduleKick 在可游玩时间段结束（随配置重载重新计时）或 playEndTime 时触发下线事件，返回取消函数；
+// playEndTime 为每日累计时长用完或等待期结束的时刻，先于可游玩时间段结束时以 endReason 下线。豁免名单内的账号不安排下线
+func (service *Service) scheduleKick(accountID int64, age int32, playEndTime int64, endReason anti_addiction.DenyReason) func() {
+	if service.checker.IsExempt(accountID) {
+		return func() {}
+	}
+	windowEnd, cancel := service.checker.SchedulePlayEnd(accountID, age)
+	// 每日累计时长或等待期先于可游玩时间段用完时，另外计时
+	var dailyEnd <-chan time.Time
+	var timer *time.Timer
+	if windowEndTime := service.checker.GetPlayEndTime(age); playEndTime > 0 && (windowEndTime == 0 || playEndTime < windowEndTime) {
+		timer = time.NewTimer(time.UnixMilli(playEndTime).Sub(service.timeNow()))
+		dailyEnd = timer.C
+	}
+	done := make(chan struct{})
+	go func() {
+		defer cancel()
+		if timer != nil {
+			defer timer.Stop()
+		}
+		event := KickEvent{AccountID: accountID}
+		select {
+		case event.At = <-windowEnd:
+			if _, event.Reason = service.checker.IsInPlayTimeAtWithReason(age, event.At); event.Reason == anti_addiction.DenyReasonNone {
+				event.Reason = anti_addiction.DenyReasonOutsideWindow
+			}
+		case <-dailyEnd:
+			event.At, event.Reason = time.UnixMilli(playEndTime), endReason
+		case <-done:
+			return
+		}
+		if service.onKick != nil {
+			service.onKick(event)
+		}
+	}()
+	return sync.OnceFunc(func() { close(done) })
+}
+
+// addLogin 记录在线账号，替换同一账号之前的登录
+func (service *Service) addLogin(accountID int64, current *login) {
+	service.mu.Lock()
+	previous := service.logins[accountID]
+	service.logins[accountID] = current
+	service.mu.Unlock()
+	if previous != nil {
+		previous.stopKick()
+	}
+}
+
+// report 账号有 PI 时上报上下线行为
+func (service *Service) report(current *login, behavior anti_addiction.BehaviorType, at time.Time) {
+	if service.reporter == nil || current.pi == "" {
+		return
+	}
+	service.reporter.Report(anti_addiction.BehaviorRecord{
+		SessionID:    current.sessionID,
+		BehaviorType: behavior,
+		OccurTime:    at.Unix(),
+		UserType:     anti_addiction.ReportUserCertified,
+		PI:           current.pi,
+	})
+}
@@ -0,0 +1,212 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	anti_addiction "github.com/NumberMan1/component/anti-addiction"
+	storage "github.com/NumberMan1/component/global-storage"
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+)
+
+const adultIdNo = "110101199003070003"
+
+// minorIdNo 15 周岁的北京身份证号，出生日期随当前时间变化
+var minorIdNo = idNoBornAt(time.Now().AddDate(-15, 0, -1))
+
+// idNoBornAt 生成出生日期为 birthday 的北京身份证号，按 GB 11643 计算校验码
+func idNoBornAt(birthday time.Time) string {
+	body := "110101" + birthday.Format("20060102") + "001"
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, c := range body {
+		sum += int(c-'0') * weights[i]
+	}
+	return body + string("10X98765432"[sum%11])
+}
+
+// countingSDK 统计服务商的调用次数
+type countingSDK struct {
+	*idcard_sdk.MockIdCardSDK
+	calls int
+}
+
+func (sdk *countingSDK) ValidE(ctx context.Context, name, idNo string) (idcard_sdk.VerifyResult, error) {
+	sdk.calls++
+	return sdk.MockIdCardSDK.ValidE(ctx, name, idNo)
+}
+
+func newTestService(t *testing.T, config anti_addiction.Config) (*Service, *countingSDK) {
+	t.Helper()
+	mock, err := idcard_sdk.NewMockIdCardSDK(idcard_sdk.MockConfig{DefaultPass: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker, err := anti_addiction.NewAntiAddictionChecker(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := idcard_sdk.NewStaticKeyProvider("v1", map[string][]byte{"v1": []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk := &countingSDK{MockIdCardSDK: mock}
+	return NewService(sdk, checker, NewMemoryAccountStore(), idcard_sdk.NewIdentityVault(keys, []byte("salt"))), sdk
+}
+
+func TestService_OnLogin(t *testing.T) {
+	// 未成年人任何时间都不可游玩
+	config := anti_addiction.DefaultConfig()
+	config.TimeConfig.AllowedWeekDays = nil
+	config.TimeConfig.Holidays = nil
+	service, sdk := newTestService(t, config)
+	var pis []string
+	service.SetPIResolver(func(ctx context.Context, accountID int64, name, idNo string) (string, error) {
+		pis = append(pis, idNo)
+		return "pi-" + idNo, nil
+	})
+	ctx := context.Background()
+
+	result, err := service.OnLogin(ctx, 1, "张三", adultIdNo)
+	if err != nil {
+		t.Fatalf("OnLogin() error = %v", err)
+	}
+	if result.IsMinor || result.PlayEndTime != 0 || result.Purchase.MaxSingle != -1 || result.PI != "pi-"+adultIdNo || result.Cached {
+		t.Errorf("OnLogin() adult = %+v", result)
+	}
+	if err = service.OnLogout(ctx, 1); err != nil {
+		t.Fatalf("OnLogout() error = %v", err)
+	}
+
+	// 再次登录使用账号记录，不再调用服务商与获取 PI
+	if result, err = service.OnLogin(ctx, 1, "张三", adultIdNo); err != nil || !result.Cached {
+		t.Errorf("OnLogin() again = %+v, %v, want cached", result, err)
+	}
+	if sdk.calls != 1 || len(pis) != 1 {
+		t.Errorf("provider calls = %d, pi calls = %d, want 1, 1", sdk.calls, len(pis))
+	}
+	if _, err = service.OnLogin(ctx, 1, "李四", minorIdNo); !errors.Is(err, ErrIdentityChanged) {
+		t.Errorf("OnLogin() other identity error = %v, want ErrIdentityChanged", err)
+	}
+
+	result, err = service.OnLogin(ctx, 2, "王五", minorIdNo)
+	if !errors.Is(err, anti_addiction.ErrPlayNotAllowed) {
+		t.Fatalf("OnLogin() minor error = %v, want ErrPlayNotAllowed", err)
+	}
+	if !result.IsMinor || result.DenyReason != anti_addiction.DenyReasonNotAllowedWeekday || result.PlayEndTime != -1 {
+		t.Errorf("OnLogin() minor = %+v", result)
+	}
+}
+
+func TestService_Pending(t *testing.T) {
+	// 全天可游玩，等待期内按未成年人的可游玩时间判断
+	config := anti_addiction.DefaultConfig()
+	config.TimeConfig = anti_addiction.TimeConfig{
+		EndHour: 23, EndMinute: 59, EndSecond: 59,
+		AllowedWeekDays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	}
+	config.GraceConfig.DurationSeconds = 600
+	service, sdk := newTestService(t, config)
+	sdk.SetError(idcard_sdk.ErrProviderUnavailable)
+	ctx := context.Background()
+
+	if _, err := service.OnLogin(ctx, 1, "张三", adultIdNo); !errors.Is(err, idcard_sdk.ErrProviderUnavailable) {
+		t.Errorf("OnLogin() without grace error = %v, want ErrProviderUnavailable", err)
+	}
+
+	service.SetGraceChecker(anti_addiction.NewGraceChecker(anti_addiction.GraceConfig{}, anti_addiction.NewMemoryGraceStore()))
+	result, err := service.OnLogin(ctx, 1, "张三", adultIdNo)
+	if err != nil || !result.Pending || !result.IsMinor || result.Purchase.MaxSingle != 0 {
+		t.Errorf("OnLogin() in grace = %+v, %v, want pending", result, err)
+	}
+}
+
+func TestService_Kick(t *testing.T) {
+	// 全天可游玩，未成年人每日限时 1 秒
+	config := anti_addiction.DefaultConfig()
+	config.TimeConfig = anti_addiction.TimeConfig{
+		EndHour: 23, EndMinute: 59, EndSecond: 59,
+		AllowedWeekDays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	}
+	config.DurationConfig = anti_addiction.DurationConfig{Limits: []anti_addiction.DurationLimit{{MinAge: 0, MaxAge: 18, DailySeconds: 1}}}
+	service, _ := newTestService(t, config)
+	service.SetSessionManager(anti_addiction.NewSessionManager(service.checker, anti_addiction.NewMemoryDurationTracker()))
+	kicked := make(chan KickEvent, 1)
+	service.SetKickHandler(func(event KickEvent) { kicked <- event })
+
+	result, err := service.OnLogin(context.Background(), 1, "王五", minorIdNo)
+	if err != nil {
+		t.Fatalf("OnLogin() error = %v", err)
+	}
+	if result.Purchase.MaxSingle != 5000 || result.Purchase.RemainingMonthly != 20000 {
+		t.Errorf("Purchase = %+v, want 5000, 20000", result.Purchase)
+	}
+	select {
+	case event := <-kicked:
+		if event.AccountID != 1 || event.Reason != anti_addiction.DenyReasonDailyDurationExhausted {
+			t.Errorf("KickEvent = %+v, want daily duration exhausted", event)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no kick event")
+	}
+	if err = service.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestService_PendingKick(t *testing.T) {
+	// 全天可游玩，等待期 1 秒
+	config := anti_addiction.DefaultConfig()
+	config.TimeConfig = anti_addiction.TimeConfig{
+		EndHour: 23, EndMinute: 59, EndSecond: 59,
+		AllowedWeekDays: []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday},
+	}
+	config.GraceConfig.DurationSeconds = 1
+	service, sdk := newTestService(t, config)
+	sdk.SetError(idcard_sdk.ErrProviderUnavailable)
+	service.SetGraceChecker(anti_addiction.NewGraceChecker(anti_addiction.GraceConfig{}, anti_addiction.NewMemoryGraceStore()))
+	kicked := make(chan KickEvent, 1)
+	service.SetKickHandler(func(event KickEvent) { kicked <- event })
+
+	result, err := service.OnLogin(context.Background(), 1, "张三", adultIdNo)
+	if err != nil || !result.Pending || result.PlayEndTime <= 0 {
+		t.Fatalf("OnLogin() = %+v, %v, want pending with play end time", result, err)
+	}
+	select {
+	case event := <-kicked:
+		if event.AccountID != 1 || event.Reason != anti_addiction.DenyReasonVerificationPending {
+			t.Errorf("KickEvent = %+v, want verification pending", event)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no kick event at the end of the grace period")
+	}
+	if err = service.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+// failingHash 读取时返回错误的 hash 存储
+type failingHash struct {
+	storage.HashTransactional
+}
+
+func (failingHash) HGet(ctx context.Context, field string) (storage.StorageData, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+func TestService_PurchaseLimitsErrorEndsSession(t *testing.T) {
+	service, _ := newTestService(t, anti_addiction.DefaultConfig())
+	sessions := anti_addiction.NewSessionManager(service.checker, anti_addiction.NewMemoryDurationTracker())
+	service.SetSessionManager(sessions)
+	service.SetPurchaseRecorder(anti_addiction.NewPurchaseRecorder(service.checker, failingHash{}))
+
+	if _, err := service.OnLogin(context.Background(), 1, "张三", adultIdNo); err == nil {
+		t.Fatal("OnLogin() error = nil, want storage error")
+	}
+	// 登录失败时结束已开始的会话
+	if sessions.OnlineCount() != 0 {
+		t.Errorf("OnlineCount() = %d, want 0", sessions.OnlineCount())
+	}
+}
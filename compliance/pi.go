@@ -0,0 +1,32 @@
+package compliance
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+
+	idcard_sdk "github.com/NumberMan1/component/idcard-sdk"
+)
+
+// PIResolver 获取已通过实名认证账号的 PI，OnLogin 在账号记录中没有 PI 时调用
+type PIResolver func(ctx context.Context, accountID int64, name, idNo string) (string, error)
+
+// NPPAPIResolver 通过出版署实名认证系统获取 PI，以账号ID的 MD5 作为 ai，同一账号保持不变；
+// 认证中时返回 idcard_sdk.ErrNPPAProcessing，下次登录时再次获取
+func NPPAPIResolver(sdk *idcard_sdk.NPPAIdCardSDK) PIResolver {
+	return func(ctx context.Context, accountID int64, name, idNo string) (string, error) {
+		sum := md5.Sum([]byte(strconv.FormatInt(accountID, 10)))
+		result, err := sdk.Check(ctx, hex.EncodeToString(sum[:]), name, idNo)
+		if err != nil {
+			return "", err
+		}
+		switch result.Status {
+		case idcard_sdk.NPPAAuthSuccess:
+			return result.PI, nil
+		case idcard_sdk.NPPAAuthProcessing:
+			return "", idcard_sdk.ErrNPPAProcessing
+		}
+		return "", idcard_sdk.ErrMismatch
+	}
+}
@@ -0,0 +1,44 @@
+// Package filewatch 按修改时间轮询文件的变更，供各组件的配置热加载共用
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Watch 按 interval 轮询 path 的修改时间，修改时间晚于上次记录时调用 onChange；
+// 读取文件信息失败或 onChange 返回错误时通过 onError 回调通知（可为 nil）。ctx 结束时返回
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func() error, onError func(error)) {
+	var lastModTime time.Time
+	if stat, err := os.Stat(path); err == nil {
+		lastModTime = stat.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			if onError != nil {
+				onError(fmt.Errorf("filewatch: stat %s: %w", path, err))
+			}
+			continue
+		}
+		if !stat.ModTime().After(lastModTime) {
+			continue
+		}
+		lastModTime = stat.ModTime()
+
+		if err = onChange(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	changes, errs := make(chan struct{}, 1), make(chan error, 1)
+	errChange := errors.New("invalid config")
+	go func() {
+		defer close(done)
+		Watch(ctx, path, 10*time.Millisecond, func() error {
+			changes <- struct{}{}
+			return errChange
+		}, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+	}()
+
+	// 未修改时不回调
+	select {
+	case <-changes:
+		t.Fatal("onChange called before modification")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	modTime := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("onChange not called after modification")
+	}
+	if err := <-errs; !errors.Is(err, errChange) {
+		t.Errorf("onError(%v), want %v", err, errChange)
+	}
+
+	// 文件删除后通知读取失败
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("onError(%v), want not exist", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("onError not called after removal")
+	}
+
+	cancel()
+	<-done
+}
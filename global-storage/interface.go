@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"encoding"
+	"time"
 )
 
 type MemoryStorageData interface {
@@ -46,6 +47,9 @@ type SortedSetData interface {
 type KVTransactional interface {
 	Set(ctx context.Context, value StorageData) error
 	Get(ctx context.Context, dest StorageData) error
+	// Update 读取当前值到 dest 后调用 fn（found 表示 key 是否存在），以 Lua 脚本确认值在读取后未被修改再写入 fn 返回的值，
+	// 值已被修改时返回 ErrTransactionConflict；fn 返回 nil 时不写入。不占用连接，适合单次读改写
+	Update(ctx context.Context, dest StorageData, fn func(found bool) (StorageData, error)) error
 	BeginTx(ctx context.Context) (KVTransaction, error)
 }
 
@@ -63,6 +67,9 @@ type HashTransactional interface {
 	HSet(ctx context.Context, field string, value StorageData) error
 	HGet(ctx context.Context, field string) (StorageData, error)
 	HDel(ctx context.Context, fields ...string) error
+	// HUpdate 读取单个字段交给 fn（字段不存在时为 nil），以 Lua 脚本确认字段在读取后未被修改再写入 fn 返回的值，
+	// 字段已被修改时返回 ErrTransactionConflict；fn 返回 nil 时不写入。只检测该字段，不受其他字段写入的影响
+	HUpdate(ctx context.Context, field string, fn func(current StorageData) (StorageData, error)) error
 	BeginTx(ctx context.Context) (HashTransaction, error)
 }
 
@@ -99,3 +106,132 @@ type SortedSetTransaction interface {
 	Commit(ctx context.Context) error
 	Rollback()
 }
+
+// ListTransactional 绑定单一 list key 的列表操作。
+type ListTransactional interface {
+	LPush(ctx context.Context, values ...StorageData) error
+	RPush(ctx context.Context, values ...StorageData) error
+	LRange(ctx context.Context, start, stop int64) ([]StorageData, error)
+	LPop(ctx context.Context) (StorageData, error)
+	LTrim(ctx context.Context, start, stop int64) error
+	BeginTx(ctx context.Context) (ListTransaction, error)
+}
+
+// ListTransaction 定义列表事务快照操作，提交时通过 Lua 脚本比对快照并原子执行。
+type ListTransaction interface {
+	LPush(values ...StorageData) error
+	RPush(values ...StorageData) error
+	LRange(start, stop int64) ([]StorageData, error)
+	LPop() (StorageData, error)
+	LTrim(start, stop int64) error
+	Commit(ctx context.Context) error
+	Rollback()
+}
+
+// SetTransactional 绑定单一 set key 的无序集合操作。
+type SetTransactional interface {
+	SAdd(ctx context.Context, members ...StorageData) error
+	SRem(ctx context.Context, members ...StorageData) error
+	SMembers(ctx context.Context) ([]StorageData, error)
+	SIsMember(ctx context.Context, member StorageData) (bool, error)
+	BeginTx(ctx context.Context) (SetTransaction, error)
+}
+
+// SetTransaction 定义无序集合事务快照操作，提交时通过 Lua 脚本比对快照并原子执行。
+type SetTransaction interface {
+	SAdd(members ...StorageData) error
+	SRem(members ...StorageData) error
+	SMembers() ([]StorageData, error)
+	SIsMember(member StorageData) (bool, error)
+	Commit(ctx context.Context) error
+	Rollback()
+}
+
+// BitmapTransactional 绑定单一 string key 的位图操作，offset 从 0 开始。
+type BitmapTransactional interface {
+	SetBit(ctx context.Context, offset int64, value bool) (bool, error)
+	GetBit(ctx context.Context, offset int64) (bool, error)
+	BitCount(ctx context.Context) (int64, error)
+	BitPos(ctx context.Context, value bool) (int64, error)
+	BeginTx(ctx context.Context) (BitmapTransaction, error)
+}
+
+// BitmapTransaction 定义位图事务快照操作，提交时通过 Lua 脚本比对快照并原子执行。
+type BitmapTransaction interface {
+	SetBit(offset int64, value bool) error
+	GetBit(offset int64) (bool, error)
+	Commit(ctx context.Context) error
+	Rollback()
+}
+
+// CounterTransactional 绑定单一 key 的原子计数器，每个操作都是单条命令或 Lua 脚本。
+type CounterTransactional interface {
+	Incr(ctx context.Context) (int64, error)
+	IncrBy(ctx context.Context, delta int64) (int64, error)
+	DecrBy(ctx context.Context, delta int64) (int64, error)
+	Get(ctx context.Context) (int64, error)
+	Reset(ctx context.Context) error
+	// Expire 设置计数器的过期时间，过期后 Get 返回 0，常用于按周期分 key 的计数
+	Expire(ctx context.Context, ttl time.Duration) error
+}
+
+// GeoMember 地理位置查询结果，Distance 为到查询中心的距离（米）
+type GeoMember struct {
+	Data      StorageData
+	Longitude float64
+	Latitude  float64
+	Distance  float64
+}
+
+// GeoTransactional 绑定单一 geo key 的地理位置操作，距离单位均为米。
+type GeoTransactional interface {
+	GeoAdd(ctx context.Context, longitude, latitude float64, member StorageData) error
+	GeoRem(ctx context.Context, members ...StorageData) error
+	GeoPos(ctx context.Context, member StorageData) (longitude, latitude float64, err error)
+	GeoDist(ctx context.Context, a, b StorageData) (float64, error)
+	GeoSearchByRadius(ctx context.Context, longitude, latitude, radius float64, count int) ([]GeoMember, error)
+	GeoSearchByMember(ctx context.Context, member StorageData, radius float64, count int) ([]GeoMember, error)
+}
+
+// StreamMessage stream 中的一条消息，Data 为 nil 表示消息已被删除或裁剪，仅剩待确认记录
+type StreamMessage struct {
+	ID   string
+	Data StorageData
+}
+
+// StreamTransactional 绑定单一 stream key 的消息流操作，支持消费组与待确认消息认领。
+type StreamTransactional interface {
+	XAdd(ctx context.Context, value StorageData) (string, error)
+	XRead(ctx context.Context, lastID string, count int64, block time.Duration) ([]StreamMessage, error)
+	XGroupCreate(ctx context.Context, group, start string) error
+	XReadGroup(ctx context.Context, group, consumer, start string, count int64, block time.Duration) ([]StreamMessage, error)
+	XAck(ctx context.Context, group string, ids ...string) error
+	XAutoClaim(ctx context.Context, group, consumer string, minIdle time.Duration, start string, count int64) ([]StreamMessage, string, error)
+	BeginTx(ctx context.Context) (StreamTransaction, error)
+}
+
+// StreamTransaction 定义批量追加消息的事务，提交时通过 MULTI/EXEC 原子追加并返回消息ID。
+type StreamTransaction interface {
+	XAdd(value StorageData) error
+	Commit(ctx context.Context) ([]string, error)
+	Rollback()
+}
+
+// TokenBucket 绑定单一 key 的令牌桶，在 Lua 脚本中按 Redis 服务器时间补充并预约令牌，多个实例使用同一 key 时共享配额。
+type TokenBucket interface {
+	// Take 以每秒 rate 个的速度补充令牌、容量为 burst，预约一个令牌并返回需等待的时长；
+	// 需等待超过 maxWait 时不预约并返回 false。rate 与 burst 需大于 0
+	Take(ctx context.Context, rate, burst float64, maxWait time.Duration) (time.Duration, bool, error)
+}
+
+// Lease 绑定单一 key 的租约，持有者需在到期前续约，用于在多个实例间分配唯一资源（如 worker ID）。
+type Lease interface {
+	// Acquire 在 key 未被持有时以 owner 持有 ttl，返回是否获得
+	Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+	// Renew 在 key 仍由 owner 持有时延长至 ttl，返回 false 表示租约已过期或被他人持有
+	Renew(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+	// Release 在 key 仍由 owner 持有时释放
+	Release(ctx context.Context, owner string) error
+	// Owner 当前的持有者，未被持有时返回 ErrFieldNotFound
+	Owner(ctx context.Context) (string, error)
+}
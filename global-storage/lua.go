@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+)
+
+// luaDigest 计算快照摘要的 Lua 片段，与 snapshotDigest 一致：每个元素编码为 长度:内容 后拼接，取 SHA1。
+// 使用前需将元素放入 items
+const luaDigest = `
+local parts = {}
+for i, item in ipairs(items) do
+	parts[i] = #item .. ':' .. item
+end
+local digest = redis.sha1hex(table.concat(parts))
+`
+
+// snapshotDigest 计算事务快照的摘要，Lua 提交时与当前数据的摘要比对以检测冲突
+func snapshotDigest(items [][]byte) string {
+	h := sha1.New()
+	for _, item := range items {
+		h.Write([]byte(strconv.Itoa(len(item)) + ":"))
+		h.Write(item)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
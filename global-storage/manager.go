@@ -29,7 +29,7 @@ type ManagerConfig struct {
 	RedisDB   int    `json:"redis_db" yaml:"redis-db"`
 }
 
-// StorageManager 管理 KV、Hash、SortedSet 存储实例，并持有统一的 Redis 客户端
+// StorageManager 管理 KV、Hash、SortedSet、List、Set、Bitmap、Geo、Stream、Counter 存储实例，并持有统一的 Redis 客户端
 // 注册 Redis 存储时，会自动初始化并复用此客户端
 // 支持内存事务快照
 type StorageManager struct {
@@ -42,6 +42,12 @@ type StorageManager struct {
 	kvs      map[string]KVTransactional
 	hashs    map[string]HashTransactional
 	zsets    map[string]SortedSetTransactional
+	lists    map[string]ListTransactional
+	sets     map[string]SetTransactional
+	bitmaps  map[string]BitmapTransactional
+	geos     map[string]GeoTransactional
+	streams  map[string]StreamTransactional
+	counters map[string]CounterTransactional
 	memHashs map[string]MemoryTransactional
 }
 
@@ -67,10 +73,26 @@ func NewManager(cfg ManagerConfig) (*StorageManager, error) {
 		kvs:         make(map[string]KVTransactional),
 		hashs:       make(map[string]HashTransactional),
 		zsets:       make(map[string]SortedSetTransactional),
+		lists:       make(map[string]ListTransactional),
+		sets:        make(map[string]SetTransactional),
+		bitmaps:     make(map[string]BitmapTransactional),
+		geos:        make(map[string]GeoTransactional),
+		streams:     make(map[string]StreamTransactional),
+		counters:    make(map[string]CounterTransactional),
 		memHashs:    make(map[string]MemoryTransactional),
 	}, nil
 }
 
+// Ping 检查 Redis 连接是否可用，可注册为健康检查
+func (m *StorageManager) Ping(ctx context.Context) error {
+	return m.redisClient.Ping(ctx).Err()
+}
+
+// AddHook 为 Redis 客户端添加钩子，如 metrics.RedisHook 统计命令的次数与延迟
+func (m *StorageManager) AddHook(hook redis.Hook) {
+	m.redisClient.AddHook(hook)
+}
+
 // Close 关闭 StorageManager 持有的 Redis 客户端连接
 func (m *StorageManager) Close() error {
 	if m.redisClient != nil {
@@ -114,6 +136,75 @@ func (m *StorageManager) RegisterSortedSetStorage(name string, dataFactory Sorte
 	return nil
 }
 
+// RegisterListStorage 直接通过 Manager 的 Redis 客户端注册 List 存储
+func (m *StorageManager) RegisterListStorage(name string, dataFactory StorageDataFactory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.lists[name]; exists {
+		return errors.New("List storage already registered: " + name)
+	}
+	m.lists[name] = NewRedisList(m.redisClient, name, dataFactory)
+	return nil
+}
+
+// RegisterSetStorage 直接通过 Manager 的 Redis 客户端注册 Set 存储
+func (m *StorageManager) RegisterSetStorage(name string, dataFactory StorageDataFactory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.sets[name]; exists {
+		return errors.New("Set storage already registered: " + name)
+	}
+	m.sets[name] = NewRedisSet(m.redisClient, name, dataFactory)
+	return nil
+}
+
+// RegisterBitmapStorage 直接通过 Manager 的 Redis 客户端注册 Bitmap 存储
+func (m *StorageManager) RegisterBitmapStorage(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.bitmaps[name]; exists {
+		return errors.New("Bitmap storage already registered: " + name)
+	}
+	m.bitmaps[name] = NewRedisBitmap(m.redisClient, name)
+	return nil
+}
+
+// RegisterGeoStorage 直接通过 Manager 的 Redis 客户端注册 Geo 存储
+func (m *StorageManager) RegisterGeoStorage(name string, dataFactory StorageDataFactory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.geos[name]; exists {
+		return errors.New("Geo storage already registered: " + name)
+	}
+	m.geos[name] = NewRedisGeo(m.redisClient, name, dataFactory)
+	return nil
+}
+
+// RegisterStreamStorage 直接通过 Manager 的 Redis 客户端注册 Stream 存储
+func (m *StorageManager) RegisterStreamStorage(name string, dataFactory StorageDataFactory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.streams[name]; exists {
+		return errors.New("Stream storage already registered: " + name)
+	}
+	m.streams[name] = NewRedisStream(m.redisClient, name, dataFactory)
+	return nil
+}
+
+// RegisterCounterStorage 直接通过 Manager 的 Redis 客户端注册 Counter 存储，bounds 为 nil 时不限制取值范围，Min 大于 Max 时返回错误
+func (m *StorageManager) RegisterCounterStorage(name string, bounds *CounterBounds) error {
+	if err := bounds.Validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.counters[name]; exists {
+		return errors.New("Counter storage already registered: " + name)
+	}
+	m.counters[name] = NewRedisCounter(m.redisClient, name, bounds)
+	return nil
+}
+
 // RegisterMemoryHash 直接通过 Manager 构建内存仓储
 func (m *StorageManager) RegisterMemoryHash(name string) error {
 	m.mu.Lock()
@@ -125,6 +216,21 @@ func (m *StorageManager) RegisterMemoryHash(name string) error {
 	return nil
 }
 
+// NewLease 通过 Manager 的 Redis 客户端创建租约，租约 key 通常按资源动态生成，无需注册
+func (m *StorageManager) NewLease(key string) Lease {
+	return NewRedisLease(m.redisClient, key)
+}
+
+// NewCounter 通过 Manager 的 Redis 客户端创建计数器，用于按周期或按玩家动态生成 key 的计数，无需注册
+func (m *StorageManager) NewCounter(key string, bounds *CounterBounds) CounterTransactional {
+	return NewRedisCounter(m.redisClient, key, bounds)
+}
+
+// NewTokenBucket 通过 Manager 的 Redis 客户端创建令牌桶，共享配额的实例需使用同一个 key
+func (m *StorageManager) NewTokenBucket(key string) TokenBucket {
+	return NewRedisTokenBucket(m.redisClient, key)
+}
+
 // —— 通用获取与事务方法 ——
 
 // GetKV 获取已注册的 KV 存储
@@ -157,6 +263,66 @@ func (m *StorageManager) GetSortedSet(name string) (SortedSetTransactional, erro
 	return nil, errors.New("SortedSet storage not found: " + name)
 }
 
+// GetList 获取已注册的 List 存储
+func (m *StorageManager) GetList(name string) (ListTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.lists[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("List storage not found: " + name)
+}
+
+// GetSet 获取已注册的 Set 存储
+func (m *StorageManager) GetSet(name string) (SetTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.sets[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("Set storage not found: " + name)
+}
+
+// GetBitmap 获取已注册的 Bitmap 存储
+func (m *StorageManager) GetBitmap(name string) (BitmapTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.bitmaps[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("Bitmap storage not found: " + name)
+}
+
+// GetGeo 获取已注册的 Geo 存储
+func (m *StorageManager) GetGeo(name string) (GeoTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.geos[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("Geo storage not found: " + name)
+}
+
+// GetStream 获取已注册的 Stream 存储
+func (m *StorageManager) GetStream(name string) (StreamTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.streams[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("Stream storage not found: " + name)
+}
+
+// GetCounter 获取已注册的 Counter 存储
+func (m *StorageManager) GetCounter(name string) (CounterTransactional, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.counters[name]; ok {
+		return s, nil
+	}
+	return nil, errors.New("Counter storage not found: " + name)
+}
+
 // GetMemoryHash 获取已注册的 MemoryHash 存储
 func (m *StorageManager) GetMemoryHash(name string) (MemoryTransactional, error) {
 	m.mu.RLock()
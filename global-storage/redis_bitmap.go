@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// bitmapCommitScript 比对当前位图与快照的摘要，一致时依次执行 SETBIT，不一致时返回 0
+var bitmapCommitScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1]) or ''
+if redis.sha1hex(value) ~= ARGV[1] then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	redis.call('SETBIT', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+return 1`)
+
+// redisBitmap 实现了 BitmapTransactional，绑定一个固定 string key，按位读写。
+type redisBitmap struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisBitmap 构造 BitmapTransactional，适合签到、在线天数等按天标记的场景。
+func NewRedisBitmap(client *redis.Client, key string) BitmapTransactional {
+	return &redisBitmap{
+		client: client,
+		key:    key,
+	}
+}
+
+// SetBit 设置 offset 位，返回该位原来的值
+func (r *redisBitmap) SetBit(ctx context.Context, offset int64, value bool) (bool, error) {
+	old, err := r.client.SetBit(ctx, r.key, offset, bitValue(value)).Result()
+	return old == 1, err
+}
+
+func (r *redisBitmap) GetBit(ctx context.Context, offset int64) (bool, error) {
+	bit, err := r.client.GetBit(ctx, r.key, offset).Result()
+	return bit == 1, err
+}
+
+// BitCount 统计值为 1 的位数
+func (r *redisBitmap) BitCount(ctx context.Context) (int64, error) {
+	return r.client.BitCount(ctx, r.key, nil).Result()
+}
+
+// BitPos 返回第一个值为 value 的位，查找 1 且没有时返回 -1；查找 0 时位图之后的位视为 0
+func (r *redisBitmap) BitPos(ctx context.Context, value bool) (int64, error) {
+	return r.client.BitPos(ctx, r.key, int64(bitValue(value))).Result()
+}
+
+// BeginTx 拉取一次位图快照，返回事务句柄
+func (r *redisBitmap) BeginTx(ctx context.Context) (BitmapTransaction, error) {
+	value, err := r.client.Get(ctx, r.key).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	sum := sha1.Sum(value)
+	return &inMemoryBitmapTx{
+		base:    r,
+		digest:  hex.EncodeToString(sum[:]),
+		current: value,
+	}, nil
+}
+
+func bitValue(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+type inMemoryBitmapTx struct {
+	base *redisBitmap
+	// digest 快照的摘要，提交时用于检测冲突
+	digest string
+	// current 快照应用本事务修改后的位图
+	current []byte
+	// args 提交时传给 Lua 脚本的 offset/value 序列
+	args []interface{}
+	done bool
+	mu   sync.Mutex
+}
+
+func (tx *inMemoryBitmapTx) SetBit(offset int64, value bool) error {
+	if offset < 0 {
+		return errors.New("bit offset must be non-negative")
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	// 与 Redis 一致，offset 0 为第一个字节的最高位
+	index := offset / 8
+	if int64(len(tx.current)) <= index {
+		grown := make([]byte, index+1)
+		copy(grown, tx.current)
+		tx.current = grown
+	}
+	mask := byte(1) << (7 - uint(offset%8))
+	if value {
+		tx.current[index] |= mask
+	} else {
+		tx.current[index] &^= mask
+	}
+	tx.args = append(tx.args, offset, bitValue(value))
+	return nil
+}
+
+func (tx *inMemoryBitmapTx) GetBit(offset int64) (bool, error) {
+	if offset < 0 {
+		return false, errors.New("bit offset must be non-negative")
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	index := offset / 8
+	if int64(len(tx.current)) <= index {
+		return false, nil
+	}
+	return tx.current[index]&(byte(1)<<(7-uint(offset%8))) != 0, nil
+}
+
+// Commit 通过 Lua 脚本原子地比对快照并提交所有位操作。
+// 如果在事务开始后，位图被其他客户端修改，此方法将返回 ErrTransactionConflict。
+func (tx *inMemoryBitmapTx) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return errors.New("transaction already finished")
+	}
+
+	if len(tx.args) == 0 {
+		return nil // 如果没有写操作，则无需提交
+	}
+
+	args := append([]interface{}{tx.digest}, tx.args...)
+	ok, err := bitmapCommitScript.Run(ctx, tx.base.client, []string{tx.base.key}, args...).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrTransactionConflict
+	}
+
+	tx.done = true
+	return nil
+}
+
+// Rollback 丢弃所有未提交的操作
+func (tx *inMemoryBitmapTx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+}
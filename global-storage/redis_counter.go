@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// counterClampScript 原子地累加后将结果限制在 [ARGV[2], ARGV[3]] 内，返回限制后的值
+var counterClampScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+local min, max = tonumber(ARGV[2]), tonumber(ARGV[3])
+if value > max then
+	value = max
+elseif value < min then
+	value = min
+else
+	return value
+end
+redis.call('SET', KEYS[1], value)
+return value`)
+
+// CounterBounds 计数器的取值范围，累加结果超出时取边界值
+type CounterBounds struct {
+	Min int64
+	Max int64
+}
+
+// Validate 校验取值范围，Min 大于 Max 时 Lua 会把所有结果都限制为边界值；nil 表示不限制，视为有效
+func (bounds *CounterBounds) Validate() error {
+	if bounds != nil && bounds.Min > bounds.Max {
+		return fmt.Errorf("counter bounds min %d greater than max %d", bounds.Min, bounds.Max)
+	}
+	return nil
+}
+
+// redisCounter 实现了 CounterTransactional，绑定一个固定 string key。
+type redisCounter struct {
+	client *redis.Client
+	key    string
+	bounds *CounterBounds
+}
+
+// NewRedisCounter 构造 CounterTransactional，bounds 为 nil 时不限制取值范围，需满足 Min 不大于 Max，见 CounterBounds.Validate。
+// 限制范围时在 Lua 中比较，绝对值超过 2^53 的边界会损失精度
+func NewRedisCounter(client *redis.Client, key string, bounds *CounterBounds) CounterTransactional {
+	return &redisCounter{
+		client: client,
+		key:    key,
+		bounds: bounds,
+	}
+}
+
+func (r *redisCounter) Incr(ctx context.Context) (int64, error) {
+	return r.IncrBy(ctx, 1)
+}
+
+// IncrBy 原子地累加 delta，返回累加后的值
+func (r *redisCounter) IncrBy(ctx context.Context, delta int64) (int64, error) {
+	if r.bounds == nil {
+		return r.client.IncrBy(ctx, r.key, delta).Result()
+	}
+	return counterClampScript.Run(ctx, r.client, []string{r.key}, delta, r.bounds.Min, r.bounds.Max).Int64()
+}
+
+// DecrBy 原子地减去 delta，返回减去后的值；delta 为 math.MinInt64 时无法取反，返回错误
+func (r *redisCounter) DecrBy(ctx context.Context, delta int64) (int64, error) {
+	if delta == math.MinInt64 {
+		return 0, errors.New("counter decrement out of range")
+	}
+	return r.IncrBy(ctx, -delta)
+}
+
+// Get 返回当前值，key 不存在时返回 0
+func (r *redisCounter) Get(ctx context.Context) (int64, error) {
+	value, err := r.client.Get(ctx, r.key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return value, err
+}
+
+// Reset 删除计数器，之后的 Get 返回 0
+func (r *redisCounter) Reset(ctx context.Context) error {
+	return r.client.Del(ctx, r.key).Err()
+}
+
+func (r *redisCounter) Expire(ctx context.Context, ttl time.Duration) error {
+	return r.client.PExpire(ctx, r.key, ttl).Err()
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// geoUnit 所有距离参数与结果的单位
+const geoUnit = "m"
+
+// redisGeo 实现了 GeoTransactional，绑定一个固定 geo key（底层为 sorted-set）。
+type redisGeo struct {
+	client  *redis.Client
+	key     string
+	factory StorageDataFactory
+}
+
+// NewRedisGeo 构造 GeoTransactional，传入 factory 用于反序列化时创建实例。
+func NewRedisGeo(client *redis.Client, key string, factory StorageDataFactory) GeoTransactional {
+	return &redisGeo{
+		client:  client,
+		key:     key,
+		factory: factory,
+	}
+}
+
+// GeoAdd 添加成员或更新其坐标
+func (r *redisGeo) GeoAdd(ctx context.Context, longitude, latitude float64, member StorageData) error {
+	b, err := member.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return r.client.GeoAdd(ctx, r.key, &redis.GeoLocation{
+		Name:      string(b),
+		Longitude: longitude,
+		Latitude:  latitude,
+	}).Err()
+}
+
+func (r *redisGeo) GeoRem(ctx context.Context, members ...StorageData) error {
+	values, err := marshalAll(members)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	return r.client.ZRem(ctx, r.key, values...).Err()
+}
+
+// GeoPos 返回成员的坐标，成员不存在时返回 ErrFieldNotFound
+func (r *redisGeo) GeoPos(ctx context.Context, member StorageData) (float64, float64, error) {
+	b, err := member.MarshalBinary()
+	if err != nil {
+		return 0, 0, err
+	}
+	positions, err := r.client.GeoPos(ctx, r.key, string(b)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(positions) == 0 || positions[0] == nil {
+		return 0, 0, ErrFieldNotFound
+	}
+	return positions[0].Longitude, positions[0].Latitude, nil
+}
+
+// GeoDist 返回两个成员之间的距离（米），任一成员不存在时返回 ErrFieldNotFound
+func (r *redisGeo) GeoDist(ctx context.Context, a, b StorageData) (float64, error) {
+	ab, err := a.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	bb, err := b.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	dist, err := r.client.GeoDist(ctx, r.key, string(ab), string(bb), geoUnit).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrFieldNotFound
+	}
+	return dist, err
+}
+
+// GeoSearchByRadius 查找以坐标为中心、radius 米内的成员，由近及远排序，count <= 0 时不限制数量
+func (r *redisGeo) GeoSearchByRadius(ctx context.Context, longitude, latitude, radius float64, count int) ([]GeoMember, error) {
+	return r.search(ctx, redis.GeoSearchQuery{
+		Longitude:  longitude,
+		Latitude:   latitude,
+		Radius:     radius,
+		RadiusUnit: geoUnit,
+		Sort:       "ASC",
+		Count:      count,
+	})
+}
+
+// GeoSearchByMember 查找以成员为中心、radius 米内的成员（包含该成员自身），由近及远排序，
+// count <= 0 时不限制数量；中心成员不存在时返回 ErrFieldNotFound
+func (r *redisGeo) GeoSearchByMember(ctx context.Context, member StorageData, radius float64, count int) ([]GeoMember, error) {
+	b, err := member.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return r.search(ctx, redis.GeoSearchQuery{
+		Member:     string(b),
+		Radius:     radius,
+		RadiusUnit: geoUnit,
+		Sort:       "ASC",
+		Count:      count,
+	})
+}
+
+func (r *redisGeo) search(ctx context.Context, query redis.GeoSearchQuery) ([]GeoMember, error) {
+	locations, err := r.client.GeoSearchLocation(ctx, r.key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: query,
+		WithCoord:      true,
+		WithDist:       true,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return []GeoMember{}, nil
+		}
+		// 中心成员不存在时 Redis 返回 ERR could not decode requested zset member
+		if query.Member != "" && errors.Is(r.client.ZScore(ctx, r.key, query.Member).Err(), redis.Nil) {
+			return nil, ErrFieldNotFound
+		}
+		return nil, err
+	}
+	out := make([]GeoMember, 0, len(locations))
+	for _, location := range locations {
+		elem := r.factory()
+		if err := elem.UnmarshalBinary([]byte(location.Name)); err != nil {
+			return nil, err
+		}
+		out = append(out, GeoMember{
+			Data:      elem,
+			Longitude: location.Longitude,
+			Latitude:  location.Latitude,
+			Distance:  location.Dist,
+		})
+	}
+	return out, nil
+}
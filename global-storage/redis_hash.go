@@ -8,6 +8,19 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// hashUpdateScript 字段与读取时一致才写入：ARGV[2] 为 1 时要求字段值等于 ARGV[3]，为 0 时要求字段不存在
+var hashUpdateScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if ARGV[2] == '1' then
+	if current ~= ARGV[3] then
+		return 0
+	end
+elseif current then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[4])
+return 1`)
+
 // redisHash 实现 HashTransactional
 type redisHash struct {
 	client      *redis.Client
@@ -71,6 +84,44 @@ func (r *redisHash) HDel(ctx context.Context, fields ...string) error {
 	return err
 }
 
+// HUpdate 读取字段后交给 fn，再以 Lua 脚本比较并写入：字段在读取后被修改（或被创建）时不写入并返回 ErrTransactionConflict，
+// 只比较该字段，其他字段的写入不影响；fn 返回 nil 时不写入
+func (r *redisHash) HUpdate(ctx context.Context, field string, fn func(current StorageData) (StorageData, error)) error {
+	b, err := r.client.HGet(ctx, r.key, field).Bytes()
+	exists := err == nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	var current StorageData
+	if exists {
+		current = r.dataFactory()
+		if err = current.UnmarshalBinary(b); err != nil {
+			return err
+		}
+	}
+	next, err := fn(current)
+	if err != nil || next == nil {
+		return err
+	}
+	value, err := next.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	flag := "0"
+	if exists {
+		flag = "1"
+	}
+	ok, err := hashUpdateScript.Run(ctx, r.client, []string{r.key}, field, flag, b, value).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrTransactionConflict
+	}
+	return nil
+}
+
+// BeginTx 读取全量快照，返回事务句柄；Commit 时 WATCH key 并在 MULTI/EXEC 中写入，key 已被修改时返回 ErrTransactionConflict
 func (r *redisHash) BeginTx(ctx context.Context) (HashTransaction, error) {
 	all, err := r.client.HGetAll(ctx, r.key).Result()
 	if err != nil && !errors.Is(err, redis.Nil) {
@@ -135,7 +186,7 @@ func (tx *inMemoryHashTx) HGet(field string, dest StorageData) error {
 		}
 	}
 	if !found {
-		return errors.New("field not found")
+		return ErrFieldNotFound
 	}
 	return dest.UnmarshalBinary(data)
 }
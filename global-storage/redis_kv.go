@@ -8,6 +8,19 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// kvUpdateScript 值与读取时一致才写入：ARGV[1] 为 1 时要求值等于 ARGV[2]，为 0 时要求 key 不存在
+var kvUpdateScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if ARGV[1] == '1' then
+	if current ~= ARGV[2] then
+		return 0
+	end
+elseif current then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[3])
+return 1`)
+
 // redisKV 实现了 KVTransactional，绑定一个固定 key。
 type redisKV struct {
 	client *redis.Client
@@ -41,6 +54,40 @@ func (r *redisKV) Get(ctx context.Context, dest StorageData) error {
 	return dest.UnmarshalBinary(b)
 }
 
+// Update 读取当前值后交给 fn，再以 Lua 脚本比较并写入，值在读取后被修改时返回 ErrTransactionConflict
+func (r *redisKV) Update(ctx context.Context, dest StorageData, fn func(found bool) (StorageData, error)) error {
+	b, err := r.client.Get(ctx, r.key).Bytes()
+	found := err == nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if found {
+		if err = dest.UnmarshalBinary(b); err != nil {
+			return err
+		}
+	}
+	next, err := fn(found)
+	if err != nil || next == nil {
+		return err
+	}
+	value, err := next.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	flag := "0"
+	if found {
+		flag = "1"
+	}
+	ok, err := kvUpdateScript.Run(ctx, r.client, []string{r.key}, flag, b, value).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrTransactionConflict
+	}
+	return nil
+}
+
 func (r *redisKV) BeginTx(ctx context.Context) (KVTransaction, error) {
 	b, err := r.client.Get(ctx, r.key).Bytes()
 	if err != nil && !errors.Is(err, redis.Nil) {
@@ -83,7 +130,7 @@ func (tx *inMemoryKVTx) Get(dest StorageData) error {
 	}
 	tx.mu.RUnlock()
 	if data == nil {
-		return errors.New("key not found")
+		return ErrFieldNotFound
 	}
 	return dest.UnmarshalBinary(data)
 }
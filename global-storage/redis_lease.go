@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	// renewScript 仅当 key 仍由 owner 持有时延长过期时间
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	// releaseScript 仅当 key 仍由 owner 持有时删除
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// redisLease 实现了 Lease，绑定一个固定 key，值为持有者标识。
+type redisLease struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisLease 根据传入的 Redis 客户端和 key 返回租约实例。
+func NewRedisLease(client *redis.Client, key string) Lease {
+	return &redisLease{
+		client: client,
+		key:    key,
+	}
+}
+
+func (r *redisLease) Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.key, owner, ttl).Result()
+}
+
+func (r *redisLease) Renew(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	n, err := renewScript.Run(ctx, r.client, []string{r.key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *redisLease) Release(ctx context.Context, owner string) error {
+	return releaseScript.Run(ctx, r.client, []string{r.key}, owner).Err()
+}
+
+func (r *redisLease) Owner(ctx context.Context) (string, error) {
+	owner, err := r.client.Get(ctx, r.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrFieldNotFound
+	}
+	return owner, err
+}
@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// listCommitScript 比对当前列表与快照的摘要，一致时按序执行操作，不一致时返回 0
+var listCommitScript = redis.NewScript(`
+local items = redis.call('LRANGE', KEYS[1], 0, -1)` + luaDigest + `
+if digest ~= ARGV[1] then
+	return 0
+end
+local i = 2
+while i <= #ARGV do
+	local op = ARGV[i]
+	if op == 'lpush' then
+		redis.call('LPUSH', KEYS[1], ARGV[i + 1])
+		i = i + 2
+	elseif op == 'rpush' then
+		redis.call('RPUSH', KEYS[1], ARGV[i + 1])
+		i = i + 2
+	elseif op == 'lpop' then
+		redis.call('LPOP', KEYS[1])
+		i = i + 1
+	elseif op == 'ltrim' then
+		redis.call('LTRIM', KEYS[1], ARGV[i + 1], ARGV[i + 2])
+		i = i + 3
+	else
+		return redis.error_reply('unknown list op ' .. op)
+	end
+end
+return 1`)
+
+// redisList 实现了 ListTransactional，绑定一个固定 list key。
+type redisList struct {
+	client  *redis.Client
+	key     string
+	factory StorageDataFactory
+}
+
+// NewRedisList 构造 ListTransactional，传入 factory 用于反序列化时创建实例。
+func NewRedisList(client *redis.Client, key string, factory StorageDataFactory) ListTransactional {
+	return &redisList{
+		client:  client,
+		key:     key,
+		factory: factory,
+	}
+}
+
+func (r *redisList) LPush(ctx context.Context, values ...StorageData) error {
+	members, err := marshalAll(values)
+	if err != nil || len(members) == 0 {
+		return err
+	}
+	return r.client.LPush(ctx, r.key, members...).Err()
+}
+
+func (r *redisList) RPush(ctx context.Context, values ...StorageData) error {
+	members, err := marshalAll(values)
+	if err != nil || len(members) == 0 {
+		return err
+	}
+	return r.client.RPush(ctx, r.key, members...).Err()
+}
+
+func (r *redisList) LRange(ctx context.Context, start, stop int64) ([]StorageData, error) {
+	items, err := r.client.LRange(ctx, r.key, start, stop).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	out := make([]StorageData, 0, len(items))
+	for _, item := range items {
+		elem := r.factory()
+		if err := elem.UnmarshalBinary([]byte(item)); err != nil {
+			return nil, err
+		}
+		out = append(out, elem)
+	}
+	return out, nil
+}
+
+// LPop 弹出并返回第一个元素，列表为空时返回 ErrFieldNotFound
+func (r *redisList) LPop(ctx context.Context) (StorageData, error) {
+	b, err := r.client.LPop(ctx, r.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrFieldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	elem := r.factory()
+	if err := elem.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return elem, nil
+}
+
+func (r *redisList) LTrim(ctx context.Context, start, stop int64) error {
+	return r.client.LTrim(ctx, r.key, start, stop).Err()
+}
+
+// BeginTx 拉取一次全量列表快照，返回事务句柄
+func (r *redisList) BeginTx(ctx context.Context) (ListTransaction, error) {
+	items, err := r.client.LRange(ctx, r.key, 0, -1).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	snap := make([][]byte, 0, len(items))
+	for _, item := range items {
+		snap = append(snap, []byte(item))
+	}
+	return &inMemoryListTx{
+		base:    r,
+		digest:  snapshotDigest(snap),
+		current: snap,
+	}, nil
+}
+
+// marshalAll 序列化全部元素
+func marshalAll(values []StorageData) ([]interface{}, error) {
+	members := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		b, err := value.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, b)
+	}
+	return members, nil
+}
+
+// listRange 按 Redis 的规则将 [start, stop] 转换为切片下标，负数表示从末尾倒数，范围为空时 ok 为 false
+func listRange(length, start, stop int64) (from, to int64, ok bool) {
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	stop = min(stop, length-1)
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop + 1, true
+}
+
+type inMemoryListTx struct {
+	base *redisList
+	// digest 快照的摘要，提交时用于检测冲突
+	digest string
+	// current 快照依次应用操作后的列表
+	current [][]byte
+	// args 提交时传给 Lua 脚本的操作序列
+	args []interface{}
+	done bool
+	mu   sync.Mutex
+}
+
+func (tx *inMemoryListTx) LPush(values ...StorageData) error {
+	members, err := marshalAll(values)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for _, member := range members {
+		b := member.([]byte)
+		tx.current = append([][]byte{b}, tx.current...)
+		tx.args = append(tx.args, "lpush", b)
+	}
+	return nil
+}
+
+func (tx *inMemoryListTx) RPush(values ...StorageData) error {
+	members, err := marshalAll(values)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for _, member := range members {
+		b := member.([]byte)
+		tx.current = append(tx.current, b)
+		tx.args = append(tx.args, "rpush", b)
+	}
+	return nil
+}
+
+func (tx *inMemoryListTx) LRange(start, stop int64) ([]StorageData, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	from, to, ok := listRange(int64(len(tx.current)), start, stop)
+	if !ok {
+		return []StorageData{}, nil
+	}
+	out := make([]StorageData, 0, to-from)
+	for _, b := range tx.current[from:to] {
+		elem := tx.base.factory()
+		if err := elem.UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		out = append(out, elem)
+	}
+	return out, nil
+}
+
+func (tx *inMemoryListTx) LPop() (StorageData, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if len(tx.current) == 0 {
+		return nil, ErrFieldNotFound
+	}
+	elem := tx.base.factory()
+	if err := elem.UnmarshalBinary(tx.current[0]); err != nil {
+		return nil, err
+	}
+	tx.current = tx.current[1:]
+	tx.args = append(tx.args, "lpop")
+	return elem, nil
+}
+
+func (tx *inMemoryListTx) LTrim(start, stop int64) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	from, to, ok := listRange(int64(len(tx.current)), start, stop)
+	if !ok {
+		tx.current = nil
+	} else {
+		tx.current = tx.current[from:to]
+	}
+	tx.args = append(tx.args, "ltrim", start, stop)
+	return nil
+}
+
+// Commit 通过 Lua 脚本原子地比对快照并提交所有操作。
+// 如果在事务开始后，列表被其他客户端修改，此方法将返回 ErrTransactionConflict。
+func (tx *inMemoryListTx) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return errors.New("transaction already finished")
+	}
+
+	if len(tx.args) == 0 {
+		return nil // 如果没有写操作，则无需提交
+	}
+
+	args := append([]interface{}{tx.digest}, tx.args...)
+	ok, err := listCommitScript.Run(ctx, tx.base.client, []string{tx.base.key}, args...).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrTransactionConflict
+	}
+
+	tx.done = true
+	return nil
+}
+
+// Rollback 丢弃所有未提交的操作
+func (tx *inMemoryListTx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// setCommitScript 比对当前集合与快照的摘要，一致时按序执行操作，不一致时返回 0；
+// 摘要与成员顺序无关，见 setDigest
+var setCommitScript = redis.NewScript(`
+local hashes = {}
+for i, member in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	hashes[i] = redis.sha1hex(member)
+end
+table.sort(hashes)
+if redis.sha1hex(table.concat(hashes)) ~= ARGV[1] then
+	return 0
+end
+for i = 2, #ARGV, 2 do
+	local op = ARGV[i]
+	if op == 'sadd' then
+		redis.call('SADD', KEYS[1], ARGV[i + 1])
+	elseif op == 'srem' then
+		redis.call('SREM', KEYS[1], ARGV[i + 1])
+	else
+		return redis.error_reply('unknown set op ' .. op)
+	end
+end
+return 1`)
+
+// redisSet 实现了 SetTransactional，绑定一个固定 set key。
+type redisSet struct {
+	client  *redis.Client
+	key     string
+	factory StorageDataFactory
+}
+
+// NewRedisSet 构造 SetTransactional，传入 factory 用于反序列化时创建实例。
+func NewRedisSet(client *redis.Client, key string, factory StorageDataFactory) SetTransactional {
+	return &redisSet{
+		client:  client,
+		key:     key,
+		factory: factory,
+	}
+}
+
+func (r *redisSet) SAdd(ctx context.Context, members ...StorageData) error {
+	values, err := marshalAll(members)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	return r.client.SAdd(ctx, r.key, values...).Err()
+}
+
+func (r *redisSet) SRem(ctx context.Context, members ...StorageData) error {
+	values, err := marshalAll(members)
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	return r.client.SRem(ctx, r.key, values...).Err()
+}
+
+// SMembers 返回全部成员，顺序不固定
+func (r *redisSet) SMembers(ctx context.Context) ([]StorageData, error) {
+	items, err := r.client.SMembers(ctx, r.key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	out := make([]StorageData, 0, len(items))
+	for _, item := range items {
+		elem := r.factory()
+		if err := elem.UnmarshalBinary([]byte(item)); err != nil {
+			return nil, err
+		}
+		out = append(out, elem)
+	}
+	return out, nil
+}
+
+func (r *redisSet) SIsMember(ctx context.Context, member StorageData) (bool, error) {
+	b, err := member.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	return r.client.SIsMember(ctx, r.key, b).Result()
+}
+
+// BeginTx 拉取一次全量集合快照，返回事务句柄
+func (r *redisSet) BeginTx(ctx context.Context) (SetTransaction, error) {
+	items, err := r.client.SMembers(ctx, r.key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	current := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		current[item] = struct{}{}
+	}
+	return &inMemorySetTx{
+		base:    r,
+		digest:  setDigest(items),
+		current: current,
+	}, nil
+}
+
+// setDigest 计算集合快照的摘要：各成员 SHA1 的16进制排序后拼接，再取 SHA1。
+// 只对16进制字符串排序，不受 Redis 中 Lua 字符串比较的 locale 影响
+func setDigest(members []string) string {
+	hashes := make([]string, 0, len(members))
+	for _, member := range members {
+		sum := sha1.Sum([]byte(member))
+		hashes = append(hashes, hex.EncodeToString(sum[:]))
+	}
+	sort.Strings(hashes)
+	sum := sha1.Sum([]byte(strings.Join(hashes, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+type inMemorySetTx struct {
+	base *redisSet
+	// digest 快照的摘要，提交时用于检测冲突
+	digest string
+	// current 快照依次应用操作后的成员
+	current map[string]struct{}
+	// args 提交时传给 Lua 脚本的操作序列
+	args []interface{}
+	done bool
+	mu   sync.Mutex
+}
+
+func (tx *inMemorySetTx) SAdd(members ...StorageData) error {
+	values, err := marshalAll(members)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for _, value := range values {
+		b := value.([]byte)
+		tx.current[string(b)] = struct{}{}
+		tx.args = append(tx.args, "sadd", b)
+	}
+	return nil
+}
+
+func (tx *inMemorySetTx) SRem(members ...StorageData) error {
+	values, err := marshalAll(members)
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	for _, value := range values {
+		b := value.([]byte)
+		delete(tx.current, string(b))
+		tx.args = append(tx.args, "srem", b)
+	}
+	return nil
+}
+
+func (tx *inMemorySetTx) SMembers() ([]StorageData, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	out := make([]StorageData, 0, len(tx.current))
+	for item := range tx.current {
+		elem := tx.base.factory()
+		if err := elem.UnmarshalBinary([]byte(item)); err != nil {
+			return nil, err
+		}
+		out = append(out, elem)
+	}
+	return out, nil
+}
+
+func (tx *inMemorySetTx) SIsMember(member StorageData) (bool, error) {
+	b, err := member.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	_, ok := tx.current[string(b)]
+	return ok, nil
+}
+
+// Commit 通过 Lua 脚本原子地比对快照并提交所有操作。
+// 如果在事务开始后，集合被其他客户端修改，此方法将返回 ErrTransactionConflict。
+func (tx *inMemorySetTx) Commit(ctx context.Context) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return errors.New("transaction already finished")
+	}
+
+	if len(tx.args) == 0 {
+		return nil // 如果没有写操作，则无需提交
+	}
+
+	args := append([]interface{}{tx.digest}, tx.args...)
+	ok, err := setCommitScript.Run(ctx, tx.base.client, []string{tx.base.key}, args...).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrTransactionConflict
+	}
+
+	tx.done = true
+	return nil
+}
+
+// Rollback 丢弃所有未提交的操作
+func (tx *inMemorySetTx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// streamDataField 消息中保存序列化数据的字段名
+const streamDataField = "data"
+
+// redisStream 实现了 StreamTransactional，绑定一个固定 stream key。
+type redisStream struct {
+	client  *redis.Client
+	key     string
+	factory StorageDataFactory
+}
+
+// NewRedisStream 构造 StreamTransactional，传入 factory 用于反序列化时创建实例。
+func NewRedisStream(client *redis.Client, key string, factory StorageDataFactory) StreamTransactional {
+	return &redisStream{
+		client:  client,
+		key:     key,
+		factory: factory,
+	}
+}
+
+func (r *redisStream) XAdd(ctx context.Context, value StorageData) (string, error) {
+	b, err := value.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return r.client.XAdd(ctx, &redis.XAddArgs{Stream: r.key, Values: []interface{}{streamDataField, b}}).Result()
+}
+
+// XRead 读取 ID 大于 lastID 的消息，lastID 为 "0" 时从头读取，为 "$" 时只读取之后的新消息；
+// block <= 0 时不阻塞，没有消息时返回空切片
+func (r *redisStream) XRead(ctx context.Context, lastID string, count int64, block time.Duration) ([]StreamMessage, error) {
+	streams, err := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{r.key, lastID},
+		Count:   count,
+		Block:   blockArg(block),
+	}).Result()
+	return r.decodeStreams(streams, err)
+}
+
+// XGroupCreate 创建消费组，stream 不存在时自动创建，消费组已存在时忽略
+func (r *redisStream) XGroupCreate(ctx context.Context, group, start string) error {
+	err := r.client.XGroupCreateMkStream(ctx, r.key, group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup 以消费组读取消息，start 为 ">" 时读取未投递过的新消息，为 "0" 时读取本消费者已投递未确认的消息
+func (r *redisStream) XReadGroup(ctx context.Context, group, consumer, start string, count int64, block time.Duration) ([]StreamMessage, error) {
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{r.key, start},
+		Count:    count,
+		Block:    blockArg(block),
+	}).Result()
+	return r.decodeStreams(streams, err)
+}
+
+func (r *redisStream) XAck(ctx context.Context, group string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.client.XAck(ctx, r.key, group, ids...).Err()
+}
+
+// XAutoClaim 将消费组中未确认超过 minIdle 的消息转移给 consumer，从 start 开始扫描，
+// 返回转移的消息与下次扫描的起点，起点为 "0-0" 时表示已扫描完；
+// 直接发送命令并自行解析回复，兼容 Redis 6.2 的两段回复与 Redis 7 附带已删除消息ID的三段回复
+func (r *redisStream) XAutoClaim(ctx context.Context, group, consumer string, minIdle time.Duration, start string, count int64) ([]StreamMessage, string, error) {
+	args := []interface{}{"xautoclaim", r.key, group, consumer, minIdle.Milliseconds(), start}
+	if count > 0 {
+		args = append(args, "count", count)
+	}
+	reply, err := r.client.Do(ctx, args...).Result()
+	if errors.Is(err, redis.Nil) {
+		return []StreamMessage{}, "0-0", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	messages, next, err := parseXAutoClaimReply(reply)
+	if err != nil {
+		return nil, "", err
+	}
+	out, err := r.decode(messages)
+	return out, next, err
+}
+
+// parseXAutoClaimReply 解析 XAUTOCLAIM 的回复：[下次起点, 消息列表(, 已删除的消息ID)]，
+// 已删除的消息已由 Redis 移出待确认列表，不再返回；Redis 6.2 中已删除的消息以 nil 表示，同样跳过
+func parseXAutoClaimReply(reply interface{}) ([]redis.XMessage, string, error) {
+	parts, ok := reply.([]interface{})
+	if !ok || len(parts) < 2 {
+		return nil, "", fmt.Errorf("unexpected XAUTOCLAIM reply %T", reply)
+	}
+	next, ok := parts[0].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected XAUTOCLAIM cursor %T", parts[0])
+	}
+	entries, ok := parts[1].([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected XAUTOCLAIM entries %T", parts[1])
+	}
+	messages := make([]redis.XMessage, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		message, err := parseXMessage(entry)
+		if err != nil {
+			return nil, "", err
+		}
+		messages = append(messages, message)
+	}
+	return messages, next, nil
+}
+
+// parseXMessage 解析 [消息ID, [字段, 值, ...]] 形式的单条消息，字段列表为 nil 时消息没有数据
+func parseXMessage(entry interface{}) (redis.XMessage, error) {
+	pair, ok := entry.([]interface{})
+	if !ok || len(pair) != 2 {
+		return redis.XMessage{}, fmt.Errorf("unexpected stream entry %T", entry)
+	}
+	id, ok := pair[0].(string)
+	if !ok {
+		return redis.XMessage{}, fmt.Errorf("unexpected stream entry id %T", pair[0])
+	}
+	values := make(map[string]interface{})
+	if pair[1] != nil {
+		fields, ok := pair[1].([]interface{})
+		if !ok || len(fields)%2 != 0 {
+			return redis.XMessage{}, fmt.Errorf("unexpected stream entry fields %T", pair[1])
+		}
+		for i := 0; i < len(fields); i += 2 {
+			field, ok := fields[i].(string)
+			if !ok {
+				return redis.XMessage{}, fmt.Errorf("unexpected stream field %T", fields[i])
+			}
+			values[field] = fields[i+1]
+		}
+	}
+	return redis.XMessage{ID: id, Values: values}, nil
+}
+
+// BeginTx 返回批量追加的事务句柄，流只追加，不做快照与冲突检测
+func (r *redisStream) BeginTx(ctx context.Context) (StreamTransaction, error) {
+	return &inMemoryStreamTx{base: r}, nil
+}
+
+// blockArg 转换为 go-redis 的阻塞参数，负数表示不阻塞
+func blockArg(block time.Duration) time.Duration {
+	if block <= 0 {
+		return -1
+	}
+	return block
+}
+
+func (r *redisStream) decodeStreams(streams []redis.XStream, err error) ([]StreamMessage, error) {
+	if errors.Is(err, redis.Nil) {
+		return []StreamMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []StreamMessage
+	for _, stream := range streams {
+		messages, err := r.decode(stream.Messages)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, messages...)
+	}
+	return out, nil
+}
+
+func (r *redisStream) decode(messages []redis.XMessage) ([]StreamMessage, error) {
+	out := make([]StreamMessage, 0, len(messages))
+	for _, message := range messages {
+		// 已被 XDEL 或裁剪的待确认消息没有数据
+		raw, ok := message.Values[streamDataField].(string)
+		if !ok {
+			out = append(out, StreamMessage{ID: message.ID})
+			continue
+		}
+		data := r.factory()
+		if err := data.UnmarshalBinary([]byte(raw)); err != nil {
+			return nil, err
+		}
+		out = append(out, StreamMessage{ID: message.ID, Data: data})
+	}
+	return out, nil
+}
+
+type inMemoryStreamTx struct {
+	base   *redisStream
+	values [][]byte
+	done   bool
+	mu     sync.Mutex
+}
+
+func (tx *inMemoryStreamTx) XAdd(value StorageData) error {
+	b, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.values = append(tx.values, b)
+	return nil
+}
+
+// Commit 使用 MULTI/EXEC 原子地追加全部消息，返回消息ID
+func (tx *inMemoryStreamTx) Commit(ctx context.Context) ([]string, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return nil, errors.New("transaction already finished")
+	}
+
+	if len(tx.values) == 0 {
+		return nil, nil // 如果没有写操作，则无需提交
+	}
+
+	cmds := make([]*redis.StringCmd, 0, len(tx.values))
+	_, err := tx.base.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, value := range tx.values {
+			cmds = append(cmds, pipe.XAdd(ctx, &redis.XAddArgs{Stream: tx.base.key, Values: []interface{}{streamDataField, value}}))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		ids = append(ids, cmd.Val())
+	}
+
+	tx.done = true
+	return ids, nil
+}
+
+// Rollback 丢弃所有未提交的消息
+func (tx *inMemoryStreamTx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenBucketScript 按 Redis 服务器时间补充令牌后预约一个令牌，返回需等待的毫秒数，超过 ARGV[3] 时不预约并返回 -1。
+// ARGV: 每秒补充的令牌数、桶容量、最长等待毫秒数；令牌数为负表示已预约的等待中调用
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local maxWait = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updatedAt = tonumber(state[2])
+if tokens == nil or updatedAt == nil then
+	tokens = burst
+elseif now > updatedAt then
+	tokens = math.min(burst, tokens + (now - updatedAt) / 1000 * rate)
+end
+if updatedAt == nil or now > updatedAt then
+	updatedAt = now
+end
+tokens = tokens - 1
+local wait = 0
+if tokens < 0 then
+	wait = math.ceil(-tokens / rate * 1000)
+end
+if wait > maxWait then
+	return -1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', updatedAt)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + wait + 1000)
+return wait`)
+
+// redisTokenBucket 实现了 TokenBucket，绑定一个固定 key，状态存放在 hash 中。
+type redisTokenBucket struct {
+	client *redis.Client
+	key    string
+}
+
+// NewRedisTokenBucket 根据传入的 Redis 客户端和 key 返回令牌桶实例。
+func NewRedisTokenBucket(client *redis.Client, key string) TokenBucket {
+	return &redisTokenBucket{
+		client: client,
+		key:    key,
+	}
+}
+
+func (r *redisTokenBucket) Take(ctx context.Context, rate, burst float64, maxWait time.Duration) (time.Duration, bool, error) {
+	if rate <= 0 || burst <= 0 {
+		return 0, false, errors.New("token bucket rate and burst must be positive")
+	}
+	wait, err := tokenBucketScript.Run(ctx, r.client, []string{r.key}, rate, burst, maxWait.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, err
+	}
+	if wait < 0 {
+		return 0, false, nil
+	}
+	return time.Duration(wait) * time.Millisecond, true, nil
+}
@@ -3,7 +3,10 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -152,6 +155,37 @@ func TestRedisKV(t *testing.T) {
 	})
 }
 
+func TestRedisKV_Update(t *testing.T) {
+	client := setupRedisClient(t)
+	kvStore := NewRedisKV(client, "test:kv:update")
+	ctx := context.Background()
+
+	increment := func(data *testData) func(found bool) (StorageData, error) {
+		return func(found bool) (StorageData, error) {
+			data.ID++
+			return data, nil
+		}
+	}
+
+	require.NoError(t, kvStore.Update(ctx, &testData{}, increment(&testData{})))
+	data := &testData{}
+	require.NoError(t, kvStore.Update(ctx, data, increment(data)))
+	assert.Equal(t, 2, data.ID)
+
+	// 读取之后值被修改，写入失败
+	data = &testData{}
+	err := kvStore.Update(ctx, data, func(found bool) (StorageData, error) {
+		require.True(t, found)
+		require.NoError(t, kvStore.Set(ctx, &testData{ID: 10}))
+		return increment(data)(found)
+	})
+	assert.Equal(t, ErrTransactionConflict, err)
+
+	final := &testData{}
+	require.NoError(t, kvStore.Get(ctx, final))
+	assert.Equal(t, 10, final.ID)
+}
+
 // --- Hash 测试 ---
 
 func TestRedisHash(t *testing.T) {
@@ -389,3 +423,468 @@ func TestTransaction_Hash_Conflict(t *testing.T) {
 	assert.Equal(t, 2, finalData.(*testData).ID)
 	assert.Equal(t, "From Tx1", finalData.(*testData).Name)
 }
+
+// --- Lease 测试 ---
+
+func TestRedisLease(t *testing.T) {
+	client := setupRedisClient(t)
+	lease := NewRedisLease(client, "test:lease:worker:1")
+	ctx := context.Background()
+
+	ok, err := lease.Acquire(ctx, "node-a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = lease.Acquire(ctx, "node-b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "已被持有的租约不能再获得")
+
+	ok, err = lease.Renew(ctx, "node-b", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok, "非持有者不能续约")
+	ok, err = lease.Renew(ctx, "node-a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, lease.Release(ctx, "node-b"))
+	owner, err := lease.Owner(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", owner, "非持有者不能释放")
+
+	require.NoError(t, lease.Release(ctx, "node-a"))
+	_, err = lease.Owner(ctx)
+	assert.Equal(t, ErrFieldNotFound, err)
+}
+
+// --- List 测试 ---
+
+func TestRedisList(t *testing.T) {
+	client := setupRedisClient(t)
+	listStore := NewRedisList(client, "test:list:events", testDataFactory)
+	ctx := context.Background()
+
+	t.Run("Push, Range, Pop and Trim", func(t *testing.T) {
+		require.NoError(t, listStore.RPush(ctx, &testData{ID: 2}, &testData{ID: 3}))
+		require.NoError(t, listStore.LPush(ctx, &testData{ID: 1}))
+
+		all, err := listStore.LRange(ctx, 0, -1)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, 1, all[0].(*testData).ID)
+		assert.Equal(t, 3, all[2].(*testData).ID)
+
+		first, err := listStore.LPop(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, first.(*testData).ID)
+
+		require.NoError(t, listStore.LTrim(ctx, 0, 0))
+		all, err = listStore.LRange(ctx, 0, -1)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, 2, all[0].(*testData).ID)
+	})
+
+	t.Run("List Transaction Commit and Conflict", func(t *testing.T) {
+		tx1, err := listStore.BeginTx(ctx)
+		require.NoError(t, err)
+		tx2, err := listStore.BeginTx(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, tx1.RPush(&testData{ID: 4}))
+		popped, err := tx1.LPop()
+		require.NoError(t, err)
+		assert.Equal(t, 2, popped.(*testData).ID)
+		inTx, err := tx1.LRange(0, -1)
+		require.NoError(t, err)
+		require.Len(t, inTx, 1)
+		require.NoError(t, tx1.Commit(ctx))
+
+		require.NoError(t, tx2.LPush(&testData{ID: 5}))
+		assert.Equal(t, ErrTransactionConflict, tx2.Commit(ctx))
+
+		all, err := listStore.LRange(ctx, 0, -1)
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, 4, all[0].(*testData).ID)
+	})
+}
+
+// --- Set 测试 ---
+
+func TestRedisSet(t *testing.T) {
+	client := setupRedisClient(t)
+	setStore := NewRedisSet(client, "test:set:online", testDataFactory)
+	ctx := context.Background()
+
+	t.Run("SAdd, SIsMember, SMembers and SRem", func(t *testing.T) {
+		require.NoError(t, setStore.SAdd(ctx, &testData{ID: 1}, &testData{ID: 2}, &testData{ID: 1}))
+		members, err := setStore.SMembers(ctx)
+		require.NoError(t, err)
+		assert.Len(t, members, 2)
+
+		ok, err := setStore.SIsMember(ctx, &testData{ID: 2})
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, setStore.SRem(ctx, &testData{ID: 2}))
+		ok, err = setStore.SIsMember(ctx, &testData{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("Set Transaction Commit and Conflict", func(t *testing.T) {
+		tx1, err := setStore.BeginTx(ctx)
+		require.NoError(t, err)
+		tx2, err := setStore.BeginTx(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, tx1.SAdd(&testData{ID: 3}))
+		require.NoError(t, tx1.SRem(&testData{ID: 1}))
+		ok, err := tx1.SIsMember(&testData{ID: 3})
+		require.NoError(t, err)
+		assert.True(t, ok)
+		require.NoError(t, tx1.Commit(ctx))
+
+		require.NoError(t, tx2.SAdd(&testData{ID: 4}))
+		assert.Equal(t, ErrTransactionConflict, tx2.Commit(ctx))
+
+		members, err := setStore.SMembers(ctx)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, 3, members[0].(*testData).ID)
+	})
+}
+
+func TestRedisStream(t *testing.T) {
+	client := setupRedisClient(t)
+	stream := NewRedisStream(client, "test:stream:events", testDataFactory)
+	ctx := context.Background()
+
+	t.Run("XAdd, XRead and Transaction", func(t *testing.T) {
+		id, err := stream.XAdd(ctx, &testData{ID: 1})
+		require.NoError(t, err)
+
+		tx, err := stream.BeginTx(ctx)
+		require.NoError(t, err)
+		require.NoError(t, tx.XAdd(&testData{ID: 2}))
+		require.NoError(t, tx.XAdd(&testData{ID: 3}))
+		ids, err := tx.Commit(ctx)
+		require.NoError(t, err)
+		assert.Len(t, ids, 2)
+		_, err = tx.Commit(ctx)
+		assert.Error(t, err)
+
+		messages, err := stream.XRead(ctx, id, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, 2, messages[0].Data.(*testData).ID)
+		assert.Equal(t, ids[1], messages[1].ID)
+	})
+
+	t.Run("Worker Acks Handled and Reclaims Failed", func(t *testing.T) {
+		var mu sync.Mutex
+		seen := map[int]int{}
+		config := StreamWorkerConfig{Group: "workers", Consumer: "c1", BlockMillis: 50, ClaimIdleMillis: 100, ClaimIntervalMillis: 100}
+		worker := NewStreamWorker(stream, config, func(ctx context.Context, message StreamMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			id := message.Data.(*testData).ID
+			seen[id]++
+			if id == 2 && seen[id] == 1 {
+				return errors.New("first delivery fails")
+			}
+			return nil
+		})
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			worker.Run(runCtx, nil)
+			close(done)
+		}()
+
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return seen[1] == 1 && seen[2] == 2 && seen[3] == 1
+		}, 3*time.Second, 20*time.Millisecond)
+		cancel()
+		<-done
+
+		pending, err := client.XPending(ctx, "test:stream:events", "workers").Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), pending.Count)
+	})
+
+	t.Run("XAutoClaim Skips Deleted Messages", func(t *testing.T) {
+		require.NoError(t, stream.XGroupCreate(ctx, "claimers", "$"))
+		kept, err := stream.XAdd(ctx, &testData{ID: 4})
+		require.NoError(t, err)
+		deleted, err := stream.XAdd(ctx, &testData{ID: 5})
+		require.NoError(t, err)
+		messages, err := stream.XReadGroup(ctx, "claimers", "c1", ">", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		require.NoError(t, client.XDel(ctx, "test:stream:events", deleted).Err())
+
+		messages, next, err := stream.XAutoClaim(ctx, "claimers", "c2", 0, "0-0", 10)
+		require.NoError(t, err)
+		assert.Equal(t, "0-0", next)
+		ids := make([]string, 0, len(messages))
+		for _, message := range messages {
+			if message.Data != nil {
+				ids = append(ids, message.ID)
+			}
+		}
+		assert.Equal(t, []string{kept}, ids)
+	})
+}
+
+func TestParseXAutoClaimReply(t *testing.T) {
+	// Redis 7 回复附带已删除的消息ID
+	reply := []interface{}{
+		"0-0",
+		[]interface{}{
+			[]interface{}{"1-0", []interface{}{"data", "payload"}},
+		},
+		[]interface{}{"2-0"},
+	}
+	messages, next, err := parseXAutoClaimReply(reply)
+	require.NoError(t, err)
+	assert.Equal(t, "0-0", next)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "1-0", messages[0].ID)
+	assert.Equal(t, "payload", messages[0].Values["data"])
+
+	// Redis 6.2 回复以 nil 表示已删除的消息
+	messages, next, err = parseXAutoClaimReply([]interface{}{"3-0", []interface{}{nil, []interface{}{"4-0", nil}}})
+	require.NoError(t, err)
+	assert.Equal(t, "3-0", next)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "4-0", messages[0].ID)
+	assert.Empty(t, messages[0].Values)
+
+	_, _, err = parseXAutoClaimReply("OK")
+	assert.Error(t, err)
+}
+
+func TestRedisBitmap(t *testing.T) {
+	client := setupRedisClient(t)
+	bitmap := NewRedisBitmap(client, "test:bitmap:checkin")
+	ctx := context.Background()
+
+	t.Run("SetBit, GetBit, BitCount and BitPos", func(t *testing.T) {
+		old, err := bitmap.SetBit(ctx, 3, true)
+		require.NoError(t, err)
+		assert.False(t, old)
+		_, err = bitmap.SetBit(ctx, 10, true)
+		require.NoError(t, err)
+
+		on, err := bitmap.GetBit(ctx, 3)
+		require.NoError(t, err)
+		assert.True(t, on)
+
+		count, err := bitmap.BitCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		pos, err := bitmap.BitPos(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), pos)
+	})
+
+	t.Run("Bitmap Transaction Commit and Conflict", func(t *testing.T) {
+		tx1, err := bitmap.BeginTx(ctx)
+		require.NoError(t, err)
+		tx2, err := bitmap.BeginTx(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, tx1.SetBit(20, true))
+		require.NoError(t, tx1.SetBit(3, false))
+		on, err := tx1.GetBit(10)
+		require.NoError(t, err)
+		assert.True(t, on)
+		on, err = tx1.GetBit(3)
+		require.NoError(t, err)
+		assert.False(t, on)
+		require.NoError(t, tx1.Commit(ctx))
+
+		require.NoError(t, tx2.SetBit(0, true))
+		assert.Equal(t, ErrTransactionConflict, tx2.Commit(ctx))
+
+		count, err := bitmap.BitCount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		on, err = bitmap.GetBit(ctx, 20)
+		require.NoError(t, err)
+		assert.True(t, on)
+	})
+}
+
+func TestRedisGeo(t *testing.T) {
+	client := setupRedisClient(t)
+	geo := NewRedisGeo(client, "test:geo:players", testDataFactory)
+	ctx := context.Background()
+
+	require.NoError(t, geo.GeoAdd(ctx, 116.397, 39.908, &testData{ID: 1})) // 北京天安门
+	require.NoError(t, geo.GeoAdd(ctx, 116.403, 39.915, &testData{ID: 2})) // 故宫，约 1km
+	require.NoError(t, geo.GeoAdd(ctx, 121.474, 31.230, &testData{ID: 3})) // 上海
+
+	t.Run("GeoPos and GeoDist", func(t *testing.T) {
+		lon, lat, err := geo.GeoPos(ctx, &testData{ID: 1})
+		require.NoError(t, err)
+		assert.InDelta(t, 116.397, lon, 0.001)
+		assert.InDelta(t, 39.908, lat, 0.001)
+
+		dist, err := geo.GeoDist(ctx, &testData{ID: 1}, &testData{ID: 2})
+		require.NoError(t, err)
+		assert.InDelta(t, 930, dist, 100)
+
+		_, err = geo.GeoDist(ctx, &testData{ID: 1}, &testData{ID: 9})
+		assert.Equal(t, ErrFieldNotFound, err)
+	})
+
+	t.Run("GeoSearchByRadius and GeoSearchByMember", func(t *testing.T) {
+		members, err := geo.GeoSearchByRadius(ctx, 116.397, 39.908, 5000, 0)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+		assert.Equal(t, 1, members[0].Data.(*testData).ID)
+		assert.Equal(t, 2, members[1].Data.(*testData).ID)
+
+		members, err = geo.GeoSearchByMember(ctx, &testData{ID: 3}, 5000, 10)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		assert.Equal(t, 3, members[0].Data.(*testData).ID)
+
+		_, err = geo.GeoSearchByMember(ctx, &testData{ID: 9}, 5000, 10)
+		assert.Equal(t, ErrFieldNotFound, err)
+	})
+
+	t.Run("GeoRem", func(t *testing.T) {
+		require.NoError(t, geo.GeoRem(ctx, &testData{ID: 2}))
+		_, _, err := geo.GeoPos(ctx, &testData{ID: 2})
+		assert.Equal(t, ErrFieldNotFound, err)
+	})
+}
+
+func TestRedisCounter(t *testing.T) {
+	client := setupRedisClient(t)
+	ctx := context.Background()
+
+	t.Run("Incr, DecrBy, Get and Reset", func(t *testing.T) {
+		counter := NewRedisCounter(client, "test:counter:plain", nil)
+		value, err := counter.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), value)
+
+		value, err = counter.Incr(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), value)
+		value, err = counter.IncrBy(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), value)
+		value, err = counter.DecrBy(ctx, 20)
+		require.NoError(t, err)
+		assert.Equal(t, int64(-9), value)
+
+		require.NoError(t, counter.Reset(ctx))
+		value, err = counter.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), value)
+	})
+
+	t.Run("Clamped Counter", func(t *testing.T) {
+		counter := NewRedisCounter(client, "test:counter:stamina", &CounterBounds{Min: 0, Max: 100})
+		value, err := counter.IncrBy(ctx, 80)
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), value)
+		value, err = counter.IncrBy(ctx, 50)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), value)
+		value, err = counter.DecrBy(ctx, 150)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), value)
+
+		value, err = counter.Get(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), value)
+	})
+}
+
+func TestRedisHash_HUpdate(t *testing.T) {
+	client := setupRedisClient(t)
+	hashStore := NewRedisHash(client, "test:hash:update", testDataFactory)
+	ctx := context.Background()
+
+	increment := func(current StorageData) (StorageData, error) {
+		data := &testData{}
+		if current != nil {
+			data = current.(*testData)
+		}
+		data.ID++
+		return data, nil
+	}
+
+	require.NoError(t, hashStore.HUpdate(ctx, "counter", increment))
+	require.NoError(t, hashStore.HUpdate(ctx, "counter", increment))
+	data, err := hashStore.HGet(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 2, data.(*testData).ID)
+
+	// 读取之后字段被修改，写入失败
+	err = hashStore.HUpdate(ctx, "counter", func(current StorageData) (StorageData, error) {
+		require.NoError(t, hashStore.HSet(ctx, "counter", &testData{ID: 10}))
+		return increment(current)
+	})
+	assert.Equal(t, ErrTransactionConflict, err)
+
+	// 其他字段的写入不影响
+	err = hashStore.HUpdate(ctx, "counter", func(current StorageData) (StorageData, error) {
+		require.NoError(t, hashStore.HSet(ctx, "other", &testData{ID: 1}))
+		return increment(current)
+	})
+	require.NoError(t, err)
+	data, err = hashStore.HGet(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, 11, data.(*testData).ID)
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	client := setupRedisClient(t)
+	bucket := NewRedisTokenBucket(client, "test:bucket:provider")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		wait, ok, err := bucket.Take(ctx, 1, 2, 0)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Zero(t, wait)
+	}
+	// 桶已空，不等待时拒绝，允许等待时预约
+	_, ok, err := bucket.Take(ctx, 1, 2, 0)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	wait, ok, err := bucket.Take(ctx, 1, 2, 2*time.Second)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Second), float64(wait), float64(100*time.Millisecond))
+
+	_, _, err = bucket.Take(ctx, 0, 2, 0)
+	assert.Error(t, err)
+}
+
+func TestRedisCounter_DecrByMinInt64(t *testing.T) {
+	// 参数校验在访问 Redis 之前，无需可用的 Redis
+	counter := NewRedisCounter(redis.NewClient(&redis.Options{}), "test:counter:overflow", nil)
+	_, err := counter.DecrBy(context.Background(), math.MinInt64)
+	assert.Error(t, err)
+}
+
+func TestRegisterCounterStorage_InvalidBounds(t *testing.T) {
+	// 取值范围在创建计数器之前校验，无需可用的 Redis
+	m := &StorageManager{counters: make(map[string]CounterTransactional)}
+	assert.Error(t, m.RegisterCounterStorage("test:counter:invalid", &CounterBounds{Min: 10, Max: 0}))
+	_, err := m.GetCounter("test:counter:invalid")
+	assert.Error(t, err)
+	assert.NoError(t, m.RegisterCounterStorage("test:counter:single", &CounterBounds{Min: 5, Max: 5}))
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultWorkerBatchSize     = 10
+	defaultWorkerBlock         = 2 * time.Second
+	defaultWorkerClaimIdle     = time.Minute
+	defaultWorkerClaimInterval = 30 * time.Second
+	defaultWorkerRetryBackoff  = time.Second
+)
+
+// StreamHandler 处理一条消息，返回 nil 时确认消息，返回错误时消息保留在待确认列表中，
+// 超过 ClaimIdleMillis 后重新投递；同一消息可能被处理多次，处理需幂等
+type StreamHandler func(ctx context.Context, message StreamMessage) error
+
+// StreamWorkerConfig 消费组 worker 配置
+type StreamWorkerConfig struct {
+	// 消费组名，不存在时从 stream 开头创建
+	Group string `json:"group" yaml:"group"`
+	// 消费者名，同一消费组内唯一，通常为节点名
+	Consumer string `json:"consumer" yaml:"consumer"`
+	// 单次读取的最多消息数，<=0 时为 10
+	BatchSize int64 `json:"batch_size" yaml:"batch-size"`
+	// 没有新消息时的阻塞时长（毫秒），<=0 时为 2000
+	BlockMillis int64 `json:"block_millis" yaml:"block-millis"`
+	// 消息未确认超过该时长（毫秒）后被认领重新处理，如消费者崩溃或处理失败，<=0 时为 60000
+	ClaimIdleMillis int64 `json:"claim_idle_millis" yaml:"claim-idle-millis"`
+	// 检查可认领消息的间隔（毫秒），<=0 时为 30000
+	ClaimIntervalMillis int64 `json:"claim_interval_millis" yaml:"claim-interval-millis"`
+}
+
+func (conf StreamWorkerConfig) batchSize() int64 {
+	if conf.BatchSize <= 0 {
+		return defaultWorkerBatchSize
+	}
+	return conf.BatchSize
+}
+
+func (conf StreamWorkerConfig) block() time.Duration {
+	if conf.BlockMillis <= 0 {
+		return defaultWorkerBlock
+	}
+	return time.Duration(conf.BlockMillis) * time.Millisecond
+}
+
+func (conf StreamWorkerConfig) claimIdle() time.Duration {
+	if conf.ClaimIdleMillis <= 0 {
+		return defaultWorkerClaimIdle
+	}
+	return time.Duration(conf.ClaimIdleMillis) * time.Millisecond
+}
+
+func (conf StreamWorkerConfig) claimInterval() time.Duration {
+	if conf.ClaimIntervalMillis <= 0 {
+		return defaultWorkerClaimInterval
+	}
+	return time.Duration(conf.ClaimIntervalMillis) * time.Millisecond
+}
+
+// StreamWorker 以消费组消费 stream，至少投递一次：处理成功后才确认，
+// 启动时先处理本消费者遗留的待确认消息，运行中定期认领其他消费者长时间未确认的消息
+type StreamWorker struct {
+	stream  StreamTransactional
+	config  StreamWorkerConfig
+	handler StreamHandler
+}
+
+// NewStreamWorker 创建消费组 worker
+func NewStreamWorker(stream StreamTransactional, config StreamWorkerConfig, handler StreamHandler) *StreamWorker {
+	return &StreamWorker{stream: stream, config: config, handler: handler}
+}
+
+// Run 消费消息直到 ctx 结束，需在独立 goroutine 中调用；Redis 出错时调用 onError 后稍后重试，onError 可为 nil
+func (worker *StreamWorker) Run(ctx context.Context, onError func(error)) {
+	report := func(err error) {
+		if onError != nil {
+			onError(err)
+		}
+	}
+	for {
+		err := worker.stream.XGroupCreate(ctx, worker.config.Group, "0")
+		if err == nil {
+			break
+		}
+		report(err)
+		if !sleepCtx(ctx, defaultWorkerRetryBackoff) {
+			return
+		}
+	}
+
+	// 先处理本消费者遗留的待确认消息
+	for ctx.Err() == nil {
+		messages, err := worker.stream.XReadGroup(ctx, worker.config.Group, worker.config.Consumer, "0", worker.config.batchSize(), 0)
+		if err != nil {
+			report(err)
+			if !sleepCtx(ctx, defaultWorkerRetryBackoff) {
+				return
+			}
+			continue
+		}
+		if len(messages) == 0 || worker.handle(ctx, messages, report) == 0 {
+			// 没有遗留消息，或遗留消息全部处理失败，留待认领重试
+			break
+		}
+	}
+
+	nextClaim := time.Now().Add(worker.config.claimInterval())
+	for ctx.Err() == nil {
+		if now := time.Now(); !now.Before(nextClaim) {
+			worker.claim(ctx, report)
+			nextClaim = now.Add(worker.config.claimInterval())
+		}
+		messages, err := worker.stream.XReadGroup(ctx, worker.config.Group, worker.config.Consumer, ">", worker.config.batchSize(), worker.config.block())
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			report(err)
+			if !sleepCtx(ctx, defaultWorkerRetryBackoff) {
+				return
+			}
+			continue
+		}
+		worker.handle(ctx, messages, report)
+	}
+}
+
+// claim 认领并处理消费组中未确认超过 ClaimIdleMillis 的消息
+func (worker *StreamWorker) claim(ctx context.Context, report func(error)) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		messages, next, err := worker.stream.XAutoClaim(ctx, worker.config.Group, worker.config.Consumer,
+			worker.config.claimIdle(), start, worker.config.batchSize())
+		if err != nil {
+			report(err)
+			return
+		}
+		worker.handle(ctx, messages, report)
+		if next == "" || next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// handle 依次处理消息并确认成功的消息，已被删除的消息直接确认；返回确认的条数
+func (worker *StreamWorker) handle(ctx context.Context, messages []StreamMessage, report func(error)) int {
+	acked := make([]string, 0, len(messages))
+	for _, message := range messages {
+		if message.Data != nil {
+			if err := worker.handler(ctx, message); err != nil {
+				report(err)
+				continue
+			}
+		}
+		acked = append(acked, message.ID)
+	}
+	if err := worker.stream.XAck(ctx, worker.config.Group, acked...); err != nil {
+		report(err)
+		return 0
+	}
+	return len(acked)
+}
+
+// sleepCtx 等待 d，ctx 先结束时返回 false
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
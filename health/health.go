@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultTimeout = 3 * time.Second
+
+// Checker 检查组件是否可用，返回 nil 表示可用；ctx 在检查超时后结束
+type Checker func(ctx context.Context) error
+
+// ComponentStatus 单个组件的检查结果
+type ComponentStatus struct {
+	Name          string `json:"name"`
+	Healthy       bool   `json:"healthy"`
+	LatencyMillis int64  `json:"latency_millis"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Report 汇总的检查结果，全部组件可用时 Healthy 为 true
+type Report struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentStatus `json:"components"`
+}
+
+type check struct {
+	name    string
+	checker Checker
+}
+
+// Registry 汇总已注册组件的可用性，如 StorageManager.Ping 与实名认证 HealthMonitor.Ready
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []check
+	timeout time.Duration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{timeout: defaultTimeout}
+}
+
+// SetTimeout 设置单个组件的检查超时，默认 3 秒
+func (registry *Registry) SetTimeout(timeout time.Duration) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.timeout = timeout
+}
+
+// Register 注册组件的检查，组件名重复时返回错误
+func (registry *Registry) Register(name string, checker Checker) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, c := range registry.checks {
+		if c.name == name {
+			return fmt.Errorf("health: component %s already registered", name)
+		}
+	}
+	registry.checks = append(registry.checks, check{name: name, checker: checker})
+	return nil
+}
+
+// Check 并发检查全部组件，结果按注册顺序排列
+func (registry *Registry) Check(ctx context.Context) Report {
+	registry.mu.RLock()
+	checks, timeout := registry.checks, registry.timeout
+	registry.mu.RUnlock()
+
+	report := Report{Healthy: true, Components: make([]ComponentStatus, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = run(ctx, c, timeout)
+		}()
+	}
+	wg.Wait()
+	for _, status := range report.Components {
+		report.Healthy = report.Healthy && status.Healthy
+	}
+	return report
+}
+
+// run 执行单个检查，panic 视为不可用
+func run(ctx context.Context, c check, timeout time.Duration) (status ComponentStatus) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	status.Name = c.name
+	defer func() {
+		if r := recover(); r != nil {
+			status.Error = fmt.Sprint("panic: ", r)
+		}
+		status.Healthy = status.Error == ""
+		status.LatencyMillis = time.Since(start).Milliseconds()
+	}()
+	if err := c.checker(ctx); err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// Handler 返回 JSON 格式的 Report，全部组件可用时状态码为 200，否则为 503；
+// 可作为 Kubernetes readinessProbe，gRPC 健康检查服务可直接调用 Check
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Check(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register 向默认 Registry 注册组件的检查
+func Register(name string, checker Checker) error {
+	return defaultRegistry.Register(name, checker)
+}
+
+// Check 检查默认 Registry 中的全部组件
+func Check(ctx context.Context) Report {
+	return defaultRegistry.Check(ctx)
+}
+
+// Handler 默认 Registry 的 HTTP 接口
+func Handler() http.Handler {
+	return defaultRegistry.Handler()
+}
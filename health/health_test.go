@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.SetTimeout(20 * time.Millisecond)
+	_ = registry.Register("storage", func(ctx context.Context) error { return nil })
+	_ = registry.Register("idcard", func(ctx context.Context) error { return errors.New("all providers unavailable") })
+	_ = registry.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	_ = registry.Register("panic", func(ctx context.Context) error { panic("boom") })
+	if err := registry.Register("storage", nil); err == nil {
+		t.Errorf("Register(duplicate) error = nil")
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", recorder.Code)
+	}
+	var report Report
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"storage": true, "idcard": false, "slow": false, "panic": false}
+	if report.Healthy || len(report.Components) != len(want) {
+		t.Fatalf("report = %+v", report)
+	}
+	for i, status := range report.Components {
+		if i == 0 && status.Name != "storage" {
+			t.Errorf("components[0] = %s, want registration order", status.Name)
+		}
+		if status.Healthy != want[status.Name] || (!status.Healthy && status.Error == "") {
+			t.Errorf("%s = %+v", status.Name, status)
+		}
+	}
+}
+
+func TestRegistry_Healthy(t *testing.T) {
+	registry := NewRegistry()
+	_ = registry.Register("storage", func(ctx context.Context) error { return nil })
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", recorder.Code)
+	}
+}
@@ -0,0 +1,174 @@
+package idcard_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+	utils2 "github.com/NumberMan1/numbox/utils"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProviderAlibaba 阿里云市场实名认证服务商名称
+const ProviderAlibaba = "alibaba"
+
+func init() {
+	RegisterProvider(ProviderAlibaba, func(config Config) (IdCardSDK, error) {
+		alibabaConfig := config.AlibabaConfig
+		client, err := alibabaConfig.HTTP.NewHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		if alibabaConfig.Sandbox {
+			alibabaConfig.Url = alibabaConfig.SandboxUrl
+		}
+		sdk := NewAlibabaIdCardSDK(alibabaConfig)
+		sdk.SetHTTPClient(client)
+		if alibabaConfig.Sandbox {
+			return newProviderSandbox(ProviderAlibaba, sdk, alibabaConfig.SandboxUrl != "", config)
+		}
+		return sdk, nil
+	})
+}
+
+type AlibabaConfig struct {
+	AppCode string `json:"app_code" yaml:"app-code"`
+	Url     string `json:"url" yaml:"url"`
+	// HTTP 客户端配置，通过 InitIdCardSDK 创建时生效
+	HTTP HTTPConfig `json:"http" yaml:"http"`
+	// 沙箱模式，通过 InitIdCardSDK 创建时生效：预置的测试身份本地应答，其他身份发送到 SandboxUrl，为空时视为不匹配
+	Sandbox    bool   `json:"sandbox" yaml:"sandbox"`
+	SandboxUrl string `json:"sandbox_url" yaml:"sandbox-url"`
+}
+
+type AlibabaIdCardSDK struct {
+	config AlibabaConfig
+	client *http.Client
+}
+
+func NewAlibabaIdCardSDK(config AlibabaConfig) *AlibabaIdCardSDK {
+	return &AlibabaIdCardSDK{config: config, client: defaultHTTPClient}
+}
+
+// SetHTTPClient 设置访问服务商的 HTTP 客户端，可由 HTTPConfig.NewHTTPClient 创建
+func (sdk *AlibabaIdCardSDK) SetHTTPClient(client *http.Client) {
+	sdk.client = client
+}
+
+// Name 服务商名称
+func (sdk *AlibabaIdCardSDK) Name() string {
+	return ProviderAlibaba
+}
+
+// Valid 通过阿里巴巴SDK验证身份证与名字
+func (sdk *AlibabaIdCardSDK) Valid(ctx context.Context, name, id string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, id)
+	return err == nil, result.Info
+}
+
+// ValidE 通过阿里巴巴SDK验证身份证与名字，信息不一致返回 ErrMismatch，网络错误或服务端 5xx 返回 ErrProviderUnavailable，
+// 额度耗尽返回 ErrQuotaExceeded
+func (sdk *AlibabaIdCardSDK) ValidE(ctx context.Context, name, id string) (result VerifyResult, err error) {
+	defer func() { result.Status = VerifyStatusOf(err) }()
+	result = VerifyResult{Provider: ProviderAlibaba}
+	if sdk.config.AppCode == "" {
+		return result, fmt.Errorf("%w: alibaba app code not configured", ErrProviderUnavailable)
+	}
+	formData := url.Values{}
+	formData.Set("name", name)
+	formData.Set("idNo", id)
+	req, err := http.NewRequestWithContext(ctx, "POST", sdk.config.Url, strings.NewReader(formData.Encode()))
+	if err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in http.NewRequest", identityFields(name, id, field.WithError(err))...)
+		return result, err
+	}
+	// 注意：Authorization 值中的"APPCODE"和后面的代码之间有一个空格
+	req.Header.Set("Authorization", fmt.Sprintf("APPCODE %s", sdk.config.AppCode))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := sdk.client.Do(req)
+	if err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in client.Do", identityFields(name, id, field.WithError(err))...)
+		return result, fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if err = alibabaStatusError(resp); err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in alibabaStatusError", identityFields(name, id, field.WithError(err))...)
+		return result, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in io.ReadAll", identityFields(name, id, field.WithError(err))...)
+		return result, fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	result.Raw = maskPayload(body, name, id)
+
+	type validResp struct {
+		Name        string `json:"name"`
+		IdNo        string `json:"idNo"`
+		RespMessage string `json:"respMessage"`
+		RespCode    string `json:"respCode"`
+		Province    string `json:"province"`
+		City        string `json:"city"`
+		County      string `json:"county"`
+		Birthday    string `json:"birthday"`
+		Sex         string `json:"sex"`
+		Age         string `json:"age"`
+	}
+	var data validResp
+	if err = json.Unmarshal(body, &data); err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in json.Unmarshal", identityFields(name, id, field.WithError(err))...)
+		return result, fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	if err = alibabaRespCodeError(data.RespCode); err != nil {
+		return result, fmt.Errorf("%w: alibaba respCode %s %s", err, data.RespCode, data.RespMessage)
+	}
+	birthDay, err := time.Parse("20060102", data.Birthday)
+	if err != nil {
+		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in time.Parse", identityFields(name, id, field.WithError(err))...)
+		return result, nil
+	}
+	result.Info = IdInfo{
+		Name:     data.Name,
+		IdNo:     data.IdNo,
+		Province: data.Province,
+		City:     data.City,
+		County:   data.County,
+		Birthday: birthDay,
+		Sex:      data.Sex,
+		Age:      utils2.ParseIntString[int32](data.Age),
+	}
+	return result, nil
+}
+
+// alibabaRespCodeError 将 respCode 转换为错误：0000 为 nil，参数错误为 ErrInvalidParam，无此身份证号为 ErrNotFound，
+// 系统维护为 ErrProviderUnavailable，其他为 ErrMismatch
+func alibabaRespCodeError(respCode string) error {
+	switch respCode {
+	case "0000":
+		return nil
+	case "0001", "0002", "0003", "0004":
+		// 姓名为空、姓名含特殊字符、身份证号为空、身份证号格式错误
+		return ErrInvalidParam
+	case "0007":
+		return ErrNotFound
+	case "0010":
+		return ErrProviderUnavailable
+	}
+	return ErrMismatch
+}
+
+// alibabaStatusError 将阿里云市场网关的 HTTP 状态转换为错误：额度耗尽为 ErrQuotaExceeded，5xx 为 ErrProviderUnavailable
+func alibabaStatusError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusForbidden && strings.Contains(resp.Header.Get("X-Ca-Error-Message"), "Quota"):
+		return fmt.Errorf("%w: %s", ErrQuotaExceeded, resp.Header.Get("X-Ca-Error-Message"))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: http status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	return nil
+}
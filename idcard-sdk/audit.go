@@ -0,0 +1,200 @@
+package idcard_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// AuditEntry 一次服务商验证调用的审计记录，姓名与身份证号已脱敏
+type AuditEntry struct {
+	Name     string       `json:"name"`
+	IdNo     string       `json:"id_no"`
+	Provider string       `json:"provider"`
+	Status   VerifyStatus `json:"status"`
+	// 失败时的错误描述
+	Error string `json:"error,omitempty"`
+	// 调用耗时（毫秒）
+	LatencyMillis int64  `json:"latency_millis"`
+	TraceId       string `json:"trace_id,omitempty"`
+	// 发生时间戳（毫秒）
+	Timestamp int64 `json:"timestamp"`
+}
+
+func (entry *AuditEntry) MarshalBinary() ([]byte, error) {
+	return json.Marshal(entry)
+}
+
+func (entry *AuditEntry) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, entry)
+}
+
+// Score 以发生时间作为有序集合分值
+func (entry *AuditEntry) Score() float64 {
+	return float64(entry.Timestamp)
+}
+
+func (entry *AuditEntry) SetScore(score float64) {
+	entry.Timestamp = int64(score)
+}
+
+// AuditSink 验证调用的审计记录，实现需并发安全
+type AuditSink interface {
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+type traceIdKey struct{}
+
+// ContextWithTraceId 在 ctx 中携带链路追踪ID，写入审计记录
+func ContextWithTraceId(ctx context.Context, traceId string) context.Context {
+	return context.WithValue(ctx, traceIdKey{}, traceId)
+}
+
+// traceIdFromContext 返回 ctx 中携带的链路追踪ID，没有时为空
+func traceIdFromContext(ctx context.Context) string {
+	traceId, _ := ctx.Value(traceIdKey{}).(string)
+	return traceId
+}
+
+// AuditSDK 为每次服务商调用写入审计记录，写入失败只记录日志，不影响验证结果
+type AuditSDK struct {
+	sdk     IdCardSDK
+	sink    AuditSink
+	timeNow func() time.Time
+}
+
+// NewAuditSDK 为服务商添加审计记录
+func NewAuditSDK(sdk IdCardSDK, sink AuditSink) *AuditSDK {
+	return &AuditSDK{sdk: sdk, sink: sink, timeNow: time.Now}
+}
+
+// Name 服务商名称
+func (sdk *AuditSDK) Name() string {
+	return providerName(sdk.sdk)
+}
+
+// Valid 验证身份证与名字并写入审计记录
+func (sdk *AuditSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 验证身份证与名字并写入审计记录
+func (sdk *AuditSDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	start := sdk.timeNow()
+	result, err := validE(ctx, sdk.sdk, name, idNo)
+	entry := AuditEntry{
+		Name:          MaskName(name),
+		IdNo:          MaskIdNo(idNo),
+		Provider:      result.Provider,
+		Status:        result.Status,
+		LatencyMillis: sdk.timeNow().Sub(start).Milliseconds(),
+		TraceId:       traceIdFromContext(ctx),
+		Timestamp:     start.UnixMilli(),
+	}
+	if entry.Provider == "" {
+		entry.Provider = sdk.Name()
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if recordErr := sdk.sink.Record(ctx, entry); recordErr != nil {
+		zaplogger.DefaultLogger().Error("AuditSDK Valid in Record", field.WithError(recordErr),
+			field.String("provider", entry.Provider), field.WithTraceId(entry.TraceId))
+	}
+	return result, err
+}
+
+// LoggerAuditSink 将审计记录写入日志
+type LoggerAuditSink struct{}
+
+func (LoggerAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	zaplogger.DefaultLogger().Info("idcard-sdk verification audit",
+		field.String("name", entry.Name),
+		field.String("id_no", entry.IdNo),
+		field.String("provider", entry.Provider),
+		field.String("status", entry.Status.String()),
+		field.String("error", entry.Error),
+		field.Int64("latency_ms", entry.LatencyMillis),
+		field.WithTraceId(entry.TraceId),
+		field.Int64("timestamp", entry.Timestamp))
+	return nil
+}
+
+// MemoryAuditSink 基于内存的审计记录，适用于单机或测试
+type MemoryAuditSink struct {
+	mu      sync.RWMutex
+	entries []AuditEntry
+}
+
+// NewMemoryAuditSink 创建基于内存的审计记录
+func NewMemoryAuditSink() *MemoryAuditSink {
+	return &MemoryAuditSink{}
+}
+
+func (sink *MemoryAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.entries = append(sink.entries, entry)
+	return nil
+}
+
+// Entries 按写入顺序返回全部审计记录
+func (sink *MemoryAuditSink) Entries() []AuditEntry {
+	sink.mu.RLock()
+	defer sink.mu.RUnlock()
+	entries := make([]AuditEntry, len(sink.entries))
+	copy(entries, sink.entries)
+	return entries
+}
+
+// StorageAuditSink 基于 global-storage sorted set 的审计记录，以发生时间作为分值
+type StorageAuditSink struct {
+	zset       storage.SortedSetTransactional
+	maxEntries int64
+}
+
+// NewStorageAuditSink 创建基于 global-storage 的审计记录
+// zset: 需以 NewAuditEntryFactory 作为数据工厂注册的 sorted set 存储
+// maxEntries: 最多保留的记录数，超出时删除最早的记录，<=0 表示不限制
+func NewStorageAuditSink(zset storage.SortedSetTransactional, maxEntries int64) *StorageAuditSink {
+	return &StorageAuditSink{zset: zset, maxEntries: maxEntries}
+}
+
+// NewAuditEntryFactory 返回审计记录的数据工厂，用于注册 sorted set 存储
+func NewAuditEntryFactory() storage.SortedSetData {
+	return &AuditEntry{}
+}
+
+func (sink *StorageAuditSink) Record(ctx context.Context, entry AuditEntry) error {
+	if err := sink.zset.ZAdd(ctx, &entry); err != nil {
+		return err
+	}
+	if sink.maxEntries > 0 {
+		return sink.zset.ZRevTrimByTopN(ctx, sink.maxEntries)
+	}
+	return nil
+}
+
+// Query 按发生时间倒序查询 [from, to] 内的审计记录
+func (sink *StorageAuditSink) Query(ctx context.Context, from, to time.Time, offset, count int) ([]AuditEntry, error) {
+	elements, err := sink.zset.ZRevRangeByScore(ctx, float64(to.UnixMilli()), float64(from.UnixMilli()), offset, count)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]AuditEntry, 0, len(elements))
+	for _, element := range elements {
+		entry, ok := element.(*AuditEntry)
+		if !ok {
+			return nil, errors.New("idcard-sdk: unexpected audit entry type")
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
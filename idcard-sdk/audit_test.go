@@ -0,0 +1,38 @@
+package idcard_sdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAuditSDK(t *testing.T) {
+	sink := NewMemoryAuditSink()
+	err := InitIdCardSDKWithAudit(Config{
+		Providers:  []string{ProviderMock},
+		MockConfig: MockConfig{Identities: []MockIdentity{{Name: "张三", IdNo: "110101199003070003"}}},
+	}, sink)
+	if err != nil {
+		t.Fatalf("InitIdCardSDKWithAudit() error = %v", err)
+	}
+	ctx := ContextWithTraceId(context.Background(), "trace-1")
+	GetIdCardSDK().Valid(ctx, "张三", "110101199003070003")
+	GetIdCardSDK().Valid(ctx, "李四", "110101199003070003")
+
+	entries := sink.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Entries() = %d, want 2", len(entries))
+	}
+	want := []VerifyStatus{VerifyMatch, VerifyMismatch}
+	for i, entry := range entries {
+		if entry.Name == "张三" || entry.IdNo != "110101**********03" || entry.Provider != ProviderMock ||
+			entry.Status != want[i] || entry.TraceId != "trace-1" {
+			t.Errorf("entries[%d] = %+v, want masked %v", i, entry, want[i])
+		}
+	}
+
+	data, _ := entries[1].MarshalBinary()
+	var decoded AuditEntry
+	if err = decoded.UnmarshalBinary(data); err != nil || decoded.Status != VerifyMismatch || decoded.Error == "" {
+		t.Errorf("UnmarshalBinary() = %+v, %v, want mismatch with error", decoded, err)
+	}
+}
@@ -0,0 +1,229 @@
+package idcard_sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProviderBaidu 百度智能云实名认证服务商名称
+const ProviderBaidu = "baidu"
+
+const (
+	defaultBaiduTokenUrl = "https://aip.baidubce.com/oauth/2.0/token"
+	defaultBaiduUrl      = "https://aip.baidubce.com/rest/2.0/face/v3/person/idmatch"
+	// baiduTokenRefreshAhead access token 在过期前提前刷新的时长
+	baiduTokenRefreshAhead = 5 * time.Minute
+)
+
+// 百度智能云错误码
+const (
+	baiduCodeSuccess            = 0
+	baiduCodeDailyLimit         = 17
+	baiduCodeQPSLimit           = 18
+	baiduCodeTotalLimit         = 19
+	baiduCodeTokenInvalid       = 110
+	baiduCodeTokenExpired       = 111
+	baiduCodeServiceUnavailable = 2
+	// baiduCodeMismatch 身份证号与姓名不匹配或身份证号不存在
+	baiduCodeMismatch = 222351
+	// baiduCodeNotFound 公安库中不存在此身份证号
+	baiduCodeNotFound = 222354
+	// baiduCodeInvalidParam 请求参数不合法
+	baiduCodeInvalidParam = 216100
+)
+
+func init() {
+	RegisterProvider(ProviderBaidu, func(config Config) (IdCardSDK, error) {
+		if config.BaiduConfig.ApiKey == "" || config.BaiduConfig.SecretKey == "" {
+			return nil, errors.New("idcard-sdk: baidu api key and secret key required")
+		}
+		baiduConfig := config.BaiduConfig
+		client, err := baiduConfig.HTTP.NewHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		if baiduConfig.Sandbox {
+			baiduConfig.Url = baiduConfig.SandboxUrl
+		}
+		sdk := NewBaiduIdCardSDK(baiduConfig)
+		sdk.SetHTTPClient(client)
+		if baiduConfig.Sandbox {
+			return newProviderSandbox(ProviderBaidu, sdk, baiduConfig.SandboxUrl != "", config)
+		}
+		return sdk, nil
+	})
+}
+
+type BaiduConfig struct {
+	ApiKey    string `json:"api_key" yaml:"api-key"`
+	SecretKey string `json:"secret_key" yaml:"secret-key"`
+	// 获取 access token 的地址，为空时使用官方地址
+	TokenUrl string `json:"token_url" yaml:"token-url"`
+	// 身份证与名字比对接口地址，为空时使用官方地址
+	Url string `json:"url" yaml:"url"`
+	// HTTP 客户端配置，通过 InitIdCardSDK 创建时生效
+	HTTP HTTPConfig `json:"http" yaml:"http"`
+	// 沙箱模式，通过 InitIdCardSDK 创建时生效：预置的测试身份本地应答，其他身份发送到 SandboxUrl 比对，为空时视为不匹配
+	Sandbox    bool   `json:"sandbox" yaml:"sandbox"`
+	SandboxUrl string `json:"sandbox_url" yaml:"sandbox-url"`
+}
+
+// BaiduIdCardSDK 百度智能云身份证与名字比对，自动获取并缓存 access token，过期前或服务端判定失效时刷新
+type BaiduIdCardSDK struct {
+	config BaiduConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expireAt    time.Time
+	timeNow     func() time.Time
+}
+
+func NewBaiduIdCardSDK(config BaiduConfig) *BaiduIdCardSDK {
+	if config.TokenUrl == "" {
+		config.TokenUrl = defaultBaiduTokenUrl
+	}
+	if config.Url == "" {
+		config.Url = defaultBaiduUrl
+	}
+	return &BaiduIdCardSDK{config: config, client: defaultHTTPClient, timeNow: time.Now}
+}
+
+// SetHTTPClient 设置访问服务商的 HTTP 客户端，可由 HTTPConfig.NewHTTPClient 创建
+func (sdk *BaiduIdCardSDK) SetHTTPClient(client *http.Client) {
+	sdk.client = client
+}
+
+// Name 服务商名称
+func (sdk *BaiduIdCardSDK) Name() string {
+	return ProviderBaidu
+}
+
+// Valid 通过百度SDK验证身份证与名字
+func (sdk *BaiduIdCardSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 通过百度SDK验证身份证与名字，access token 失效时刷新后重试一次
+func (sdk *BaiduIdCardSDK) ValidE(ctx context.Context, name, idNo string) (result VerifyResult, err error) {
+	defer func() { result.Status = VerifyStatusOf(err) }()
+	result = VerifyResult{Provider: ProviderBaidu}
+	var code int
+	for attempt := 0; attempt < 2; attempt++ {
+		token, err := sdk.token(ctx, attempt > 0)
+		if err != nil {
+			zaplogger.DefaultLogger().Error("BaiduIdCardSDK Valid in token", identityFields(name, idNo, field.WithError(err))...)
+			return result, err
+		}
+		if code, result.Raw, err = sdk.idMatch(ctx, token, name, idNo); err != nil {
+			zaplogger.DefaultLogger().Error("BaiduIdCardSDK Valid in idMatch", identityFields(name, idNo, field.WithError(err))...)
+			return result, err
+		}
+		if code != baiduCodeTokenInvalid && code != baiduCodeTokenExpired {
+			break
+		}
+	}
+
+	switch code {
+	case baiduCodeSuccess:
+		result.Info = IdInfo{Name: name, IdNo: idNo}
+		return result, nil
+	case baiduCodeMismatch:
+		return result, fmt.Errorf("%w: baidu error code %d", ErrMismatch, code)
+	case baiduCodeNotFound:
+		return result, fmt.Errorf("%w: baidu error code %d", ErrNotFound, code)
+	case baiduCodeInvalidParam:
+		return result, fmt.Errorf("%w: baidu error code %d", ErrInvalidParam, code)
+	case baiduCodeDailyLimit, baiduCodeQPSLimit, baiduCodeTotalLimit:
+		return result, fmt.Errorf("%w: baidu error code %d", ErrQuotaExceeded, code)
+	case baiduCodeServiceUnavailable, baiduCodeTokenInvalid, baiduCodeTokenExpired:
+		return result, fmt.Errorf("%w: baidu error code %d", ErrProviderUnavailable, code)
+	}
+	return result, fmt.Errorf("idcard-sdk: baidu error code %d", code)
+}
+
+// token 返回缓存的 access token，即将过期或 refresh 为 true 时重新获取
+func (sdk *BaiduIdCardSDK) token(ctx context.Context, refresh bool) (string, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	now := sdk.timeNow()
+	if !refresh && sdk.accessToken != "" && now.Before(sdk.expireAt) {
+		return sdk.accessToken, nil
+	}
+
+	query := url.Values{}
+	query.Set("grant_type", "client_credentials")
+	query.Set("client_id", sdk.config.ApiKey)
+	query.Set("client_secret", sdk.config.SecretKey)
+	var data struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := sdk.post(ctx, sdk.config.TokenUrl+"?"+query.Encode(), nil, &data); err != nil {
+		return "", err
+	}
+	if data.AccessToken == "" {
+		return "", fmt.Errorf("idcard-sdk: baidu token %s: %s", data.Error, data.ErrorDescription)
+	}
+	sdk.accessToken = data.AccessToken
+	sdk.expireAt = now.Add(time.Duration(data.ExpiresIn)*time.Second - baiduTokenRefreshAhead)
+	return sdk.accessToken, nil
+}
+
+// idMatch 调用比对接口，返回百度错误码与脱敏后的原始响应
+func (sdk *BaiduIdCardSDK) idMatch(ctx context.Context, token, name, idNo string) (int, string, error) {
+	body, err := json.Marshal(map[string]string{
+		"id_card_number": idNo,
+		"name":           name,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	var raw json.RawMessage
+	if err = sdk.post(ctx, sdk.config.Url+"?access_token="+url.QueryEscape(token), body, &raw); err != nil {
+		return 0, "", err
+	}
+	var data struct {
+		ErrorCode int    `json:"error_code"`
+		ErrorMsg  string `json:"error_msg"`
+	}
+	if err = json.Unmarshal(raw, &data); err != nil {
+		return 0, "", err
+	}
+	return data.ErrorCode, maskPayload(raw, name, idNo), nil
+}
+
+// post 发送 JSON 请求并解析响应，网络错误或服务端 5xx 返回 ErrProviderUnavailable
+func (sdk *BaiduIdCardSDK) post(ctx context.Context, reqUrl string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", reqUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := sdk.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: http status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	return json.Unmarshal(data, v)
+}
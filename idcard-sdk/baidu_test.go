@@ -0,0 +1,57 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBaiduIdCardSDK_ValidE(t *testing.T) {
+	var tokenCalls int
+	var matchCode int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenCalls++
+			fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":2592000}`, tokenCalls)
+		case "/idmatch":
+			// 第一个 token 模拟被服务端判定失效
+			if r.URL.Query().Get("access_token") == "token-1" && matchCode == baiduCodeTokenExpired {
+				fmt.Fprintf(w, `{"error_code":%d}`, baiduCodeTokenExpired)
+				return
+			}
+			fmt.Fprintf(w, `{"error_code":%d}`, matchCode)
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewBaiduIdCardSDK(BaiduConfig{ApiKey: "key", SecretKey: "secret", TokenUrl: server.URL + "/token", Url: server.URL + "/idmatch"})
+	tests := []struct {
+		name           string
+		code           int
+		wantCheckRes   bool
+		wantErr        error
+		wantTokenCalls int
+	}{
+		{name: "比对通过", code: baiduCodeSuccess, wantCheckRes: true, wantTokenCalls: 1},
+		{name: "复用缓存的 token", code: baiduCodeMismatch, wantErr: ErrMismatch, wantTokenCalls: 1},
+		{name: "额度耗尽", code: baiduCodeDailyLimit, wantErr: ErrQuotaExceeded, wantTokenCalls: 1},
+		{name: "token 失效刷新后重试", code: baiduCodeTokenExpired, wantErr: ErrProviderUnavailable, wantTokenCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matchCode = tt.code
+			_, err := sdk.ValidE(context.Background(), "张三", "110101199003070000")
+			if (err == nil) != tt.wantCheckRes || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidE() error = %v, want %v", err, tt.wantErr)
+			}
+			if tokenCalls != tt.wantTokenCalls {
+				t.Errorf("token calls = %d, want %d", tokenCalls, tt.wantTokenCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package idcard_sdk
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency 批量验证的默认并发数
+const defaultBatchConcurrency = 8
+
+// Request 一条待验证的身份信息
+type Request struct {
+	Name string
+	IdNo string
+}
+
+// BatchResult 批量验证中一条请求的结果
+type BatchResult struct {
+	// 请求在输入中的下标
+	Index   int
+	Request Request
+	Result  VerifyResult
+	// 验证失败的原因，含义同 ValidE；ctx 结束后未执行的请求为 ctx 的错误
+	Err error
+}
+
+// BatchVerifier 以有限并发批量验证身份信息，用于后台重新核验历史账号等任务
+type BatchVerifier struct {
+	sdk         IdCardSDK
+	concurrency int
+}
+
+// NewBatchVerifier 创建批量验证器
+// concurrency: 同时进行的验证数，<=0 时为 8；服务商的限流与重试同样生效
+func NewBatchVerifier(sdk IdCardSDK, concurrency int) *BatchVerifier {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	return &BatchVerifier{sdk: sdk, concurrency: concurrency}
+}
+
+// ValidBatch 异步验证全部请求，每条请求恰好产生一条结果，结果按完成顺序发送，全部完成后关闭通道；
+// 调用方需读完通道，ctx 结束后剩余请求不再调用服务商，直接以 ctx 的错误返回
+func (verifier *BatchVerifier) ValidBatch(ctx context.Context, requests []Request) <-chan BatchResult {
+	results := make(chan BatchResult, verifier.concurrency)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < verifier.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results <- verifier.valid(ctx, index, requests[index])
+			}
+		}()
+	}
+	go func() {
+		for index := range requests {
+			indexes <- index
+		}
+		close(indexes)
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (verifier *BatchVerifier) valid(ctx context.Context, index int, request Request) BatchResult {
+	batchResult := BatchResult{Index: index, Request: request}
+	if err := ctx.Err(); err != nil {
+		batchResult.Err = err
+		return batchResult
+	}
+	batchResult.Result, batchResult.Err = validE(ctx, verifier.sdk, request.Name, request.IdNo)
+	return batchResult
+}
@@ -0,0 +1,42 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBatchVerifier_ValidBatch(t *testing.T) {
+	requests := []Request{
+		{Name: "张三", IdNo: "110101199003070003"},
+		{Name: "李四", IdNo: "110101199003070011"},
+		{Name: "王五", IdNo: "440301201001011234"},
+	}
+	provider := &flakyProvider{}
+	verifier := NewBatchVerifier(NewChainSDK(provider), 2)
+
+	seen := make(map[int]bool)
+	for result := range verifier.ValidBatch(context.Background(), requests) {
+		if result.Err != nil || result.Result.Info.IdNo != requests[result.Index].IdNo {
+			t.Errorf("ValidBatch() result = %+v, want match for request %d", result, result.Index)
+		}
+		seen[result.Index] = true
+	}
+	if len(seen) != len(requests) {
+		t.Errorf("ValidBatch() results = %d, want %d", len(seen), len(requests))
+	}
+
+	// ctx 已结束时每条请求仍有结果
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	count := 0
+	for result := range verifier.ValidBatch(ctx, requests) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("ValidBatch() canceled error = %v, want context.Canceled", result.Err)
+		}
+		count++
+	}
+	if count != len(requests) {
+		t.Errorf("ValidBatch() canceled results = %d, want %d", count, len(requests))
+	}
+}
@@ -0,0 +1,48 @@
+package idcard_sdk
+
+import "time"
+
+// adultAge 成年年龄
+const adultAge int32 = 18
+
+// 年龄段标签，与 anti-addiction 默认充值年龄段配置的 AgeBracket 一致
+const (
+	AgeBracketUnder8  = "0-8"
+	AgeBracket8To16   = "8-16"
+	AgeBracket16To18  = "16-18"
+	AgeBracketAdult   = "adult"
+	AgeBracketUnknown = "unknown"
+)
+
+// AgeBracketOf 返回年龄所在的年龄段标签，年龄为负时为 AgeBracketUnknown
+func AgeBracketOf(age int32) string {
+	switch {
+	case age < 0:
+		return AgeBracketUnknown
+	case age < 8:
+		return AgeBracketUnder8
+	case age < 16:
+		return AgeBracket8To16
+	case age < adultAge:
+		return AgeBracket16To18
+	}
+	return AgeBracketAdult
+}
+
+// fillAge 验证通过时补全年龄信息：服务商未返回出生日期时由身份证号离线解析，
+// 并按 now 重新计算年龄、是否未成年与年龄段；无法得到出生日期时保留服务商返回的年龄
+func (info *IdInfo) fillAge(idNo string, now time.Time) {
+	if info.Birthday.IsZero() {
+		if birthday, _, err := ExtractBirthdayAndAge(idNo, now); err == nil {
+			info.Birthday = birthday
+		}
+	}
+	if !info.Birthday.IsZero() {
+		info.Age = ageAt(info.Birthday, now)
+	} else if info.Age == 0 {
+		info.AgeBracket = AgeBracketUnknown
+		return
+	}
+	info.IsMinor = info.Age < adultAge
+	info.AgeBracket = AgeBracketOf(info.Age)
+}
@@ -0,0 +1,44 @@
+package idcard_sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAgeBracketOf(t *testing.T) {
+	tests := []struct {
+		name string
+		age  int32
+		want string
+	}{
+		{name: "未满8周岁", age: 7, want: AgeBracketUnder8},
+		{name: "8周岁", age: 8, want: AgeBracket8To16},
+		{name: "16周岁", age: 16, want: AgeBracket16To18},
+		{name: "成年", age: 18, want: AgeBracketAdult},
+		{name: "未知", age: -1, want: AgeBracketUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeBracketOf(tt.age); got != tt.want {
+				t.Errorf("AgeBracketOf(%d) = %s, want %s", tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdInfo_fillAge(t *testing.T) {
+	// 服务商仅返回姓名与身份证号时由身份证号解析
+	provider := &fakeProvider{name: "fake", checkRes: true}
+	result, err := validE(context.Background(), provider, "李四", "440301201001011234")
+	if err != nil || result.Info.Birthday.IsZero() || !result.Info.IsMinor || result.Info.AgeBracket == AgeBracketAdult {
+		t.Errorf("validE() info = %+v, %v, want minor", result.Info, err)
+	}
+
+	info := IdInfo{Birthday: time.Date(2010, 1, 1, 0, 0, 0, 0, time.Local), Age: 99}
+	info.fillAge("", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local))
+	if info.Age != 16 || !info.IsMinor || info.AgeBracket != AgeBracket16To18 {
+		t.Errorf("fillAge() = %+v, want age 16 in 16-18", info)
+	}
+}
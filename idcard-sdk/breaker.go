@@ -0,0 +1,205 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// ErrCircuitOpen 服务商熔断中，包装了 ErrProviderUnavailable，ChainSDK 会立即切换到备用服务商
+var ErrCircuitOpen = fmt.Errorf("%w: circuit open", ErrProviderUnavailable)
+
+const (
+	defaultBreakerWindowSize  = 20
+	defaultBreakerMinRequests = 10
+	defaultBreakerErrorRate   = 0.5
+	defaultBreakerCoolDown    = 30 * time.Second
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	// BreakerClosed 正常调用
+	BreakerClosed BreakerState = iota
+	// BreakerOpen 熔断中，直接返回 ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen 冷却结束，放行一次探测调用
+	BreakerHalfOpen
+)
+
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// BreakerConfig 熔断配置，零值字段使用默认值
+type BreakerConfig struct {
+	// 是否启用熔断
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 统计错误率的最近调用次数，默认 20
+	WindowSize int `json:"window_size" yaml:"window-size"`
+	// 窗口内调用次数达到该值后才会熔断，默认 10
+	MinRequests int `json:"min_requests" yaml:"min-requests"`
+	// 触发熔断的错误率，取值 (0, 1]，默认 0.5
+	ErrorRate float64 `json:"error_rate" yaml:"error-rate"`
+	// 熔断后的冷却时间（毫秒），默认 30000
+	CoolDownMillis int64 `json:"cool_down_millis" yaml:"cool-down-millis"`
+}
+
+func (config BreakerConfig) withDefaults() BreakerConfig {
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaultBreakerWindowSize
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaultBreakerMinRequests
+	}
+	config.MinRequests = min(config.MinRequests, config.WindowSize)
+	if config.ErrorRate <= 0 || config.ErrorRate > 1 {
+		config.ErrorRate = defaultBreakerErrorRate
+	}
+	if config.CoolDownMillis <= 0 {
+		config.CoolDownMillis = defaultBreakerCoolDown.Milliseconds()
+	}
+	return config
+}
+
+// isBreakerFailure 是否计为服务商故障：服务商不可用、超时或额度耗尽；不匹配与本地限流不计入
+func isBreakerFailure(err error) bool {
+	return isTransient(err) || errors.Is(err, ErrQuotaExceeded)
+}
+
+// CircuitBreakerSDK 统计服务商最近调用的错误率，超过阈值时熔断一段时间，避免在已故障的服务商上堆积超时
+type CircuitBreakerSDK struct {
+	sdk     IdCardSDK
+	config  BreakerConfig
+	timeNow func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	outcomes []bool
+	next     int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreakerSDK 为服务商添加熔断
+func NewCircuitBreakerSDK(sdk IdCardSDK, config BreakerConfig) *CircuitBreakerSDK {
+	config = config.withDefaults()
+	return &CircuitBreakerSDK{
+		sdk:      sdk,
+		config:   config,
+		timeNow:  time.Now,
+		outcomes: make([]bool, 0, config.WindowSize),
+	}
+}
+
+// Name 服务商名称
+func (sdk *CircuitBreakerSDK) Name() string {
+	return providerName(sdk.sdk)
+}
+
+// State 返回当前熔断状态
+func (sdk *CircuitBreakerSDK) State() BreakerState {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	if sdk.state == BreakerOpen && !sdk.coolingDown() {
+		return BreakerHalfOpen
+	}
+	return sdk.state
+}
+
+// Valid 未熔断时验证身份证与名字
+func (sdk *CircuitBreakerSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 未熔断时验证身份证与名字，熔断中返回 ErrCircuitOpen
+func (sdk *CircuitBreakerSDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	if !sdk.allow() {
+		return VerifyResult{Provider: sdk.Name(), Status: VerifyProviderError}, ErrCircuitOpen
+	}
+	result, err := validE(ctx, sdk.sdk, name, idNo)
+	sdk.record(isBreakerFailure(err))
+	return result, err
+}
+
+func (sdk *CircuitBreakerSDK) coolingDown() bool {
+	return sdk.timeNow().Sub(sdk.openedAt) < time.Duration(sdk.config.CoolDownMillis)*time.Millisecond
+}
+
+// allow 是否放行本次调用，冷却结束后仅放行一次探测调用
+func (sdk *CircuitBreakerSDK) allow() bool {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	switch sdk.state {
+	case BreakerOpen:
+		if sdk.coolingDown() {
+			return false
+		}
+		sdk.state = BreakerHalfOpen
+		sdk.probing = true
+		return true
+	case BreakerHalfOpen:
+		if sdk.probing {
+			return false
+		}
+		sdk.probing = true
+	}
+	return true
+}
+
+// record 记录调用结果：探测成功时恢复并清空统计，探测失败或错误率超过阈值时熔断
+func (sdk *CircuitBreakerSDK) record(failure bool) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	if sdk.state == BreakerHalfOpen {
+		sdk.probing = false
+		if failure {
+			sdk.open()
+			return
+		}
+		sdk.state = BreakerClosed
+		sdk.outcomes, sdk.next, sdk.failures = sdk.outcomes[:0], 0, 0
+		zaplogger.DefaultLogger().Info("CircuitBreakerSDK closed", field.String("provider", providerName(sdk.sdk)))
+		return
+	}
+
+	if len(sdk.outcomes) < sdk.config.WindowSize {
+		sdk.outcomes = append(sdk.outcomes, failure)
+	} else {
+		if sdk.outcomes[sdk.next] {
+			sdk.failures--
+		}
+		sdk.outcomes[sdk.next] = failure
+		sdk.next = (sdk.next + 1) % sdk.config.WindowSize
+	}
+	if failure {
+		sdk.failures++
+	}
+	if sdk.state == BreakerClosed && len(sdk.outcomes) >= sdk.config.MinRequests &&
+		float64(sdk.failures)/float64(len(sdk.outcomes)) >= sdk.config.ErrorRate {
+		sdk.open()
+	}
+}
+
+func (sdk *CircuitBreakerSDK) open() {
+	sdk.state = BreakerOpen
+	sdk.openedAt = sdk.timeNow()
+	zaplogger.DefaultLogger().Warn("CircuitBreakerSDK opened",
+		field.String("provider", providerName(sdk.sdk)),
+		field.Int("failures", sdk.failures),
+		field.Int("requests", len(sdk.outcomes)))
+}
@@ -0,0 +1,55 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerSDK_ValidE(t *testing.T) {
+	ctx := context.Background()
+	provider := &fakeProvider{name: "primary", err: ErrProviderUnavailable}
+	sdk := NewCircuitBreakerSDK(provider, BreakerConfig{Enabled: true, WindowSize: 4, MinRequests: 4, ErrorRate: 0.5, CoolDownMillis: 1000})
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+	sdk.timeNow = func() time.Time { return now }
+
+	// 不匹配不计入故障
+	provider.err = nil
+	for i := 0; i < 2; i++ {
+		if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); !errors.Is(err, ErrMismatch) {
+			t.Fatalf("ValidE() error = %v, want ErrMismatch", err)
+		}
+	}
+	provider.err = ErrProviderUnavailable
+	for i := 0; i < 2; i++ {
+		_, _ = sdk.ValidE(ctx, "张三", "110101199003070003")
+	}
+	if sdk.State() != BreakerOpen {
+		t.Fatalf("State() = %v, want open", sdk.State())
+	}
+	calls := provider.calls
+	if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("ValidE() open error = %v, want ErrCircuitOpen", err)
+	}
+	if provider.calls != calls {
+		t.Errorf("calls while open = %d, want %d", provider.calls, calls)
+	}
+
+	// 冷却结束后探测失败，重新熔断
+	now = now.Add(time.Second)
+	if sdk.State() != BreakerHalfOpen {
+		t.Errorf("State() after cool down = %v, want half-open", sdk.State())
+	}
+	_, _ = sdk.ValidE(ctx, "张三", "110101199003070003")
+	if sdk.State() != BreakerOpen {
+		t.Errorf("State() after failed probe = %v, want open", sdk.State())
+	}
+
+	// 探测成功后恢复
+	now = now.Add(time.Second)
+	provider.err, provider.checkRes = nil, true
+	if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); err != nil || sdk.State() != BreakerClosed {
+		t.Errorf("ValidE() probe = %v, state %v, want closed", err, sdk.State())
+	}
+}
@@ -0,0 +1,67 @@
+package idcard_sdk
+
+import (
+	"context"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// ChainSDK 按优先级依次调用服务商，当前服务商超时、不可用或额度耗尽时自动切换到下一个；
+// 信息不匹配是确定的结果，不会切换
+type ChainSDK struct {
+	sdks    []IdCardSDK
+	monitor *HealthMonitor
+}
+
+// NewChainSDK 创建服务商链
+// primary: 主服务商
+// fallbacks: 按优先级排列的备用服务商；未实现 Provider 的服务商无法报告错误，其结果总是被直接采用
+func NewChainSDK(primary IdCardSDK, fallbacks ...IdCardSDK) *ChainSDK {
+	return &ChainSDK{sdks: append([]IdCardSDK{primary}, fallbacks...)}
+}
+
+// SetHealthMonitor 按健康探测结果调整调用顺序，为 nil 时按配置的优先级；需在开始验证前设置
+func (chain *ChainSDK) SetHealthMonitor(monitor *HealthMonitor) {
+	chain.monitor = monitor
+}
+
+// Name 服务商名称
+func (chain *ChainSDK) Name() string {
+	return "chain"
+}
+
+// Valid 依次通过服务商验证身份证与名字
+func (chain *ChainSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := chain.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 先离线校验身份证号，不合法时直接返回 ErrInvalidIdNo 而不调用服务商；
+// 之后以规范化后的姓名依次通过服务商验证身份证与名字，全部服务商都不可用时返回最后一个错误；
+// ctx 结束后不再切换，返回 ctx 的错误
+func (chain *ChainSDK) ValidE(ctx context.Context, name, idNo string) (result VerifyResult, err error) {
+	inputName := name
+	name = NormalizeName(name)
+	defer func() { result.InputName, result.NormalizedName = inputName, name }()
+	if _, err = PrevalidateIdNo(idNo); err != nil {
+		return VerifyResult{Status: VerifyInvalidParam}, err
+	}
+	sdks := chain.sdks
+	if chain.monitor != nil {
+		sdks = chain.monitor.Rank(sdks)
+	}
+	for i, sdk := range sdks {
+		if err = ctx.Err(); err != nil {
+			return VerifyResult{Status: VerifyProviderError}, err
+		}
+		result, err = validE(ctx, sdk, name, idNo)
+		if err == nil || !shouldFailover(err) {
+			return
+		}
+		if i < len(sdks)-1 {
+			zaplogger.DefaultLogger().Warn("ChainSDK Valid failover", identityFields(name, idNo,
+				field.String("provider", providerName(sdk)), field.WithError(err))...)
+		}
+	}
+	return
+}
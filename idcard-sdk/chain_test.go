@@ -0,0 +1,116 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errBadRequest = errors.New("bad request")
+
+// fakeProvider 返回固定结果的服务商，记录调用次数
+type fakeProvider struct {
+	name     string
+	checkRes bool
+	err      error
+	calls    int
+}
+
+func (provider *fakeProvider) Name() string {
+	return provider.name
+}
+
+func (provider *fakeProvider) Valid(ctx context.Context, name, idNo string) (bool, IdInfo) {
+	result, err := provider.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+func (provider *fakeProvider) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	provider.calls++
+	result := VerifyResult{Provider: provider.name}
+	switch {
+	case provider.err != nil:
+		return result, provider.err
+	case !provider.checkRes:
+		return result, ErrMismatch
+	}
+	result.Info = IdInfo{Name: name, IdNo: idNo}
+	return result, nil
+}
+
+func TestChainSDK_ValidE(t *testing.T) {
+	tests := []struct {
+		name          string
+		primary       *fakeProvider
+		wantCheckRes  bool
+		wantErr       error
+		wantFallbacks int
+	}{
+		{
+			name:          "主服务商通过",
+			primary:       &fakeProvider{name: "primary", checkRes: true},
+			wantCheckRes:  true,
+			wantFallbacks: 0,
+		},
+		{
+			name:          "信息不匹配不切换",
+			primary:       &fakeProvider{name: "primary"},
+			wantCheckRes:  false,
+			wantErr:       ErrMismatch,
+			wantFallbacks: 0,
+		},
+		{
+			name:          "服务不可用切换",
+			primary:       &fakeProvider{name: "primary", err: ErrProviderUnavailable},
+			wantCheckRes:  true,
+			wantFallbacks: 1,
+		},
+		{
+			name:          "额度耗尽切换",
+			primary:       &fakeProvider{name: "primary", err: ErrQuotaExceeded},
+			wantCheckRes:  true,
+			wantFallbacks: 1,
+		},
+		{
+			name:          "其他错误不切换",
+			primary:       &fakeProvider{name: "primary", err: errBadRequest},
+			wantCheckRes:  false,
+			wantErr:       errBadRequest,
+			wantFallbacks: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &fakeProvider{name: "fallback", checkRes: true}
+			result, err := NewChainSDK(tt.primary, fallback).ValidE(context.Background(), "张三", "110101199003070003")
+			if (err == nil) != tt.wantCheckRes || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidE() = %+v, %v, want %v, %v", result, err, tt.wantCheckRes, tt.wantErr)
+			}
+			if tt.wantCheckRes && result.Provider != "fallback" && tt.wantFallbacks > 0 {
+				t.Errorf("ValidE() provider = %q, want fallback", result.Provider)
+			}
+			if fallback.calls != tt.wantFallbacks {
+				t.Errorf("fallback calls = %d, want %d", fallback.calls, tt.wantFallbacks)
+			}
+		})
+	}
+
+	// 全部服务商不可用时返回最后一个错误
+	chain := NewChainSDK(&fakeProvider{err: ErrProviderUnavailable}, &fakeProvider{err: ErrQuotaExceeded})
+	if _, err := chain.ValidE(context.Background(), "张三", "110101199003070003"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("ValidE() all down error = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestChainSDK_ValidE_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	primary := &fakeProvider{name: "primary", checkRes: true}
+	if _, err := NewChainSDK(primary).ValidE(ctx, "张三", "110101199003070003"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ValidE() canceled error = %v, want context.Canceled", err)
+	}
+	if primary.calls != 0 {
+		t.Errorf("primary calls = %d, want 0", primary.calls)
+	}
+}
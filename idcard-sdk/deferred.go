@@ -0,0 +1,126 @@
+package idcard_sdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrVerificationPending 验证尚未完成，需稍后再次查询
+	ErrVerificationPending = errors.New("idcard-sdk: verification pending")
+	// ErrReceiptNotFound 回执不存在或结果已被取走
+	ErrReceiptNotFound = errors.New("idcard-sdk: receipt not found")
+)
+
+// defaultReceiptPollInterval 轮询回执结果的默认间隔
+const defaultReceiptPollInterval = time.Second
+
+// Receipt 提交验证后返回的回执，不包含姓名与身份证号，可安全地持久化或返回给客户端
+type Receipt struct {
+	Id          string    `json:"id"`
+	Provider    string    `json:"provider"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// DeferredProvider 两步式验证：提交后返回回执，之后凭回执查询结果，用于服务商返回"认证中"的非即时流程
+type DeferredProvider interface {
+	// Submit 提交验证，验证结果已明确失败时直接返回错误
+	Submit(ctx context.Context, name, idNo string) (Receipt, error)
+	// Query 凭回执查询结果，尚未完成时返回 ErrVerificationPending
+	Query(ctx context.Context, receipt Receipt) (VerifyResult, error)
+}
+
+// WaitReceipt 按 interval 轮询回执直到验证完成，ctx 结束时返回 ctx 的错误
+// interval: 轮询间隔，<=0 时为 1 秒
+func WaitReceipt(ctx context.Context, provider DeferredProvider, receipt Receipt, interval time.Duration) (VerifyResult, error) {
+	if interval <= 0 {
+		interval = defaultReceiptPollInterval
+	}
+	for {
+		result, err := provider.Query(ctx, receipt)
+		if !errors.Is(err, ErrVerificationPending) {
+			return result, err
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return result, err
+		}
+	}
+}
+
+// WatchReceipt 在后台轮询回执，验证完成或 ctx 结束后调用一次 callback
+func WatchReceipt(ctx context.Context, provider DeferredProvider, receipt Receipt, interval time.Duration, callback func(Receipt, VerifyResult, error)) {
+	go func() {
+		result, err := WaitReceipt(ctx, provider, receipt, interval)
+		callback(receipt, result, err)
+	}()
+}
+
+// deferredCall 一次后台验证
+type deferredCall struct {
+	done   chan struct{}
+	result VerifyResult
+	err    error
+}
+
+// DeferredSDK 将即时验证的 IdCardSDK 适配为两步式验证：Submit 在后台发起验证并立即返回回执，
+// 完成的结果在首次被 Query 取走后删除。出版署请使用 NPPADeferredSDK，以便凭回执查询认证中的结果
+type DeferredSDK struct {
+	sdk IdCardSDK
+
+	mu    sync.Mutex
+	calls map[string]*deferredCall
+}
+
+// NewDeferredSDK 创建两步式验证适配器
+func NewDeferredSDK(sdk IdCardSDK) *DeferredSDK {
+	return &DeferredSDK{sdk: sdk, calls: make(map[string]*deferredCall)}
+}
+
+// Submit 在后台发起验证，验证不随 ctx 取消而中止
+func (sdk *DeferredSDK) Submit(ctx context.Context, name, idNo string) (Receipt, error) {
+	id, err := newReceiptId()
+	if err != nil {
+		return Receipt{}, err
+	}
+	call := &deferredCall{done: make(chan struct{})}
+	sdk.mu.Lock()
+	sdk.calls[id] = call
+	sdk.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		call.result, call.err = validE(ctx, sdk.sdk, name, idNo)
+		close(call.done)
+	}()
+	return Receipt{Id: id, Provider: providerName(sdk.sdk), SubmittedAt: time.Now()}, nil
+}
+
+// Query 查询回执结果，尚未完成时返回 ErrVerificationPending，回执不存在或结果已被取走时返回 ErrReceiptNotFound
+func (sdk *DeferredSDK) Query(ctx context.Context, receipt Receipt) (VerifyResult, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	call, ok := sdk.calls[receipt.Id]
+	if !ok {
+		return VerifyResult{}, ErrReceiptNotFound
+	}
+	select {
+	case <-call.done:
+		delete(sdk.calls, receipt.Id)
+		return call.result, call.err
+	default:
+		return VerifyResult{Provider: receipt.Provider}, ErrVerificationPending
+	}
+}
+
+// newReceiptId 生成随机的回执编号
+func newReceiptId() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
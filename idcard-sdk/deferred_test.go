@@ -0,0 +1,44 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeferredSDK(t *testing.T) {
+	ctx := context.Background()
+	provider := &flakyProvider{release: make(chan struct{})}
+	sdk := NewDeferredSDK(provider)
+
+	receipt, err := sdk.Submit(ctx, "张三", "110101199003070003")
+	if err != nil || receipt.Id == "" || receipt.Provider != "flaky" {
+		t.Fatalf("Submit() = %+v, %v", receipt, err)
+	}
+	if _, err = sdk.Query(ctx, receipt); !errors.Is(err, ErrVerificationPending) {
+		t.Errorf("Query() before done error = %v, want ErrVerificationPending", err)
+	}
+
+	done := make(chan VerifyResult, 1)
+	WatchReceipt(ctx, sdk, receipt, time.Millisecond, func(got Receipt, result VerifyResult, err error) {
+		if got.Id != receipt.Id || err != nil {
+			t.Errorf("callback = %+v, %v", got, err)
+		}
+		done <- result
+	})
+	close(provider.release)
+	select {
+	case result := <-done:
+		if result.Info.IdNo != "110101199003070003" {
+			t.Errorf("callback result = %+v, want verified info", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("callback not called")
+	}
+
+	// 结果已被取走
+	if _, err = sdk.Query(ctx, receipt); !errors.Is(err, ErrReceiptNotFound) {
+		t.Errorf("Query() after taken error = %v, want ErrReceiptNotFound", err)
+	}
+}
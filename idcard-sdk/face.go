@@ -0,0 +1,79 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFaceVerifyPending 用户尚未完成人脸核身
+var ErrFaceVerifyPending = errors.New("idcard-sdk: face verification pending")
+
+// defaultFaceVerifyPollInterval 轮询人脸核身结果的默认间隔
+const defaultFaceVerifyPollInterval = 2 * time.Second
+
+// FaceVerifyRequest 发起人脸核身的参数
+type FaceVerifyRequest struct {
+	Name string
+	IdNo string
+	// 核身完成后客户端跳转的地址，可为空
+	RedirectUrl string
+	// 透传字段，查询结果时原样返回，可为空
+	Extra string
+}
+
+// FaceVerifyTicket 发起人脸核身的凭据，客户端使用 Url 或 BizToken 完成采集
+type FaceVerifyTicket struct {
+	// 查询结果使用的令牌
+	BizToken string
+	// H5 核身地址，部分服务商为空
+	Url string
+}
+
+// FaceVerifyStatus 人脸核身状态
+type FaceVerifyStatus int
+
+const (
+	// FaceVerifyPending 用户尚未完成核身
+	FaceVerifyPending FaceVerifyStatus = iota
+	// FaceVerifyPassed 核身通过
+	FaceVerifyPassed
+	// FaceVerifyFailed 核身不通过，如非本人或活体检测失败
+	FaceVerifyFailed
+)
+
+// FaceVerifyResult 人脸核身结果
+type FaceVerifyResult struct {
+	Status FaceVerifyStatus
+	// 服务商返回的结果码与描述，用于排查失败原因
+	Code    string
+	Message string
+	// 与公安照片的相似度，0-100
+	Similarity float64
+	Extra      string
+}
+
+// FaceVerifySDK 人脸核身与活体检测，出版署要求对可疑的成年账号进行人脸识别验证
+type FaceVerifySDK interface {
+	// Submit 发起人脸核身，返回客户端完成采集所需的凭据
+	Submit(ctx context.Context, req FaceVerifyRequest) (FaceVerifyTicket, error)
+	// Query 查询核身结果，用户未完成时状态为 FaceVerifyPending
+	Query(ctx context.Context, bizToken string) (FaceVerifyResult, error)
+}
+
+// WaitFaceVerify 按 interval 轮询核身结果直到不再是 FaceVerifyPending，ctx 结束时返回 ctx 的错误
+// interval: 轮询间隔，<=0 时为 2 秒
+func WaitFaceVerify(ctx context.Context, sdk FaceVerifySDK, bizToken string, interval time.Duration) (FaceVerifyResult, error) {
+	if interval <= 0 {
+		interval = defaultFaceVerifyPollInterval
+	}
+	for {
+		result, err := sdk.Query(ctx, bizToken)
+		if err != nil || result.Status != FaceVerifyPending {
+			return result, err
+		}
+		if err = sleepContext(ctx, interval); err != nil {
+			return result, err
+		}
+	}
+}
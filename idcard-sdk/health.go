@@ -0,0 +1,204 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+const (
+	defaultHealthIntervalMillis     = 5 * 60 * 1000
+	defaultHealthTimeoutMillis      = 5000
+	defaultHealthUnhealthyThreshold = 3
+	defaultHealthProbeName          = "张三"
+	defaultHealthProbeIdNo          = "110101199003070003"
+	// healthLatencyAlpha 延迟指数移动平均的权重
+	healthLatencyAlpha = 0.3
+)
+
+// HealthConfig 服务商健康探测配置，探测使用固定的合成身份调用服务商，会消耗调用额度
+type HealthConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 探测间隔（毫秒），默认 5 分钟
+	IntervalMillis int64 `json:"interval_millis" yaml:"interval-millis"`
+	// 单次探测超时（毫秒），默认 5 秒
+	TimeoutMillis int64 `json:"timeout_millis" yaml:"timeout-millis"`
+	// 探测使用的姓名与身份证号，为空时使用内置的合成身份；信息不一致同样视为服务商可用
+	ProbeName string `json:"probe_name" yaml:"probe-name"`
+	ProbeIdNo string `json:"probe_id_no" yaml:"probe-id-no"`
+	// 连续失败多少次判定为不健康，默认 3
+	UnhealthyThreshold int `json:"unhealthy_threshold" yaml:"unhealthy-threshold"`
+	// 为 true 时健康的服务商按探测延迟升序排列，否则保持配置的优先级
+	RankByLatency bool `json:"rank_by_latency" yaml:"rank-by-latency"`
+}
+
+func (config HealthConfig) withDefaults() HealthConfig {
+	if config.IntervalMillis <= 0 {
+		config.IntervalMillis = defaultHealthIntervalMillis
+	}
+	if config.TimeoutMillis <= 0 {
+		config.TimeoutMillis = defaultHealthTimeoutMillis
+	}
+	if config.ProbeName == "" || config.ProbeIdNo == "" {
+		config.ProbeName, config.ProbeIdNo = defaultHealthProbeName, defaultHealthProbeIdNo
+	}
+	if config.UnhealthyThreshold <= 0 {
+		config.UnhealthyThreshold = defaultHealthUnhealthyThreshold
+	}
+	return config
+}
+
+// ProviderHealth 服务商健康状况
+type ProviderHealth struct {
+	Provider string `json:"provider"`
+	Healthy  bool   `json:"healthy"`
+	// 探测延迟的指数移动平均（毫秒）
+	LatencyMillis       int64     `json:"latency_millis"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastCheck           time.Time `json:"last_check"`
+}
+
+// HealthMonitor 定期探测服务商并记录延迟与错误，ChainSDK 据此调整调用顺序：
+// 不健康的服务商排到最后，仍在全部服务商都不健康时作为兜底
+type HealthMonitor struct {
+	config  HealthConfig
+	sdks    []IdCardSDK
+	timeNow func() time.Time
+
+	mu     sync.RWMutex
+	health map[IdCardSDK]*ProviderHealth
+}
+
+// NewHealthMonitor 创建服务商健康探测，探测前所有服务商视为健康
+func NewHealthMonitor(sdks []IdCardSDK, config HealthConfig) *HealthMonitor {
+	monitor := &HealthMonitor{
+		config:  config.withDefaults(),
+		sdks:    sdks,
+		timeNow: time.Now,
+		health:  make(map[IdCardSDK]*ProviderHealth, len(sdks)),
+	}
+	for _, sdk := range sdks {
+		monitor.health[sdk] = &ProviderHealth{Provider: providerName(sdk), Healthy: true}
+	}
+	return monitor
+}
+
+// Start 在后台按间隔探测，直到 ctx 结束
+func (monitor *HealthMonitor) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Duration(monitor.config.IntervalMillis) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			monitor.Probe(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Probe 并发探测一轮所有服务商
+func (monitor *HealthMonitor) Probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sdk := range monitor.sdks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			monitor.probe(ctx, sdk)
+		}()
+	}
+	wg.Wait()
+}
+
+func (monitor *HealthMonitor) probe(ctx context.Context, sdk IdCardSDK) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(monitor.config.TimeoutMillis)*time.Millisecond)
+	defer cancel()
+	start := monitor.timeNow()
+	_, err := validE(ctx, sdk, monitor.config.ProbeName, monitor.config.ProbeIdNo)
+	now := monitor.timeNow()
+	latency := now.Sub(start).Milliseconds()
+
+	monitor.mu.Lock()
+	defer monitor.mu.Unlock()
+	health := monitor.health[sdk]
+	wasHealthy := health.Healthy
+	health.LastCheck = now
+	if health.LatencyMillis == 0 {
+		health.LatencyMillis = latency
+	} else {
+		health.LatencyMillis = int64(healthLatencyAlpha*float64(latency) + (1-healthLatencyAlpha)*float64(health.LatencyMillis))
+	}
+	// 信息不一致等确定的结果说明服务商可用
+	if err == nil || !isBreakerFailure(err) {
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.Healthy = true
+	} else {
+		health.ConsecutiveFailures++
+		health.LastError = err.Error()
+		health.Healthy = health.ConsecutiveFailures < monitor.config.UnhealthyThreshold
+	}
+	if wasHealthy != health.Healthy {
+		zaplogger.DefaultLogger().Warn("HealthMonitor provider health changed",
+			field.String("provider", health.Provider),
+			field.Any("healthy", health.Healthy),
+			field.String("last_error", health.LastError))
+	}
+}
+
+// Health 按配置的优先级返回各服务商的健康状况
+func (monitor *HealthMonitor) Health() []ProviderHealth {
+	monitor.mu.RLock()
+	defer monitor.mu.RUnlock()
+	result := make([]ProviderHealth, 0, len(monitor.sdks))
+	for _, sdk := range monitor.sdks {
+		result = append(result, *monitor.health[sdk])
+	}
+	return result
+}
+
+// Ready 最近一次探测中有可用的服务商时返回 nil，否则返回各服务商的最后错误；不会发起探测，可注册为健康检查
+func (monitor *HealthMonitor) Ready(ctx context.Context) error {
+	var errs []error
+	for _, health := range monitor.Health() {
+		if health.Healthy {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", health.Provider, health.LastError))
+	}
+	if len(errs) == 0 {
+		return errors.New("idcard-sdk: no provider configured")
+	}
+	return errors.Join(errs...)
+}
+
+// Rank 返回调整后的调用顺序：健康的在前，开启 RankByLatency 时按延迟升序，其余保持原顺序；未被探测的服务商视为健康
+func (monitor *HealthMonitor) Rank(sdks []IdCardSDK) []IdCardSDK {
+	monitor.mu.RLock()
+	defer monitor.mu.RUnlock()
+	ranked := slices.Clone(sdks)
+	slices.SortStableFunc(ranked, func(a, b IdCardSDK) int {
+		healthA, healthB := monitor.health[a], monitor.health[b]
+		healthyA, healthyB := healthA == nil || healthA.Healthy, healthB == nil || healthB.Healthy
+		switch {
+		case healthyA != healthyB:
+			if healthyA {
+				return -1
+			}
+			return 1
+		case monitor.config.RankByLatency && healthA != nil && healthB != nil:
+			return int(healthA.LatencyMillis - healthB.LatencyMillis)
+		}
+		return 0
+	})
+	return ranked
+}
@@ -0,0 +1,65 @@
+package idcard_sdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeProvider{name: "primary", err: ErrProviderUnavailable}
+	secondary := &fakeProvider{name: "secondary", checkRes: false}
+	monitor := NewHealthMonitor([]IdCardSDK{primary, secondary}, HealthConfig{UnhealthyThreshold: 2})
+	chain := NewChainSDK(primary, secondary)
+	chain.SetHealthMonitor(monitor)
+
+	// 未达到连续失败次数时仍按优先级
+	monitor.Probe(ctx)
+	if health := monitor.Health(); !health[0].Healthy || health[0].ConsecutiveFailures != 1 || !health[1].Healthy {
+		t.Fatalf("Health() after 1 probe = %+v", health)
+	}
+	monitor.Probe(ctx)
+	if health := monitor.Health(); health[0].Healthy || health[0].LastError == "" || !health[1].Healthy {
+		t.Fatalf("Health() after 2 probes = %+v, want primary unhealthy", health)
+	}
+
+	// 不健康的主服务商排到最后，不再被优先调用
+	primary.calls, secondary.calls = 0, 0
+	secondary.checkRes = true
+	if result, err := chain.ValidE(ctx, "张三", "110101199003070003"); err != nil || result.Provider != "secondary" {
+		t.Errorf("ValidE() = %+v, %v, want secondary", result, err)
+	}
+	if primary.calls != 0 {
+		t.Errorf("primary calls = %d, want 0", primary.calls)
+	}
+
+	// 恢复后重新按优先级
+	primary.err = nil
+	primary.checkRes = true
+	monitor.Probe(ctx)
+	if ranked := monitor.Rank([]IdCardSDK{primary, secondary}); ranked[0] != primary {
+		t.Errorf("Rank() first = %v, want primary after recovery", providerName(ranked[0]))
+	}
+}
+
+func TestHealthMonitor_Ready(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeProvider{name: "primary", err: ErrProviderUnavailable}
+	secondary := &fakeProvider{name: "secondary", err: ErrProviderUnavailable}
+	monitor := NewHealthMonitor([]IdCardSDK{primary, secondary}, HealthConfig{UnhealthyThreshold: 1})
+	if err := monitor.Ready(ctx); err != nil {
+		t.Errorf("Ready() before probe = %v, want nil", err)
+	}
+	monitor.Probe(ctx)
+	if err := monitor.Ready(ctx); err == nil {
+		t.Errorf("Ready() with all providers unhealthy = nil")
+	}
+	secondary.err = nil
+	monitor.Probe(ctx)
+	if err := monitor.Ready(ctx); err != nil {
+		t.Errorf("Ready() with secondary healthy = %v, want nil", err)
+	}
+	if err := NewHealthMonitor(nil, HealthConfig{}).Ready(ctx); err == nil {
+		t.Errorf("Ready() without providers = nil")
+	}
+}
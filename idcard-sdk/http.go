@@ -0,0 +1,119 @@
+package idcard_sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// defaultRequestTimeout 单次请求服务商的默认超时，ctx 的截止时间更早时以 ctx 为准
+const defaultRequestTimeout = 10 * time.Second
+
+// defaultHTTPClient 未配置 HTTPConfig 的服务商共用的 HTTP 客户端，复用连接
+var defaultHTTPClient = &http.Client{Timeout: defaultRequestTimeout}
+
+// TLSConfig 访问服务商的 TLS 配置
+type TLSConfig struct {
+	// 额外信任的 CA 证书文件（PEM），为空时使用系统证书
+	CAFile string `json:"ca_file" yaml:"ca-file"`
+	// 双向认证的客户端证书与私钥文件（PEM），需同时配置
+	CertFile string `json:"cert_file" yaml:"cert-file"`
+	KeyFile  string `json:"key_file" yaml:"key-file"`
+	// 校验证书时使用的服务器名，为空时使用请求地址中的主机名
+	ServerName string `json:"server_name" yaml:"server-name"`
+	// 跳过证书校验，仅用于测试环境
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure-skip-verify"`
+}
+
+// HTTPConfig 访问服务商的 HTTP 客户端配置，零值表示使用默认客户端
+type HTTPConfig struct {
+	// 单次请求超时（毫秒），<=0 时为 10000
+	TimeoutMillis int64 `json:"timeout_millis" yaml:"timeout-millis"`
+	// 代理地址，如 http://127.0.0.1:8080，为空时使用环境变量中的代理
+	ProxyUrl string    `json:"proxy_url" yaml:"proxy-url"`
+	TLS      TLSConfig `json:"tls" yaml:"tls"`
+	// TCP keep-alive 间隔（秒），<0 表示关闭，0 时使用默认值
+	KeepAliveSeconds int64 `json:"keep_alive_seconds" yaml:"keep-alive-seconds"`
+	// 空闲连接数上限与每个主机的空闲连接数上限，0 时使用默认值
+	MaxIdleConns        int `json:"max_idle_conns" yaml:"max-idle-conns"`
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host" yaml:"max-idle-conns-per-host"`
+	// 空闲连接的保留时间（秒），0 时使用默认值
+	IdleConnTimeoutSeconds int64 `json:"idle_conn_timeout_seconds" yaml:"idle-conn-timeout-seconds"`
+}
+
+// NewHTTPClient 按配置创建 HTTP 客户端，零值配置返回共用的默认客户端；代理地址或证书文件非法时返回错误
+func (config HTTPConfig) NewHTTPClient() (*http.Client, error) {
+	if config == (HTTPConfig{}) {
+		return defaultHTTPClient, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(config.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("idcard-sdk: invalid proxy url %q: %w", config.ProxyUrl, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+	tlsConfig, err := config.TLS.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	if config.KeepAliveSeconds != 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: time.Duration(config.KeepAliveSeconds) * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeoutSeconds > 0 {
+		transport.IdleConnTimeout = time.Duration(config.IdleConnTimeoutSeconds) * time.Second
+	}
+
+	timeout := time.Duration(config.TimeoutMillis) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}, nil
+}
+
+// newTLSConfig 按配置创建 tls.Config，零值配置返回 nil 使用默认设置
+func (config TLSConfig) newTLSConfig() (*tls.Config, error) {
+	if config == (TLSConfig{}) {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("idcard-sdk: read ca file %s: %w", config.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("idcard-sdk: no certificate found in ca file %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("idcard-sdk: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
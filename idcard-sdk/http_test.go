@@ -0,0 +1,37 @@
+package idcard_sdk
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHTTPConfig_NewHTTPClient(t *testing.T) {
+	if client, err := (HTTPConfig{}).NewHTTPClient(); err != nil || client != defaultHTTPClient {
+		t.Errorf("NewHTTPClient() zero config = %v, %v, want default client", client, err)
+	}
+
+	client, err := HTTPConfig{TimeoutMillis: 1500, ProxyUrl: "http://127.0.0.1:8080", MaxIdleConnsPerHost: 32}.NewHTTPClient()
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	transport := client.Transport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://api.example.com", nil)
+	if proxy, _ := transport.Proxy(req); proxy == nil || proxy.Host != "127.0.0.1:8080" {
+		t.Errorf("NewHTTPClient() proxy = %v, want 127.0.0.1:8080", proxy)
+	}
+	if client.Timeout != 1500*time.Millisecond || transport.MaxIdleConnsPerHost != 32 {
+		t.Errorf("NewHTTPClient() timeout = %v, max idle per host = %d", client.Timeout, transport.MaxIdleConnsPerHost)
+	}
+
+	invalid := []HTTPConfig{
+		{ProxyUrl: "://bad"},
+		{TLS: TLSConfig{CAFile: "/nonexistent/ca.pem"}},
+		{TLS: TLSConfig{CertFile: "/nonexistent/cert.pem"}},
+	}
+	for _, config := range invalid {
+		if _, err = config.NewHTTPClient(); err == nil {
+			t.Errorf("NewHTTPClient(%+v) error = nil, want error", config)
+		}
+	}
+}
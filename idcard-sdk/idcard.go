@@ -1,26 +1,39 @@
 package idcard_sdk
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	zaplogger "github.com/NumberMan1/component/zap-logger"
-	"github.com/NumberMan1/component/zap-logger/field"
 	utils2 "github.com/NumberMan1/numbox/utils"
-	"io"
-	"net/http"
-	"net/url"
-	"strings"
 	"time"
 )
 
 type Config struct {
 	AlibabaConfig AlibabaConfig `json:"alibaba_config" yaml:"alibaba-config"`
+	BaiduConfig   BaiduConfig   `json:"baidu_config" yaml:"baidu-config"`
+	NPPAConfig    NPPAConfig    `json:"nppa_config" yaml:"nppa-config"`
+	MockConfig    MockConfig    `json:"mock_config" yaml:"mock-config"`
+	// 人脸核身配置，仅在使用 InitFaceVerifySDK 时需要
+	TencentFaceConfig TencentFaceConfig `json:"tencent_face_config" yaml:"tencent-face-config"`
+	// 按优先级排列的服务商名称，第一个为主服务商，其余依次作为备用；为空时仅使用 ProviderAlibaba
+	Providers []string `json:"providers" yaml:"providers"`
+	// 按服务商名称配置的单机限流，未配置的服务商不限流；多节点共享配额时使用 NewStorageLimiter 自行组装
+	RateLimits map[string]RateLimitConfig `json:"rate_limits" yaml:"rate-limits"`
+	// 按服务商名称配置的单机调用额度统计，未配置的服务商不统计；多节点共享计数时使用 NewStorageQuotaTracker 自行组装
+	Quotas map[string]QuotaConfig `json:"quotas" yaml:"quotas"`
+	// 各服务商调用的重试策略，每次重试同样受限流约束
+	Retry RetryPolicy `json:"retry" yaml:"retry"`
+	// 各服务商的熔断配置，熔断中的服务商被直接跳过
+	CircuitBreaker BreakerConfig `json:"circuit_breaker" yaml:"circuit-breaker"`
+	// 服务商健康探测，开启后 ChainSDK 优先调用健康的服务商
+	Health HealthConfig `json:"health" yaml:"health"`
+	// 开启沙箱模式的服务商预置的测试身份，为空时使用 DefaultSandboxIdentities
+	SandboxIdentities []MockIdentity `json:"sandbox_identities" yaml:"sandbox-identities"`
 }
 
 type IdCardSDK interface {
-	// Valid 验证身份证与名字
-	Valid(name, idNo string) (checkRes bool, info IdInfo)
+	// Valid 验证身份证与名字，ctx 用于控制超时与取消
+	Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo)
 }
 
 type IdInfo struct {
@@ -32,93 +45,104 @@ type IdInfo struct {
 	Birthday time.Time
 	Sex      string
 	Age      int32
+	// 是否未成年与年龄段标签，在验证通过时按验证时刻计算，见 AgeBracketOf
+	IsMinor    bool
+	AgeBracket string
 }
 
-var alibabaIdCardSDKInstance IdCardSDK
+var (
+	idCardSDKInstance        IdCardSDK
+	alibabaIdCardSDKInstance IdCardSDK
+	faceVerifySDKInstance    FaceVerifySDK
+	// stopHealthMonitor 停止上一次初始化启动的健康探测
+	stopHealthMonitor context.CancelFunc
+)
 
-func GetAlibabaIdCardSDK() IdCardSDK {
-	utils2.Asset(alibabaIdCardSDKInstance != nil, errors.New("AlibabaIdCard sdk not initialized"))
-	return alibabaIdCardSDKInstance
+// GetIdCardSDK 获取按 Config.Providers 组装的全局实名认证实例
+func GetIdCardSDK() IdCardSDK {
+	utils2.Asset(idCardSDKInstance != nil, errors.New("IdCard sdk not initialized"))
+	return idCardSDKInstance
 }
 
-func InitAlibabaIdCardSDK(config Config) {
-	alibabaIdCardSDKInstance = NewAlibabaIdCardSDK(config.AlibabaConfig)
+// InitIdCardSDK 按 Config.Providers 的顺序创建服务商并组装为 ChainSDK，主服务商不可用时自动切换到备用服务商
+func InitIdCardSDK(config Config) error {
+	return InitIdCardSDKWithAudit(config, nil)
 }
 
-type AlibabaConfig struct {
-	AppCode string `json:"app_code" yaml:"app-code"`
-	Url     string `json:"url" yaml:"url"`
+// InitIdCardSDKWithAudit 同 InitIdCardSDK，并为每次服务商调用（包括重试）写入审计记录，sink 为 nil 时不记录
+func InitIdCardSDKWithAudit(config Config, sink AuditSink) error {
+	names := config.Providers
+	if len(names) == 0 {
+		names = []string{ProviderAlibaba}
+	}
+	sdks := make([]IdCardSDK, 0, len(names))
+	for _, name := range names {
+		factory, ok := LookupProvider(name)
+		if !ok {
+			return fmt.Errorf("idcard-sdk: unknown provider %q", name)
+		}
+		sdk, err := factory(config)
+		if err != nil {
+			return fmt.Errorf("idcard-sdk: create provider %q: %w", name, err)
+		}
+		if sink != nil {
+			sdk = NewAuditSDK(sdk, sink)
+		}
+		if quota, ok := config.Quotas[name]; ok {
+			sdk = NewQuotaSDK(sdk, NewMemoryQuotaTracker(), quota)
+		}
+		if rateLimit, ok := config.RateLimits[name]; ok {
+			limiter, err := NewMemoryLimiter(rateLimit)
+			if err != nil {
+				return fmt.Errorf("idcard-sdk: rate limit of provider %q: %w", name, err)
+			}
+			sdk = NewRateLimitedSDK(sdk, limiter)
+		}
+		if config.Retry.MaxAttempts > 1 {
+			sdk = NewRetrySDK(sdk, config.Retry)
+		}
+		if config.CircuitBreaker.Enabled {
+			sdk = NewCircuitBreakerSDK(sdk, config.CircuitBreaker)
+		}
+		sdks = append(sdks, sdk)
+	}
+	chain := NewChainSDK(sdks[0], sdks[1:]...)
+	if stopHealthMonitor != nil {
+		stopHealthMonitor()
+		stopHealthMonitor = nil
+	}
+	if config.Health.Enabled {
+		var ctx context.Context
+		ctx, stopHealthMonitor = context.WithCancel(context.Background())
+		monitor := NewHealthMonitor(sdks, config.Health)
+		chain.SetHealthMonitor(monitor)
+		monitor.Start(ctx)
+	}
+	idCardSDKInstance = chain
+	return nil
 }
 
-type AlibabaIdCardSDK struct {
-	config AlibabaConfig
+func GetAlibabaIdCardSDK() IdCardSDK {
+	utils2.Asset(alibabaIdCardSDKInstance != nil, errors.New("AlibabaIdCard sdk not initialized"))
+	return alibabaIdCardSDKInstance
 }
 
-func NewAlibabaIdCardSDK(config AlibabaConfig) *AlibabaIdCardSDK {
-	return &AlibabaIdCardSDK{config: config}
+func InitAlibabaIdCardSDK(config Config) {
+	alibabaIdCardSDKInstance = NewAlibabaIdCardSDK(config.AlibabaConfig)
 }
 
-// Valid 通过阿里巴巴SDK验证身份证与名字
-func (sdk *AlibabaIdCardSDK) Valid(name, id string) (checkRes bool, info IdInfo) {
-	if sdk.config.AppCode == "" {
-		return
-	}
-	formData := url.Values{}
-	formData.Set("name", name)
-	formData.Set("idNo", id)
-	req, err := http.NewRequest("POST", sdk.config.Url, strings.NewReader(formData.Encode()))
-	if err != nil {
-		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in http.NewRequest", field.WithError(err))
-		return
-	}
-	// 注意：Authorization 值中的"APPCODE"和后面的代码之间有一个空格
-	req.Header.Set("Authorization", fmt.Sprintf("APPCODE %s", sdk.config.AppCode))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in client.Do", field.WithError(err))
-		return
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in io.ReadAll", field.WithError(err))
-		return
-	}
+// GetFaceVerifySDK 获取全局人脸核身实例
+func GetFaceVerifySDK() FaceVerifySDK {
+	utils2.Asset(faceVerifySDKInstance != nil, errors.New("FaceVerify sdk not initialized"))
+	return faceVerifySDKInstance
+}
 
-	type validResp struct {
-		Name        string `json:"name"`
-		IdNo        string `json:"idNo"`
-		RespMessage string `json:"respMessage"`
-		RespCode    string `json:"respCode"`
-		Province    string `json:"province"`
-		City        string `json:"city"`
-		County      string `json:"county"`
-		Birthday    string `json:"birthday"`
-		Sex         string `json:"sex"`
-		Age         string `json:"age"`
-	}
-	var data validResp
-	err = json.Unmarshal(body, &data)
-	checkRes = err == nil && data.RespCode == "0000"
-	if !checkRes {
-		return
-	}
-	birthDay, err := time.Parse("20060102", data.Birthday)
+// InitFaceVerifySDK 以腾讯云慧眼初始化全局人脸核身实例，配置非法时返回错误
+func InitFaceVerifySDK(config Config) error {
+	sdk, err := NewTencentFaceVerifySDK(config.TencentFaceConfig)
 	if err != nil {
-		zaplogger.DefaultLogger().Error("AlibabaIdCardSDK Valid in time.Parse", field.WithError(err))
-		return
-	}
-	info = IdInfo{
-		Name:     data.Name,
-		IdNo:     data.IdNo,
-		Province: data.Province,
-		City:     data.City,
-		County:   data.County,
-		Birthday: birthDay,
-		Sex:      data.Sex,
-		Age:      utils2.ParseIntString[int32](data.Age),
+		return err
 	}
-	return
+	faceVerifySDKInstance = sdk
+	return nil
 }
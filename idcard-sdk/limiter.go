@@ -0,0 +1,178 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// ErrRateLimited 本地限流拒绝了调用，ChainSDK 会切换到备用服务商
+var ErrRateLimited = errors.New("idcard-sdk: rate limited")
+
+// RateLimitConfig 令牌桶限流配置
+type RateLimitConfig struct {
+	// 每秒允许的调用次数
+	QPS float64 `json:"qps" yaml:"qps"`
+	// 允许的突发调用次数，<=0 时取 QPS 向上取整且不少于 1
+	Burst int `json:"burst" yaml:"burst"`
+	// 无可用许可时的最长等待时间（毫秒），0 表示不等待直接拒绝
+	MaxWaitMillis int64 `json:"max_wait_millis" yaml:"max-wait-millis"`
+}
+
+// Validate 校验配置，QPS 需大于 0
+func (config RateLimitConfig) Validate() error {
+	if !(config.QPS > 0) || math.IsInf(config.QPS, 1) {
+		return fmt.Errorf("idcard-sdk: rate limit qps must be positive, got %v", config.QPS)
+	}
+	if config.MaxWaitMillis < 0 {
+		return fmt.Errorf("idcard-sdk: rate limit max wait must not be negative, got %d", config.MaxWaitMillis)
+	}
+	return nil
+}
+
+// burst 生效的突发调用次数
+func (config RateLimitConfig) burst() float64 {
+	if config.Burst > 0 {
+		return float64(config.Burst)
+	}
+	return math.Max(math.Ceil(config.QPS), 1)
+}
+
+// Limiter 调用服务商前的限流器
+type Limiter interface {
+	// Wait 获取一次调用许可，需等待的时长超过最长等待时间时返回 ErrRateLimited，等待期间 ctx 结束时返回 ctx 的错误
+	Wait(ctx context.Context) error
+}
+
+// bucketState 单机令牌桶状态
+type bucketState struct {
+	// 剩余令牌数，为负表示已预约的等待中调用
+	Tokens float64 `json:"tokens"`
+	// 最后更新时间戳（毫秒），0 表示尚未使用，令牌桶为满
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// take 按经过的时间补充令牌后预约一个令牌，返回需等待的时长；等待超过上限时不预约并返回 false
+func (state *bucketState) take(config RateLimitConfig, now time.Time) (time.Duration, bool) {
+	burst := config.burst()
+	if state.UpdatedAt == 0 {
+		state.Tokens = burst
+	} else if elapsed := now.UnixMilli() - state.UpdatedAt; elapsed > 0 {
+		state.Tokens = math.Min(burst, state.Tokens+float64(elapsed)/1000*config.QPS)
+	}
+	state.UpdatedAt = now.UnixMilli()
+
+	tokens := state.Tokens - 1
+	var wait time.Duration
+	if tokens < 0 {
+		wait = time.Duration(-tokens / config.QPS * float64(time.Second))
+	}
+	if wait > time.Duration(config.MaxWaitMillis)*time.Millisecond {
+		return 0, false
+	}
+	state.Tokens = tokens
+	return wait, true
+}
+
+// sleepContext 等待指定时长，期间 ctx 结束时返回 ctx 的错误
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// memoryLimiter 单机令牌桶限流器
+type memoryLimiter struct {
+	config  RateLimitConfig
+	mu      sync.Mutex
+	state   bucketState
+	timeNow func() time.Time
+}
+
+// NewMemoryLimiter 创建单机令牌桶限流器，配置无效时返回错误
+func NewMemoryLimiter(config RateLimitConfig) (Limiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &memoryLimiter{config: config, timeNow: time.Now}, nil
+}
+
+func (limiter *memoryLimiter) Wait(ctx context.Context) error {
+	limiter.mu.Lock()
+	wait, ok := limiter.state.take(limiter.config, limiter.timeNow())
+	limiter.mu.Unlock()
+	if !ok {
+		return ErrRateLimited
+	}
+	return sleepContext(ctx, wait)
+}
+
+// storageLimiter 基于 global-storage 令牌桶的限流器，补充与预约令牌在一个 Lua 脚本中完成，多个节点使用同一个 key 时共享配额
+type storageLimiter struct {
+	bucket storage.TokenBucket
+	config RateLimitConfig
+}
+
+// NewStorageLimiter 创建多节点共享的令牌桶限流器，配置无效时返回错误
+// bucket: 共享配额的节点需使用同一个 key 创建，见 storage.StorageManager.NewTokenBucket
+func NewStorageLimiter(bucket storage.TokenBucket, config RateLimitConfig) (Limiter, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &storageLimiter{bucket: bucket, config: config}, nil
+}
+
+func (limiter *storageLimiter) Wait(ctx context.Context) error {
+	maxWait := time.Duration(limiter.config.MaxWaitMillis) * time.Millisecond
+	wait, ok, err := limiter.bucket.Take(ctx, limiter.config.QPS, limiter.config.burst(), maxWait)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrRateLimited
+	}
+	return sleepContext(ctx, wait)
+}
+
+// RateLimitedSDK 在调用服务商前先获取限流许可，防止登录高峰耗尽付费额度或触发服务商封禁
+type RateLimitedSDK struct {
+	sdk     IdCardSDK
+	limiter Limiter
+}
+
+// NewRateLimitedSDK 为服务商添加限流
+func NewRateLimitedSDK(sdk IdCardSDK, limiter Limiter) *RateLimitedSDK {
+	return &RateLimitedSDK{sdk: sdk, limiter: limiter}
+}
+
+// Name 服务商名称
+func (sdk *RateLimitedSDK) Name() string {
+	return providerName(sdk.sdk)
+}
+
+// Valid 获取限流许可后验证身份证与名字
+func (sdk *RateLimitedSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 获取限流许可后验证身份证与名字，被限流时返回 ErrRateLimited
+func (sdk *RateLimitedSDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	if err := sdk.limiter.Wait(ctx); err != nil {
+		return VerifyResult{Provider: sdk.Name(), Status: VerifyProviderError}, err
+	}
+	return validE(ctx, sdk.sdk, name, idNo)
+}
@@ -0,0 +1,91 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTokenBucket 基于内存实现的 storage.TokenBucket，补充与预约规则与 Lua 脚本一致
+type fakeTokenBucket struct {
+	mu      sync.Mutex
+	state   bucketState
+	timeNow func() time.Time
+}
+
+func (bucket *fakeTokenBucket) Take(ctx context.Context, rate, burst float64, maxWait time.Duration) (time.Duration, bool, error) {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	config := RateLimitConfig{QPS: rate, Burst: int(burst), MaxWaitMillis: maxWait.Milliseconds()}
+	wait, ok := bucket.state.take(config, bucket.timeNow())
+	return wait, ok, nil
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	ctx := context.Background()
+	config := RateLimitConfig{QPS: 1, Burst: 2}
+	now := time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+	timeNow := func() time.Time { return now }
+
+	limiter, err := NewMemoryLimiter(config)
+	if err != nil {
+		t.Fatalf("NewMemoryLimiter() error = %v", err)
+	}
+	memory := limiter.(*memoryLimiter)
+	memory.timeNow = timeNow
+	shared, err := NewStorageLimiter(&fakeTokenBucket{timeNow: timeNow}, config)
+	if err != nil {
+		t.Fatalf("NewStorageLimiter() error = %v", err)
+	}
+
+	for name, limiter := range map[string]Limiter{"单机": memory, "共享": shared} {
+		t.Run(name, func(t *testing.T) {
+			now = time.Date(2025, 3, 29, 20, 0, 0, 0, time.Local)
+			for i := 0; i < 2; i++ {
+				if err := limiter.Wait(ctx); err != nil {
+					t.Fatalf("Wait() burst %d error = %v", i, err)
+				}
+			}
+			if err := limiter.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+				t.Errorf("Wait() exhausted error = %v, want ErrRateLimited", err)
+			}
+			now = now.Add(time.Second)
+			if err := limiter.Wait(ctx); err != nil {
+				t.Errorf("Wait() after refill error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRateLimitConfig_Validate(t *testing.T) {
+	for _, config := range []RateLimitConfig{{QPS: 0}, {QPS: -1}, {QPS: 1, MaxWaitMillis: -1}} {
+		if _, err := NewMemoryLimiter(config); err == nil {
+			t.Errorf("NewMemoryLimiter(%+v) error = nil, want error", config)
+		}
+	}
+	err := InitIdCardSDK(Config{Providers: []string{ProviderMock}, RateLimits: map[string]RateLimitConfig{ProviderMock: {}}})
+	if err == nil {
+		t.Error("InitIdCardSDK() with zero qps error = nil, want error")
+	}
+}
+
+func TestRateLimitedSDK_ValidE(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := NewMemoryLimiter(RateLimitConfig{QPS: 1, Burst: 1})
+	if err != nil {
+		t.Fatalf("NewMemoryLimiter() error = %v", err)
+	}
+	primary := NewRateLimitedSDK(&fakeProvider{name: "primary", checkRes: true}, limiter)
+	fallback := &fakeProvider{name: "fallback", checkRes: true}
+	chain := NewChainSDK(primary, fallback)
+
+	if result, err := chain.ValidE(ctx, "张三", "110101199003070003"); err != nil || result.Provider != "primary" {
+		t.Errorf("ValidE() = %+v, %v, want primary", result, err)
+	}
+	// 主服务商被限流后切换到备用服务商
+	if result, err := chain.ValidE(ctx, "张三", "110101199003070003"); err != nil || result.Provider != "fallback" {
+		t.Errorf("ValidE() rate limited = %+v, %v, want fallback", result, err)
+	}
+}
@@ -0,0 +1,44 @@
+package idcard_sdk
+
+import (
+	"strings"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// MaskIdNo 身份证号脱敏，保留前 6 位地区码与后 2 位，其余替换为 *；长度不超过 8 时全部替换
+func MaskIdNo(idNo string) string {
+	return field.MaskIdNo(idNo)
+}
+
+// MaskName 姓名脱敏，仅保留第一个字作为姓氏，其余每个字替换为 *；单字姓名全部替换
+func MaskName(name string) string {
+	return field.MaskName(name)
+}
+
+// Masked 返回姓名与身份证号脱敏后的副本，用于日志与审计记录
+func (info IdInfo) Masked() IdInfo {
+	info.Name = MaskName(info.Name)
+	info.IdNo = MaskIdNo(info.IdNo)
+	return info
+}
+
+// maskPayload 将服务商响应中出现的名字与身份证号替换为脱敏后的值，用于 VerifyResult.Raw
+func maskPayload(payload []byte, name, idNo string) string {
+	pairs := make([]string, 0, 6)
+	if idNo != "" {
+		pairs = append(pairs, idNo, MaskIdNo(idNo))
+		if upper := strings.ToUpper(idNo); upper != idNo {
+			pairs = append(pairs, upper, MaskIdNo(upper))
+		}
+	}
+	if name != "" {
+		pairs = append(pairs, name, MaskName(name))
+	}
+	return strings.NewReplacer(pairs...).Replace(string(payload))
+}
+
+// identityFields 在 fields 前加上脱敏后的姓名与身份证号日志字段，日志中不得出现明文
+func identityFields(name, idNo string, fields ...field.Field) []field.Field {
+	return append([]field.Field{field.MaskedName(name), field.MaskedIdNo(idNo)}, fields...)
+}
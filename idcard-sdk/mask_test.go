@@ -0,0 +1,34 @@
+package idcard_sdk
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    IdInfo
+		wantName string
+		wantIdNo string
+	}{
+		{name: "常规姓名与身份证号", input: IdInfo{Name: "张三", IdNo: "110101199003070003"}, wantName: "张*", wantIdNo: "110101**********03"},
+		{name: "多字姓名", input: IdInfo{Name: "欧阳娜娜", IdNo: "11010119900307002X"}, wantName: "欧***", wantIdNo: "110101**********2X"},
+		{name: "单字与过短的号码全部替换", input: IdInfo{Name: "张", IdNo: "1101"}, wantName: "*", wantIdNo: "****"},
+		{name: "空值", input: IdInfo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.input.Masked()
+			if got.Name != tt.wantName || got.IdNo != tt.wantIdNo {
+				t.Errorf("Masked() = %q, %q, want %q, %q", got.Name, got.IdNo, tt.wantName, tt.wantIdNo)
+			}
+		})
+	}
+}
+
+func TestMaskPayload(t *testing.T) {
+	payload := []byte(`{"name":"张三","idNo":"11010119900307002X","respCode":"0000"}`)
+	want := `{"name":"张*","idNo":"110101**********2X","respCode":"0000"}`
+	if got := maskPayload(payload, "张三", "11010119900307002x"); got != want {
+		t.Errorf("maskPayload() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package idcard_sdk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProviderMock 内存模拟服务商名称，用于集成测试
+const ProviderMock = "mock"
+
+// 模拟身份可配置的错误
+const (
+	MockErrorUnavailable = "unavailable"
+	MockErrorQuota       = "quota"
+)
+
+func init() {
+	RegisterProvider(ProviderMock, func(config Config) (IdCardSDK, error) {
+		return NewMockIdCardSDK(config.MockConfig)
+	})
+}
+
+// MockIdentity 模拟服务商中预置的身份
+type MockIdentity struct {
+	Name string `json:"name" yaml:"name"`
+	IdNo string `json:"id_no" yaml:"id-no"`
+	// 模拟的错误，MockErrorUnavailable 或 MockErrorQuota，为空时按名字与身份证号比对
+	Error string `json:"error" yaml:"error"`
+}
+
+// MockConfig 模拟服务商配置
+type MockConfig struct {
+	// 未预置的身份是否通过，通过时身份信息由身份证号离线解析
+	DefaultPass bool `json:"default_pass" yaml:"default-pass"`
+	// 预置的身份，身份证号已预置但名字不同时视为不匹配
+	Identities []MockIdentity `json:"identities" yaml:"identities"`
+	// 每次调用的模拟延迟（毫秒）
+	LatencyMillis int64 `json:"latency_millis" yaml:"latency-millis"`
+}
+
+// Validate 校验模拟服务商配置
+func (config MockConfig) Validate() error {
+	for _, identity := range config.Identities {
+		switch identity.Error {
+		case "", MockErrorUnavailable, MockErrorQuota:
+		default:
+			return fmt.Errorf("idcard-sdk: invalid mock error %q for %s", identity.Error, identity.IdNo)
+		}
+	}
+	return nil
+}
+
+// MockIdCardSDK 确定性的内存模拟服务商，不发起网络请求，可在运行时调整规则，并发安全
+type MockIdCardSDK struct {
+	mu         sync.RWMutex
+	config     MockConfig
+	identities map[string]MockIdentity
+	err        error
+	rule       func(name, idNo string) (IdInfo, error)
+}
+
+// NewMockIdCardSDK 创建模拟服务商
+func NewMockIdCardSDK(config MockConfig) (*MockIdCardSDK, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	identities := make(map[string]MockIdentity, len(config.Identities))
+	for _, identity := range config.Identities {
+		identities[identity.IdNo] = identity
+	}
+	return &MockIdCardSDK{config: config, identities: identities}, nil
+}
+
+// InitMockIdCardSDK 以模拟服务商初始化 GetIdCardSDK 返回的全局实例，用于不调用付费接口的集成测试
+func InitMockIdCardSDK(config MockConfig) (*MockIdCardSDK, error) {
+	sdk, err := NewMockIdCardSDK(config)
+	if err != nil {
+		return nil, err
+	}
+	idCardSDKInstance = sdk
+	return sdk, nil
+}
+
+// Name 服务商名称
+func (sdk *MockIdCardSDK) Name() string {
+	return ProviderMock
+}
+
+// AddIdentity 预置或替换身份
+func (sdk *MockIdCardSDK) AddIdentity(identity MockIdentity) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	sdk.identities[identity.IdNo] = identity
+}
+
+// SetError 设置所有调用返回的错误，如 ErrProviderUnavailable，为 nil 时恢复正常
+func (sdk *MockIdCardSDK) SetError(err error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	sdk.err = err
+}
+
+// SetLatency 设置每次调用的模拟延迟
+func (sdk *MockIdCardSDK) SetLatency(latency time.Duration) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	sdk.config.LatencyMillis = latency.Milliseconds()
+}
+
+// SetRule 设置自定义比对规则，优先于预置身份与 DefaultPass，为 nil 时恢复默认规则
+func (sdk *MockIdCardSDK) SetRule(rule func(name, idNo string) (IdInfo, error)) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+	sdk.rule = rule
+}
+
+// Valid 按模拟规则验证身份证与名字
+func (sdk *MockIdCardSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 按模拟规则验证身份证与名字，模拟延迟期间 ctx 结束时返回 ctx 的错误
+func (sdk *MockIdCardSDK) ValidE(ctx context.Context, name, idNo string) (result VerifyResult, err error) {
+	defer func() { result.Status = VerifyStatusOf(err) }()
+	result = VerifyResult{Provider: ProviderMock}
+	sdk.mu.RLock()
+	latency := time.Duration(sdk.config.LatencyMillis) * time.Millisecond
+	err, rule := sdk.err, sdk.rule
+	identity, ok := sdk.identities[idNo]
+	defaultPass := sdk.config.DefaultPass
+	sdk.mu.RUnlock()
+
+	if err := sleepContext(ctx, latency); err != nil {
+		return result, err
+	}
+	if err != nil {
+		return result, err
+	}
+	if rule != nil {
+		result.Info, err = rule(name, idNo)
+		return result, err
+	}
+	switch {
+	case ok && identity.Error == MockErrorUnavailable:
+		return result, fmt.Errorf("%w: mock", ErrProviderUnavailable)
+	case ok && identity.Error == MockErrorQuota:
+		return result, fmt.Errorf("%w: mock", ErrQuotaExceeded)
+	case ok && identity.Name != name, !ok && !defaultPass:
+		return result, ErrMismatch
+	}
+
+	preInfo, err := PrevalidateIdNo(idNo)
+	if err != nil {
+		return result, ErrMismatch
+	}
+	result.Info = IdInfo{
+		Name:     name,
+		IdNo:     preInfo.IdNo,
+		Province: preInfo.Province,
+		Birthday: preInfo.Birthday,
+		Sex:      preInfo.Sex,
+		Age:      preInfo.Age,
+	}
+	return result, nil
+}
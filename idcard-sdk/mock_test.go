@@ -0,0 +1,68 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMockIdCardSDK_ValidE(t *testing.T) {
+	config := MockConfig{
+		Identities: []MockIdentity{
+			{Name: "张三", IdNo: "110101199003070003"},
+			{Name: "李四", IdNo: "110101199003070011", Error: MockErrorQuota},
+		},
+	}
+	sdk, err := NewMockIdCardSDK(config)
+	if err != nil {
+		t.Fatalf("NewMockIdCardSDK() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		idName       string
+		idNo         string
+		wantErr      error
+		wantProvince string
+	}{
+		{name: "预置身份通过", idName: "张三", idNo: "110101199003070003", wantProvince: "北京"},
+		{name: "名字不一致", idName: "王五", idNo: "110101199003070003", wantErr: ErrMismatch},
+		{name: "预置错误", idName: "李四", idNo: "110101199003070011", wantErr: ErrQuotaExceeded},
+		{name: "未预置默认不通过", idName: "王五", idNo: "440301201001011234", wantErr: ErrMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sdk.ValidE(context.Background(), tt.idName, tt.idNo)
+			if !errors.Is(err, tt.wantErr) || result.Info.Province != tt.wantProvince {
+				t.Errorf("ValidE() = %+v, %v, want province %q, %v", result, err, tt.wantProvince, tt.wantErr)
+			}
+		})
+	}
+
+	sdk.SetError(ErrProviderUnavailable)
+	if _, err = sdk.ValidE(context.Background(), "张三", "110101199003070003"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("ValidE() injected error = %v, want ErrProviderUnavailable", err)
+	}
+
+	if _, err = NewMockIdCardSDK(MockConfig{Identities: []MockIdentity{{IdNo: "1", Error: "boom"}}}); err == nil {
+		t.Errorf("NewMockIdCardSDK() invalid error = nil, want error")
+	}
+}
+
+func TestInitIdCardSDK_Mock(t *testing.T) {
+	err := InitIdCardSDK(Config{
+		Providers:  []string{ProviderMock},
+		MockConfig: MockConfig{DefaultPass: true},
+	})
+	if err != nil {
+		t.Fatalf("InitIdCardSDK() error = %v", err)
+	}
+	checkRes, info := GetIdCardSDK().Valid(context.Background(), "王五", "440301201001011234")
+	if !checkRes || info.Province != "广东" {
+		t.Errorf("Valid() = %v, %+v, want pass in 广东", checkRes, info)
+	}
+
+	if err = InitIdCardSDK(Config{Providers: []string{"unknown"}}); err == nil {
+		t.Errorf("InitIdCardSDK() unknown provider error = nil, want error")
+	}
+}
@@ -0,0 +1,65 @@
+package idcard_sdk
+
+import (
+	"strings"
+	"unicode"
+)
+
+// nameMiddleDot 公安户籍中少数民族姓名使用的间隔号
+const nameMiddleDot = '·'
+
+// middleDotVariants 输入法或排版中常见的间隔号变体
+var middleDotVariants = map[rune]struct{}{
+	'.':      {},
+	'\u0387': {}, // 希腊文上点
+	'\u2022': {}, // 项目符号
+	'\u2027': {}, // 连字点
+	'\u2219': {}, // 项目符号运算符
+	'\u22C5': {}, // 点运算符
+	'\u2E31': {}, // 词间点
+	'\u30FB': {}, // 片假名中点
+	'\uFF0E': {}, // 全角句点
+	'\uFF65': {}, // 半角片假名中点
+}
+
+// traditionalChars 姓名常用字的繁体到简体映射，只覆盖常见姓氏与名字用字，并非完整的繁简转换
+var traditionalChars = map[rune]rune{
+	'張': '张', '陳': '陈', '劉': '刘', '黃': '黄', '楊': '杨', '趙': '赵', '吳': '吴', '孫': '孙',
+	'馬': '马', '羅': '罗', '鄭': '郑', '謝': '谢', '韓': '韩', '馮': '冯', '鄧': '邓', '許': '许',
+	'蘇': '苏', '蔣': '蒋', '葉': '叶', '盧': '卢', '呂': '吕', '賈': '贾', '陸': '陆', '鐘': '钟',
+	'龍': '龙', '萬': '万', '錢': '钱', '嚴': '严', '顧': '顾', '華': '华', '湯': '汤', '譚': '谭',
+	'閻': '阎', '範': '范', '鄒': '邹', '龔': '龚', '喬': '乔',
+	'偉': '伟', '國': '国', '東': '东', '軍': '军', '麗': '丽', '紅': '红', '強': '强', '傑': '杰',
+	'鳳': '凤', '蘭': '兰', '雲': '云', '輝': '辉', '嬌': '娇', '寶': '宝', '慶': '庆', '鵬': '鹏',
+	'貴': '贵', '飛': '飞', '鳴': '鸣', '義': '义', '榮': '荣', '誠': '诚', '學': '学', '聖': '圣',
+	'愛': '爱', '靜': '静', '儀': '仪', '潔': '洁', '瑩': '莹', '開': '开', '亞': '亚', '興': '兴',
+	'歡': '欢', '樂': '乐', '藝': '艺', '時': '时', '書': '书', '長': '长', '達': '达', '進': '进',
+	'曉': '晓', '婭': '娅', '陽': '阳', '濤': '涛', '鋒': '锋', '剛': '刚', '廣': '广', '龐': '庞',
+	'綠': '绿', '順': '顺', '穎': '颖', '嬋': '婵', '滿': '满', '倫': '伦', '傳': '传',
+}
+
+// NormalizeName 规范化姓名以减少因输入差异导致的误判：去除所有空白（包括全角空格）、全角字母数字转半角、
+// 间隔号变体统一为"·"并去除首尾多余的间隔号、姓名常用字的繁体转简体
+func NormalizeName(name string) string {
+	var builder strings.Builder
+	builder.Grow(len(name))
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r >= '！' && r <= '～':
+			// 全角 ASCII 字符转半角，全角句点按间隔号处理
+			if r != '．' {
+				r -= 0xFEE0
+			}
+		}
+		if _, ok := middleDotVariants[r]; ok {
+			r = nameMiddleDot
+		}
+		if simplified, ok := traditionalChars[r]; ok {
+			r = simplified
+		}
+		builder.WriteRune(r)
+	}
+	return strings.Trim(builder.String(), string(nameMiddleDot))
+}
@@ -0,0 +1,37 @@
+package idcard_sdk
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "去除空白", input: " 张 三　", want: "张三"},
+		{name: "全角字母转半角", input: "ＡＢＣ", want: "ABC"},
+		{name: "间隔号变体", input: "买买提・艾力", want: "买买提·艾力"},
+		{name: "全角句点与英文句点", input: "阿依．古丽.娜", want: "阿依·古丽·娜"},
+		{name: "去除首尾间隔号", input: "·热依拉·", want: "热依拉"},
+		{name: "繁体转简体", input: "張偉", want: "张伟"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.input); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainSDK_NormalizeName(t *testing.T) {
+	mock, _ := NewMockIdCardSDK(MockConfig{Identities: []MockIdentity{{Name: "张伟", IdNo: "110101199003070003"}}})
+	result, err := NewChainSDK(mock).ValidE(context.Background(), " 張偉 ", "110101199003070003")
+	if err != nil || result.InputName != " 張偉 " || result.NormalizedName != "张伟" {
+		t.Errorf("ValidE() = %+v, %v, want normalized match", result, err)
+	}
+}
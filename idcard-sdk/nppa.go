@@ -0,0 +1,407 @@
+package idcard_sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ProviderNPPA 国家新闻出版署网络游戏防沉迷实名认证系统服务商名称
+const ProviderNPPA = "nppa"
+
+const (
+	defaultNPPACheckUrl    = "https://api.wlc.nppa.gov.cn/idcard/authentication/check"
+	defaultNPPAQueryUrl    = "http://api2.wlc.nppa.gov.cn/idcard/authentication/query"
+	defaultNPPALoginOutUrl = "http://api2.wlc.nppa.gov.cn/behavior/collection/loginout"
+)
+
+const (
+	// nppaCodeSysError 系统错误，可重试
+	nppaCodeSysError = 1001
+	// nppaCodeIdNumIllegal 身份证号格式校验失败
+	nppaCodeIdNumIllegal = 2001
+	// nppaCodeResourceLimit 实名认证条目已达上限
+	nppaCodeResourceLimit = 2002
+)
+
+// ErrNPPAProcessing 认证中，需稍后通过 Query 查询结果
+var ErrNPPAProcessing = fmt.Errorf("%w: nppa authentication processing", ErrVerificationPending)
+
+func init() {
+	RegisterProvider(ProviderNPPA, func(config Config) (IdCardSDK, error) {
+		nppaConfig := config.NPPAConfig
+		if !nppaConfig.Sandbox {
+			return NewNPPAIdCardSDK(nppaConfig)
+		}
+		if nppaConfig.SandboxCheckUrl == "" {
+			return newProviderSandbox(ProviderNPPA, nil, false, config)
+		}
+		if nppaConfig.SandboxQueryUrl == "" || nppaConfig.SandboxLoginOutUrl == "" {
+			return nil, errors.New("idcard-sdk: nppa sandbox query and loginout urls required")
+		}
+		nppaConfig.CheckUrl = nppaConfig.SandboxCheckUrl
+		nppaConfig.QueryUrl = nppaConfig.SandboxQueryUrl
+		nppaConfig.LoginOutUrl = nppaConfig.SandboxLoginOutUrl
+		sdk, err := NewNPPAIdCardSDK(nppaConfig)
+		if err != nil {
+			return nil, err
+		}
+		return newProviderSandbox(ProviderNPPA, sdk, true, config)
+	})
+}
+
+type NPPAConfig struct {
+	AppId string `json:"app_id" yaml:"app-id"`
+	BizId string `json:"biz_id" yaml:"biz-id"`
+	// 16进制编码的密钥，用于 AES-128-GCM 加密与签名
+	SecretKey string `json:"secret_key" yaml:"secret-key"`
+	// 各接口地址，为空时使用官方地址
+	CheckUrl    string `json:"check_url" yaml:"check-url"`
+	QueryUrl    string `json:"query_url" yaml:"query-url"`
+	LoginOutUrl string `json:"login_out_url" yaml:"login-out-url"`
+	// HTTP 客户端配置
+	HTTP HTTPConfig `json:"http" yaml:"http"`
+	// 沙箱模式，通过 InitIdCardSDK 创建时生效：预置的测试身份本地应答，其他身份发送到测试地址；
+	// 未配置测试地址时视为不匹配，配置了 SandboxCheckUrl 时三个测试地址均须配置
+	Sandbox            bool   `json:"sandbox" yaml:"sandbox"`
+	SandboxCheckUrl    string `json:"sandbox_check_url" yaml:"sandbox-check-url"`
+	SandboxQueryUrl    string `json:"sandbox_query_url" yaml:"sandbox-query-url"`
+	SandboxLoginOutUrl string `json:"sandbox_login_out_url" yaml:"sandbox-login-out-url"`
+}
+
+// NPPAAuthStatus 实名认证结果状态
+type NPPAAuthStatus int
+
+const (
+	// NPPAAuthSuccess 认证成功
+	NPPAAuthSuccess NPPAAuthStatus = 0
+	// NPPAAuthProcessing 认证中
+	NPPAAuthProcessing NPPAAuthStatus = 1
+	// NPPAAuthFailed 认证失败
+	NPPAAuthFailed NPPAAuthStatus = 2
+)
+
+// NPPAResult 实名认证结果
+type NPPAResult struct {
+	Status NPPAAuthStatus `json:"status"`
+	// 出版署分配的用户唯一标识，认证成功时返回，上报游戏行为时使用
+	PI string `json:"pi"`
+}
+
+// NPPABehaviorType 游戏用户行为类型
+type NPPABehaviorType int
+
+const (
+	// NPPABehaviorLogout 下线
+	NPPABehaviorLogout NPPABehaviorType = 0
+	// NPPABehaviorLogin 上线
+	NPPABehaviorLogin NPPABehaviorType = 1
+)
+
+// NPPAUserType 上报行为的用户类型
+type NPPAUserType int
+
+const (
+	// NPPAUserCertified 已认证用户
+	NPPAUserCertified NPPAUserType = 0
+	// NPPAUserGuest 游客用户
+	NPPAUserGuest NPPAUserType = 2
+)
+
+// NPPABehavior 一条游戏用户上下线行为
+type NPPABehavior struct {
+	// 批量上报中的条目编码，从 1 开始
+	No int `json:"no"`
+	// 游戏内部会话标识
+	SessionId string           `json:"si"`
+	Behavior  NPPABehaviorType `json:"bt"`
+	// 行为发生时间戳（秒）
+	OccurredAt int64        `json:"ot"`
+	UserType   NPPAUserType `json:"ct"`
+	// 游客模式设备标识，游客用户必填
+	DeviceId string `json:"di,omitempty"`
+	// 已认证用户的 PI，已认证用户必填
+	PI string `json:"pi,omitempty"`
+}
+
+// NPPAError 出版署接口返回的业务错误
+type NPPAError struct {
+	Code    int
+	Message string
+}
+
+func (err *NPPAError) Error() string {
+	return fmt.Sprintf("idcard-sdk: nppa errcode %d: %s", err.Code, err.Message)
+}
+
+// ErrorCode 出版署错误码，日志中通过 field.WithErrorCode 输出
+func (err *NPPAError) ErrorCode() string {
+	return strconv.Itoa(err.Code)
+}
+
+// NPPAIdCardSDK 国家新闻出版署网络游戏防沉迷实名认证系统，请求体使用 AES-128-GCM 加密并按规范签名
+type NPPAIdCardSDK struct {
+	config  NPPAConfig
+	key     []byte
+	signer  *Signer
+	client  *http.Client
+	timeNow func() time.Time
+}
+
+// NewNPPAIdCardSDK 创建出版署实名认证实例，密钥不是合法的16进制 AES 密钥或 HTTP 配置非法时返回错误
+func NewNPPAIdCardSDK(config NPPAConfig) (*NPPAIdCardSDK, error) {
+	key, err := hex.DecodeString(config.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("idcard-sdk: invalid nppa secret key: %w", err)
+	}
+	if _, err = aes.NewCipher(key); err != nil {
+		return nil, fmt.Errorf("idcard-sdk: invalid nppa secret key: %w", err)
+	}
+	client, err := config.HTTP.NewHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	if config.CheckUrl == "" {
+		config.CheckUrl = defaultNPPACheckUrl
+	}
+	if config.QueryUrl == "" {
+		config.QueryUrl = defaultNPPAQueryUrl
+	}
+	if config.LoginOutUrl == "" {
+		config.LoginOutUrl = defaultNPPALoginOutUrl
+	}
+	signer, err := NewNPPASigner(config.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &NPPAIdCardSDK{config: config, key: key, signer: signer, client: client, timeNow: time.Now}, nil
+}
+
+// NewNPPASigner 按出版署接口规范创建签名器：密钥 + 按参数名排序的系统参数与查询参数的名值拼接 + 请求体，
+// 取 SHA256 的16进制，写入 sign 请求头。需要自行校验或发送出版署请求时使用，如模拟出版署接口的测试
+func NewNPPASigner(secretKey string) (*Signer, error) {
+	return NewSigner(SignerConfig{
+		Algorithm:     SignSHA256,
+		Secret:        secretKey,
+		SignedHeaders: []string{"appId", "bizId", "timestamps"},
+	})
+}
+
+// Name 服务商名称
+func (sdk *NPPAIdCardSDK) Name() string {
+	return ProviderNPPA
+}
+
+// Valid 通过出版署实名认证系统验证身份证与名字
+func (sdk *NPPAIdCardSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 通过出版署实名认证系统验证身份证与名字，以身份证号的 MD5 作为 ai；
+// 需要 PI 或按账号上报时请使用 Check。认证失败返回 ErrMismatch，认证中返回 ErrNPPAProcessing
+func (sdk *NPPAIdCardSDK) ValidE(ctx context.Context, name, idNo string) (result VerifyResult, err error) {
+	defer func() { result.Status = VerifyStatusOf(err) }()
+	result = VerifyResult{Provider: ProviderNPPA}
+	nppaResult, err := sdk.Check(ctx, nppaAi(idNo), name, idNo)
+	if err != nil {
+		zaplogger.DefaultLogger().Error("NPPAIdCardSDK Valid in Check", identityFields(name, idNo, field.WithError(err))...)
+		return result, err
+	}
+	// 响应已解密，仅含 ai 对应的认证状态与 pi，不含名字与身份证号
+	if raw, marshalErr := json.Marshal(nppaResult); marshalErr == nil {
+		result.Raw = string(raw)
+	}
+	if err = nppaStatusError(nppaResult.Status); err != nil {
+		return result, err
+	}
+	result.Info = IdInfo{Name: name, IdNo: idNo}
+	return result, nil
+}
+
+// nppaAi 以身份证号的 MD5 作为 ai
+func nppaAi(idNo string) string {
+	sum := md5.Sum([]byte(idNo))
+	return hex.EncodeToString(sum[:])
+}
+
+// nppaStatusError 认证状态转换为错误：成功为 nil，认证中为 ErrNPPAProcessing，其他为 ErrMismatch
+func nppaStatusError(status NPPAAuthStatus) error {
+	switch status {
+	case NPPAAuthSuccess:
+		return nil
+	case NPPAAuthProcessing:
+		return ErrNPPAProcessing
+	}
+	return ErrMismatch
+}
+
+// Check 实名认证
+// ai: 游戏内部成员标识，32位，同一账号须保持不变
+func (sdk *NPPAIdCardSDK) Check(ctx context.Context, ai, name, idNo string) (NPPAResult, error) {
+	body, err := sdk.encryptBody(map[string]string{"ai": ai, "name": name, "idNum": idNo})
+	if err != nil {
+		return NPPAResult{}, err
+	}
+	var data struct {
+		Result NPPAResult `json:"result"`
+	}
+	err = sdk.do(ctx, "POST", sdk.config.CheckUrl, nil, body, &data)
+	return data.Result, err
+}
+
+// Query 查询实名认证结果，用于 Check 返回认证中之后
+func (sdk *NPPAIdCardSDK) Query(ctx context.Context, ai string) (NPPAResult, error) {
+	var data struct {
+		Result NPPAResult `json:"result"`
+	}
+	err := sdk.do(ctx, "GET", sdk.config.QueryUrl, url.Values{"ai": []string{ai}}, nil, &data)
+	return data.Result, err
+}
+
+// LoginOut 上报游戏用户上下线行为，单次最多128条
+func (sdk *NPPAIdCardSDK) LoginOut(ctx context.Context, behaviors []NPPABehavior) error {
+	body, err := sdk.encryptBody(map[string][]NPPABehavior{"collections": behaviors})
+	if err != nil {
+		return err
+	}
+	var data struct {
+		Results []struct {
+			No      int    `json:"no"`
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		} `json:"results"`
+	}
+	if err = sdk.do(ctx, "POST", sdk.config.LoginOutUrl, nil, body, &data); err != nil {
+		return err
+	}
+	for _, result := range data.Results {
+		if result.ErrCode != 0 {
+			return fmt.Errorf("idcard-sdk: nppa loginout no %d: %w", result.No, &NPPAError{Code: result.ErrCode, Message: result.ErrMsg})
+		}
+	}
+	return nil
+}
+
+// encryptBody 将请求参数序列化后以 AES-128-GCM 加密，返回 {"data":"base64(iv+密文+tag)"}
+func (sdk *NPPAIdCardSDK) encryptBody(payload any) ([]byte, error) {
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sdk.key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	return json.Marshal(map[string]string{"data": base64.StdEncoding.EncodeToString(sealed)})
+}
+
+// do 发送签名请求并解析响应中的 data，网络错误、服务端 5xx 或系统错误返回 ErrProviderUnavailable
+func (sdk *NPPAIdCardSDK) do(ctx context.Context, method, reqUrl string, query url.Values, body []byte, v any) error {
+	timestamps := strconv.FormatInt(sdk.timeNow().UnixMilli(), 10)
+	if len(query) > 0 {
+		reqUrl += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json;charset=utf-8")
+	req.Header.Set("appId", sdk.config.AppId)
+	req.Header.Set("bizId", sdk.config.BizId)
+	req.Header.Set("timestamps", timestamps)
+	sdk.signer.SignRequest(req, body)
+	resp, err := sdk.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: http status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+
+	var data struct {
+		ErrCode int             `json:"errcode"`
+		ErrMsg  string          `json:"errmsg"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(respBody, &data); err != nil {
+		return err
+	}
+	switch {
+	case data.ErrCode == nppaCodeSysError:
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, &NPPAError{Code: data.ErrCode, Message: data.ErrMsg})
+	case data.ErrCode == nppaCodeIdNumIllegal:
+		return fmt.Errorf("%w: %w", ErrInvalidParam, &NPPAError{Code: data.ErrCode, Message: data.ErrMsg})
+	case data.ErrCode == nppaCodeResourceLimit:
+		return fmt.Errorf("%w: %w", ErrQuotaExceeded, &NPPAError{Code: data.ErrCode, Message: data.ErrMsg})
+	case data.ErrCode != 0:
+		return &NPPAError{Code: data.ErrCode, Message: data.ErrMsg}
+	case len(data.Data) == 0:
+		return nil
+	}
+	return json.Unmarshal(data.Data, v)
+}
+
+// NPPADeferredSDK 出版署实名认证的两步式验证，回执编号即 ai，不在本地保存状态，可跨进程查询
+type NPPADeferredSDK struct {
+	sdk *NPPAIdCardSDK
+}
+
+// NewNPPADeferredSDK 创建出版署两步式验证
+func NewNPPADeferredSDK(sdk *NPPAIdCardSDK) *NPPADeferredSDK {
+	return &NPPADeferredSDK{sdk: sdk}
+}
+
+// Submit 提交实名认证，认证失败时返回 ErrMismatch，认证成功或认证中均返回回执
+func (deferred *NPPADeferredSDK) Submit(ctx context.Context, name, idNo string) (Receipt, error) {
+	ai := nppaAi(idNo)
+	nppaResult, err := deferred.sdk.Check(ctx, ai, name, idNo)
+	if err != nil {
+		zaplogger.DefaultLogger().Error("NPPADeferredSDK Submit in Check", identityFields(name, idNo, field.WithError(err))...)
+		return Receipt{}, err
+	}
+	if err = nppaStatusError(nppaResult.Status); err != nil && !errors.Is(err, ErrNPPAProcessing) {
+		return Receipt{}, err
+	}
+	return Receipt{Id: ai, Provider: ProviderNPPA, SubmittedAt: deferred.sdk.timeNow()}, nil
+}
+
+// Query 凭回执查询认证结果，认证中返回 ErrNPPAProcessing（同时匹配 ErrVerificationPending）；
+// 查询接口不返回姓名与身份证号，成功时 Info 为空
+func (deferred *NPPADeferredSDK) Query(ctx context.Context, receipt Receipt) (VerifyResult, error) {
+	result := VerifyResult{Provider: ProviderNPPA}
+	nppaResult, err := deferred.sdk.Query(ctx, receipt.Id)
+	if err != nil {
+		return result, err
+	}
+	return result, nppaStatusError(nppaResult.Status)
+}
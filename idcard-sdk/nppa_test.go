@@ -0,0 +1,113 @@
+package idcard_sdk
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const testNPPASecretKey = "2836e95fcd10e04b0069bb1ee659955b"
+
+// decryptNPPABody 按出版署规范解密请求体
+func decryptNPPABody(t *testing.T, sdk *NPPAIdCardSDK, body []byte) map[string]string {
+	var wrapper struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil {
+		t.Fatalf("unmarshal body error = %v", err)
+	}
+	sealed, _ := base64.StdEncoding.DecodeString(wrapper.Data)
+	block, _ := aes.NewCipher(sdk.key)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		t.Fatalf("decrypt body error = %v", err)
+	}
+	var payload map[string]string
+	_ = json.Unmarshal(plain, &payload)
+	return payload
+}
+
+func TestNPPAIdCardSDK(t *testing.T) {
+	var sdk *NPPAIdCardSDK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		params := url.Values{}
+		for key, values := range r.URL.Query() {
+			params[key] = values
+		}
+		params.Set("appId", r.Header.Get("appId"))
+		params.Set("bizId", r.Header.Get("bizId"))
+		params.Set("timestamps", r.Header.Get("timestamps"))
+		if r.Header.Get("sign") != sdk.signer.Sign(params, body) {
+			_, _ = w.Write([]byte(`{"errcode":1007,"errmsg":"SYS REQ SIGN ERROR"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/check":
+			payload := decryptNPPABody(t, sdk, body)
+			if payload["idNum"] == "110101199003070000" {
+				_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"OK","data":{"result":{"status":0,"pi":"1fffbjzos82bs9cnyj1dna7d6d29zg4esnh99u"}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"OK","data":{"result":{"status":1}}}`))
+		case "/query":
+			_, _ = w.Write([]byte(`{"errcode":1001,"errmsg":"SYS ERROR"}`))
+		case "/loginout":
+			_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"OK","data":{"results":[{"no":1,"errcode":4002,"errmsg":"BUS COLLECT PI ILLEGAL"}]}}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	var err error
+	sdk, err = NewNPPAIdCardSDK(NPPAConfig{
+		AppId:       "app",
+		BizId:       "1101999999",
+		SecretKey:   testNPPASecretKey,
+		CheckUrl:    server.URL + "/check",
+		QueryUrl:    server.URL + "/query",
+		LoginOutUrl: server.URL + "/loginout",
+	})
+	if err != nil {
+		t.Fatalf("NewNPPAIdCardSDK() error = %v", err)
+	}
+
+	result, err := sdk.Check(ctx, "100000000000000001", "张三", "110101199003070000")
+	if err != nil || result.Status != NPPAAuthSuccess || result.PI == "" {
+		t.Errorf("Check() = %+v, %v, want success with pi", result, err)
+	}
+	if _, err := sdk.ValidE(ctx, "李四", "110101199003070001"); !errors.Is(err, ErrNPPAProcessing) {
+		t.Errorf("ValidE() processing error = %v, want ErrNPPAProcessing", err)
+	}
+	if _, err = sdk.Query(ctx, "100000000000000001"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Query() error = %v, want ErrProviderUnavailable", err)
+	}
+	deferred := NewNPPADeferredSDK(sdk)
+	receipt, err := deferred.Submit(ctx, "李四", "110101199003070001")
+	if err != nil || receipt.Id != nppaAi("110101199003070001") {
+		t.Errorf("NPPADeferredSDK Submit() = %+v, %v, want receipt with ai", receipt, err)
+	}
+	if _, err = deferred.Query(ctx, receipt); !errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("NPPADeferredSDK Query() error = %v, want ErrProviderUnavailable", err)
+	}
+	var nppaErr *NPPAError
+	err = sdk.LoginOut(ctx, []NPPABehavior{{No: 1, SessionId: "s1", Behavior: NPPABehaviorLogin, OccurredAt: time.Now().Unix(), PI: "bad"}})
+	if !errors.As(err, &nppaErr) || nppaErr.Code != 4002 {
+		t.Errorf("LoginOut() error = %v, want errcode 4002", err)
+	}
+
+	if _, err = NewNPPAIdCardSDK(NPPAConfig{SecretKey: "not-hex"}); err == nil {
+		t.Errorf("NewNPPAIdCardSDK() invalid key error = nil, want error")
+	}
+}
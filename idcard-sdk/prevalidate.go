@@ -0,0 +1,131 @@
+package idcard_sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidIdNo 身份证号格式、校验码、地区码或出生日期不合法
+var ErrInvalidIdNo = errors.New("idcard-sdk: invalid id number")
+
+// minBirthYear 可信的最早出生年份
+const minBirthYear = 1900
+
+// idNoWeights 18位身份证号前17位的加权因子
+var idNoWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// idNoCheckCodes 加权和对 11 取模后对应的校验码
+const idNoCheckCodes = "10X98765432"
+
+// regionCodes 身份证号前两位的省级行政区划代码
+var regionCodes = map[string]string{
+	"11": "北京", "12": "天津", "13": "河北", "14": "山西", "15": "内蒙古",
+	"21": "辽宁", "22": "吉林", "23": "黑龙江",
+	"31": "上海", "32": "江苏", "33": "浙江", "34": "安徽", "35": "福建", "36": "江西", "37": "山东",
+	"41": "河南", "42": "湖北", "43": "湖南", "44": "广东", "45": "广西", "46": "海南",
+	"50": "重庆", "51": "四川", "52": "贵州", "53": "云南", "54": "西藏",
+	"61": "陕西", "62": "甘肃", "63": "青海", "64": "宁夏", "65": "新疆",
+	"71": "台湾", "81": "香港", "82": "澳门", "83": "台湾",
+}
+
+const (
+	SexMale   = "男"
+	SexFemale = "女"
+)
+
+// PreInfo 从身份证号本身解析出的信息，未经过远程核验
+type PreInfo struct {
+	// 规范化后的身份证号，末位校验码 x 转为大写
+	IdNo     string
+	Province string
+	Birthday time.Time
+	// 第 17 位顺序码奇数为 SexMale，偶数为 SexFemale
+	Sex string
+	Age int32
+}
+
+// PrevalidateIdNo 离线校验18位身份证号：校验码、省级地区码与出生日期，不发起网络请求，
+// 用于在调用付费接口前拦截明显非法的输入
+// 返回值：不合法时返回包装了 ErrInvalidIdNo 的错误
+func PrevalidateIdNo(idNo string) (PreInfo, error) {
+	return prevalidateIdNoAt(idNo, time.Now())
+}
+
+// prevalidateIdNoAt 按指定时刻计算年龄并校验出生日期不晚于该时刻
+func prevalidateIdNoAt(idNo string, now time.Time) (PreInfo, error) {
+	idNo = strings.ToUpper(strings.TrimSpace(idNo))
+	if len(idNo) != 18 {
+		return PreInfo{}, fmt.Errorf("%w: length %d, want 18", ErrInvalidIdNo, len(idNo))
+	}
+	sum := 0
+	for i, weight := range idNoWeights {
+		digit := idNo[i]
+		if digit < '0' || digit > '9' {
+			return PreInfo{}, fmt.Errorf("%w: non-digit at position %d", ErrInvalidIdNo, i+1)
+		}
+		sum += int(digit-'0') * weight
+	}
+	if want := idNoCheckCodes[sum%11]; idNo[17] != want {
+		return PreInfo{}, fmt.Errorf("%w: check code %c, want %c", ErrInvalidIdNo, idNo[17], want)
+	}
+
+	province, ok := regionCodes[idNo[:2]]
+	if !ok {
+		return PreInfo{}, fmt.Errorf("%w: unknown region code %s", ErrInvalidIdNo, idNo[:6])
+	}
+	birthday, err := time.ParseInLocation("20060102", idNo[6:14], now.Location())
+	if err != nil {
+		return PreInfo{}, fmt.Errorf("%w: invalid birthday %s", ErrInvalidIdNo, idNo[6:14])
+	}
+	if birthday.Year() < minBirthYear || birthday.After(now) {
+		return PreInfo{}, fmt.Errorf("%w: implausible birthday %s", ErrInvalidIdNo, idNo[6:14])
+	}
+
+	return PreInfo{
+		IdNo:     idNo,
+		Province: province,
+		Birthday: birthday,
+		Sex:      sexOf(idNo),
+		Age:      ageAt(birthday, now),
+	}, nil
+}
+
+// ExtractBirthdayAndAge 从身份证号离线解析出生日期与在 at 时刻的周岁年龄，不依赖远程核验，
+// 也可用于核对服务商返回的出生日期与年龄
+// 返回值：身份证号不合法或出生日期晚于 at 时返回包装了 ErrInvalidIdNo 的错误
+func ExtractBirthdayAndAge(idNo string, at time.Time) (time.Time, int32, error) {
+	info, err := prevalidateIdNoAt(idNo, at)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return info.Birthday, info.Age, nil
+}
+
+// ExtractSex 从身份证号离线解析性别
+// 返回值：SexMale 或 SexFemale；身份证号不合法时返回包装了 ErrInvalidIdNo 的错误
+func ExtractSex(idNo string) (string, error) {
+	info, err := PrevalidateIdNo(idNo)
+	if err != nil {
+		return "", err
+	}
+	return info.Sex, nil
+}
+
+// sexOf 按第 17 位顺序码的奇偶判断性别，调用前需已校验格式
+func sexOf(idNo string) string {
+	if (idNo[16]-'0')%2 == 1 {
+		return SexMale
+	}
+	return SexFemale
+}
+
+// ageAt 计算指定时刻的周岁年龄
+func ageAt(birthday, now time.Time) int32 {
+	age := int32(now.Year() - birthday.Year())
+	if now.Month() < birthday.Month() || (now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
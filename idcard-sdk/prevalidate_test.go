@@ -0,0 +1,60 @@
+package idcard_sdk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPrevalidateIdNo(t *testing.T) {
+	now := time.Date(2025, 3, 29, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name         string
+		idNo         string
+		wantErr      bool
+		wantProvince string
+		wantAge      int32
+		wantSex      string
+	}{
+		{name: "合法", idNo: "110101199003070003", wantProvince: "北京", wantAge: 35, wantSex: SexFemale},
+		{name: "生日前一天", idNo: "440301201001011234", wantProvince: "广东", wantAge: 15, wantSex: SexMale},
+		{name: "小写校验码", idNo: "11010119900307002x", wantProvince: "北京", wantAge: 35, wantSex: SexFemale},
+		{name: "校验码错误", idNo: "110101199003070000", wantErr: true},
+		{name: "长度错误", idNo: "11010119900307000", wantErr: true},
+		{name: "非数字", idNo: "1101011990030A0003", wantErr: true},
+		{name: "未知地区码", idNo: "990101199003070007", wantErr: true},
+		{name: "非法日期", idNo: "110101199002300006", wantErr: true},
+		{name: "出生日期晚于当前", idNo: "110101203003070002", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := prevalidateIdNoAt(tt.idNo, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prevalidateIdNoAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidIdNo) {
+					t.Errorf("prevalidateIdNoAt() error = %v, want ErrInvalidIdNo", err)
+				}
+				return
+			}
+			if info.Province != tt.wantProvince || info.Age != tt.wantAge || info.Sex != tt.wantSex {
+				t.Errorf("prevalidateIdNoAt() = %+v, want province %s age %d sex %s", info, tt.wantProvince, tt.wantAge, tt.wantSex)
+			}
+		})
+	}
+}
+
+func TestExtractBirthdayAndAge(t *testing.T) {
+	birthday, age, err := ExtractBirthdayAndAge("440301201001011234", time.Date(2028, 1, 1, 0, 0, 0, 0, time.Local))
+	if err != nil || age != 18 || !birthday.Equal(time.Date(2010, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("ExtractBirthdayAndAge() = %v, %d, %v, want 2010-01-01, 18", birthday, age, err)
+	}
+	if _, _, err = ExtractBirthdayAndAge("440301201001011234", time.Date(2009, 1, 1, 0, 0, 0, 0, time.Local)); !errors.Is(err, ErrInvalidIdNo) {
+		t.Errorf("ExtractBirthdayAndAge() before birth error = %v, want ErrInvalidIdNo", err)
+	}
+	if sex, err := ExtractSex("440301201001011234"); err != nil || sex != SexMale {
+		t.Errorf("ExtractSex() = %s, %v, want %s", sex, err, SexMale)
+	}
+}
@@ -0,0 +1,116 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrProviderUnavailable 服务商不可用，如网络错误、超时或服务端 5xx
+	ErrProviderUnavailable = errors.New("idcard-sdk: provider unavailable")
+	// ErrQuotaExceeded 服务商调用额度已耗尽
+	ErrQuotaExceeded = errors.New("idcard-sdk: quota exceeded")
+	// ErrMismatch 身份证号与名字不一致或身份证号不存在
+	ErrMismatch = errors.New("idcard-sdk: name and id number mismatch")
+)
+
+// VerifyResult 实名认证结果
+type VerifyResult struct {
+	// 信息一致时的身份信息，部分服务商仅返回名字与身份证号
+	Info IdInfo
+	// 给出结果的服务商名称
+	Provider string
+	// 统一后的验证状态，与返回的错误一一对应，见 VerifyStatusOf
+	Status VerifyStatus
+	// 本次调用的耗时，经过 ChainSDK 时为给出结果的服务商的耗时
+	Latency time.Duration
+	// 服务商原始响应，其中的名字与身份证号已脱敏，仅用于排查问题，未收到响应时为空
+	Raw string
+	// 调用方传入的原始姓名与提交给服务商的规范化姓名，由 ChainSDK 设置，见 NormalizeName
+	InputName      string
+	NormalizedName string
+}
+
+// Result 实名认证结果
+//
+// Deprecated: 使用 VerifyResult
+type Result = VerifyResult
+
+// Provider 可报告调用错误的实名认证服务商，ChainSDK 据此判断是否切换到备用服务商
+type Provider interface {
+	IdCardSDK
+	// Name 服务商名称，用于日志
+	Name() string
+	// ValidE 验证身份证与名字，信息不一致返回 ErrMismatch，服务商不可用返回 ErrProviderUnavailable，
+	// 额度耗尽返回 ErrQuotaExceeded，可通过 errors.Is 区分
+	ValidE(ctx context.Context, name, idNo string) (VerifyResult, error)
+}
+
+// ProviderFactory 根据配置创建服务商实现
+type ProviderFactory func(config Config) (IdCardSDK, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{}
+)
+
+// RegisterProvider 注册服务商，之后可在 Config.Providers 中按名称引用；同名注册会覆盖
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// LookupProvider 按名称查找已注册的服务商
+func LookupProvider(name string) (ProviderFactory, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	factory, ok := providers[name]
+	return factory, ok
+}
+
+// Verify 以 ValidE 的语义调用任意 IdCardSDK，返回结构化的验证结果，替代 Valid 返回的 (checkRes, info)
+func Verify(ctx context.Context, sdk IdCardSDK, name, idNo string) (VerifyResult, error) {
+	return validE(ctx, sdk, name, idNo)
+}
+
+// validE 以 ValidE 的语义调用服务商，未实现 Provider 的服务商无法报告错误，验证不通过时返回 ErrMismatch；
+// 结果中的 Status 按返回的错误设置，Latency 为本次调用的耗时，验证通过时补全年龄、是否未成年与年龄段
+func validE(ctx context.Context, sdk IdCardSDK, name, idNo string) (result VerifyResult, err error) {
+	start := time.Now()
+	defer func() {
+		result.Status = VerifyStatusOf(err)
+		result.Latency = time.Since(start)
+		if err == nil {
+			result.Info.fillAge(idNo, time.Now())
+		}
+	}()
+	if provider, ok := sdk.(Provider); ok {
+		return provider.ValidE(ctx, name, idNo)
+	}
+	checkRes, info := sdk.Valid(ctx, name, idNo)
+	if !checkRes {
+		return VerifyResult{}, ErrMismatch
+	}
+	return VerifyResult{Info: info}, nil
+}
+
+// providerName 返回服务商名称，未实现 Provider 时为空
+func providerName(sdk IdCardSDK) string {
+	if provider, ok := sdk.(Provider); ok {
+		return provider.Name()
+	}
+	return ""
+}
+
+// shouldFailover 是否应切换到备用服务商：服务商不可用、超时、额度耗尽或被本地限流
+func shouldFailover(err error) bool {
+	if errors.Is(err, ErrProviderUnavailable) || errors.Is(err, ErrQuotaExceeded) || errors.Is(err, ErrRateLimited) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,256 @@
+package idcard_sdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+const (
+	// defaultQuotaAlertRatio 默认在用量达到上限的 80% 时告警
+	defaultQuotaAlertRatio = 0.8
+	// 共享计数按日、按月分 key，过期时间覆盖整个周期
+	quotaDailyTTL   = 48 * time.Hour
+	quotaMonthlyTTL = 32 * 24 * time.Hour
+)
+
+// QuotaConfig 付费接口的调用额度配置，仅服务商给出确定结果（一致、不一致或不存在）的调用计费
+type QuotaConfig struct {
+	// 每日与每月的调用上限，<=0 表示不限制
+	DailyLimit   int64 `json:"daily_limit" yaml:"daily-limit"`
+	MonthlyLimit int64 `json:"monthly_limit" yaml:"monthly-limit"`
+	// 用量达到上限的比例时告警，<=0 时为 0.8
+	AlertRatio float64 `json:"alert_ratio" yaml:"alert-ratio"`
+	// 达到上限后是否直接拒绝调用并返回 ErrQuotaExceeded，ChainSDK 会切换到备用服务商
+	HardStop bool `json:"hard_stop" yaml:"hard-stop"`
+}
+
+func (config QuotaConfig) alertRatio() float64 {
+	if config.AlertRatio <= 0 {
+		return defaultQuotaAlertRatio
+	}
+	return config.AlertRatio
+}
+
+// QuotaUsage 服务商在当日与当月的调用量
+type QuotaUsage struct {
+	// 2006-01-02 格式的日期
+	Day   string `json:"day"`
+	Daily int64  `json:"daily"`
+	// 2006-01 格式的月份
+	Month   string `json:"month"`
+	Monthly int64  `json:"monthly"`
+}
+
+// rollover 跨日或跨月时清零对应的计数
+func (usage *QuotaUsage) rollover(now time.Time) {
+	if day := now.Format(time.DateOnly); usage.Day != day {
+		usage.Day, usage.Daily = day, 0
+	}
+	if month := now.Format("2006-01"); usage.Month != month {
+		usage.Month, usage.Monthly = month, 0
+	}
+}
+
+// QuotaTracker 服务商调用量计数
+type QuotaTracker interface {
+	// Usage 返回 now 所在日与月的调用量
+	Usage(ctx context.Context, now time.Time) (QuotaUsage, error)
+	// Add 增加一次调用并返回增加后的调用量
+	Add(ctx context.Context, now time.Time) (QuotaUsage, error)
+}
+
+// memoryQuotaTracker 单机调用量计数
+type memoryQuotaTracker struct {
+	mu    sync.Mutex
+	usage QuotaUsage
+}
+
+// NewMemoryQuotaTracker 创建单机调用量计数，重启后清零
+func NewMemoryQuotaTracker() QuotaTracker {
+	return &memoryQuotaTracker{}
+}
+
+func (tracker *memoryQuotaTracker) Usage(ctx context.Context, now time.Time) (QuotaUsage, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.usage.rollover(now)
+	return tracker.usage, nil
+}
+
+func (tracker *memoryQuotaTracker) Add(ctx context.Context, now time.Time) (QuotaUsage, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.usage.rollover(now)
+	tracker.usage.Daily++
+	tracker.usage.Monthly++
+	return tracker.usage, nil
+}
+
+// CounterFactory 按 key 创建计数器，见 storage.StorageManager.NewCounter
+type CounterFactory func(key string) storage.CounterTransactional
+
+// storageQuotaTracker 基于 global-storage 计数器的调用量计数，按日、按月分 key 原子递增，多个节点使用同一个 key 前缀时共享计数
+type storageQuotaTracker struct {
+	newCounter CounterFactory
+	key        string
+}
+
+// NewStorageQuotaTracker 创建多节点共享的调用量计数
+// key: 计数器的 key 前缀，每个服务商使用独立的前缀，实际 key 为 前缀:d:日期 与 前缀:m:月份
+func NewStorageQuotaTracker(newCounter CounterFactory, key string) QuotaTracker {
+	return &storageQuotaTracker{newCounter: newCounter, key: key}
+}
+
+func (tracker *storageQuotaTracker) counters(now time.Time) (daily, monthly storage.CounterTransactional, usage QuotaUsage) {
+	usage = QuotaUsage{Day: now.Format(time.DateOnly), Month: now.Format("2006-01")}
+	daily = tracker.newCounter(tracker.key + ":d:" + usage.Day)
+	monthly = tracker.newCounter(tracker.key + ":m:" + usage.Month)
+	return
+}
+
+func (tracker *storageQuotaTracker) Usage(ctx context.Context, now time.Time) (QuotaUsage, error) {
+	daily, monthly, usage := tracker.counters(now)
+	var err error
+	if usage.Daily, err = daily.Get(ctx); err != nil {
+		return QuotaUsage{}, err
+	}
+	if usage.Monthly, err = monthly.Get(ctx); err != nil {
+		return QuotaUsage{}, err
+	}
+	return usage, nil
+}
+
+func (tracker *storageQuotaTracker) Add(ctx context.Context, now time.Time) (QuotaUsage, error) {
+	daily, monthly, usage := tracker.counters(now)
+	var err error
+	if usage.Daily, err = incrWithTTL(ctx, daily, quotaDailyTTL); err != nil {
+		return QuotaUsage{}, err
+	}
+	if usage.Monthly, err = incrWithTTL(ctx, monthly, quotaMonthlyTTL); err != nil {
+		return QuotaUsage{}, err
+	}
+	return usage, nil
+}
+
+// incrWithTTL 递增计数器，首次创建时设置过期时间
+func incrWithTTL(ctx context.Context, counter storage.CounterTransactional, ttl time.Duration) (int64, error) {
+	value, err := counter.Incr(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if value == 1 {
+		if err = counter.Expire(ctx, ttl); err != nil {
+			return 0, err
+		}
+	}
+	return value, nil
+}
+
+// QuotaAlert 调用量告警
+type QuotaAlert struct {
+	Provider string
+	// "daily" 或 "monthly"
+	Period string
+	Used   int64
+	Limit  int64
+	// 是否已达到上限，否则为达到告警比例
+	Exhausted bool
+}
+
+// QuotaSDK 统计付费接口的调用量，达到告警比例或上限时告警，开启 HardStop 时达到上限后拒绝调用
+type QuotaSDK struct {
+	sdk     IdCardSDK
+	tracker QuotaTracker
+	config  QuotaConfig
+	timeNow func() time.Time
+	onAlert atomic.Pointer[func(QuotaAlert)]
+}
+
+// NewQuotaSDK 为服务商添加调用量统计
+func NewQuotaSDK(sdk IdCardSDK, tracker QuotaTracker, config QuotaConfig) *QuotaSDK {
+	return &QuotaSDK{sdk: sdk, tracker: tracker, config: config, timeNow: time.Now}
+}
+
+// SetAlertHandler 设置本服务商调用量告警的回调，为 nil 时仅记录日志；回调在验证调用的 goroutine 中同步执行
+func (sdk *QuotaSDK) SetAlertHandler(handler func(QuotaAlert)) {
+	if handler == nil {
+		sdk.onAlert.Store(nil)
+		return
+	}
+	sdk.onAlert.Store(&handler)
+}
+
+// Name 服务商名称
+func (sdk *QuotaSDK) Name() string {
+	return providerName(sdk.sdk)
+}
+
+// Valid 检查额度后验证身份证与名字
+func (sdk *QuotaSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 开启 HardStop 且已达到上限时返回 ErrQuotaExceeded；计数失败只记录日志，不影响验证结果
+func (sdk *QuotaSDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	if sdk.config.HardStop {
+		usage, err := sdk.tracker.Usage(ctx, sdk.timeNow())
+		if err != nil {
+			zaplogger.DefaultLogger().Error("QuotaSDK Valid in Usage", field.WithError(err), field.String("provider", sdk.Name()))
+		} else if exceeded(usage.Daily, sdk.config.DailyLimit) || exceeded(usage.Monthly, sdk.config.MonthlyLimit) {
+			return VerifyResult{Provider: sdk.Name(), Status: VerifyQuotaExceeded},
+				fmt.Errorf("%w: local quota of %s used up", ErrQuotaExceeded, sdk.Name())
+		}
+	}
+
+	result, err := validE(ctx, sdk.sdk, name, idNo)
+	switch result.Status {
+	case VerifyMatch, VerifyMismatch, VerifyNotFound:
+		usage, addErr := sdk.tracker.Add(ctx, sdk.timeNow())
+		if addErr != nil {
+			zaplogger.DefaultLogger().Error("QuotaSDK Valid in Add", field.WithError(addErr), field.String("provider", sdk.Name()))
+			break
+		}
+		sdk.alert("daily", usage.Daily, sdk.config.DailyLimit)
+		sdk.alert("monthly", usage.Monthly, sdk.config.MonthlyLimit)
+	}
+	return result, err
+}
+
+// exceeded 是否已达到上限，limit <= 0 表示不限制
+func exceeded(used, limit int64) bool {
+	return limit > 0 && used >= limit
+}
+
+// alert 本次调用使用量首次达到告警比例或上限时告警，每次 Add 只增加 1，因此每个周期各告警一次
+func (sdk *QuotaSDK) alert(period string, used, limit int64) {
+	if limit <= 0 {
+		return
+	}
+	threshold := max(int64(math.Ceil(float64(limit)*sdk.config.alertRatio())), 1)
+	if !reached(used, threshold) && !reached(used, limit) {
+		return
+	}
+	alert := QuotaAlert{Provider: sdk.Name(), Period: period, Used: used, Limit: limit, Exhausted: used >= limit}
+	zaplogger.DefaultLogger().Warn("QuotaSDK quota alert",
+		field.String("provider", alert.Provider),
+		field.String("period", period),
+		field.Int64("used", used),
+		field.Int64("limit", limit))
+	if handler := sdk.onAlert.Load(); handler != nil {
+		(*handler)(alert)
+	}
+}
+
+// reached 使用量是否由本次调用首次达到 threshold
+func reached(used, threshold int64) bool {
+	return used >= threshold && used-1 < threshold
+}
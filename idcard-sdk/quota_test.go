@@ -0,0 +1,135 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// fakeCounters 基于内存实现的 storage.CounterTransactional 集合，按 key 共享计数
+type fakeCounters struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func newFakeCounters() *fakeCounters {
+	return &fakeCounters{values: make(map[string]int64)}
+}
+
+func (counters *fakeCounters) get(key string) storage.CounterTransactional {
+	return &fakeCounter{counters: counters, key: key}
+}
+
+type fakeCounter struct {
+	counters *fakeCounters
+	key      string
+}
+
+func (counter *fakeCounter) Incr(ctx context.Context) (int64, error) {
+	return counter.IncrBy(ctx, 1)
+}
+
+func (counter *fakeCounter) IncrBy(ctx context.Context, delta int64) (int64, error) {
+	counter.counters.mu.Lock()
+	defer counter.counters.mu.Unlock()
+	counter.counters.values[counter.key] += delta
+	return counter.counters.values[counter.key], nil
+}
+
+func (counter *fakeCounter) DecrBy(ctx context.Context, delta int64) (int64, error) {
+	return counter.IncrBy(ctx, -delta)
+}
+
+func (counter *fakeCounter) Get(ctx context.Context) (int64, error) {
+	counter.counters.mu.Lock()
+	defer counter.counters.mu.Unlock()
+	return counter.counters.values[counter.key], nil
+}
+
+func (counter *fakeCounter) Reset(ctx context.Context) error {
+	counter.counters.mu.Lock()
+	defer counter.counters.mu.Unlock()
+	delete(counter.counters.values, counter.key)
+	return nil
+}
+
+func (counter *fakeCounter) Expire(ctx context.Context, ttl time.Duration) error {
+	return nil
+}
+
+func TestQuotaSDK(t *testing.T) {
+	ctx := context.Background()
+	var alerts []QuotaAlert
+	mock, _ := NewMockIdCardSDK(MockConfig{DefaultPass: true})
+	counters := newFakeCounters()
+	sdk := NewQuotaSDK(mock, NewStorageQuotaTracker(counters.get, "quota:mock"), QuotaConfig{DailyLimit: 3, AlertRatio: 0.5, HardStop: true})
+	sdk.SetAlertHandler(func(alert QuotaAlert) { alerts = append(alerts, alert) })
+	now := time.Date(2025, 3, 20, 10, 0, 0, 0, time.Local)
+	sdk.timeNow = func() time.Time { return now }
+
+	// 服务商不可用的调用不计费
+	mock.SetError(ErrProviderUnavailable)
+	if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("ValidE() error = %v, want ErrProviderUnavailable", err)
+	}
+	mock.SetError(nil)
+	for i := 0; i < 3; i++ {
+		if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); err != nil {
+			t.Fatalf("ValidE() call %d error = %v", i+1, err)
+		}
+	}
+	if len(alerts) != 2 || alerts[0].Used != 2 || alerts[0].Exhausted || !alerts[1].Exhausted {
+		t.Errorf("alerts = %+v, want threshold then exhausted", alerts)
+	}
+	if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("ValidE() after limit error = %v, want ErrQuotaExceeded", err)
+	}
+
+	// 次日重新计数
+	now = now.AddDate(0, 0, 1)
+	if _, err := sdk.ValidE(ctx, "张三", "110101199003070003"); err != nil {
+		t.Errorf("ValidE() next day error = %v", err)
+	}
+	if usage, _ := sdk.tracker.Usage(ctx, now); usage.Daily != 1 || usage.Monthly != 4 {
+		t.Errorf("Usage() = %+v, want daily 1 monthly 4", usage)
+	}
+}
+
+func TestQuotaSDK_ConcurrentAlert(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var alerts []QuotaAlert
+	mock, _ := NewMockIdCardSDK(MockConfig{DefaultPass: true})
+	sdk := NewQuotaSDK(mock, NewStorageQuotaTracker(newFakeCounters().get, "quota:mock"), QuotaConfig{MonthlyLimit: 100})
+	sdk.SetAlertHandler(func(alert QuotaAlert) {
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, alert)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = sdk.ValidE(ctx, "张三", "110101199003070003")
+		}()
+	}
+	wg.Wait()
+
+	// 并发调用不丢计数，告警比例与上限各告警一次
+	if usage, _ := sdk.tracker.Usage(ctx, time.Now()); usage.Monthly != 100 {
+		t.Errorf("Usage() monthly = %d, want 100", usage.Monthly)
+	}
+	used := map[int64]bool{}
+	for _, alert := range alerts {
+		used[alert.Used] = true
+	}
+	if len(alerts) != 2 || !used[80] || !used[100] {
+		t.Errorf("alerts = %+v, want at 80 and 100", alerts)
+	}
+}
@@ -0,0 +1,135 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+const (
+	defaultRetryInitialBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+)
+
+// RetryPolicy 服务商调用的重试策略，仅对网络错误、超时与服务端 5xx 等暂时性错误重试
+type RetryPolicy struct {
+	// 最大尝试次数（含首次），<=1 表示不重试
+	MaxAttempts int `json:"max_attempts" yaml:"max-attempts"`
+	// 首次重试前的等待时间（毫秒），<=0 时为 200
+	InitialBackoffMillis int64 `json:"initial_backoff_millis" yaml:"initial-backoff-millis"`
+	// 等待时间上限（毫秒），<=0 时为 2000
+	MaxBackoffMillis int64 `json:"max_backoff_millis" yaml:"max-backoff-millis"`
+}
+
+// backoff 第 attempt 次重试前的等待时间，从 1 开始，每次翻倍且不超过上限
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	initial := time.Duration(policy.InitialBackoffMillis) * time.Millisecond
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	maxBackoff := time.Duration(policy.MaxBackoffMillis) * time.Millisecond
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+	wait := initial
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// isTransient 是否为可重试的暂时性错误；额度耗尽与限流重试无益，交由 ChainSDK 切换服务商
+func isTransient(err error) bool {
+	if errors.Is(err, ErrProviderUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryCall 一次进行中的调用，相同请求的并发调用共享其结果
+type retryCall struct {
+	done   chan struct{}
+	result VerifyResult
+	err    error
+}
+
+// RetrySDK 按重试策略调用服务商，并将相同名字与身份证号的并发请求合并为一次调用，
+// 避免重复点击与重试叠加造成重复计费；合并的请求共享首个请求的 ctx
+type RetrySDK struct {
+	sdk    IdCardSDK
+	policy RetryPolicy
+	sleep  func(ctx context.Context, wait time.Duration) error
+
+	mu       sync.Mutex
+	inflight map[string]*retryCall
+}
+
+// NewRetrySDK 为服务商添加重试
+func NewRetrySDK(sdk IdCardSDK, policy RetryPolicy) *RetrySDK {
+	return &RetrySDK{
+		sdk:      sdk,
+		policy:   policy,
+		sleep:    sleepContext,
+		inflight: make(map[string]*retryCall),
+	}
+}
+
+// Name 服务商名称
+func (sdk *RetrySDK) Name() string {
+	return providerName(sdk.sdk)
+}
+
+// Valid 按重试策略验证身份证与名字
+func (sdk *RetrySDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 按重试策略验证身份证与名字，重试耗尽时返回最后一次的错误
+func (sdk *RetrySDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	key := name + "\x00" + idNo
+	sdk.mu.Lock()
+	if call, ok := sdk.inflight[key]; ok {
+		sdk.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return VerifyResult{Provider: sdk.Name(), Status: VerifyProviderError}, ctx.Err()
+		case <-call.done:
+			return call.result, call.err
+		}
+	}
+	call := &retryCall{done: make(chan struct{})}
+	sdk.inflight[key] = call
+	sdk.mu.Unlock()
+
+	call.result, call.err = sdk.validWithRetry(ctx, name, idNo)
+	sdk.mu.Lock()
+	delete(sdk.inflight, key)
+	sdk.mu.Unlock()
+	close(call.done)
+	return call.result, call.err
+}
+
+func (sdk *RetrySDK) validWithRetry(ctx context.Context, name, idNo string) (result VerifyResult, err error) {
+	for attempt := 1; ; attempt++ {
+		result, err = validE(ctx, sdk.sdk, name, idNo)
+		if err == nil || !isTransient(err) || attempt >= sdk.policy.MaxAttempts {
+			return
+		}
+		wait := sdk.policy.backoff(attempt)
+		zaplogger.DefaultLogger().Warn("RetrySDK Valid retry", identityFields(name, idNo,
+			field.String("provider", sdk.Name()),
+			field.Int("attempt", attempt),
+			field.Int64("backoff_ms", wait.Milliseconds()),
+			field.WithError(err))...)
+		if sleepErr := sdk.sleep(ctx, wait); sleepErr != nil {
+			return result, sleepErr
+		}
+	}
+}
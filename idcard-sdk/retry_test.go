@@ -0,0 +1,125 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+)
+
+// flakyProvider 前 failures 次调用返回 err，之后通过；release 非 nil 时每次调用先等待其关闭
+type flakyProvider struct {
+	failures int32
+	err      error
+	calls    atomic.Int32
+	release  chan struct{}
+}
+
+func (provider *flakyProvider) Name() string {
+	return "flaky"
+}
+
+func (provider *flakyProvider) Valid(ctx context.Context, name, idNo string) (bool, IdInfo) {
+	result, err := provider.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+func (provider *flakyProvider) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	if provider.release != nil {
+		<-provider.release
+	}
+	if provider.calls.Add(1) <= provider.failures {
+		return VerifyResult{Provider: "flaky"}, provider.err
+	}
+	return VerifyResult{Provider: "flaky", Info: IdInfo{Name: name, IdNo: idNo}}, nil
+}
+
+func TestRetrySDK_ValidE(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		err       error
+		wantErr   error
+		wantCalls int32
+		wantWaits []time.Duration
+	}{
+		{name: "暂时性错误重试后通过", failures: 2, err: ErrProviderUnavailable, wantCalls: 3, wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{name: "重试耗尽", failures: 5, err: ErrProviderUnavailable, wantErr: ErrProviderUnavailable, wantCalls: 4, wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond}},
+		{name: "不匹配不重试", failures: 1, err: ErrMismatch, wantErr: ErrMismatch, wantCalls: 1},
+		{name: "额度耗尽不重试", failures: 1, err: ErrQuotaExceeded, wantErr: ErrQuotaExceeded, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := zaplogger.NewTestLogger()
+			defer zaplogger.SetDefaultLogger(logger)()
+			provider := &flakyProvider{failures: tt.failures, err: tt.err}
+			sdk := NewRetrySDK(provider, RetryPolicy{MaxAttempts: 4, InitialBackoffMillis: 100, MaxBackoffMillis: 250})
+			var waits []time.Duration
+			sdk.sleep = func(ctx context.Context, wait time.Duration) error {
+				waits = append(waits, wait)
+				return nil
+			}
+			if _, err := sdk.ValidE(context.Background(), "张三", "110101199003070003"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidE() error = %v, want %v", err, tt.wantErr)
+			}
+			if provider.calls.Load() != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", provider.calls.Load(), tt.wantCalls)
+			}
+			if len(waits) != len(tt.wantWaits) {
+				t.Fatalf("waits = %v, want %v", waits, tt.wantWaits)
+			}
+			for i := range waits {
+				if waits[i] != tt.wantWaits[i] {
+					t.Errorf("waits = %v, want %v", waits, tt.wantWaits)
+				}
+			}
+			// 每次重试记录一条脱敏的告警
+			retries := logs.FilterMessage("RetrySDK Valid retry").AllUntimed()
+			if len(retries) != len(tt.wantWaits) {
+				t.Errorf("retry logs = %d, want %d", len(retries), len(tt.wantWaits))
+			}
+			for _, entry := range retries {
+				if entry.ContextMap()["id_no"] != "110101**********03" {
+					t.Errorf("retry log id_no = %v, want masked", entry.ContextMap()["id_no"])
+				}
+			}
+		})
+	}
+}
+
+func TestRetrySDK_ValidE_Dedup(t *testing.T) {
+	provider := &flakyProvider{release: make(chan struct{})}
+	sdk := NewRetrySDK(provider, RetryPolicy{MaxAttempts: 3})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sdk.ValidE(context.Background(), "张三", "110101199003070003"); err != nil {
+				t.Errorf("ValidE() error = %v", err)
+			}
+		}()
+	}
+	// 等待所有请求进入合并等待后再放行
+	for {
+		sdk.mu.Lock()
+		n := len(sdk.inflight)
+		sdk.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(provider.release)
+	wg.Wait()
+	if provider.calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", provider.calls.Load())
+	}
+}
@@ -0,0 +1,68 @@
+package idcard_sdk
+
+import (
+	"context"
+)
+
+// DefaultSandboxIdentities 未配置 Config.SandboxIdentities 时沙箱预置的测试身份：成年人、未成年人与额度耗尽各一
+var DefaultSandboxIdentities = []MockIdentity{
+	{Name: "张三", IdNo: "110101199003070003"},
+	{Name: "李四", IdNo: "440301201001011234"},
+	{Name: "王五", IdNo: "110101199003070011", Error: MockErrorQuota},
+}
+
+// SandboxSDK 服务商的沙箱模式：预置的测试身份在本地按 MockIdCardSDK 的规则应答，不消耗正式额度；
+// 其他身份转发到指向沙箱地址的服务商，未配置沙箱地址时视为不匹配
+type SandboxSDK struct {
+	name   string
+	sdk    IdCardSDK
+	mock   *MockIdCardSDK
+	canned map[string]struct{}
+}
+
+// NewSandboxSDK 创建沙箱模式的服务商
+// sdk: 指向沙箱地址的服务商，为 nil 时仅应答预置身份
+// identities: 预置的测试身份，为空时使用 DefaultSandboxIdentities
+func NewSandboxSDK(name string, sdk IdCardSDK, identities []MockIdentity) (*SandboxSDK, error) {
+	if len(identities) == 0 {
+		identities = DefaultSandboxIdentities
+	}
+	mock, err := NewMockIdCardSDK(MockConfig{Identities: identities})
+	if err != nil {
+		return nil, err
+	}
+	canned := make(map[string]struct{}, len(identities))
+	for _, identity := range identities {
+		canned[identity.IdNo] = struct{}{}
+	}
+	return &SandboxSDK{name: name, sdk: sdk, mock: mock, canned: canned}, nil
+}
+
+// Name 服务商名称
+func (sdk *SandboxSDK) Name() string {
+	return sdk.name
+}
+
+// Valid 以沙箱模式验证身份证与名字
+func (sdk *SandboxSDK) Valid(ctx context.Context, name, idNo string) (checkRes bool, info IdInfo) {
+	result, err := sdk.ValidE(ctx, name, idNo)
+	return err == nil, result.Info
+}
+
+// ValidE 预置身份在本地应答，其他身份转发到沙箱地址，未配置沙箱地址时返回 ErrMismatch
+func (sdk *SandboxSDK) ValidE(ctx context.Context, name, idNo string) (VerifyResult, error) {
+	if _, ok := sdk.canned[idNo]; ok || sdk.sdk == nil {
+		result, err := validE(ctx, sdk.mock, name, idNo)
+		result.Provider = sdk.name
+		return result, err
+	}
+	return validE(ctx, sdk.sdk, name, idNo)
+}
+
+// newProviderSandbox 服务商工厂在沙箱模式下使用：forward 为 false 时不转发未预置的身份
+func newProviderSandbox(name string, sdk IdCardSDK, forward bool, config Config) (IdCardSDK, error) {
+	if !forward {
+		sdk = nil
+	}
+	return NewSandboxSDK(name, sdk, config.SandboxIdentities)
+}
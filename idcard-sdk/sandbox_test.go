@@ -0,0 +1,65 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSandboxSDK(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"respCode":"0008","respMessage":"mismatch"}`)
+	}))
+	defer server.Close()
+
+	err := InitIdCardSDK(Config{
+		AlibabaConfig: AlibabaConfig{AppCode: "code", Url: "http://production.invalid", Sandbox: true, SandboxUrl: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("InitIdCardSDK() error = %v", err)
+	}
+	sdk := GetIdCardSDK().(Provider)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		person    string
+		idNo      string
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "预置成年人本地通过", person: "张三", idNo: "110101199003070003", wantCalls: 0},
+		{name: "预置身份名字不符", person: "李五", idNo: "440301201001011234", wantErr: ErrMismatch, wantCalls: 0},
+		{name: "预置额度耗尽", person: "王五", idNo: "110101199003070011", wantErr: ErrQuotaExceeded, wantCalls: 0},
+		{name: "其他身份转发到沙箱地址", person: "赵六", idNo: "11010119900307002X", wantErr: ErrMismatch, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := sdk.ValidE(ctx, tt.person, tt.idNo)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("ValidE() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Provider != ProviderAlibaba {
+				t.Errorf("ValidE() provider = %q, want %q", result.Provider, ProviderAlibaba)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("sandbox url calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+
+	// 未配置测试地址时不发起网络请求
+	nppa, err := NewSandboxSDK(ProviderNPPA, nil, nil)
+	if err != nil {
+		t.Fatalf("NewSandboxSDK() error = %v", err)
+	}
+	if _, err = nppa.ValidE(ctx, "赵六", "11010119900307002X"); !errors.Is(err, ErrMismatch) {
+		t.Errorf("ValidE() without sandbox url error = %v, want ErrMismatch", err)
+	}
+}
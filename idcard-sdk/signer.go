@@ -0,0 +1,140 @@
+package idcard_sdk
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// 签名算法
+const (
+	// SignHMACSHA256 以密钥为 key 计算 HMAC-SHA256
+	SignHMACSHA256 = "hmac-sha256"
+	// SignSHA256 密钥加盐后计算 SHA256
+	SignSHA256 = "sha256"
+	// SignMD5 密钥加盐后计算 MD5
+	SignMD5 = "md5"
+)
+
+// 签名放置位置
+const (
+	SignInHeader = "header"
+	SignInQuery  = "query"
+)
+
+// defaultSignKey 默认的签名参数名
+const defaultSignKey = "sign"
+
+// SignerConfig 请求签名配置。待签名串为按参数名排序的参数拼接后再拼接请求体
+type SignerConfig struct {
+	// 签名算法，SignHMACSHA256、SignSHA256 或 SignMD5
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	Secret    string `json:"secret" yaml:"secret"`
+	// 加盐摘要时密钥拼接在待签名串末尾，默认拼接在开头；对 SignHMACSHA256 无效
+	SaltSuffix bool `json:"salt_suffix" yaml:"salt-suffix"`
+	// 签名放置位置，SignInHeader 或 SignInQuery，为空时为 SignInHeader
+	Placement string `json:"placement" yaml:"placement"`
+	// 签名的参数名，为空时为 sign
+	SignKey string `json:"sign_key" yaml:"sign-key"`
+	// 除查询参数外参与签名的请求头，按此处的名称参与排序
+	SignedHeaders []string `json:"signed_headers" yaml:"signed-headers"`
+	// 为 true 时参数按 k1=v1&k2=v2 拼接，否则直接拼接参数名与值
+	Delimited bool `json:"delimited" yaml:"delimited"`
+	// 为 true 时输出大写16进制
+	UpperCase bool `json:"upper_case" yaml:"upper-case"`
+}
+
+// Signer 按 SignerConfig 为请求签名，新接入需要签名的服务商时无需重复实现签名逻辑
+type Signer struct {
+	config SignerConfig
+}
+
+// NewSigner 创建签名器，算法或放置位置不支持时返回错误
+func NewSigner(config SignerConfig) (*Signer, error) {
+	switch config.Algorithm {
+	case SignHMACSHA256, SignSHA256, SignMD5:
+	default:
+		return nil, fmt.Errorf("idcard-sdk: unsupported sign algorithm %q", config.Algorithm)
+	}
+	switch config.Placement {
+	case "":
+		config.Placement = SignInHeader
+	case SignInHeader, SignInQuery:
+	default:
+		return nil, fmt.Errorf("idcard-sdk: unsupported sign placement %q", config.Placement)
+	}
+	if config.SignKey == "" {
+		config.SignKey = defaultSignKey
+	}
+	return &Signer{config: config}, nil
+}
+
+// Sign 计算参数与请求体的签名
+func (signer *Signer) Sign(params url.Values, body []byte) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var builder strings.Builder
+	for i, key := range keys {
+		if signer.config.Delimited {
+			if i > 0 {
+				builder.WriteByte('&')
+			}
+			builder.WriteString(key)
+			builder.WriteByte('=')
+		} else {
+			builder.WriteString(key)
+		}
+		builder.WriteString(params.Get(key))
+	}
+	builder.Write(body)
+
+	var h hash.Hash
+	switch signer.config.Algorithm {
+	case SignHMACSHA256:
+		h = hmac.New(sha256.New, []byte(signer.config.Secret))
+	case SignSHA256:
+		h = sha256.New()
+	default:
+		h = md5.New()
+	}
+	salted := signer.config.Algorithm != SignHMACSHA256
+	if salted && !signer.config.SaltSuffix {
+		h.Write([]byte(signer.config.Secret))
+	}
+	h.Write([]byte(builder.String()))
+	if salted && signer.config.SaltSuffix {
+		h.Write([]byte(signer.config.Secret))
+	}
+	sign := hex.EncodeToString(h.Sum(nil))
+	if signer.config.UpperCase {
+		sign = strings.ToUpper(sign)
+	}
+	return sign
+}
+
+// SignRequest 以请求的查询参数、SignedHeaders 中的请求头与 body 计算签名，并按 Placement 写入请求头或查询参数；
+// 需在设置好参与签名的请求头之后调用
+func (signer *Signer) SignRequest(req *http.Request, body []byte) {
+	params := req.URL.Query()
+	for _, name := range signer.config.SignedHeaders {
+		params.Set(name, req.Header.Get(name))
+	}
+	sign := signer.Sign(params, body)
+	if signer.config.Placement == SignInQuery {
+		query := req.URL.Query()
+		query.Set(signer.config.SignKey, sign)
+		req.URL.RawQuery = query.Encode()
+		return
+	}
+	req.Header.Set(signer.config.SignKey, sign)
+}
@@ -0,0 +1,55 @@
+package idcard_sdk
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SignerConfig
+		body   string
+		want   string
+	}{
+		{
+			name:   "HMAC-SHA256 按 & 拼接",
+			config: SignerConfig{Algorithm: SignHMACSHA256, Secret: "secret", Delimited: true},
+			body:   "body",
+			want:   "143d0a020236c0f493d6065dd14a84d54c4721c53f238127401964707cd1892c",
+		},
+		{
+			name:   "MD5 密钥拼接在末尾",
+			config: SignerConfig{Algorithm: SignMD5, Secret: "salt", SaltSuffix: true, UpperCase: true},
+			want:   "03CAB0DB8D9C6D9B243CBEB4CE991722",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(tt.config)
+			if err != nil {
+				t.Fatalf("NewSigner() error = %v", err)
+			}
+			if got := signer.Sign(url.Values{"b": {"2"}, "a": {"1"}}, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	signer, err := NewSigner(SignerConfig{Algorithm: SignMD5, Secret: "salt", SaltSuffix: true, UpperCase: true, Placement: SignInQuery, SignKey: "signature", SignedHeaders: []string{"b"}})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	req, _ := http.NewRequest("GET", "http://example.com/?a=1", nil)
+	req.Header.Set("b", "2")
+	signer.SignRequest(req, nil)
+	if got := req.URL.Query().Get("signature"); got != "03CAB0DB8D9C6D9B243CBEB4CE991722" {
+		t.Errorf("SignRequest() query signature = %s", got)
+	}
+
+	if _, err = NewSigner(SignerConfig{Algorithm: "rsa"}); err == nil {
+		t.Errorf("NewSigner() unsupported algorithm error = nil, want error")
+	}
+}
@@ -0,0 +1,85 @@
+package idcard_sdk
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound 身份证号不存在，同时匹配 ErrMismatch
+	ErrNotFound = fmt.Errorf("%w: id number not found", ErrMismatch)
+	// ErrInvalidParam 服务商判定请求参数不合法，如姓名含特殊字符或身份证号格式错误
+	ErrInvalidParam = errors.New("idcard-sdk: invalid parameter")
+)
+
+// VerifyStatus 各服务商结果码统一后的验证状态
+type VerifyStatus int
+
+const (
+	// VerifyUnknown 未得出结果
+	VerifyUnknown VerifyStatus = iota
+	// VerifyMatch 姓名与身份证号一致
+	VerifyMatch
+	// VerifyMismatch 姓名与身份证号不一致
+	VerifyMismatch
+	// VerifyNotFound 身份证号不存在
+	VerifyNotFound
+	// VerifyInvalidParam 请求参数不合法
+	VerifyInvalidParam
+	// VerifyQuotaExceeded 服务商调用额度已耗尽
+	VerifyQuotaExceeded
+	// VerifyProviderError 服务商不可用、被本地限流或熔断等其他错误
+	VerifyProviderError
+)
+
+func (status VerifyStatus) String() string {
+	switch status {
+	case VerifyMatch:
+		return "match"
+	case VerifyMismatch:
+		return "mismatch"
+	case VerifyNotFound:
+		return "not_found"
+	case VerifyInvalidParam:
+		return "invalid_param"
+	case VerifyQuotaExceeded:
+		return "quota_exceeded"
+	case VerifyProviderError:
+		return "provider_error"
+	}
+	return "unknown"
+}
+
+// MarshalText 以 String 的形式序列化，便于审计记录阅读
+func (status VerifyStatus) MarshalText() ([]byte, error) {
+	return []byte(status.String()), nil
+}
+
+// UnmarshalText 解析 String 的结果，无法识别时为 VerifyUnknown
+func (status *VerifyStatus) UnmarshalText(text []byte) error {
+	*status = VerifyUnknown
+	for candidate := VerifyMatch; candidate <= VerifyProviderError; candidate++ {
+		if candidate.String() == string(text) {
+			*status = candidate
+			break
+		}
+	}
+	return nil
+}
+
+// VerifyStatusOf 将 ValidE 返回的错误转换为验证状态，nil 为 VerifyMatch
+func VerifyStatusOf(err error) VerifyStatus {
+	switch {
+	case err == nil:
+		return VerifyMatch
+	case errors.Is(err, ErrNotFound):
+		return VerifyNotFound
+	case errors.Is(err, ErrMismatch):
+		return VerifyMismatch
+	case errors.Is(err, ErrInvalidParam), errors.Is(err, ErrInvalidIdNo):
+		return VerifyInvalidParam
+	case errors.Is(err, ErrQuotaExceeded):
+		return VerifyQuotaExceeded
+	}
+	return VerifyProviderError
+}
@@ -0,0 +1,63 @@
+package idcard_sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAlibabaIdCardSDK_Status(t *testing.T) {
+	var respCode string
+	var httpStatus int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if httpStatus != 0 {
+			w.Header().Set("X-Ca-Error-Message", "Quota Exhausted")
+			w.WriteHeader(httpStatus)
+			return
+		}
+		fmt.Fprintf(w, `{"respCode":%q,"respMessage":"msg","name":"张三","idNo":"110101199003070003","birthday":"19900307","age":"35"}`, respCode)
+	}))
+	defer server.Close()
+
+	sdk := NewAlibabaIdCardSDK(AlibabaConfig{AppCode: "code", Url: server.URL})
+	tests := []struct {
+		name       string
+		respCode   string
+		httpStatus int
+		want       VerifyStatus
+	}{
+		{name: "一致", respCode: "0000", want: VerifyMatch},
+		{name: "身份证号格式错误", respCode: "0004", want: VerifyInvalidParam},
+		{name: "无此身份证号", respCode: "0007", want: VerifyNotFound},
+		{name: "不一致", respCode: "0008", want: VerifyMismatch},
+		{name: "系统维护", respCode: "0010", want: VerifyProviderError},
+		{name: "额度耗尽", httpStatus: http.StatusForbidden, want: VerifyQuotaExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respCode, httpStatus = tt.respCode, tt.httpStatus
+			result, err := sdk.ValidE(context.Background(), "张三", "110101199003070003")
+			if result.Status != tt.want || VerifyStatusOf(err) != tt.want {
+				t.Errorf("ValidE() status = %v, error = %v, want %v", result.Status, err, tt.want)
+			}
+		})
+	}
+
+	// 结构化结果带有耗时与脱敏后的原始响应
+	respCode, httpStatus = "0000", 0
+	result, err := Verify(context.Background(), NewChainSDK(sdk), "张三", "110101199003070003")
+	if err != nil || result.Provider != ProviderAlibaba || result.Latency <= 0 ||
+		!strings.Contains(result.Raw, "110101**********03") || strings.Contains(result.Raw, "110101199003070003") {
+		t.Errorf("Verify() = %+v, %v, want masked raw payload", result, err)
+	}
+
+	// 离线校验不通过时不调用服务商
+	chain := NewChainSDK(sdk)
+	if result, _ := chain.ValidE(context.Background(), "张三", "110101199003070000"); result.Status != VerifyInvalidParam {
+		t.Errorf("ChainSDK ValidE() status = %v, want %v", result.Status, VerifyInvalidParam)
+	}
+}
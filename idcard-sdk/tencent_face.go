@@ -0,0 +1,203 @@
+package idcard_sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTencentFaceEndpoint = "https://faceid.tencentcloudapi.com"
+	tencentFaceService         = "faceid"
+	tencentFaceVersion         = "2018-03-01"
+)
+
+// TencentFaceConfig 腾讯云慧眼人脸核身配置
+type TencentFaceConfig struct {
+	SecretId  string `json:"secret_id" yaml:"secret-id"`
+	SecretKey string `json:"secret_key" yaml:"secret-key"`
+	// 控制台申请的业务流程 RuleId
+	RuleId string `json:"rule_id" yaml:"rule-id"`
+	Region string `json:"region" yaml:"region"`
+	// 接口地址，为空时使用官方地址
+	Endpoint string     `json:"endpoint" yaml:"endpoint"`
+	HTTP     HTTPConfig `json:"http" yaml:"http"`
+}
+
+// TencentFaceVerifySDK 腾讯云慧眼人脸核身，使用 TC3-HMAC-SHA256 签名
+type TencentFaceVerifySDK struct {
+	config  TencentFaceConfig
+	host    string
+	client  *http.Client
+	timeNow func() time.Time
+}
+
+// NewTencentFaceVerifySDK 创建腾讯云人脸核身实例，配置非法时返回错误
+func NewTencentFaceVerifySDK(config TencentFaceConfig) (*TencentFaceVerifySDK, error) {
+	if config.SecretId == "" || config.SecretKey == "" || config.RuleId == "" {
+		return nil, errors.New("idcard-sdk: tencent face secret id, secret key and rule id required")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = defaultTencentFaceEndpoint
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("idcard-sdk: invalid tencent face endpoint %q: %w", config.Endpoint, err)
+	}
+	client, err := config.HTTP.NewHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &TencentFaceVerifySDK{config: config, host: endpoint.Host, client: client, timeNow: time.Now}, nil
+}
+
+// Submit 调用 DetectAuth 发起核身，返回 H5 核身地址与 BizToken
+func (sdk *TencentFaceVerifySDK) Submit(ctx context.Context, req FaceVerifyRequest) (FaceVerifyTicket, error) {
+	var data struct {
+		Url      string `json:"Url"`
+		BizToken string `json:"BizToken"`
+	}
+	err := sdk.call(ctx, "DetectAuth", map[string]string{
+		"RuleId":      sdk.config.RuleId,
+		"IdCard":      req.IdNo,
+		"Name":        req.Name,
+		"RedirectUrl": req.RedirectUrl,
+		"Extra":       req.Extra,
+	}, &data)
+	return FaceVerifyTicket{BizToken: data.BizToken, Url: data.Url}, err
+}
+
+// Query 调用 GetDetectInfoEnhanced 查询核身结果，ErrCode 为空表示用户尚未完成
+func (sdk *TencentFaceVerifySDK) Query(ctx context.Context, bizToken string) (FaceVerifyResult, error) {
+	var data struct {
+		Text struct {
+			ErrCode *int   `json:"ErrCode"`
+			ErrMsg  string `json:"ErrMsg"`
+			Sim     string `json:"Sim"`
+			Extra   string `json:"Extra"`
+		} `json:"Text"`
+	}
+	if err := sdk.call(ctx, "GetDetectInfoEnhanced", map[string]string{
+		"BizToken": bizToken,
+		"RuleId":   sdk.config.RuleId,
+		"InfoType": "0",
+	}, &data); err != nil {
+		return FaceVerifyResult{}, err
+	}
+
+	text := data.Text
+	result := FaceVerifyResult{Message: text.ErrMsg, Extra: text.Extra}
+	switch {
+	case text.ErrCode == nil:
+		result.Status = FaceVerifyPending
+	case *text.ErrCode == 0:
+		result.Status = FaceVerifyPassed
+	default:
+		result.Status = FaceVerifyFailed
+	}
+	if text.ErrCode != nil {
+		result.Code = strconv.Itoa(*text.ErrCode)
+	}
+	result.Similarity, _ = strconv.ParseFloat(text.Sim, 64)
+	return result, nil
+}
+
+// call 调用云 API 3.0 接口并解析 Response，业务错误返回包含错误码的错误，网络错误或服务端 5xx 返回 ErrProviderUnavailable
+func (sdk *TencentFaceVerifySDK) call(ctx context.Context, action string, params map[string]string, v any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", sdk.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := sdk.timeNow().Unix()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Host", sdk.host)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Version", tencentFaceVersion)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+	if sdk.config.Region != "" {
+		req.Header.Set("X-TC-Region", sdk.config.Region)
+	}
+	req.Header.Set("Authorization", sdk.authorization(timestamp, body))
+
+	resp, err := sdk.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: http status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+
+	var data struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err = json.Unmarshal(respBody, &data); err != nil {
+		return err
+	}
+	var apiErr struct {
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err = json.Unmarshal(data.Response, &apiErr); err != nil {
+		return err
+	}
+	if apiErr.Error != nil {
+		if strings.HasPrefix(apiErr.Error.Code, "InternalError") {
+			return fmt.Errorf("%w: tencent %s: %s", ErrProviderUnavailable, apiErr.Error.Code, apiErr.Error.Message)
+		}
+		return fmt.Errorf("idcard-sdk: tencent %s: %s", apiErr.Error.Code, apiErr.Error.Message)
+	}
+	return json.Unmarshal(data.Response, v)
+}
+
+// authorization 计算 TC3-HMAC-SHA256 签名的 Authorization 头，签名 content-type 与 host 两个头
+func (sdk *TencentFaceVerifySDK) authorization(timestamp int64, body []byte) string {
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		"content-type:application/json; charset=utf-8\nhost:" + sdk.host + "\n",
+		"content-type;host",
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	scope := date + "/" + tencentFaceService + "/tc3_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "TC3-HMAC-SHA256\n" + strconv.FormatInt(timestamp, 10) + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	secretDate := hmacSHA256([]byte("TC3"+sdk.config.SecretKey), date)
+	secretService := hmacSHA256(secretDate, tencentFaceService)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+	return "TC3-HMAC-SHA256 Credential=" + sdk.config.SecretId + "/" + scope +
+		", SignedHeaders=content-type;host, Signature=" + signature
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,67 @@
+package idcard_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTencentFaceVerifySDK(t *testing.T) {
+	var queries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "TC3-HMAC-SHA256 Credential=id/") {
+			t.Errorf("Authorization = %q, want TC3 signature", r.Header.Get("Authorization"))
+		}
+		var params map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&params)
+		switch r.Header.Get("X-TC-Action") {
+		case "DetectAuth":
+			if params["RuleId"] != "1" || params["IdCard"] != "110101199003070003" {
+				t.Errorf("DetectAuth params = %v", params)
+			}
+			fmt.Fprint(w, `{"Response":{"Url":"https://h5/verify","BizToken":"biz-1","RequestId":"r1"}}`)
+		case "GetDetectInfoEnhanced":
+			if params["BizToken"] == "unknown" {
+				fmt.Fprint(w, `{"Response":{"Error":{"Code":"InvalidParameter","Message":"BizToken"},"RequestId":"r2"}}`)
+				return
+			}
+			queries++
+			// 第一次查询时用户尚未完成核身
+			if queries == 1 {
+				fmt.Fprint(w, `{"Response":{"Text":{"ErrCode":null}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"Response":{"Text":{"ErrCode":0,"ErrMsg":"成功","Sim":"93.50"}}}`)
+		}
+	}))
+	defer server.Close()
+
+	sdk, err := NewTencentFaceVerifySDK(TencentFaceConfig{SecretId: "id", SecretKey: "key", RuleId: "1", Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewTencentFaceVerifySDK() error = %v", err)
+	}
+	ctx := context.Background()
+	ticket, err := sdk.Submit(ctx, FaceVerifyRequest{Name: "张三", IdNo: "110101199003070003"})
+	if err != nil || ticket.BizToken != "biz-1" || ticket.Url != "https://h5/verify" {
+		t.Fatalf("Submit() = %+v, %v", ticket, err)
+	}
+
+	result, err := WaitFaceVerify(ctx, sdk, ticket.BizToken, time.Millisecond)
+	if err != nil || result.Status != FaceVerifyPassed || result.Similarity != 93.5 || queries != 2 {
+		t.Errorf("WaitFaceVerify() = %+v, %v after %d queries, want passed", result, err, queries)
+	}
+
+	if _, err = sdk.Query(ctx, "unknown"); err == nil || errors.Is(err, ErrProviderUnavailable) {
+		t.Errorf("Query() unknown token error = %v, want business error", err)
+	}
+
+	if _, err = NewTencentFaceVerifySDK(TencentFaceConfig{SecretId: "id"}); err == nil {
+		t.Errorf("NewTencentFaceVerifySDK() missing config error = nil, want error")
+	}
+}
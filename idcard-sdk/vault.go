@@ -0,0 +1,239 @@
+package idcard_sdk
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+var (
+	// ErrKeyNotFound 密钥不存在，通常是解密使用了已下线的密钥
+	ErrKeyNotFound = errors.New("idcard-sdk: encryption key not found")
+	// ErrResultNotFound 没有保存过该身份证号的验证结果
+	ErrResultNotFound = errors.New("idcard-sdk: verification result not found")
+)
+
+// KeyProvider 提供加密姓名与身份证号的 AES 密钥，支持密钥轮换：新数据使用当前密钥，旧数据按密钥ID解密；实现需并发安全
+type KeyProvider interface {
+	// CurrentKey 返回加密新数据使用的密钥及其ID
+	CurrentKey(ctx context.Context) (keyId string, key []byte, err error)
+	// Key 按ID返回密钥，不存在时返回 ErrKeyNotFound
+	Key(ctx context.Context, keyId string) ([]byte, error)
+}
+
+// StaticKeyProvider 基于固定密钥表的 KeyProvider，适用于从配置或环境变量加载密钥
+type StaticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyProvider 创建固定密钥表
+// current: 加密新数据使用的密钥ID，须存在于 keys 中
+// keys: 密钥ID到 16、24 或 32 字节 AES 密钥的映射
+func NewStaticKeyProvider(current string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: current key %q", ErrKeyNotFound, current)
+	}
+	for keyId, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("idcard-sdk: invalid key %q: %w", keyId, err)
+		}
+	}
+	return &StaticKeyProvider{current: current, keys: keys}, nil
+}
+
+func (provider *StaticKeyProvider) CurrentKey(ctx context.Context) (string, []byte, error) {
+	return provider.current, provider.keys[provider.current], nil
+}
+
+func (provider *StaticKeyProvider) Key(ctx context.Context, keyId string) ([]byte, error) {
+	key, ok := provider.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyId)
+	}
+	return key, nil
+}
+
+// SealedIdentity 加密后的姓名与身份证号，可安全持久化
+type SealedIdentity struct {
+	KeyId string `json:"key_id"`
+	// base64(nonce+密文+tag)
+	Name string `json:"name"`
+	IdNo string `json:"id_no"`
+	// 身份证号的加盐哈希，用于无需解密的查找
+	LookupHash string `json:"lookup_hash"`
+}
+
+// IdentityVault 加密姓名与身份证号，并计算身份证号的加盐哈希用于查找
+type IdentityVault struct {
+	keys KeyProvider
+	salt []byte
+}
+
+// NewIdentityVault 创建身份信息加密
+// salt: 计算查找哈希的盐，更换后旧数据无法再按身份证号查找
+func NewIdentityVault(keys KeyProvider, salt []byte) *IdentityVault {
+	return &IdentityVault{keys: keys, salt: salt}
+}
+
+// LookupHash 返回身份证号的加盐哈希（HMAC-SHA256），末位校验码不区分大小写
+func (vault *IdentityVault) LookupHash(idNo string) string {
+	mac := hmac.New(sha256.New, vault.salt)
+	mac.Write([]byte(strings.ToUpper(strings.TrimSpace(idNo))))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Seal 使用当前密钥以 AES-GCM 加密姓名与身份证号
+func (vault *IdentityVault) Seal(ctx context.Context, name, idNo string) (SealedIdentity, error) {
+	keyId, key, err := vault.keys.CurrentKey(ctx)
+	if err != nil {
+		return SealedIdentity{}, err
+	}
+	sealed := SealedIdentity{KeyId: keyId, LookupHash: vault.LookupHash(idNo)}
+	if sealed.Name, err = sealString(key, keyId, name); err != nil {
+		return SealedIdentity{}, err
+	}
+	if sealed.IdNo, err = sealString(key, keyId, idNo); err != nil {
+		return SealedIdentity{}, err
+	}
+	return sealed, nil
+}
+
+// Open 按密钥ID解密姓名与身份证号
+func (vault *IdentityVault) Open(ctx context.Context, sealed SealedIdentity) (name, idNo string, err error) {
+	key, err := vault.keys.Key(ctx, sealed.KeyId)
+	if err != nil {
+		return "", "", err
+	}
+	if name, err = openString(key, sealed.KeyId, sealed.Name); err != nil {
+		return "", "", err
+	}
+	if idNo, err = openString(key, sealed.KeyId, sealed.IdNo); err != nil {
+		return "", "", err
+	}
+	return name, idNo, nil
+}
+
+// sealString 以密钥ID作为附加数据加密，防止密文被挪用到其他密钥ID下
+func sealString(key []byte, keyId, plain string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plain), []byte(keyId))), nil
+}
+
+func openString(key []byte, keyId, sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("idcard-sdk: decode sealed identity: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("idcard-sdk: sealed identity too short")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(keyId))
+	if err != nil {
+		return "", fmt.Errorf("idcard-sdk: open sealed identity: %w", err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// StoredResult 持久化的验证结果，姓名与身份证号已加密
+type StoredResult struct {
+	Identity   SealedIdentity `json:"identity"`
+	Provider   string         `json:"provider"`
+	Status     VerifyStatus   `json:"status"`
+	Birthday   time.Time      `json:"birthday"`
+	IsMinor    bool           `json:"is_minor"`
+	AgeBracket string         `json:"age_bracket"`
+	// 验证时间戳（毫秒）
+	VerifiedAt int64 `json:"verified_at"`
+}
+
+func (result *StoredResult) MarshalBinary() ([]byte, error) {
+	return json.Marshal(result)
+}
+
+func (result *StoredResult) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, result)
+}
+
+// NewStoredResultFactory 返回验证结果的数据工厂，用于注册 hash 存储
+func NewStoredResultFactory() storage.StorageData {
+	return &StoredResult{}
+}
+
+// ResultStore 基于 global-storage hash 保存加密后的验证结果，以身份证号的加盐哈希为字段，查找时无需解密
+type ResultStore struct {
+	hash    storage.HashTransactional
+	vault   *IdentityVault
+	timeNow func() time.Time
+}
+
+// NewResultStore 创建验证结果存储
+// hash: 需以 NewStoredResultFactory 作为数据工厂注册的 hash 存储
+func NewResultStore(hash storage.HashTransactional, vault *IdentityVault) *ResultStore {
+	return &ResultStore{hash: hash, vault: vault, timeNow: time.Now}
+}
+
+// Save 加密后保存验证结果，同一身份证号只保留最后一次
+func (store *ResultStore) Save(ctx context.Context, name, idNo string, result VerifyResult) error {
+	identity, err := store.vault.Seal(ctx, name, idNo)
+	if err != nil {
+		return err
+	}
+	return store.hash.HSet(ctx, identity.LookupHash, &StoredResult{
+		Identity:   identity,
+		Provider:   result.Provider,
+		Status:     result.Status,
+		Birthday:   result.Info.Birthday,
+		IsMinor:    result.Info.IsMinor,
+		AgeBracket: result.Info.AgeBracket,
+		VerifiedAt: store.timeNow().UnixMilli(),
+	})
+}
+
+// Lookup 按身份证号的加盐哈希查找验证结果，不解密；需要明文时使用 IdentityVault.Open
+func (store *ResultStore) Lookup(ctx context.Context, idNo string) (StoredResult, error) {
+	data, err := store.hash.HGet(ctx, store.vault.LookupHash(idNo))
+	if errors.Is(err, storage.ErrFieldNotFound) {
+		return StoredResult{}, ErrResultNotFound
+	}
+	if err != nil {
+		return StoredResult{}, err
+	}
+	result, ok := data.(*StoredResult)
+	if !ok {
+		return StoredResult{}, errors.New("idcard-sdk: unexpected stored result type")
+	}
+	return *result, nil
+}
@@ -0,0 +1,97 @@
+package idcard_sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// fakeHash 基于内存实现的 storage.HashTransactional，不支持事务
+type fakeHash struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (hash *fakeHash) HGetAll(ctx context.Context) (map[string]storage.StorageData, error) {
+	return nil, errors.New("not supported")
+}
+
+func (hash *fakeHash) HSet(ctx context.Context, field string, value storage.StorageData) error {
+	data, err := value.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	hash.mu.Lock()
+	defer hash.mu.Unlock()
+	hash.data[field] = data
+	return nil
+}
+
+func (hash *fakeHash) HGet(ctx context.Context, field string) (storage.StorageData, error) {
+	hash.mu.Lock()
+	data, ok := hash.data[field]
+	hash.mu.Unlock()
+	if !ok {
+		return nil, storage.ErrFieldNotFound
+	}
+	result := NewStoredResultFactory()
+	return result, result.UnmarshalBinary(data)
+}
+
+func (hash *fakeHash) HDel(ctx context.Context, fields ...string) error {
+	return errors.New("not supported")
+}
+
+func (hash *fakeHash) HUpdate(ctx context.Context, field string, fn func(current storage.StorageData) (storage.StorageData, error)) error {
+	return errors.New("not supported")
+}
+
+func (hash *fakeHash) BeginTx(ctx context.Context) (storage.HashTransaction, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestResultStore(t *testing.T) {
+	ctx := context.Background()
+	oldKeys, err := NewStaticKeyProvider("v1", map[string][]byte{"v1": []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatalf("NewStaticKeyProvider() error = %v", err)
+	}
+	hash := &fakeHash{data: map[string][]byte{}}
+	store := NewResultStore(hash, NewIdentityVault(oldKeys, []byte("salt")))
+	result := VerifyResult{Provider: ProviderMock, Status: VerifyMatch, Info: IdInfo{IsMinor: true, AgeBracket: AgeBracket8To16}}
+	if err = store.Save(ctx, "张三", "11010119900307002X", result); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for field, data := range hash.data {
+		if strings.Contains(field+string(data), "11010119900307002X") || strings.Contains(string(data), "张三") {
+			t.Fatalf("stored data contains plaintext: %s", data)
+		}
+	}
+
+	// 轮换密钥后仍可查找并解密旧数据，末位校验码不区分大小写
+	newKeys, _ := NewStaticKeyProvider("v2", map[string][]byte{
+		"v1": []byte("0123456789abcdef"),
+		"v2": []byte("fedcba9876543210fedcba9876543210"),
+	})
+	vault := NewIdentityVault(newKeys, []byte("salt"))
+	store = NewResultStore(hash, vault)
+	stored, err := store.Lookup(ctx, "11010119900307002x")
+	if err != nil || stored.Identity.KeyId != "v1" || stored.Status != VerifyMatch || stored.AgeBracket != AgeBracket8To16 {
+		t.Fatalf("Lookup() = %+v, %v", stored, err)
+	}
+	if name, idNo, err := vault.Open(ctx, stored.Identity); err != nil || name != "张三" || idNo != "11010119900307002X" {
+		t.Errorf("Open() = %s, %s, %v", name, idNo, err)
+	}
+
+	if _, err = store.Lookup(ctx, "110101199003070003"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Lookup() missing error = %v, want ErrResultNotFound", err)
+	}
+	stored.Identity.KeyId = "v3"
+	if _, _, err = vault.Open(ctx, stored.Identity); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Open() unknown key error = %v, want ErrKeyNotFound", err)
+	}
+}
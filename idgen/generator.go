@@ -0,0 +1,102 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// WorkerBits worker ID 占用的位数，最多 1024 个实例
+	WorkerBits = 10
+	// SequenceBits 同一毫秒内序号占用的位数，每毫秒最多 4096 个 ID
+	SequenceBits = 12
+	// MaxWorkerID 最大的 worker ID
+	MaxWorkerID = 1<<WorkerBits - 1
+
+	maxSequence = 1<<SequenceBits - 1
+	// maxBackwards 时钟回拨不超过该值时等待追上，否则返回 ErrClockBackwards
+	maxBackwards = 5 * time.Millisecond
+)
+
+// DefaultEpoch 时间戳的起点，2024-01-01 UTC，41 位毫秒时间戳可用约 69 年
+var DefaultEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var ErrClockBackwards = errors.New("idgen: clock moved backwards")
+
+// Generator 雪花算法 ID 生成器：1 位符号 + 41 位毫秒时间戳 + 10 位 worker ID + 12 位序号，
+// 同一 worker ID 生成的 ID 唯一且递增；不同实例需使用不同的 worker ID，见 Node
+type Generator struct {
+	epoch    time.Time
+	workerID int64
+	now      func() time.Time
+
+	mu       sync.Mutex
+	last     int64
+	sequence int64
+}
+
+// NewGenerator 以 DefaultEpoch 为起点创建 worker ID 固定的生成器
+func NewGenerator(workerID int64) (*Generator, error) {
+	return newGenerator(workerID, DefaultEpoch, time.Now)
+}
+
+func newGenerator(workerID int64, epoch time.Time, now func() time.Time) (*Generator, error) {
+	if workerID < 0 || workerID > MaxWorkerID {
+		return nil, fmt.Errorf("idgen: worker id %d out of range [0, %d]", workerID, MaxWorkerID)
+	}
+	if epoch.IsZero() {
+		epoch = DefaultEpoch
+	}
+	return &Generator{epoch: epoch, workerID: workerID, now: now}, nil
+}
+
+// WorkerID 生成器的 worker ID
+func (generator *Generator) WorkerID() int64 {
+	return generator.workerID
+}
+
+// Next 生成下一个 ID；同一毫秒内序号用尽时等待下一毫秒，时钟回拨超过 5 毫秒时返回 ErrClockBackwards
+func (generator *Generator) Next() (int64, error) {
+	generator.mu.Lock()
+	defer generator.mu.Unlock()
+	millis := generator.millis()
+	if millis < generator.last {
+		if time.Duration(generator.last-millis)*time.Millisecond > maxBackwards {
+			return 0, fmt.Errorf("%w: %dms", ErrClockBackwards, generator.last-millis)
+		}
+		for millis < generator.last {
+			time.Sleep(time.Duration(generator.last-millis) * time.Millisecond)
+			millis = generator.millis()
+		}
+	}
+	if millis == generator.last {
+		generator.sequence = (generator.sequence + 1) & maxSequence
+		if generator.sequence == 0 {
+			for millis <= generator.last {
+				millis = generator.millis()
+			}
+		}
+	} else {
+		generator.sequence = 0
+	}
+	generator.last = millis
+	return millis<<(WorkerBits+SequenceBits) | generator.workerID<<SequenceBits | generator.sequence, nil
+}
+
+func (generator *Generator) millis() int64 {
+	return generator.now().Sub(generator.epoch).Milliseconds()
+}
+
+// Parse 以 DefaultEpoch 为起点解析 ID 的生成时间、worker ID 与序号
+func Parse(id int64) (at time.Time, workerID, sequence int64) {
+	return parse(id, DefaultEpoch)
+}
+
+func parse(id int64, epoch time.Time) (at time.Time, workerID, sequence int64) {
+	millis := id >> (WorkerBits + SequenceBits)
+	workerID = id >> SequenceBits & MaxWorkerID
+	sequence = id & maxSequence
+	return epoch.Add(time.Duration(millis) * time.Millisecond), workerID, sequence
+}
@@ -0,0 +1,169 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+)
+
+// memoryLeases 以内存模拟租约，不处理过期
+type memoryLeases struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+type memoryLease struct {
+	leases *memoryLeases
+	key    string
+}
+
+func (leases *memoryLeases) newLease(key string) storage.Lease {
+	return &memoryLease{leases: leases, key: key}
+}
+
+func (lease *memoryLease) Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	lease.leases.mu.Lock()
+	defer lease.leases.mu.Unlock()
+	if _, ok := lease.leases.owners[lease.key]; ok {
+		return false, nil
+	}
+	lease.leases.owners[lease.key] = owner
+	return true, nil
+}
+
+func (lease *memoryLease) Renew(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	lease.leases.mu.Lock()
+	defer lease.leases.mu.Unlock()
+	return lease.leases.owners[lease.key] == owner, nil
+}
+
+func (lease *memoryLease) Release(ctx context.Context, owner string) error {
+	lease.leases.mu.Lock()
+	defer lease.leases.mu.Unlock()
+	if lease.leases.owners[lease.key] == owner {
+		delete(lease.leases.owners, lease.key)
+	}
+	return nil
+}
+
+func (lease *memoryLease) Owner(ctx context.Context) (string, error) {
+	lease.leases.mu.Lock()
+	defer lease.leases.mu.Unlock()
+	if owner, ok := lease.leases.owners[lease.key]; ok {
+		return owner, nil
+	}
+	return "", storage.ErrFieldNotFound
+}
+
+func TestGenerator(t *testing.T) {
+	generator, err := NewGenerator(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int64]bool)
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := generator.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] || id <= last {
+			t.Fatalf("id %d duplicated or not increasing after %d", id, last)
+		}
+		seen[id], last = true, id
+	}
+	at, workerID, _ := Parse(last)
+	if workerID != 7 || time.Since(at) > time.Second {
+		t.Errorf("Parse() = %v, %d, want now, 7", at, workerID)
+	}
+	if _, err := NewGenerator(MaxWorkerID + 1); err == nil {
+		t.Errorf("NewGenerator(%d) want error", MaxWorkerID+1)
+	}
+}
+
+func TestGenerator_ClockBackwards(t *testing.T) {
+	now := time.Now()
+	generator, _ := newGenerator(1, DefaultEpoch, func() time.Time { return now })
+	if _, err := generator.Next(); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(-time.Second)
+	if _, err := generator.Next(); !errors.Is(err, ErrClockBackwards) {
+		t.Errorf("Next() error = %v, want ErrClockBackwards", err)
+	}
+}
+
+func TestNode(t *testing.T) {
+	leases := &memoryLeases{owners: map[string]string{}}
+	ctx := context.Background()
+	a, err := NewNode(ctx, Config{}, leases.newLease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewNode(ctx, Config{}, leases.newLease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 跳过获得租约后的等待，见 TestNode_WaitLeaseTTL
+	a.readyAt = time.Time{}
+	if a.WorkerID() == b.WorkerID() {
+		t.Errorf("nodes share worker id %d", a.WorkerID())
+	}
+	if _, err := a.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 租约被他人持有后续约失败，不再生成 ID
+	leases.mu.Lock()
+	leases.owners[a.lease.(*memoryLease).key] = "other"
+	leases.mu.Unlock()
+	a.renew(ctx)
+	if _, err := a.Next(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Next() error = %v, want ErrLeaseLost", err)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.lease.Owner(ctx); !errors.Is(err, storage.ErrFieldNotFound) {
+		t.Errorf("lease not released after Close: %v", err)
+	}
+}
+
+func TestNode_WaitLeaseTTL(t *testing.T) {
+	leases := &memoryLeases{owners: map[string]string{}}
+	ttl := 50 * time.Millisecond
+	acquiredAt := time.Now()
+	node, err := NewNode(context.Background(), Config{LeaseTTLMillis: ttl.Milliseconds()}, leases.newLease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+	// 获得租约后一个租约时长内不生成 ID，上一个持有者可能用过的时间戳都已越过
+	id, err := node.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at, _, _ := Parse(id); at.Before(acquiredAt.Add(ttl).Truncate(time.Millisecond)) {
+		t.Errorf("first id at %v, want not before %v", at, acquiredAt.Add(ttl))
+	}
+}
+
+func TestNode_LeaseExpired(t *testing.T) {
+	leases := &memoryLeases{owners: map[string]string{}}
+	now := time.Now()
+	node, err := newNode(context.Background(), Config{}, leases.newLease, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer node.Close()
+	// 未能续约且已超过租约时长
+	now = now.Add(time.Minute)
+	if _, err := node.Next(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Next() error = %v, want ErrLeaseLost", err)
+	}
+}
@@ -0,0 +1,215 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	storage "github.com/NumberMan1/component/global-storage"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+const (
+	defaultPrefix   = "idgen:worker:"
+	defaultLeaseTTL = 30 * time.Second
+)
+
+var (
+	// ErrNoWorkerID 全部 worker ID 都已被其他实例持有
+	ErrNoWorkerID = errors.New("idgen: no free worker id")
+	// ErrLeaseLost worker ID 的租约已过期或被其他实例持有，为避免重复不再生成 ID
+	ErrLeaseLost = errors.New("idgen: worker id lease lost")
+)
+
+// Config 分配 worker ID 的配置
+type Config struct {
+	// 租约 key 的前缀，key 为前缀 + worker ID，为空时为 idgen:worker:
+	Prefix string `json:"prefix" yaml:"prefix"`
+	// 租约时长（毫秒），每 1/3 时长续约一次，<=0 时为 30000；获得 worker ID 后等待一个租约时长才生成第一个 ID
+	LeaseTTLMillis int64 `json:"lease_ttl_millis" yaml:"lease-ttl-millis"`
+	// 时间戳的起点（毫秒时间戳），<=0 时为 DefaultEpoch；同一业务的所有实例需一致
+	EpochMillis int64 `json:"epoch_millis" yaml:"epoch-millis"`
+}
+
+func (conf Config) prefix() string {
+	if conf.Prefix == "" {
+		return defaultPrefix
+	}
+	return conf.Prefix
+}
+
+func (conf Config) leaseTTL() time.Duration {
+	if conf.LeaseTTLMillis <= 0 {
+		return defaultLeaseTTL
+	}
+	return time.Duration(conf.LeaseTTLMillis) * time.Millisecond
+}
+
+func (conf Config) epoch() time.Time {
+	if conf.EpochMillis <= 0 {
+		return DefaultEpoch
+	}
+	return time.UnixMilli(conf.EpochMillis)
+}
+
+// LeaseFactory 按 key 创建租约，如 StorageManager.NewLease
+type LeaseFactory func(key string) storage.Lease
+
+// Node 通过 global-storage 的租约持有唯一的 worker ID 并生成 ID，后台定期续约；
+// 租约到期前未能续约时 Next 返回 ErrLeaseLost，避免与接手该 worker ID 的实例重复
+type Node struct {
+	generator *Generator
+	lease     storage.Lease
+	owner     string
+	ttl       time.Duration
+	now       func() time.Time
+	// readyAt 获得租约后一个租约时长，此前不生成 ID
+	readyAt time.Time
+
+	mu sync.RWMutex
+	// expiresAt 最近一次获得或续约成功的租约到期时间
+	expiresAt time.Time
+	lost      bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewNode 从随机位置依次尝试获得 worker ID 的租约，获得后开始续约
+func NewNode(ctx context.Context, config Config, newLease LeaseFactory) (*Node, error) {
+	return newNode(ctx, config, newLease, time.Now)
+}
+
+func newNode(ctx context.Context, config Config, newLease LeaseFactory, now func() time.Time) (*Node, error) {
+	hostname, _ := os.Hostname()
+	owner := hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+	ttl := config.leaseTTL()
+	start := rand.Int64N(MaxWorkerID + 1)
+	for i := int64(0); i <= MaxWorkerID; i++ {
+		workerID := (start + i) % (MaxWorkerID + 1)
+		lease := newLease(config.prefix() + strconv.FormatInt(workerID, 10))
+		acquiredAt := now()
+		ok, err := lease.Acquire(ctx, owner, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("idgen: acquire worker id %d: %w", workerID, err)
+		}
+		if !ok {
+			continue
+		}
+		generator, err := newGenerator(workerID, config.epoch(), now)
+		if err != nil {
+			return nil, err
+		}
+		renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		node := &Node{
+			generator: generator,
+			lease:     lease,
+			owner:     owner,
+			ttl:       ttl,
+			now:       now,
+			readyAt:   now().Add(ttl),
+			expiresAt: acquiredAt.Add(ttl),
+			cancel:    cancel,
+			done:      make(chan struct{}),
+		}
+		go node.renewLoop(renewCtx)
+		return node, nil
+	}
+	return nil, ErrNoWorkerID
+}
+
+// WorkerID 持有的 worker ID
+func (node *Node) WorkerID() int64 {
+	return node.generator.WorkerID()
+}
+
+// Next 生成下一个 ID，租约失效后返回 ErrLeaseLost。
+// 上一个持有者在租约到期前仍可能生成 ID，且其时钟可能快于本机，获得租约后的第一个租约时长内会等待，
+// 使本机的时间戳越过上一个持有者可能用过的时间戳，可容忍小于租约时长的时钟偏差
+func (node *Node) Next() (int64, error) {
+	if wait := node.readyAt.Sub(node.now()); wait > 0 {
+		time.Sleep(wait)
+	}
+	node.mu.RLock()
+	valid := !node.lost && node.now().Before(node.expiresAt)
+	node.mu.RUnlock()
+	if !valid {
+		return 0, ErrLeaseLost
+	}
+	return node.generator.Next()
+}
+
+func (node *Node) renewLoop(ctx context.Context) {
+	defer close(node.done)
+	ticker := time.NewTicker(node.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if !node.renew(ctx) {
+			return
+		}
+	}
+}
+
+// renew 续约一次，租约已被他人持有时返回 false；续约出错时等待下次重试，直到租约到期
+func (node *Node) renew(ctx context.Context) bool {
+	logger := zaplogger.DefaultLogger().Named("idgen")
+	renewedAt := node.now()
+	ok, err := node.lease.Renew(ctx, node.owner, node.ttl)
+	if err != nil {
+		logger.Warn("renew worker id lease failed", field.Int64("worker_id", node.WorkerID()), field.WithError(err))
+		return true
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if !ok {
+		node.lost = true
+		logger.Error("worker id lease lost", field.Int64("worker_id", node.WorkerID()))
+		return false
+	}
+	node.expiresAt = renewedAt.Add(node.ttl)
+	return true
+}
+
+// Close 停止续约并释放 worker ID，之后 Next 返回 ErrLeaseLost；可通过 lifecycle.Closer 注册
+func (node *Node) Close() error {
+	node.cancel()
+	<-node.done
+	node.mu.Lock()
+	node.lost = true
+	node.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), node.ttl)
+	defer cancel()
+	return node.lease.Release(ctx, node.owner)
+}
+
+var defaultNode *Node
+
+// InitNode 创建默认的 Node，之后可通过 NextID 生成 ID
+func InitNode(ctx context.Context, config Config, newLease LeaseFactory) (err error) {
+	defaultNode, err = NewNode(ctx, config, newLease)
+	return
+}
+
+// DefaultNode 返回 InitNode 创建的 Node，未初始化时为 nil
+func DefaultNode() *Node {
+	return defaultNode
+}
+
+// NextID 通过默认的 Node 生成 ID
+func NextID() (int64, error) {
+	if defaultNode == nil {
+		return 0, errors.New("idgen: default node not initialized")
+	}
+	return defaultNode.Next()
+}
@@ -0,0 +1,45 @@
+package lifecycle
+
+import (
+	"context"
+	"io"
+)
+
+// Closer 停止时调用 closer.Close 的组件，如 global-storage 的 StorageManager
+func Closer(name string, closer io.Closer, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Stop: func(ctx context.Context) error {
+			return closer.Close()
+		},
+	}
+}
+
+// Background 启动时在新协程中运行 run 的组件，停止时取消 run 的 ctx 并等待其返回，如 WatchConfigFile 等轮询任务
+func Background(name string, run func(ctx context.Context), dependsOn ...string) Component {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			go func() {
+				defer close(done)
+				run(runCtx)
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
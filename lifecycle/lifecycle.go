@@ -0,0 +1,181 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+const defaultStopTimeout = 30 * time.Second
+
+// Component 由 Manager 管理启动与停止的组件
+type Component struct {
+	// 组件名，在同一 Manager 中唯一
+	Name string
+	// 依赖的组件名，依赖先于本组件启动、后于本组件停止
+	DependsOn []string
+	// 启动，可为 nil；返回错误时 Run 停止已启动的组件并返回
+	Start func(ctx context.Context) error
+	// 停止，可为 nil；ctx 在 StopBudget 或 Manager 的停止超时用尽后结束
+	Stop func(ctx context.Context) error
+	// 停止的时间预算，如刷新日志 2s、写完存储 5s；<=0 时只受 Manager 的停止超时限制。
+	// 超出预算后不再等待该组件，继续停止其他组件，并在 ShutdownReport 中标记
+	StopBudget time.Duration
+}
+
+// Manager 按依赖顺序启动组件，收到退出信号或 ctx 结束时按相反顺序停止
+type Manager struct {
+	mu          sync.Mutex
+	components  []Component
+	started     []Component
+	stopTimeout time.Duration
+	signals     []os.Signal
+}
+
+func NewManager() *Manager {
+	return &Manager{stopTimeout: defaultStopTimeout, signals: []os.Signal{syscall.SIGINT, syscall.SIGTERM}}
+}
+
+// SetStopTimeout 设置停止全部组件的超时，默认 30 秒
+func (manager *Manager) SetStopTimeout(timeout time.Duration) {
+	manager.stopTimeout = timeout
+}
+
+// Register 注册组件，组件名重复时返回错误
+func (manager *Manager) Register(component Component) error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	if component.Name == "" {
+		return errors.New("lifecycle: component name is empty")
+	}
+	for _, registered := range manager.components {
+		if registered.Name == component.Name {
+			return fmt.Errorf("lifecycle: component %s already registered", component.Name)
+		}
+	}
+	manager.components = append(manager.components, component)
+	return nil
+}
+
+// order 按依赖排序，依赖在前；依赖不存在或循环依赖时返回错误，无依赖关系的组件保持注册顺序
+func (manager *Manager) order() ([]Component, error) {
+	byName := make(map[string]Component, len(manager.components))
+	for _, component := range manager.components {
+		byName[component.Name] = component
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(manager.components))
+	ordered := make([]Component, 0, len(manager.components))
+	var visit func(component Component, path []string) error
+	visit = func(component Component, path []string) error {
+		switch state[component.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("lifecycle: dependency cycle %v", append(path, component.Name))
+		}
+		state[component.Name] = visiting
+		for _, name := range component.DependsOn {
+			dependency, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("lifecycle: component %s depends on unknown component %s", component.Name, name)
+			}
+			if err := visit(dependency, append(path, component.Name)); err != nil {
+				return err
+			}
+		}
+		state[component.Name] = visited
+		ordered = append(ordered, component)
+		return nil
+	}
+	for _, component := range manager.components {
+		if err := visit(component, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Start 按依赖顺序启动全部组件，某个组件启动失败时停止已启动的组件并返回错误
+func (manager *Manager) Start(ctx context.Context) error {
+	manager.mu.Lock()
+	ordered, err := manager.order()
+	manager.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	logger := zaplogger.DefaultLogger().Named("lifecycle")
+	for _, component := range ordered {
+		start := time.Now()
+		if component.Start != nil {
+			if err := component.Start(ctx); err != nil {
+				logger.Error("component start failed", field.String("component", component.Name), field.WithError(err))
+				return errors.Join(fmt.Errorf("lifecycle: start %s: %w", component.Name, err), manager.Stop(context.Background()))
+			}
+		}
+		manager.mu.Lock()
+		manager.started = append(manager.started, component)
+		manager.mu.Unlock()
+		logger.Info("component started", field.String("component", component.Name), field.Duration("cost", time.Since(start)))
+	}
+	return nil
+}
+
+// Stop 按启动的相反顺序停止已启动的组件，全部停止后关闭默认日志，返回各组件的错误；停止详情见 Shutdown
+func (manager *Manager) Stop(ctx context.Context) error {
+	return manager.Shutdown(ctx).Err()
+}
+
+// Run 启动全部组件后阻塞，直到 ctx 结束或收到 SIGINT、SIGTERM，之后停止全部组件
+func (manager *Manager) Run(ctx context.Context) error {
+	if err := manager.Start(ctx); err != nil {
+		syncDefaultLogger()
+		return err
+	}
+	waitCtx, stop := signal.NotifyContext(ctx, manager.signals...)
+	<-waitCtx.Done()
+	stop()
+	zaplogger.DefaultLogger().Named("lifecycle").Info("stopping components", field.String("cause", context.Cause(waitCtx).Error()))
+	// ctx 已结束，停止时使用新的 ctx 以保证停止超时生效
+	return manager.Stop(context.WithoutCancel(ctx))
+}
+
+// syncDefaultLogger 刷新默认日志的缓冲
+func syncDefaultLogger() {
+	if logger, ok := zaplogger.DefaultLogger().(interface{ Sync() }); ok {
+		logger.Sync()
+	}
+}
+
+// closeDefaultLogger 写完并关闭默认日志，停止其异步队列等后台协程；不支持关闭时只刷新缓冲
+func closeDefaultLogger() {
+	if logger, ok := zaplogger.DefaultLogger().(io.Closer); ok {
+		_ = logger.Close()
+		return
+	}
+	syncDefaultLogger()
+}
+
+var defaultManager = NewManager()
+
+// Register 向默认 Manager 注册组件
+func Register(component Component) error {
+	return defaultManager.Register(component)
+}
+
+// Run 运行默认 Manager，见 Manager.Run
+func Run(ctx context.Context) error {
+	return defaultManager.Run(ctx)
+}
@@ -0,0 +1,161 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder 记录组件启动与停止的顺序
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) component(name string, startErr error, dependsOn ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		Start: func(ctx context.Context) error {
+			r.events = append(r.events, "start "+name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			r.events = append(r.events, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	r := &recorder{}
+	manager := NewManager()
+	for _, component := range []Component{
+		r.component("api", nil, "storage", "logger"),
+		r.component("storage", nil, "logger"),
+		r.component("logger", nil),
+	} {
+		if err := manager.Register(component); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := manager.Register(Component{Name: "api"}); err == nil {
+		t.Errorf("Register(duplicate) error = nil")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- manager.Run(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"start logger", "start storage", "start api", "stop api", "stop storage", "stop logger"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestManager_StartFailed(t *testing.T) {
+	r := &recorder{}
+	manager := NewManager()
+	_ = manager.Register(r.component("logger", nil))
+	_ = manager.Register(r.component("storage", errors.New("dial failed"), "logger"))
+	_ = manager.Register(r.component("api", nil, "storage"))
+
+	err := manager.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "dial failed") {
+		t.Fatalf("Run() error = %v, want start error", err)
+	}
+	want := []string{"start logger", "start storage", "stop logger"}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+}
+
+func TestManager_Order(t *testing.T) {
+	tests := []struct {
+		name       string
+		components []Component
+		err        string
+	}{
+		{name: "依赖不存在", components: []Component{{Name: "a", DependsOn: []string{"b"}}}, err: "unknown component b"},
+		{name: "循环依赖", components: []Component{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}}, err: "cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager()
+			for _, component := range tt.components {
+				_ = manager.Register(component)
+			}
+			if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Start() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestBackground(t *testing.T) {
+	stopped := make(chan struct{})
+	component := Background("watcher", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	if err := component.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := component.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Errorf("run not returned after Stop")
+	}
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	manager := NewManager()
+	noop := func(ctx context.Context) error { return nil }
+	for _, component := range []Component{
+		{Name: "logger", Start: noop, Stop: noop, StopBudget: time.Second},
+		{
+			Name:  "storage",
+			Start: noop,
+			// 忽略 ctx，超出预算后不再等待
+			Stop: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+			StopBudget: 20 * time.Millisecond,
+			DependsOn:  []string{"logger"},
+		},
+		{
+			Name:       "verify",
+			Start:      noop,
+			Stop:       func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() },
+			StopBudget: 10 * time.Millisecond,
+			DependsOn:  []string{"logger"},
+		},
+	} {
+		if err := manager.Register(component); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	report := manager.Shutdown(context.Background())
+	if got, want := report.Exceeded(), []string{"verify", "storage"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Exceeded() = %v, want %v", got, want)
+	}
+	if report.Duration >= time.Second {
+		t.Errorf("Duration = %v, want < 1s", report.Duration)
+	}
+	if err := report.Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Err() = %v, want DeadlineExceeded", err)
+	}
+}
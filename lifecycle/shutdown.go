@@ -0,0 +1,115 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// StopResult 单个组件的停止结果
+type StopResult struct {
+	Name     string        `json:"name"`
+	Budget   time.Duration `json:"budget"`
+	Duration time.Duration `json:"duration"`
+	// 超出 StopBudget 或 Manager 的停止超时仍未返回
+	Exceeded bool  `json:"exceeded"`
+	Err      error `json:"-"`
+}
+
+// ShutdownReport 一次停止的结果，按停止顺序排列
+type ShutdownReport struct {
+	Components []StopResult
+	Duration   time.Duration
+}
+
+// Exceeded 超出预算的组件名
+func (report ShutdownReport) Exceeded() []string {
+	var names []string
+	for _, result := range report.Components {
+		if result.Exceeded {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// Err 各组件停止的错误，超出预算的组件为 context.DeadlineExceeded
+func (report ShutdownReport) Err() error {
+	var errs []error
+	for _, result := range report.Components {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("lifecycle: stop %s: %w", result.Name, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown 按启动的相反顺序停止已启动的组件，每个组件的停止受 StopBudget 与剩余的停止超时限制，
+// 超出的组件不再等待；全部停止后关闭默认日志，并记录超出预算的组件
+func (manager *Manager) Shutdown(ctx context.Context) ShutdownReport {
+	manager.mu.Lock()
+	started := manager.started
+	manager.started = nil
+	manager.mu.Unlock()
+
+	begin := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, manager.stopTimeout)
+	defer cancel()
+	logger := zaplogger.DefaultLogger().Named("lifecycle")
+	report := ShutdownReport{}
+	for i := len(started) - 1; i >= 0; i-- {
+		component := started[i]
+		if component.Stop == nil {
+			continue
+		}
+		result := stopComponent(ctx, component)
+		report.Components = append(report.Components, result)
+		fields := []field.Field{
+			field.String("component", component.Name),
+			field.Duration("cost", result.Duration),
+			field.Duration("budget", result.Budget),
+		}
+		switch {
+		case result.Exceeded:
+			logger.Warn("component stop exceeded budget", fields...)
+		case result.Err != nil:
+			logger.Error("component stop failed", append(fields, field.WithError(result.Err))...)
+		default:
+			logger.Info("component stopped", fields...)
+		}
+	}
+	report.Duration = time.Since(begin)
+	if exceeded := report.Exceeded(); len(exceeded) > 0 {
+		logger.Warn("shutdown exceeded budgets", field.Strings("components", exceeded), field.Duration("cost", report.Duration))
+	}
+	closeDefaultLogger()
+	return report
+}
+
+// stopComponent 在预算内停止组件，Stop 未在预算内返回时不再等待
+func stopComponent(ctx context.Context, component Component) StopResult {
+	result := StopResult{Name: component.Name, Budget: component.StopBudget}
+	if component.StopBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, component.StopBudget)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- component.Stop(ctx)
+	}()
+	select {
+	case result.Err = <-done:
+		// Stop 因 ctx 结束而返回时同样视为超出预算
+		result.Exceeded = ctx.Err() != nil && errors.Is(result.Err, ctx.Err())
+	case <-ctx.Done():
+		result.Err, result.Exceeded = ctx.Err(), true
+	}
+	result.Duration = time.Since(start)
+	return result
+}
@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	antiaddiction "github.com/NumberMan1/component/anti-addiction"
+	idcardsdk "github.com/NumberMan1/component/idcard-sdk"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// InstrumentLogger 按等级与模块统计 zap-logger 输出的日志条数：component_log_entries_total
+func InstrumentLogger() {
+	entries := NewCounterVec("log", "entries_total", "Log entries written by level and module.", "level", "module")
+	zaplogger.SetMetricsHook(func(level zapcore.Level, module string) {
+		entries.WithLabelValues(level.String(), module).Inc()
+	})
+}
+
+// antiAddictionMetrics 以 Prometheus 实现 anti-addiction 的 Metrics
+type antiAddictionMetrics struct {
+	playChecks     *prometheus.CounterVec
+	purchaseChecks *prometheus.CounterVec
+	amounts        *prometheus.HistogramVec
+}
+
+// AntiAddictionMetrics 防沉迷检查的指标，通过 checker.SetMetrics 设置：
+// component_anti_addiction_play_checks_total、purchase_checks_total 与 purchase_amount
+func AntiAddictionMetrics() antiaddiction.Metrics {
+	return &antiAddictionMetrics{
+		playChecks: NewCounterVec("anti_addiction", "play_checks_total",
+			"Play checks by age bracket, result and deny reason.", "bracket", "allowed", "reason"),
+		purchaseChecks: NewCounterVec("anti_addiction", "purchase_checks_total",
+			"Purchase checks by age bracket, result and deny reason.", "bracket", "allowed", "reason"),
+		amounts: NewHistogramVec("anti_addiction", "purchase_amount",
+			"Purchase amount in minor currency units by age bracket.",
+			[]float64{100, 600, 1000, 3000, 5000, 10000, 20000, 50000}, "bracket"),
+	}
+}
+
+func (m *antiAddictionMetrics) IncPlayCheck(bracket string, allowed bool, reason antiaddiction.DenyReason) {
+	m.playChecks.WithLabelValues(bracket, strconv.FormatBool(allowed), reason.String()).Inc()
+}
+
+func (m *antiAddictionMetrics) IncPurchaseCheck(bracket string, allowed bool, reason antiaddiction.DenyReason) {
+	m.purchaseChecks.WithLabelValues(bracket, strconv.FormatBool(allowed), reason.String()).Inc()
+}
+
+func (m *antiAddictionMetrics) ObservePurchaseAmount(bracket string, amount int64) {
+	m.amounts.WithLabelValues(bracket).Observe(float64(amount))
+}
+
+// idCardAuditSink 统计实名认证结果与延迟后转发到下一个 AuditSink
+type idCardAuditSink struct {
+	next      idcardsdk.AuditSink
+	verifies  *prometheus.CounterVec
+	latencies *prometheus.HistogramVec
+}
+
+// IdCardAuditSink 实名认证的指标，以 idcard-sdk 的 NewAuditSDK 接入：
+// component_idcard_verifications_total 与 verification_seconds，next 不为 nil 时继续记录审计
+func IdCardAuditSink(next idcardsdk.AuditSink) idcardsdk.AuditSink {
+	return &idCardAuditSink{
+		next: next,
+		verifies: NewCounterVec("idcard", "verifications_total",
+			"Identity verifications by provider and status.", "provider", "status"),
+		latencies: NewHistogramVec("idcard", "verification_seconds",
+			"Identity verification latency by provider.", nil, "provider"),
+	}
+}
+
+func (sink *idCardAuditSink) Record(ctx context.Context, entry idcardsdk.AuditEntry) error {
+	sink.verifies.WithLabelValues(entry.Provider, entry.Status.String()).Inc()
+	sink.latencies.WithLabelValues(entry.Provider).Observe(float64(entry.LatencyMillis) / 1000)
+	if sink.next == nil {
+		return nil
+	}
+	return sink.next.Record(ctx, entry)
+}
+
+// redisStartKey 命令开始时间在 ctx 中的 key
+type redisStartKey struct{}
+
+// redisHook 统计 Redis 命令的次数、错误与延迟
+type redisHook struct {
+	commands  *prometheus.CounterVec
+	latencies *prometheus.HistogramVec
+}
+
+// RedisHook global-storage 的 Redis 命令指标，以 StorageManager.AddHook 接入：
+// component_global_storage_redis_commands_total 与 redis_command_seconds，redis.Nil 不计为错误
+func RedisHook() redis.Hook {
+	return &redisHook{
+		commands: NewCounterVec("global_storage", "redis_commands_total",
+			"Redis commands by command name and result.", "command", "result"),
+		latencies: NewHistogramVec("global_storage", "redis_command_seconds",
+			"Redis command latency by command name.",
+			[]float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}, "command"),
+	}
+}
+
+func (hook *redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (hook *redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	hook.observe(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (hook *redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (hook *redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+			break
+		}
+	}
+	hook.observe(ctx, "pipeline", err)
+	return nil
+}
+
+func (hook *redisHook) observe(ctx context.Context, command string, err error) {
+	result := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		result = "error"
+	}
+	hook.commands.WithLabelValues(command, result).Inc()
+	if start, ok := ctx.Value(redisStartKey{}).(time.Time); ok {
+		hook.latencies.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	}
+}
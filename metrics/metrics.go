@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 各组件指标名的前缀，如 component_global_storage_redis_commands_total
+const Namespace = "component"
+
+var registry = newRegistry()
+
+// newRegistry 创建包含 Go 运行时与进程指标的 Registry
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return r
+}
+
+// Registry 各组件共享的 Registry，业务自定义的指标也可注册在此，由 Handler 统一暴露
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Handler 以 Prometheus 文本格式暴露 Registry 中的指标，通常挂载在 /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// register 注册指标，已注册同名同标签的指标时返回已注册的指标，多个实例可共享同一指标
+func register[T prometheus.Collector](collector T) T {
+	if err := registry.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return collector
+}
+
+// NewCounterVec 在共享的 Registry 中注册计数器，名称为 component_<subsystem>_<name>
+func NewCounterVec(subsystem, name, help string, labels ...string) *prometheus.CounterVec {
+	return register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels))
+}
+
+// NewGaugeVec 在共享的 Registry 中注册仪表盘
+func NewGaugeVec(subsystem, name, help string, labels ...string) *prometheus.GaugeVec {
+	return register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels))
+}
+
+// NewHistogramVec 在共享的 Registry 中注册直方图，buckets 为 nil 时使用 prometheus.DefBuckets
+func NewHistogramVec(subsystem, name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	return register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels))
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	antiaddiction "github.com/NumberMan1/component/anti-addiction"
+	idcardsdk "github.com/NumberMan1/component/idcard-sdk"
+	zaplogger "github.com/NumberMan1/component/zap-logger"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNewCounterVec_Shared(t *testing.T) {
+	first := NewCounterVec("test", "shared_total", "Shared counter.", "kind")
+	second := NewCounterVec("test", "shared_total", "Shared counter.", "kind")
+	first.WithLabelValues("a").Inc()
+	second.WithLabelValues("a").Inc()
+	if got := testutil.ToFloat64(first.WithLabelValues("a")); got != 2 {
+		t.Errorf("shared counter = %v, want 2", got)
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	InstrumentLogger()
+	defer zaplogger.SetMetricsHook(nil)
+	logger := zaplogger.NewZapLoggerWithConfig(zaplogger.Config{Level: "info", Outputs: []zaplogger.TeeConfig{{Target: t.TempDir() + "/app.log"}}})
+	logger.Named("storage").Error("failed")
+
+	m := AntiAddictionMetrics()
+	m.IncPurchaseCheck("8-16", false, antiaddiction.DenyReasonCurfew)
+	m.ObservePurchaseAmount("8-16", 600)
+
+	var forwarded int
+	sink := IdCardAuditSink(auditSinkFunc(func() { forwarded++ }))
+	_ = sink.Record(context.Background(), idcardsdk.AuditEntry{Provider: "nppa", Status: idcardsdk.VerifyMatch, LatencyMillis: 120})
+
+	hook := RedisHook()
+	for _, err := range []error{nil, redis.Nil, errors.New("timeout")} {
+		cmd := redis.NewStringCmd(context.Background(), "get", "key")
+		cmd.SetErr(err)
+		ctx, _ := hook.BeforeProcess(context.Background(), cmd)
+		_ = hook.AfterProcess(ctx, cmd)
+	}
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+	for _, want := range []string{
+		`component_log_entries_total{level="error",module="storage"} 1`,
+		`component_anti_addiction_purchase_checks_total{allowed="false",bracket="8-16",reason="curfew"} 1`,
+		`component_anti_addiction_purchase_amount_count{bracket="8-16"} 1`,
+		`component_idcard_verifications_total{provider="nppa",status="match"} 1`,
+		`component_global_storage_redis_commands_total{command="get",result="ok"} 2`,
+		`component_global_storage_redis_commands_total{command="get",result="error"} 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if forwarded != 1 {
+		t.Errorf("forwarded = %d, want 1", forwarded)
+	}
+}
+
+type auditSinkFunc func()
+
+func (fn auditSinkFunc) Record(ctx context.Context, entry idcardsdk.AuditEntry) error {
+	fn()
+	return nil
+}
@@ -0,0 +1,166 @@
+package zap_logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncQueueSize = 8192
+	// AsyncDropNewest 队列满时丢弃当前日志，调用方不会被阻塞
+	AsyncDropNewest = "drop-newest"
+	// AsyncBlock 队列满时阻塞等待，不丢弃日志
+	AsyncBlock = "block"
+)
+
+// AsyncConfig 异步写入配置，开启后日志先进入有界队列，由后台协程写入各输出，磁盘阻塞不会阻塞调用方
+type AsyncConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 队列长度，<=0 时为 8192
+	QueueSize int `json:"queue_size" yaml:"queue-size"`
+	// 队列满时的策略：AsyncDropNewest（默认）或 AsyncBlock
+	DropPolicy string `json:"drop_policy" yaml:"drop-policy"`
+}
+
+// asyncItem 队列中的一条日志，core 为 With 之后的输出；done 不为 nil 时为 Sync 的标记
+type asyncItem struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+	done   chan struct{}
+}
+
+// asyncQueue 所有 With 出的 asyncCore 共享的队列与后台写入协程
+type asyncQueue struct {
+	items   chan asyncItem
+	block   bool
+	dropped atomic.Uint64
+
+	// mu 使放入队列与关闭队列互斥，关闭后放入的日志计入 dropped
+	mu     sync.RWMutex
+	closed bool
+	// done 后台协程写完队列后退出时关闭
+	done chan struct{}
+}
+
+func newAsyncQueue(config AsyncConfig) *asyncQueue {
+	size := config.QueueSize
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+	queue := &asyncQueue{
+		items: make(chan asyncItem, size),
+		block: config.DropPolicy == AsyncBlock,
+		done:  make(chan struct{}),
+	}
+	go queue.run()
+	return queue
+}
+
+func (queue *asyncQueue) run() {
+	defer close(queue.done)
+	for item := range queue.items {
+		if item.done != nil {
+			close(item.done)
+			continue
+		}
+		_ = writeChecked(item.core, item.entry, item.fields)
+	}
+}
+
+func (queue *asyncQueue) push(item asyncItem) {
+	queue.mu.RLock()
+	defer queue.mu.RUnlock()
+	if queue.closed {
+		queue.dropped.Add(1)
+		return
+	}
+	if queue.block {
+		queue.items <- item
+		return
+	}
+	select {
+	case queue.items <- item:
+	default:
+		queue.dropped.Add(1)
+	}
+}
+
+// drain 等待此前进入队列的日志全部写入
+func (queue *asyncQueue) drain() {
+	queue.mu.RLock()
+	if queue.closed {
+		queue.mu.RUnlock()
+		<-queue.done
+		return
+	}
+	done := make(chan struct{})
+	queue.items <- asyncItem{done: done}
+	queue.mu.RUnlock()
+	<-done
+}
+
+// close 停止接收日志，等待队列中的日志写完后后台协程退出；可重复调用
+func (queue *asyncQueue) close() {
+	queue.mu.Lock()
+	if !queue.closed {
+		queue.closed = true
+		close(queue.items)
+	}
+	queue.mu.Unlock()
+	<-queue.done
+}
+
+// asyncCore 将日志放入队列后立即返回；panic 与 fatal 等级先写完队列再同步写入，保证退出前输出
+type asyncCore struct {
+	core  zapcore.Core
+	queue *asyncQueue
+}
+
+func newAsyncCore(core zapcore.Core, config AsyncConfig) *asyncCore {
+	return &asyncCore{core: core, queue: newAsyncQueue(config)}
+}
+
+func (core *asyncCore) Enabled(level zapcore.Level) bool {
+	return core.core.Enabled(level)
+}
+
+func (core *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	return &asyncCore{core: core.core.With(fields), queue: core.queue}
+}
+
+func (core *asyncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level > zapcore.ErrorLevel {
+		core.queue.drain()
+		return writeChecked(core.core, entry, fields)
+	}
+	// fields 在 Write 返回后可能被复用，需复制
+	core.queue.push(asyncItem{core: core.core, entry: entry, fields: append([]zapcore.Field(nil), fields...)})
+	return nil
+}
+
+// Sync 等待队列中的日志写入后刷新各输出，退出前调用
+func (core *asyncCore) Sync() error {
+	core.queue.drain()
+	return core.core.Sync()
+}
+
+// Close 写完队列中的日志后停止后台协程，之后的日志计入 Dropped；不关闭内部的输出
+func (core *asyncCore) Close() error {
+	core.queue.close()
+	return nil
+}
+
+// Dropped 队列满或关闭后被丢弃的日志条数
+func (core *asyncCore) Dropped() uint64 {
+	return core.queue.dropped.Load()
+}
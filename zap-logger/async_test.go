@@ -0,0 +1,116 @@
+package zap_logger
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// blockingCore 在 release 关闭前阻塞写入，模拟磁盘阻塞
+type blockingCore struct {
+	zapcore.Core
+	release chan struct{}
+}
+
+func (core *blockingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, core)
+}
+
+func (core *blockingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	<-core.release
+	return core.Core.Write(entry, fields)
+}
+
+func TestAsyncCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	inner := &blockingCore{Core: observed, release: make(chan struct{})}
+	async := newAsyncCore(inner, AsyncConfig{QueueSize: 2})
+	base := zap.New(async)
+	logger := &ZapLogger{base: base, root: base}
+
+	// 后台协程阻塞在第一条，队列容纳两条，其余被丢弃且调用方不阻塞
+	for i := 0; i < 10; i++ {
+		logger.Info("message")
+	}
+	if async.Dropped() == 0 {
+		t.Errorf("Dropped() = 0, want > 0")
+	}
+	close(inner.release)
+	logger.Sync()
+	if got := logs.Len() + int(async.Dropped()); got != 10 {
+		t.Errorf("written + dropped = %d, want 10", got)
+	}
+}
+
+func TestAsyncCore_OutputLevels(t *testing.T) {
+	debug, debugLogs := observer.New(zapcore.DebugLevel)
+	warn, warnLogs := observer.New(zapcore.WarnLevel)
+	async := newAsyncCore(zapcore.NewTee(debug, warn), AsyncConfig{})
+	base := zap.New(async)
+	logger := &ZapLogger{base: base, root: base}
+
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Sync()
+	// 各输出仍按自身等级过滤
+	if debugLogs.Len() != 2 || warnLogs.Len() != 1 {
+		t.Errorf("debug = %d, warn = %d, want 2, 1", debugLogs.Len(), warnLogs.Len())
+	}
+}
+
+func TestAsyncCore_Close(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	async := newAsyncCore(observed, AsyncConfig{DropPolicy: AsyncBlock})
+	logger := zap.New(async)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("message")
+	}
+	// 关闭前进入队列的日志全部写入，之后的日志被丢弃
+	if err := async.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if logs.Len() != 5 {
+		t.Errorf("written = %d, want 5", logs.Len())
+	}
+	logger.Info("after close")
+	_ = logger.Sync()
+	if logs.Len() != 5 || async.Dropped() != 1 {
+		t.Errorf("written = %d, dropped = %d, want 5, 1", logs.Len(), async.Dropped())
+	}
+	if err := async.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestZapLogger_ReloadClosesAsync(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "app.log")
+	config := Config{Level: "info", Outputs: []TeeConfig{{Target: target}}, Async: AsyncConfig{Enabled: true}}
+	logger := NewZapLoggerWithConfig(config).(*ZapLogger)
+	old := logger.reload.current.Load().async
+
+	logger.Info("before reload")
+	if err := logger.Reload(config); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	// 旧队列的后台协程已退出
+	select {
+	case <-old.queue.done:
+	default:
+		t.Error("old async queue still running after Reload")
+	}
+
+	current := logger.reload.current.Load().async
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case <-current.queue.done:
+	default:
+		t.Error("async queue still running after Close")
+	}
+	logger.Info("after close")
+}
@@ -1,15 +1,129 @@
 package zap_logger
 
-import "github.com/NumberMan1/log"
+import (
+	"slices"
+
+	"github.com/NumberMan1/log"
+)
+
+const (
+	// EncodingJSON 每行一个 JSON 对象
+	EncodingJSON = "json"
+	// EncodingConsole 便于阅读的文本格式
+	EncodingConsole = "console"
+	// EncodingColorConsole 便于本地开发阅读的彩色文本格式：时间只保留时分秒，等级与模块名带颜色，调用位置只保留文件名
+	EncodingColorConsole = "color-console"
+)
+
+// StacktraceNone 不自动附加调用栈，用于 Config.StacktraceLevel
+const StacktraceNone log.Level = "none"
+
+// OutputConfig 单个输出的编码与等级
+type OutputConfig struct {
+	// 编码格式：EncodingJSON、EncodingConsole 或 EncodingColorConsole，为空时使用该输出的默认格式
+	Encoding string `json:"encoding" yaml:"encoding"`
+	// 该输出的最低等级，为空时使用 Config.Level
+	Level log.Level `json:"level" yaml:"level"`
+}
+
+const (
+	// OutputStdout 标准输出，用于 TeeConfig.Target
+	OutputStdout = "stdout"
+	// OutputStderr 标准错误，用于 TeeConfig.Target
+	OutputStderr = "stderr"
+)
+
+// TeeConfig 额外的输出目标，与其他输出同时写入，等级相互独立
+type TeeConfig struct {
+	// 输出目标：OutputStdout、OutputStderr 或文件路径，文件按 Config.Rotate 切分
+	Target string `json:"target" yaml:"target"`
+	// 编码格式，为空时控制台为 console，文件为 json
+	Encoding string `json:"encoding" yaml:"encoding"`
+	// 最低等级，为空时使用 Config.Level
+	Level log.Level `json:"level" yaml:"level"`
+	// 最高等级，为空时不限制
+	MaxLevel log.Level `json:"max_level" yaml:"max-level"`
+}
 
 type Config struct {
-	Name        string    `json:"name" yaml:"name"`
-	Level       log.Level `json:"level" yaml:"level"`
-	StdoutTyp   string    `json:"stdout_typ" yaml:"stdout-typ"`
-	Stdout      bool      `json:"stdout" yaml:"stdout"`
-	LogFilePath string    `json:"log_file_path" yaml:"log-file-path"`
+	Name  string    `json:"name" yaml:"name"`
+	Level log.Level `json:"level" yaml:"level"`
+	// 控制台输出的编码：EncodingJSON、EncodingConsole 或 EncodingColorConsole，为空时为 console
+	StdoutTyp   string `json:"stdout_typ" yaml:"stdout-typ"`
+	Stdout      bool   `json:"stdout" yaml:"stdout"`
+	LogFilePath string `json:"log_file_path" yaml:"log-file-path"`
+	// 文件输出的切分与清理策略
+	Rotate RotateConfig `json:"rotate" yaml:"rotate"`
+	// 控制台输出的编码与等级，Encoding 为空时按 StdoutTyp 选择 json 或 console
+	StdoutOutput OutputConfig `json:"stdout_output" yaml:"stdout-output"`
+	// 文件输出的编码与等级，Encoding 为空时为 json
+	FileOutput OutputConfig `json:"file_output" yaml:"file-output"`
+	// 自动附加调用栈的最低等级，为空时为 error，StacktraceNone 时不附加
+	StacktraceLevel log.Level `json:"stacktrace_level" yaml:"stacktrace-level"`
+	// 每条日志附加的主机名、Pod 名称、环境、版本与区域
+	GlobalFields GlobalFieldsConfig `json:"global_fields" yaml:"global-fields"`
+	// 开发模式：DPanic 会 panic，未配置编码的控制台输出使用 color-console、其他输出使用 console，强制输出调用位置，warn 及以上附加调用栈；
+	// 未开启任何输出时输出到控制台，仅用于本地运行
+	Development bool `json:"development" yaml:"development"`
+	// 按 Named 模块名覆盖 Level，如 storage: debug、network: warn；storage 同时作用于 storage.redis 等子模块
+	Modules map[string]log.Level `json:"modules" yaml:"modules"`
+	// 允许通过 EnableDebugTrace、EnableDebugPlayer 或 WatchDebugTargets 对指定请求或玩家临时输出 debug 日志；
+	// 开启后未单独配置等级的输出以 debug 创建，再按 Level 过滤
+	RequestDebug bool `json:"request_debug" yaml:"request-debug"`
+	// 不输出调用位置
+	DisableCaller bool `json:"disable_caller" yaml:"disable-caller"`
+	// 异步写入，开启后调用方不会被输出阻塞
+	Async AsyncConfig `json:"async" yaml:"async"`
+	// 合并窗口内重复的日志，避免连接反复断开等故障时输出大量相同的日志
+	Dedup DedupConfig `json:"dedup" yaml:"dedup"`
+	// 额外的错误日志文件路径，在其他输出之外收录 ErrorFileLevel 及以上的日志，便于值班时 tail；为空时不输出
+	ErrorFile string `json:"error_file" yaml:"error-file"`
+	// 错误日志文件的最低等级，为空时为 warn
+	ErrorFileLevel log.Level `json:"error_file_level" yaml:"error-file-level"`
+	// 额外的输出目标，如全部等级写入文件的同时 warn 及以上写入 stderr
+	Outputs []TeeConfig `json:"outputs" yaml:"outputs"`
+	// 脱敏规则，保证密钥、token 等不会写入任何输出
+	Redact RedactConfig `json:"redact" yaml:"redact"`
+	// 额外的日志输出，如 Loki、Kafka 或 HTTP 批量接口，见 RegisterSink
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks"`
 }
 
 func (conf Config) OutputFile() bool {
 	return conf.LogFilePath != ""
 }
+
+// errorFileOutput 错误日志文件对应的输出，编码与文件输出相同
+func (conf Config) errorFileOutput() TeeConfig {
+	level := conf.ErrorFileLevel
+	if level == "" {
+		level = log.WARN
+	}
+	return TeeConfig{Target: conf.ErrorFile, Encoding: conf.FileOutput.Encoding, Level: level}
+}
+
+// developmentDefaults 开发模式下补全的配置，非开发模式原样返回
+func (conf Config) developmentDefaults() Config {
+	if !conf.Development {
+		return conf
+	}
+	if !conf.Stdout && !conf.OutputFile() && len(conf.Outputs) == 0 && len(conf.Sinks) == 0 {
+		conf.Stdout = true
+	}
+	if conf.StdoutOutput.Encoding == "" && conf.StdoutTyp == "" {
+		conf.StdoutOutput.Encoding = EncodingColorConsole
+	}
+	if conf.FileOutput.Encoding == "" {
+		conf.FileOutput.Encoding = EncodingConsole
+	}
+	conf.Outputs = slices.Clone(conf.Outputs)
+	for i := range conf.Outputs {
+		if conf.Outputs[i].Encoding == "" {
+			conf.Outputs[i].Encoding = EncodingConsole
+		}
+	}
+	conf.DisableCaller = false
+	if conf.StacktraceLevel == "" {
+		conf.StacktraceLevel = log.WARN
+	}
+	return conf
+}
@@ -0,0 +1,148 @@
+package zap_logger
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"github.com/NumberMan1/general/sign"
+	"go.uber.org/zap/zapcore"
+)
+
+// SpanContext 链路追踪的标识，字段为空时不输出
+type SpanContext struct {
+	TraceId      string
+	SpanId       string
+	ParentSpanId string
+}
+
+// SpanExtractor 从 ctx 中读取链路追踪标识，如 OpenTelemetry 的 trace.SpanContextFromContext
+type SpanExtractor func(ctx context.Context) (SpanContext, bool)
+
+var spanExtractor atomic.Pointer[SpanExtractor]
+
+// SetSpanExtractor 设置链路追踪标识的读取方式，优先于 sign.TRACE_ID 等约定的 ctx 值；为 nil 时取消
+//
+// 接入 OpenTelemetry 时：
+//
+//	zaplogger.SetSpanExtractor(func(ctx context.Context) (zaplogger.SpanContext, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return zaplogger.SpanContext{TraceId: sc.TraceID().String(), SpanId: sc.SpanID().String()}, sc.IsValid()
+//	})
+func SetSpanExtractor(extractor SpanExtractor) {
+	if extractor == nil {
+		spanExtractor.Store(nil)
+		return
+	}
+	spanExtractor.Store(&extractor)
+}
+
+// ContextWithSpan 按 sign.TRACE_ID、sign.SPAN_ID 与 sign.PARENT_SPAN_ID 约定将链路追踪标识写入 ctx
+func ContextWithSpan(ctx context.Context, span SpanContext) context.Context {
+	ctx = context.WithValue(ctx, sign.TRACE_ID, span.TraceId)
+	ctx = context.WithValue(ctx, sign.SPAN_ID, span.SpanId)
+	return context.WithValue(ctx, sign.PARENT_SPAN_ID, span.ParentSpanId)
+}
+
+// SpanFromContext 读取 ctx 中的链路追踪标识，优先使用 SetSpanExtractor 设置的方式
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	if extractor := spanExtractor.Load(); extractor != nil {
+		if span, ok := (*extractor)(ctx); ok {
+			return span, true
+		}
+	}
+	span := SpanContext{
+		TraceId:      signValue(ctx, sign.TRACE_ID),
+		SpanId:       signValue(ctx, sign.SPAN_ID),
+		ParentSpanId: signValue(ctx, sign.PARENT_SPAN_ID),
+	}
+	return span, span != SpanContext{}
+}
+
+// signValue 读取以 sign 或其字符串为 key 的字符串值
+func signValue(ctx context.Context, key sign.Sign) string {
+	if value, ok := ctx.Value(key).(string); ok {
+		return value
+	}
+	value, _ := ctx.Value(key.String()).(string)
+	return value
+}
+
+// SpanFields ctx 中链路追踪标识对应的日志字段，没有时为空
+func SpanFields(ctx context.Context) []field.Field {
+	span, ok := SpanFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	fields := make([]field.Field, 0, 3)
+	if span.TraceId != "" {
+		fields = append(fields, field.WithTraceId(span.TraceId))
+	}
+	if span.SpanId != "" {
+		fields = append(fields, field.WithSpanId(span.SpanId))
+	}
+	if span.ParentSpanId != "" {
+		fields = append(fields, field.WithParentSpanId(span.ParentSpanId))
+	}
+	return fields
+}
+
+// FromContext 返回 ctx 中的请求日志；没有时返回附加了 ctx 中 trace_id、span_id 与 parent_span_id 的默认日志，
+// 代替在每个调用处手动 With(field.WithTraceId(...))
+func FromContext(ctx context.Context) Logger {
+	if logger, err := GetLoggerCtx(ctx); err == nil {
+		return logger
+	}
+	if fields := SpanFields(ctx); len(fields) > 0 {
+		return DefaultLogger().With(fields...)
+	}
+	return DefaultLogger()
+}
+
+// ctxFieldsKey 请求日志累积的字段在 ctx 中的 key
+type ctxFieldsKey struct{}
+
+// FieldsFromCtx 读取 ContextWithRequestLogger、AppendCtxFields 与 AddFieldsToCtxLogger 累积在 ctx 中的字段，
+// 可转发到下游调用，见 FieldsToMetadata
+func FieldsFromCtx(ctx context.Context) []field.Field {
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]field.Field)
+	return slices.Clone(fields)
+}
+
+// AppendCtxFields 返回追加了字段的 ctx，ctx 中有请求日志时同时附加到请求日志
+func AppendCtxFields(ctx context.Context, fields ...field.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	if logger, err := GetLoggerCtx(ctx); err == nil {
+		ctx = context.WithValue(ctx, sign.LOGGER, logger.With(fields...))
+	}
+	return context.WithValue(ctx, ctxFieldsKey{}, appendFields(ctx, fields))
+}
+
+// appendFields ctx 中累积的字段追加 fields 后的副本，不修改 ctx 中的切片
+func appendFields(ctx context.Context, fields []field.Field) []field.Field {
+	return append(FieldsFromCtx(ctx), fields...)
+}
+
+// FieldsToMetadata 将字段转换为字符串键值，用于写入下游 RPC 的 metadata 或 HTTP 请求头；
+// 非字符串的值以 fmt.Sprint 格式化，Namespace 等没有值的字段被忽略
+func FieldsToMetadata(fields []field.Field) map[string]string {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		if f.Type != zapcore.NamespaceType {
+			f.AddTo(encoder)
+		}
+	}
+	metadata := make(map[string]string, len(encoder.Fields))
+	for key, value := range encoder.Fields {
+		if s, ok := value.(string); ok {
+			metadata[key] = s
+			continue
+		}
+		metadata[key] = fmt.Sprint(value)
+	}
+	return metadata
+}
@@ -0,0 +1,87 @@
+package zap_logger
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	contextimpl "github.com/NumberMan1/general/context/implement"
+	"github.com/NumberMan1/general/sign"
+	"go.uber.org/zap"
+)
+
+func TestFromContext(t *testing.T) {
+	logger, logs := NewTestLogger()
+	defer SetDefaultLogger(logger)()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		extractor SpanExtractor
+		want      map[string]any
+	}{
+		{name: "无追踪标识", ctx: context.Background(), want: map[string]any{}},
+		{name: "按 sign 约定读取", ctx: ContextWithSpan(context.Background(), SpanContext{TraceId: "t1", SpanId: "s1"}),
+			want: map[string]any{"trace_id": "t1", "span_id": "s1"}},
+		{name: "字符串 key", ctx: context.WithValue(context.Background(), sign.TRACE_ID.String(), "t2"),
+			want: map[string]any{"trace_id": "t2"}},
+		{name: "自定义读取优先", ctx: ContextWithSpan(context.Background(), SpanContext{TraceId: "t1"}),
+			extractor: func(ctx context.Context) (SpanContext, bool) {
+				return SpanContext{TraceId: "otel", SpanId: "otel-span"}, true
+			},
+			want: map[string]any{"trace_id": "otel", "span_id": "otel-span"}},
+	}
+
+	// ctx 中已有请求日志时直接使用
+	requestLogger := logger.With(zap.String("request", "r1"))
+	ctx := ContextWithSpan(context.WithValue(context.Background(), sign.LOGGER, requestLogger), SpanContext{TraceId: "t1"})
+	if FromContext(ctx) != requestLogger {
+		t.Errorf("FromContext() did not return the request logger")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSpanExtractor(tt.extractor)
+			defer SetSpanExtractor(nil)
+			FromContext(tt.ctx).Info("message")
+			entries := logs.TakeAll()
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			got := entries[0].ContextMap()
+			if len(got) != len(tt.want) {
+				t.Errorf("fields = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("field %s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldsFromCtx(t *testing.T) {
+	logger, logs := NewTestLogger()
+	ctx := context.WithValue(context.Background(), sign.LOGGER, logger)
+	ctx, _ = ContextWithRequestLogger(ctx, "trace-1", "/login", 7)
+	ctx = AppendCtxFields(ctx, field.WithPlayerId(1001))
+	mutable := contextimpl.Background()
+	mutable.With(sign.LOGGER, MustGetLoggerCtx(ctx))
+	mutable.With(ctxFieldsKey{}, FieldsFromCtx(ctx))
+	AddFieldsToCtxLogger(mutable, field.String("zone", "cn"))
+
+	MustGetLoggerCtx(mutable).Info("done")
+	want := map[string]string{"trace_id": "trace-1", "method": "/login", "session_id": "7", "player_id": "1001", "zone": "cn"}
+	if got := FieldsToMetadata(FieldsFromCtx(mutable)); !maps.Equal(got, want) {
+		t.Errorf("FieldsToMetadata = %v, want %v", got, want)
+	}
+	if got := logs.All()[0].ContextMap(); len(got) != len(want) {
+		t.Errorf("logged fields = %v, want %v", got, want)
+	}
+	// 追加字段不影响原 ctx
+	if got := len(FieldsFromCtx(ctx)); got != 4 {
+		t.Errorf("len(FieldsFromCtx(ctx)) = %d, want 4", got)
+	}
+}
@@ -0,0 +1,229 @@
+package zap_logger
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugTargets 临时输出 debug 日志的 trace id 与玩家 id，需开启 Config.RequestDebug；
+// 通过 With 附加了 field.WithTraceId 或 field.WithPlayerId 的日志按匹配结果输出 debug
+type DebugTargets struct {
+	TraceIds  []string `json:"trace_ids"`
+	PlayerIds []int64  `json:"player_ids"`
+}
+
+// debugSet 不可变的目标集合，值为过期时间，零值不过期
+type debugSet struct {
+	traces  map[string]time.Time
+	players map[int64]time.Time
+}
+
+var (
+	debugMu         sync.Mutex
+	currentDebugSet atomic.Pointer[debugSet]
+)
+
+func debugActive(expire, now time.Time) bool {
+	return expire.IsZero() || now.Before(expire)
+}
+
+// updateDebugSet 复制当前集合修改后替换
+func updateDebugSet(update func(set *debugSet)) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	set := &debugSet{traces: map[string]time.Time{}, players: map[int64]time.Time{}}
+	if current := currentDebugSet.Load(); current != nil {
+		set.traces, set.players = maps.Clone(current.traces), maps.Clone(current.players)
+	}
+	update(set)
+	now := time.Now()
+	maps.DeleteFunc(set.traces, func(_ string, expire time.Time) bool { return !debugActive(expire, now) })
+	maps.DeleteFunc(set.players, func(_ int64, expire time.Time) bool { return !debugActive(expire, now) })
+	if len(set.traces) == 0 && len(set.players) == 0 {
+		set = nil
+	}
+	currentDebugSet.Store(set)
+}
+
+func expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// EnableDebugTrace 对 trace id 的请求临时输出 debug 日志，ttl<=0 时直到 DisableDebugTrace
+func EnableDebugTrace(traceId string, ttl time.Duration) {
+	updateDebugSet(func(set *debugSet) { set.traces[traceId] = expireAt(ttl) })
+}
+
+func DisableDebugTrace(traceId string) {
+	updateDebugSet(func(set *debugSet) { delete(set.traces, traceId) })
+}
+
+// EnableDebugPlayer 对玩家的日志临时输出 debug 日志，ttl<=0 时直到 DisableDebugPlayer
+func EnableDebugPlayer(playerId int64, ttl time.Duration) {
+	updateDebugSet(func(set *debugSet) { set.players[playerId] = expireAt(ttl) })
+}
+
+func DisableDebugPlayer(playerId int64) {
+	updateDebugSet(func(set *debugSet) { delete(set.players, playerId) })
+}
+
+// SetDebugTargets 替换全部目标，不过期
+func SetDebugTargets(targets DebugTargets) {
+	updateDebugSet(func(set *debugSet) {
+		clear(set.traces)
+		clear(set.players)
+		for _, traceId := range targets.TraceIds {
+			set.traces[traceId] = time.Time{}
+		}
+		for _, playerId := range targets.PlayerIds {
+			set.players[playerId] = time.Time{}
+		}
+	})
+}
+
+// GetDebugTargets 当前未过期的目标
+func GetDebugTargets() DebugTargets {
+	targets := DebugTargets{TraceIds: []string{}, PlayerIds: []int64{}}
+	set := currentDebugSet.Load()
+	if set == nil {
+		return targets
+	}
+	now := time.Now()
+	for traceId, expire := range set.traces {
+		if debugActive(expire, now) {
+			targets.TraceIds = append(targets.TraceIds, traceId)
+		}
+	}
+	for playerId, expire := range set.players {
+		if debugActive(expire, now) {
+			targets.PlayerIds = append(targets.PlayerIds, playerId)
+		}
+	}
+	slices.Sort(targets.TraceIds)
+	slices.Sort(targets.PlayerIds)
+	return targets
+}
+
+// WatchDebugTargets 每隔 interval 调用 load 并以结果替换全部目标，直到 ctx 结束；load 出错时保留当前目标。
+// 用于从存储读取开关，使集群内所有节点同时生效，如：
+//
+//	go zaplogger.WatchDebugTargets(ctx, 5*time.Second, func(ctx context.Context) (zaplogger.DebugTargets, error) {
+//		ids, err := rdb.SMembers(ctx, "log:debug:players").Result()
+//		...
+//	})
+func WatchDebugTargets(ctx context.Context, interval time.Duration, load func(ctx context.Context) (DebugTargets, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if targets, err := load(ctx); err == nil {
+			SetDebugTargets(targets)
+		} else {
+			DefaultLogger().Warn("load debug targets failed", field.WithError(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DebugTargetsHandler 管理目标的 HTTP 接口：GET 返回当前目标；POST 以 trace_id 或 player_id 参数开启，
+// ttl 参数为持续时间（如 10m），为空时不过期；DELETE 以 trace_id 或 player_id 参数关闭
+func DebugTargetsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		traceId := query.Get("trace_id")
+		var playerId int64
+		if value := query.Get("player_id"); value != "" {
+			var err error
+			if playerId, err = strconv.ParseInt(value, 10, 64); err != nil {
+				http.Error(w, "invalid player_id", http.StatusBadRequest)
+				return
+			}
+		}
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var ttl time.Duration
+			if value := query.Get("ttl"); value != "" {
+				var err error
+				if ttl, err = time.ParseDuration(value); err != nil {
+					http.Error(w, "invalid ttl", http.StatusBadRequest)
+					return
+				}
+			}
+			if traceId == "" && playerId == 0 {
+				http.Error(w, "trace_id or player_id required", http.StatusBadRequest)
+				return
+			}
+			if traceId != "" {
+				EnableDebugTrace(traceId, ttl)
+			}
+			if playerId != 0 {
+				EnableDebugPlayer(playerId, ttl)
+			}
+		case http.MethodDelete:
+			if traceId != "" {
+				DisableDebugTrace(traceId)
+			}
+			if playerId != 0 {
+				DisableDebugPlayer(playerId)
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetDebugTargets())
+	})
+}
+
+// debugIds 通过 With 附加的 trace id 与玩家 id
+type debugIds struct {
+	traceId  string
+	playerId int64
+}
+
+// with 读取字段中的 trace_id 与 player_id，后附加的覆盖先附加的
+func (ids debugIds) with(fields []zapcore.Field) debugIds {
+	for _, f := range fields {
+		switch {
+		case f.Key == "trace_id" && f.Type == zapcore.StringType:
+			ids.traceId = f.String
+		case f.Key == "player_id" && f.Type == zapcore.Int64Type:
+			ids.playerId = f.Integer
+		}
+	}
+	return ids
+}
+
+// debugging 是否为临时输出 debug 日志的目标
+func (ids debugIds) debugging() bool {
+	if ids == (debugIds{}) {
+		return false
+	}
+	set := currentDebugSet.Load()
+	if set == nil {
+		return false
+	}
+	now := time.Now()
+	if expire, ok := set.traces[ids.traceId]; ok && ids.traceId != "" && debugActive(expire, now) {
+		return true
+	}
+	expire, ok := set.players[ids.playerId]
+	return ok && ids.playerId != 0 && debugActive(expire, now)
+}
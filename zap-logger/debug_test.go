@@ -0,0 +1,74 @@
+package zap_logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+func TestRequestDebug(t *testing.T) {
+	t.Cleanup(func() { SetDebugTargets(DebugTargets{}) })
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewZapLoggerWithConfig(Config{Level: "info", RequestDebug: true, Outputs: []TeeConfig{{Target: path}}}).(*ZapLogger)
+
+	EnableDebugTrace("trace-1", 0)
+	EnableDebugPlayer(1001, time.Minute)
+	EnableDebugPlayer(1002, -time.Minute)
+	EnableDebugPlayer(1003, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	logger.Debug("root")
+	logger.With(field.WithTraceId("trace-1")).Debug("trace-1")
+	logger.With(field.WithTraceId("trace-2")).Debug("trace-2")
+	logger.With(field.WithPlayerId(1001)).Debug("player-1001")
+	logger.With(field.WithPlayerId(1002)).Debug("player-1002")
+	logger.With(field.WithPlayerId(1003)).Debug("player-1003")
+	DisableDebugTrace("trace-1")
+	logger.With(field.WithTraceId("trace-1")).Debug("trace-1 disabled")
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for message, want := range map[string]bool{
+		"root": false, "trace-1": true, "trace-2": false, "player-1001": true,
+		"player-1002": true, "player-1003": false, "trace-1 disabled": false,
+	} {
+		if got := strings.Contains(string(data), `"`+message+`"`); got != want {
+			t.Errorf("%s written = %v, want %v", message, got, want)
+		}
+	}
+}
+
+func TestDebugTargetsHandler(t *testing.T) {
+	t.Cleanup(func() { SetDebugTargets(DebugTargets{}) })
+	handler := DebugTargetsHandler()
+	for _, tt := range []struct {
+		method, query string
+		status        int
+		body          string
+	}{
+		{http.MethodPost, "trace_id=t1&ttl=10m", http.StatusOK, `{"trace_ids":["t1"],"player_ids":[]}`},
+		{http.MethodPost, "player_id=7", http.StatusOK, `{"trace_ids":["t1"],"player_ids":[7]}`},
+		{http.MethodDelete, "trace_id=t1", http.StatusOK, `{"trace_ids":[],"player_ids":[7]}`},
+		{http.MethodPost, "player_id=x", http.StatusBadRequest, ""},
+		{http.MethodPost, "ttl=1m", http.StatusBadRequest, ""},
+		{http.MethodGet, "", http.StatusOK, `{"trace_ids":[],"player_ids":[7]}`},
+	} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/?"+tt.query, nil))
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.query, recorder.Code, tt.status)
+		}
+		if tt.body != "" && strings.TrimSpace(recorder.Body.String()) != tt.body {
+			t.Errorf("%s %s body = %s, want %s", tt.method, tt.query, recorder.Body.String(), tt.body)
+		}
+	}
+}
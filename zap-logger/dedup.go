@@ -0,0 +1,198 @@
+package zap_logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultDedupWindow = time.Second
+
+// DedupConfig 相同日志的去重：窗口内等级、模块与内容相同的日志只输出前 Burst 条，
+// 其余在窗口结束时合并为一条，附加 repeated 字段记录被合并的条数，字段为最后一条的字段
+type DedupConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// 窗口（毫秒），<=0 时为 1000
+	WindowMillis int64 `json:"window_millis" yaml:"window-millis"`
+	// 窗口内直接输出的条数，<=0 时为 1
+	Burst int `json:"burst" yaml:"burst"`
+}
+
+func (conf DedupConfig) window() time.Duration {
+	if conf.WindowMillis <= 0 {
+		return defaultDedupWindow
+	}
+	return time.Duration(conf.WindowMillis) * time.Millisecond
+}
+
+func (conf DedupConfig) burst() int {
+	return max(conf.Burst, 1)
+}
+
+type dedupKey struct {
+	level   zapcore.Level
+	name    string
+	message string
+}
+
+// dedupState 一个窗口内相同日志的计数与最后一条被合并的日志
+type dedupState struct {
+	start  time.Time
+	count  int
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+// summary 窗口内被合并的条数，没有时为 0
+func (state *dedupState) summary(burst int) int {
+	return max(state.count-burst, 0)
+}
+
+// deduper 所有 With 出的 dedupCore 共享的计数，后台协程在窗口结束时输出合并的日志
+type deduper struct {
+	window time.Duration
+	burst  int
+	now    func() time.Time
+
+	mu     sync.Mutex
+	states map[dedupKey]*dedupState
+
+	// stop 关闭后后台协程退出，退出时关闭 done
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newDeduper(config DedupConfig, now func() time.Time) *deduper {
+	d := &deduper{
+		window: config.window(),
+		burst:  config.burst(),
+		now:    now,
+		states: map[dedupKey]*dedupState{},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+func (d *deduper) run() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.flush(false)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// close 停止后台协程并输出所有合并的日志；可重复调用
+func (d *deduper) close() {
+	d.stopOnce.Do(func() { close(d.stop) })
+	<-d.done
+	d.flush(true)
+}
+
+// allow 记录一条日志，返回是否直接输出；上一窗口未输出的合并日志一并返回
+func (d *deduper) allow(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) (bool, *dedupState) {
+	key := dedupKey{level: entry.Level, name: entry.LoggerName, message: entry.Message}
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state := d.states[key]
+	var expired *dedupState
+	if state == nil || now.Sub(state.start) >= d.window {
+		if state != nil && state.summary(d.burst) > 0 {
+			expired = state
+		}
+		d.states[key] = &dedupState{start: now, count: 1}
+		return true, expired
+	}
+	state.count++
+	if state.count <= d.burst {
+		return true, nil
+	}
+	// fields 在 Write 返回后可能被复用，需复制
+	state.core, state.entry, state.fields = core, entry, append(state.fields[:0:0], fields...)
+	return false, nil
+}
+
+// flush 输出已结束窗口中合并的日志并清理，all 时同时输出未结束的窗口
+func (d *deduper) flush(all bool) {
+	now := d.now()
+	d.mu.Lock()
+	var summaries []*dedupState
+	for key, state := range d.states {
+		if !all && now.Sub(state.start) < d.window {
+			continue
+		}
+		if state.summary(d.burst) > 0 {
+			summaries = append(summaries, state)
+		}
+		delete(d.states, key)
+	}
+	d.mu.Unlock()
+	for _, state := range summaries {
+		d.write(state)
+	}
+}
+
+// write 输出合并的日志
+func (d *deduper) write(state *dedupState) {
+	fields := append(state.fields, zap.Int("repeated", state.summary(d.burst)))
+	_ = writeChecked(state.core, state.entry, fields)
+}
+
+// dedupCore 合并窗口内重复的日志，panic 与 fatal 等级不合并
+type dedupCore struct {
+	zapcore.Core
+	deduper *deduper
+}
+
+func newDedupCore(core zapcore.Core, config DedupConfig, now func() time.Time) *dedupCore {
+	return &dedupCore{Core: core, deduper: newDeduper(config, now)}
+}
+
+func (core *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: core.Core.With(fields), deduper: core.deduper}
+}
+
+func (core *dedupCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *dedupCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level > zapcore.ErrorLevel {
+		return writeChecked(core.Core, entry, fields)
+	}
+	ok, expired := core.deduper.allow(core.Core, entry, fields)
+	if expired != nil {
+		core.deduper.write(expired)
+	}
+	if !ok {
+		return nil
+	}
+	return writeChecked(core.Core, entry, fields)
+}
+
+// Close 停止后台协程并输出所有合并的日志，不关闭内部的输出
+func (core *dedupCore) Close() error {
+	core.deduper.close()
+	return nil
+}
+
+// Sync 输出所有合并的日志后刷新各输出
+func (core *dedupCore) Sync() error {
+	core.deduper.flush(true)
+	return core.Core.Sync()
+}
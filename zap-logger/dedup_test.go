@@ -0,0 +1,92 @@
+package zap_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	now := time.Unix(0, 0)
+	// 不启动后台协程，窗口由 now 控制
+	core := &dedupCore{Core: observed, deduper: &deduper{
+		window: defaultDedupWindow,
+		burst:  2,
+		now:    func() time.Time { return now },
+		states: map[dedupKey]*dedupState{},
+	}}
+	base := zap.New(core)
+	logger := &ZapLogger{base: base, root: base}
+
+	for i := 0; i < 10; i++ {
+		logger.Error("redis disconnected", field.Int("attempt", i))
+	}
+	logger.Error("other")
+	if logs.Len() != 3 {
+		t.Fatalf("written = %d, want 3", logs.Len())
+	}
+
+	// 窗口结束后的下一条日志先输出上一窗口合并的日志
+	now = now.Add(time.Second)
+	logger.Error("redis disconnected", field.Int("attempt", 10))
+	entries := logs.FilterMessage("redis disconnected").All()
+	if len(entries) != 4 {
+		t.Fatalf("written = %d, want 4", len(entries))
+	}
+	summary := entries[2].ContextMap()
+	if summary["repeated"] != int64(8) || summary["attempt"] != int64(9) {
+		t.Errorf("summary = %v, want repeated 8 and fields of the last entry", summary)
+	}
+
+	for i := 0; i < 3; i++ {
+		logger.Error("redis disconnected")
+	}
+	logger.Sync()
+	if got := logs.FilterField(zap.Int("repeated", 2)).Len(); got != 1 {
+		t.Errorf("summary after Sync = %d, want 1", got)
+	}
+}
+
+func TestZapLogger_ReloadClosesDedup(t *testing.T) {
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.log"), filepath.Join(dir, "after.log")
+	config := Config{Level: "info", Outputs: []TeeConfig{{Target: before}}, Dedup: DedupConfig{Enabled: true, WindowMillis: 60000}}
+	logger := NewZapLoggerWithConfig(config).(*ZapLogger)
+	old := logger.reload.current.Load().core.(*dedupCore).deduper
+
+	for i := 0; i < 3; i++ {
+		logger.Error("redis disconnected")
+	}
+	config.Outputs = []TeeConfig{{Target: after}}
+	if err := logger.Reload(config); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	// 旧的后台协程已退出，合并的日志在关闭前写入旧输出
+	select {
+	case <-old.done:
+	default:
+		t.Error("old deduper still running after Reload")
+	}
+	data, _ := os.ReadFile(before)
+	if !strings.Contains(string(data), `"repeated":2`) {
+		t.Errorf("before = %q, want summary with repeated 2", data)
+	}
+
+	current := logger.reload.current.Load().core.(*dedupCore).deduper
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	select {
+	case <-current.done:
+	default:
+		t.Error("deduper still running after Close")
+	}
+}
@@ -1,25 +1,52 @@
 package field
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/NumberMan1/numbox/utils"
 	"go.uber.org/zap"
 )
 
 var (
-	String  = zap.String
-	Int     = zap.Int
-	Any     = zap.Any
-	Int64   = zap.Int64
-	Uint64  = zap.Uint64
-	Float64 = zap.Float64
-	Int64s  = zap.Int64s
-	Ints    = zap.Ints
-	Int32   = zap.Int32
-	Int32s  = zap.Int32s
-	Strings = zap.Strings
-	Error   = zap.Error
+	String   = zap.String
+	Int      = zap.Int
+	Any      = zap.Any
+	Int64    = zap.Int64
+	Uint64   = zap.Uint64
+	Float64  = zap.Float64
+	Int64s   = zap.Int64s
+	Ints     = zap.Ints
+	Int32    = zap.Int32
+	Int32s   = zap.Int32s
+	Strings  = zap.Strings
+	Error    = zap.Error
+	Bool     = zap.Bool
+	Duration = zap.Duration
+	Time     = zap.Time
+	Uint32   = zap.Uint32
+	Uint32s  = zap.Uint32s
+	Float32  = zap.Float32
+	// Namespace 之后的字段都嵌套在 name 下
+	Namespace = zap.Namespace
 )
 
+// ByteSize 以 B、KB、MB、GB、TB 输出字节数，保留两位小数，如 1.50MB
+func ByteSize(key string, size int64) Field {
+	const unit = 1024
+	if size < unit && size > -unit {
+		return String(key, fmt.Sprintf("%dB", size))
+	}
+	value := float64(size)
+	units := []string{"KB", "MB", "GB", "TB"}
+	i := -1
+	for ; i < len(units)-1 && (value >= unit || value <= -unit); i++ {
+		value /= unit
+	}
+	return String(key, fmt.Sprintf("%.2f%s", value, units[i]))
+}
+
 func NewFields(fields ...Field) Fields {
 	var res Fields
 	for _, field := range fields {
@@ -65,12 +92,51 @@ func WithMethod(methodName string) Field {
 	return String("method", methodName)
 }
 
+// WithData 以 JSON 字符串输出 data，调用时即序列化；Debug 日志中的大结构体使用 Lazy
 func WithData(data any) Field {
 	return String("data", utils.ToJsonString(data))
 }
 
+// ErrorCoder 带错误码的错误，WithErrorCode 沿错误链读取第一个错误码
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+// WithError 输出错误，保留错误值交由 zap 编码，带调用栈的错误同时输出 errorVerbose；err 为 nil 时不输出
 func WithError(err error) Field {
-	return String("error", err.Error())
+	return zap.NamedError("error", err)
+}
+
+// WithErrorCode 输出错误链中第一个 ErrorCoder 的错误码，没有时不输出
+func WithErrorCode(err error) Field {
+	var coder ErrorCoder
+	if !errors.As(err, &coder) {
+		return zap.Skip()
+	}
+	return String("error_code", coder.ErrorCode())
+}
+
+// WithErrorChain 按 Unwrap 的顺序输出错误链上每个错误的信息，多个错误的包装按深度优先展开；err 为 nil 时不输出
+func WithErrorChain(err error) Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	chain := make([]string, 0)
+	var walk func(err error)
+	walk = func(err error) {
+		for err != nil {
+			chain = append(chain, err.Error())
+			if multi, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, inner := range multi.Unwrap() {
+					walk(inner)
+				}
+				return
+			}
+			err = errors.Unwrap(err)
+		}
+	}
+	walk(err)
+	return Strings("error_chain", chain)
 }
 
 func WithCostUS(costTime int64) Field {
@@ -101,10 +167,40 @@ func WithParentSpanId(data string) Field {
 	return String("parent_span_id", data)
 }
 
+// WithErrorStack 输出错误与调用方获取的调用栈
+//
+// Deprecated: 使用 WithStack，无需调用方获取调用栈
 func WithErrorStack(innerErr error, stack []byte) zap.Field {
 	return String("error_stack", "error:"+innerErr.Error()+" stack:"+string(stack))
 }
 
+// WithStack 输出错误与调用栈：使用错误链中最内层 github.com/pkg/errors 风格（%+v 输出调用栈）的错误创建时的调用栈，
+// 没有时使用当前调用栈
+func WithStack(err error) Field {
+	stack, ok := errorStack(err)
+	if !ok {
+		stack = zap.StackSkip("", 1).String
+	}
+	return String("error_stack", "error:"+err.Error()+" stack:"+stack)
+}
+
+// errorStack 沿 Unwrap 与 Cause 查找最内层带调用栈的错误，返回其调用栈
+func errorStack(err error) (stack string, ok bool) {
+	for err != nil {
+		if _, isFormatter := err.(fmt.Formatter); isFormatter {
+			if s := strings.TrimPrefix(fmt.Sprintf("%+v", err), err.Error()); s != "" {
+				stack, ok = strings.TrimLeft(s, "\n"), true
+			}
+		}
+		if cause, isCauser := err.(interface{ Cause() error }); isCauser {
+			err = cause.Cause()
+			continue
+		}
+		err = errors.Unwrap(err)
+	}
+	return
+}
+
 func WithStringsMap(key string, headersMap map[string]string) Field {
 	var strings = make([]string, 0)
 	for k, v := range headersMap {
@@ -0,0 +1,141 @@
+package field
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stackError 模拟 github.com/pkg/errors 的错误，%+v 时输出创建时的调用栈
+type stackError struct {
+	msg   string
+	stack string
+}
+
+func (err *stackError) Error() string {
+	return err.msg
+}
+
+func (err *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\n%s", err.msg, err.stack)
+		return
+	}
+	fmt.Fprint(s, err.msg)
+}
+
+func TestWithStack(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantStack string
+	}{
+		{name: "带调用栈的错误", err: &stackError{msg: "boom", stack: "main.origin\n\tmain.go:10"}, wantStack: "main.origin\n\tmain.go:10"},
+		{name: "包装后取最内层调用栈", err: fmt.Errorf("query: %w", &stackError{msg: "boom", stack: "main.origin"}), wantStack: "main.origin"},
+		{name: "没有调用栈时使用当前调用栈", err: errors.New("boom"), wantStack: "TestWithStack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithStack(tt.err)
+			if got.Key != "error_stack" || !strings.HasPrefix(got.String, "error:"+tt.err.Error()+" stack:") || !strings.Contains(got.String, tt.wantStack) {
+				t.Errorf("WithStack() = %q, want stack containing %q", got.String, tt.wantStack)
+			}
+		})
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		want string
+	}{
+		{name: "字节", size: 512, want: "512B"},
+		{name: "KB", size: 1536, want: "1.50KB"},
+		{name: "MB", size: 5 * 1024 * 1024, want: "5.00MB"},
+		{name: "超过 TB 仍以 TB 输出", size: 2048 * 1024 * 1024 * 1024 * 1024, want: "2048.00TB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ByteSize("size", tt.size); got.String != tt.want {
+				t.Errorf("ByteSize() = %q, want %q", got.String, tt.want)
+			}
+		})
+	}
+}
+
+func TestMasked(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		want  string
+	}{
+		{name: "身份证号", field: MaskedIdNo("11010119900307002X"), want: "110101**********2X"},
+		{name: "过短的身份证号", field: MaskedIdNo("1101"), want: "****"},
+		{name: "姓名", field: MaskedName("欧阳娜娜"), want: "欧***"},
+		{name: "手机号", field: MaskedPhone("13812345678"), want: "138****5678"},
+		{name: "带国家码的手机号", field: MaskedPhone("+8613812345678"), want: "+86*******5678"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.field.String != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field.Key, tt.field.String, tt.want)
+			}
+		})
+	}
+}
+
+type codeError struct {
+	code string
+}
+
+func (err *codeError) Error() string {
+	return "code " + err.code
+}
+
+func (err *codeError) ErrorCode() string {
+	return err.code
+}
+
+func TestWithErrorDetail(t *testing.T) {
+	inner := &codeError{code: "2002"}
+	sentinel := errors.New("quota exceeded")
+	err := fmt.Errorf("%w: %w", sentinel, inner)
+	wrapped := fmt.Errorf("check: %w", err)
+
+	if got := WithError(wrapped); got.Key != "error" || !errors.Is(got.Interface.(error), inner) {
+		t.Errorf("WithError() = %v, want error value preserved", got)
+	}
+	if got := WithErrorCode(wrapped); got.String != "2002" {
+		t.Errorf("WithErrorCode() = %q, want 2002", got.String)
+	}
+	if got := WithErrorCode(sentinel); got.Key != "" {
+		t.Errorf("WithErrorCode() = %v, want skip", got)
+	}
+	chain := WithErrorChain(wrapped).Interface
+	want := []string{wrapped.Error(), err.Error(), sentinel.Error(), inner.Error()}
+	if fmt.Sprint(chain) != fmt.Sprint(want) {
+		t.Errorf("WithErrorChain() = %v, want %v", chain, want)
+	}
+	if got := WithError(nil); got.Key != "" {
+		t.Errorf("WithError(nil) = %v, want skip", got)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	f := Lazy("data", func() any {
+		calls++
+		return map[string]int{"a": 1}
+	})
+	if calls != 0 {
+		t.Fatalf("Lazy called fn before encoding")
+	}
+	if got := f.Interface.(fmt.Stringer).String(); got != `{"a":1}` || calls != 1 {
+		t.Errorf("Lazy = %s, calls = %d", got, calls)
+	}
+}
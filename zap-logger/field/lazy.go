@@ -0,0 +1,21 @@
+package field
+
+import (
+	"github.com/NumberMan1/numbox/utils"
+	"go.uber.org/zap"
+)
+
+// lazyJson 编码时才调用 fn 并转换为 JSON 字符串
+type lazyJson func() any
+
+func (fn lazyJson) String() string {
+	return utils.ToJsonString(fn())
+}
+
+// Lazy 与 WithData 相同以 JSON 字符串输出 fn 的结果，但仅在日志实际输出时才调用 fn，
+// 等级未开启的 Debug 日志不会序列化大结构体：
+//
+//	logger.Debug("room state", field.Lazy("data", func() any { return room.Snapshot() }))
+func Lazy(key string, fn func() any) Field {
+	return zap.Stringer(key, lazyJson(fn))
+}
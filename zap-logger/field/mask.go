@@ -0,0 +1,47 @@
+package field
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaskIdNo 身份证号脱敏，保留前 6 位地区码与后 2 位，其余替换为 *；长度不超过 8 时全部替换
+func MaskIdNo(idNo string) string {
+	if len(idNo) <= 8 {
+		return strings.Repeat("*", len(idNo))
+	}
+	return idNo[:6] + strings.Repeat("*", len(idNo)-8) + idNo[len(idNo)-2:]
+}
+
+// MaskName 姓名脱敏，仅保留第一个字作为姓氏，其余每个字替换为 *；单字姓名全部替换
+func MaskName(name string) string {
+	count := utf8.RuneCountInString(name)
+	if count <= 1 {
+		return strings.Repeat("*", count)
+	}
+	first, _ := utf8.DecodeRuneInString(name)
+	return string(first) + strings.Repeat("*", count-1)
+}
+
+// MaskPhone 手机号脱敏，保留前 3 位与后 4 位，其余替换为 *；长度不超过 7 时全部替换
+func MaskPhone(phone string) string {
+	if len(phone) <= 7 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
+
+// MaskedIdNo 脱敏后的身份证号，日志中不得出现明文
+func MaskedIdNo(idNo string) Field {
+	return String("id_no", MaskIdNo(idNo))
+}
+
+// MaskedName 脱敏后的姓名
+func MaskedName(name string) Field {
+	return String("name", MaskName(name))
+}
+
+// MaskedPhone 脱敏后的手机号
+func MaskedPhone(phone string) Field {
+	return String("phone", MaskPhone(phone))
+}
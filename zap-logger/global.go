@@ -0,0 +1,72 @@
+package zap_logger
+
+import (
+	"os"
+	"runtime/debug"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+)
+
+// envPodName Kubernetes 通过 downward API 注入 Pod 名称的环境变量
+const envPodName = "POD_NAME"
+
+// GlobalFieldsConfig 每条日志附加的全局字段，为空的字段不输出
+type GlobalFieldsConfig struct {
+	// 附加主机名（host）与 Pod 名称（pod，读取环境变量 POD_NAME）
+	Host bool `json:"host" yaml:"host"`
+	// 环境（env），如 prod、test
+	Env string `json:"env" yaml:"env"`
+	// 版本（version），为空时使用构建信息中的 git 提交，有未提交的修改时带 -dirty 后缀
+	Version string `json:"version" yaml:"version"`
+	// 区域（region）
+	Region string `json:"region" yaml:"region"`
+}
+
+// fields 全局字段
+func (conf GlobalFieldsConfig) fields() []field.Field {
+	fields := make([]field.Field, 0, 5)
+	if conf.Host {
+		if hostname, err := os.Hostname(); err == nil {
+			fields = append(fields, field.String("host", hostname))
+		}
+		if pod := os.Getenv(envPodName); pod != "" {
+			fields = append(fields, field.String("pod", pod))
+		}
+	}
+	if conf.Env != "" {
+		fields = append(fields, field.String("env", conf.Env))
+	}
+	version := conf.Version
+	if version == "" {
+		version = buildVersion()
+	}
+	if version != "" {
+		fields = append(fields, field.String("version", version))
+	}
+	if conf.Region != "" {
+		fields = append(fields, field.String("region", conf.Region))
+	}
+	return fields
+}
+
+// buildVersion 构建信息中的 git 提交，未以 git 仓库构建时为空
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,37 @@
+package zap_logger
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewZap_GlobalFields(t *testing.T) {
+	t.Setenv(envPodName, "pod-1")
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &ZapLogger{base: newZap(core, Config{
+		Name:         "app",
+		GlobalFields: GlobalFieldsConfig{Host: true, Env: "prod", Version: "v1.2.3", Region: "cn-north"},
+	})}
+	logger.Info("hello")
+
+	hostname, _ := os.Hostname()
+	fields := logs.All()[0].ContextMap()
+	want := map[string]any{"app": "app", "host": hostname, "pod": "pod-1", "env": "prod", "version": "v1.2.3", "region": "cn-north"}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s = %v, want %v", k, fields[k], v)
+		}
+	}
+}
+
+func TestGlobalFieldsConfig_Empty(t *testing.T) {
+	t.Setenv(envPodName, "pod-1")
+	for _, f := range (GlobalFieldsConfig{Version: "v1"}).fields() {
+		if f.Key != "version" {
+			t.Errorf("unexpected field %s", f.Key)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package zap_logger
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// LogHook 日志钩子，在日志写入各输出的同时收到完整的日志，如将 error 发送到告警 webhook 或上报 Sentry；
+// 在记录日志的协程（开启异步写入时为后台协程）中调用，耗时操作需自行异步处理
+type LogHook interface {
+	// Level 接收的最低等级
+	Level() zapcore.Level
+	// OnLog 收到一条日志，fields 含 With 附加的字段，可在返回后继续持有
+	OnLog(entry zapcore.Entry, fields []zapcore.Field)
+}
+
+type logHookFunc struct {
+	level zapcore.Level
+	fn    func(entry zapcore.Entry, fields []zapcore.Field)
+}
+
+func (hook *logHookFunc) Level() zapcore.Level {
+	return hook.level
+}
+
+func (hook *logHookFunc) OnLog(entry zapcore.Entry, fields []zapcore.Field) {
+	hook.fn(entry, fields)
+}
+
+// LogHookFunc 以函数创建接收 level 及以上等级日志的钩子
+func LogHookFunc(level zapcore.Level, fn func(entry zapcore.Entry, fields []zapcore.Field)) LogHook {
+	return &logHookFunc{level: level, fn: fn}
+}
+
+var (
+	hooksMu sync.Mutex
+	// hooks 已注册的钩子，写时复制
+	hooks atomic.Pointer[[]LogHook]
+)
+
+// RegisterHook 注册日志钩子，对已创建的日志同样生效，返回取消注册的函数
+func RegisterHook(hook LogHook) (unregister func()) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	var current []LogHook
+	if loaded := hooks.Load(); loaded != nil {
+		current = *loaded
+	}
+	updated := append(slices.Clip(current), hook)
+	hooks.Store(&updated)
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		current := *hooks.Load()
+		updated := slices.DeleteFunc(slices.Clone(current), func(h LogHook) bool { return h == hook })
+		hooks.Store(&updated)
+	}
+}
+
+func loadHooks() []LogHook {
+	if loaded := hooks.Load(); loaded != nil {
+		return *loaded
+	}
+	return nil
+}
+
+// hookCore 作为一个输出将日志交给已注册的钩子，经过模块等级过滤与脱敏
+type hookCore struct {
+	fields []zapcore.Field
+}
+
+func (core *hookCore) Enabled(level zapcore.Level) bool {
+	for _, hook := range loadHooks() {
+		if level >= hook.Level() {
+			return true
+		}
+	}
+	return false
+}
+
+func (core *hookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &hookCore{fields: append(slices.Clip(core.fields), fields...)}
+}
+
+func (core *hookCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *hookCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	all := append(slices.Clip(core.fields), fields...)
+	for _, hook := range loadHooks() {
+		if entry.Level >= hook.Level() {
+			hook.OnLog(entry, all)
+		}
+	}
+	return nil
+}
+
+func (core *hookCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,30 @@
+package zap_logger
+
+import (
+	"testing"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRegisterHook(t *testing.T) {
+	logger := NewZapLoggerWithConfig(Config{Level: "debug"}).With(field.String("app", "game"))
+	var entries []zapcore.Entry
+	var fields [][]zapcore.Field
+	unregister := RegisterHook(LogHookFunc(zapcore.ErrorLevel, func(entry zapcore.Entry, f []zapcore.Field) {
+		entries = append(entries, entry)
+		fields = append(fields, f)
+	}))
+
+	logger.Warn("warn")
+	logger.Error("error", field.Int("code", 1))
+	unregister()
+	logger.Error("after unregister")
+
+	if len(entries) != 1 || entries[0].Message != "error" {
+		t.Fatalf("entries = %v, want only error", entries)
+	}
+	if len(fields[0]) != 2 || fields[0][0].Key != "app" || fields[0][1].Key != "code" {
+		t.Errorf("fields = %v, want With fields followed by entry fields", fields[0])
+	}
+}
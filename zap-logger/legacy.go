@@ -0,0 +1,80 @@
+package zap_logger
+
+import (
+	"os"
+	"slices"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"github.com/NumberMan1/log"
+)
+
+// NewZapLogger 以 github.com/NumberMan1/log 的选项创建日志，Clone 时按相同选项重新创建
+func NewZapLogger(logOpts ...log.Option) Logger {
+	root := log.New(logOpts...).(*log.Logger)
+	return &optionLogger{Logger: root, root: root, logOpts: logOpts}
+}
+
+// optionLogger 基于 github.com/NumberMan1/log 的日志。
+// 该后端不支持跳过调用栈与开发模式：WithCaller 原样返回，DPanic 同 Error
+type optionLogger struct {
+	// Logger 附加了模块名与 With 字段的日志
+	*log.Logger
+	root    *log.Logger
+	logOpts []log.Option
+	// name Named 累积的模块名，以 logger 字段输出
+	name   string
+	fields []field.Field
+}
+
+// derive 以模块名与字段从 root 创建新的日志
+func (logger *optionLogger) derive(name string, fields []field.Field) *optionLogger {
+	all := fields
+	if name != "" {
+		all = append([]field.Field{field.String("logger", name)}, fields...)
+	}
+	derived := logger.root
+	if len(all) > 0 {
+		derived = logger.root.With(all...).(*log.Logger)
+	}
+	return &optionLogger{Logger: derived, root: logger.root, logOpts: logger.logOpts, name: name, fields: fields}
+}
+
+func (logger *optionLogger) With(fields ...field.Field) Logger {
+	return logger.derive(logger.name, append(slices.Clip(logger.fields), fields...))
+}
+
+func (logger *optionLogger) Named(name string) Logger {
+	if logger.name != "" {
+		name = logger.name + "." + name
+	}
+	return logger.derive(name, logger.fields)
+}
+
+func (logger *optionLogger) WithGroup(name string) Logger {
+	return logger.With(field.Namespace(name))
+}
+
+func (logger *optionLogger) WithCaller(skip int) Logger {
+	return logger
+}
+
+func (logger *optionLogger) DPanic(msg string, fields ...field.Field) {
+	logger.Logger.Error(msg, fields...)
+}
+
+func (logger *optionLogger) Panic(msg string, fields ...field.Field) {
+	logger.Logger.Error(msg, fields...)
+	logger.Logger.Sync()
+	panic(msg)
+}
+
+func (logger *optionLogger) Fatal(msg string, fields ...field.Field) {
+	logger.Logger.Error(msg, fields...)
+	logger.Logger.Sync()
+	os.Exit(1)
+}
+
+// Clone 按创建时的选项重新创建日志，不带 With 字段与模块名
+func (logger *optionLogger) Clone() Logger {
+	return NewZapLogger(logger.logOpts...)
+}
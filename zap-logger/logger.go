@@ -1,35 +1,37 @@
 package zap_logger
 
 import (
+	stdcontext "context"
 	standarderrs "errors"
 	"github.com/NumberMan1/component/zap-logger/field"
 	"github.com/NumberMan1/general/context"
 	"github.com/NumberMan1/general/sign"
-	"github.com/NumberMan1/log"
 	"github.com/NumberMan1/numbox/utils"
+	"go.uber.org/zap"
 	"time"
 )
 
-var defaultLogger Logger
+var (
+	defaultLogger Logger
+	// nodeSuffix InitLogger 附加在 Config.Name 后的节点后缀，Reload 时沿用
+	nodeSuffix string
+)
 
 func InitLogger(nodeId int32, config Config) {
-	nodeIdStr := utils.FormatIntString(nodeId)
-	defaultLogger = NewZapLogger(
-		log.WithAppName(config.Name+"-"+nodeIdStr),
-		log.WithStdout(config.Stdout, config.StdoutTyp),
-		log.WithLevel(config.Level),
-		log.WithFileOut(config.OutputFile(), config.LogFilePath, true),
-	)
+	nodeSuffix = "-" + utils.FormatIntString(nodeId)
+	config.Name = config.Name + nodeSuffix
+	defaultLogger = NewZapLoggerWithConfig(config)
 }
 
-func GetLoggerCtx(ctx context.Context) (Logger, error) {
+// GetLoggerCtx 获取按 sign.LOGGER 存入 ctx 的日志，如 HTTPMiddleware 与 UnaryCall 创建的请求日志
+func GetLoggerCtx(ctx stdcontext.Context) (Logger, error) {
 	if lg := ctx.Value(sign.LOGGER); lg != nil {
 		return lg.(Logger), nil
 	}
 	return nil, standarderrs.New("no logger found")
 }
 
-func MustGetLoggerCtx(ctx context.Context) Logger {
+func MustGetLoggerCtx(ctx stdcontext.Context) Logger {
 	logger, err := GetLoggerCtx(ctx)
 	if err != nil {
 		return DefaultLogger()
@@ -39,46 +41,142 @@ func MustGetLoggerCtx(ctx context.Context) Logger {
 
 type Logger interface {
 	With(field ...field.Field) Logger
+	// Named 返回名为 name 的子模块日志，嵌套调用时以 . 连接；等级可在 Config.Modules 中单独配置
+	Named(name string) Logger
+	// WithGroup 返回之后的字段都嵌套在 name 下的日志，避免不同模块的字段在顶层冲突
+	WithGroup(name string) Logger
+	// WithCaller 返回额外跳过 skip 层调用栈的日志，用于封装日志的辅助函数报告真实的调用位置
+	WithCaller(skip int) Logger
 	Debug(msg string, field ...field.Field)
 	Info(msg string, field ...field.Field)
 	Warn(msg string, field ...field.Field)
 	Error(msg string, field ...field.Field)
+	// DPanic 开发模式下记录后 panic，其他模式同 Error
+	DPanic(msg string, field ...field.Field)
+	// Panic 记录后 panic，即使当前等级不输出 panic 日志
+	Panic(msg string, field ...field.Field)
+	// Fatal 记录并刷新输出后调用 os.Exit(1)
+	Fatal(msg string, field ...field.Field)
 	Clone() Logger
 }
 
-func NewZapLogger(logOpts ...log.Option) Logger {
-	return &ZapLogger{
-		Logger:  log.New(logOpts...).(*log.Logger),
-		logOpts: logOpts,
+// NewZapLoggerWithConfig 按配置创建日志，输出无法创建时 panic
+func NewZapLoggerWithConfig(config Config, opts ...Option) Logger {
+	o := newOptions(opts)
+	config = config.developmentDefaults()
+	gen, err := newReloadGen(config, o.clock)
+	if err != nil {
+		panic(err)
 	}
+	reload := newReloadRoot(gen, o.clock)
+	base := newZap(newReloadCore(reload), config, o.zapOptions()...)
+	return &ZapLogger{base: base, root: base, reload: reload}
 }
 
 func DefaultLogger() Logger {
 	if defaultLogger == nil {
-		defaultLogger = NewZapLogger()
+		defaultLogger = NewZapLoggerWithConfig(Config{})
 	}
 	return defaultLogger
 }
 
 type ZapLogger struct {
-	*log.Logger
-	logOpts []log.Option
+	base *zap.Logger
+	// root 未附加 With 字段的 logger，用于 Clone
+	root *zap.Logger
+	// reload 可替换的输出，NewTestLogger 创建时为 nil
+	reload *reloadRoot
 }
 
 func (logger *ZapLogger) With(fields ...field.Field) Logger {
 	return &ZapLogger{
-		Logger:  logger.Logger.With(fields...).(*log.Logger),
-		logOpts: logger.logOpts,
+		base:   logger.base.With(fields...),
+		root:   logger.root,
+		reload: logger.reload,
+	}
+}
+
+func (logger *ZapLogger) Named(name string) Logger {
+	return &ZapLogger{
+		base:   logger.base.Named(name),
+		root:   logger.root,
+		reload: logger.reload,
+	}
+}
+
+func (logger *ZapLogger) WithGroup(name string) Logger {
+	return logger.With(field.Namespace(name))
+}
+
+func (logger *ZapLogger) WithCaller(skip int) Logger {
+	return &ZapLogger{
+		base:   logger.base.WithOptions(zap.AddCallerSkip(skip)),
+		root:   logger.root,
+		reload: logger.reload,
+	}
+}
+
+func (logger *ZapLogger) Debug(msg string, fields ...field.Field) {
+	logger.base.Debug(msg, fields...)
+}
+
+func (logger *ZapLogger) Info(msg string, fields ...field.Field) {
+	logger.base.Info(msg, fields...)
+}
+
+func (logger *ZapLogger) Warn(msg string, fields ...field.Field) {
+	logger.base.Warn(msg, fields...)
+}
+
+func (logger *ZapLogger) Error(msg string, fields ...field.Field) {
+	logger.base.Error(msg, fields...)
+}
+
+func (logger *ZapLogger) DPanic(msg string, fields ...field.Field) {
+	logger.base.DPanic(msg, fields...)
+}
+
+func (logger *ZapLogger) Panic(msg string, fields ...field.Field) {
+	logger.base.Panic(msg, fields...)
+}
+
+func (logger *ZapLogger) Fatal(msg string, fields ...field.Field) {
+	logger.base.Fatal(msg, fields...)
+}
+
+// Dropped 异步写入时因队列满或已关闭被丢弃的日志条数
+func (logger *ZapLogger) Dropped() uint64 {
+	if logger.reload == nil {
+		return 0
+	}
+	return logger.reload.Dropped()
+}
+
+// Sync 刷新缓冲中的日志，开启异步写入时等待队列写完
+func (logger *ZapLogger) Sync() {
+	_ = logger.base.Sync()
+}
+
+// Close 写完异步队列并刷新缓冲后停止后台协程、关闭各输出，之后的日志被丢弃；退出前调用。
+// 关闭作用于同一 NewZapLoggerWithConfig 创建的全部日志，包括 With、Named 与 Clone 出的日志
+func (logger *ZapLogger) Close() error {
+	if logger.reload == nil {
+		return nil
 	}
+	_ = logger.base.Sync()
+	return logger.reload.close()
 }
 
+// Clone 返回不带 With 字段的日志，共享同一组输出
 func (logger *ZapLogger) Clone() Logger {
 	return &ZapLogger{
-		Logger:  log.New(logger.logOpts...).(*log.Logger),
-		logOpts: logger.logOpts,
+		base:   logger.root,
+		root:   logger.root,
+		reload: logger.reload,
 	}
 }
 
+// AddFieldsToCtxLogger 将字段附加到 ctx 中的请求日志，并累积到 FieldsFromCtx；ctx 中没有请求日志时返回 false
 func AddFieldsToCtxLogger(ctx context.Context, fields ...field.Field) bool {
 	ctxLogger, err := GetLoggerCtx(ctx)
 	if err != nil {
@@ -86,6 +184,7 @@ func AddFieldsToCtxLogger(ctx context.Context, fields ...field.Field) bool {
 	}
 	ctxLogger = ctxLogger.With(fields...)
 	ctx.With(sign.LOGGER, ctxLogger)
+	ctx.With(ctxFieldsKey{}, appendFields(ctx, fields))
 	return true
 }
 
@@ -0,0 +1,157 @@
+package zap_logger
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"github.com/NumberMan1/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapLogger_Panic(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(core)
+	logger := &ZapLogger{base: base, root: base}
+
+	// 非开发模式下 DPanic 只记录不 panic
+	logger.DPanic("dpanic")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Panic() did not panic")
+			}
+		}()
+		logger.Panic("panic")
+	}()
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[0].Level != zapcore.DPanicLevel || entries[1].Level != zapcore.PanicLevel {
+		t.Errorf("entries = %v, want dpanic and panic", entries)
+	}
+}
+
+func logThroughHelper(logger Logger) {
+	logger.WithCaller(1).Info("helper")
+}
+
+func TestZapLogger_WithCaller(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	logger := &ZapLogger{base: base, root: base}
+
+	_, _, line, _ := runtime.Caller(0)
+	logThroughHelper(logger)
+
+	// 调用位置为测试函数而非辅助函数
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Caller.Line != line+1 || !strings.HasSuffix(entries[0].Caller.File, "logger_test.go") {
+		t.Errorf("caller = %v, want logger_test.go:%d", entries[0].Caller, line+1)
+	}
+}
+
+func TestZapLogger_WithGroup(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(core)
+	logger := &ZapLogger{base: base, root: base}
+
+	logger.With(zap.String("id", "top")).WithGroup("storage").With(zap.String("id", "nested")).Info("message", zap.Int("count", 1))
+
+	got := logs.AllUntimed()[0].ContextMap()
+	storage, ok := got["storage"].(map[string]any)
+	if got["id"] != "top" || !ok || storage["id"] != "nested" || storage["count"] != int64(1) {
+		t.Errorf("fields = %v, want id and storage.{id,count}", got)
+	}
+}
+
+func TestNewZapLoggerWithConfig_Development(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewZapLoggerWithConfig(Config{Development: true, Level: "debug", Outputs: []TeeConfig{{Target: path}}}).(*ZapLogger)
+
+	// 开发模式下 DPanic 会 panic，文件输出默认为 console 编码
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("DPanic() did not panic in development mode")
+			}
+		}()
+		logger.DPanic("misuse")
+	}()
+	logger.Sync()
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "misuse") || strings.HasPrefix(string(data), "{") {
+		t.Errorf("file = %q, %v, want console encoded entry", data, err)
+	}
+}
+
+func TestZapLogger_LazyNotEvaluated(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := newZap(core, Config{})
+	logger := &ZapLogger{base: base, root: base}
+	calls := 0
+	data := field.Lazy("data", func() any {
+		calls++
+		return "value"
+	})
+	logger.Debug("suppressed", data)
+	logger.Info("written", data)
+	if calls != 0 {
+		t.Errorf("fn called %d times before encoding, want 0", calls)
+	}
+	if got := logs.All()[0].ContextMap()["data"]; got != `"value"` || calls != 1 {
+		t.Errorf("data = %v, calls = %d", got, calls)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logger, logs := NewTestLogger(WithClock(func() time.Time { return now }))
+	logger.Info("first")
+	now = now.Add(time.Second)
+	logger.Info("second")
+
+	entries := logs.All()
+	if !entries[0].Time.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || entries[1].Time.Sub(entries[0].Time) != time.Second {
+		t.Errorf("times = %v, %v", entries[0].Time, entries[1].Time)
+	}
+}
+
+func TestNewZapLogger(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewZapLogger(log.WithAppName("app"), log.WithLevel(log.INFO), log.WithFileOut(true, dir))
+
+	logger.With(field.String("player", "p1")).Named("storage").Named("redis").Info("saved")
+	func() {
+		defer func() {
+			if recover() != "broken" {
+				t.Errorf("Panic() did not panic with message")
+			}
+		}()
+		logger.Clone().Panic("broken")
+	}()
+
+	// 文件由后台协程写入
+	var info, errs []byte
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		info, _ = os.ReadFile(filepath.Join(dir, "info", "app-info"))
+		errs, _ = os.ReadFile(filepath.Join(dir, "error", "app-error"))
+		if len(info) > 0 && len(errs) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := string(info); !strings.Contains(got, `"msg":"saved"`) || !strings.Contains(got, `"logger":"storage.redis"`) ||
+		!strings.Contains(got, `"player":"p1"`) || strings.Count(got, `"logger"`) != 1 {
+		t.Errorf("info file = %q, want saved with storage.redis and player", got)
+	}
+	if got := string(errs); !strings.Contains(got, `"msg":"broken"`) || strings.Contains(got, "player") {
+		t.Errorf("error file = %q, want broken without With fields", got)
+	}
+}
@@ -0,0 +1,44 @@
+package zap_logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// MetricsHook 每输出一条日志调用一次，module 为 Named 的模块名，未命名时为空；
+// 用于接入 Prometheus 等指标，如按等级统计 error 的增长速率；调用在记录日志的协程中进行，需快速返回
+type MetricsHook func(level zapcore.Level, module string)
+
+var (
+	metricsHook atomic.Pointer[MetricsHook]
+	levelCounts [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+)
+
+// SetMetricsHook 设置日志计数的回调，为 nil 时取消
+func SetMetricsHook(hook MetricsHook) {
+	if hook == nil {
+		metricsHook.Store(nil)
+		return
+	}
+	metricsHook.Store(&hook)
+}
+
+// LogCount 进程内已输出的 level 等级日志条数，未被任何输出接收的日志不计入
+func LogCount(level zapcore.Level) uint64 {
+	if level < zapcore.DebugLevel || level > zapcore.FatalLevel {
+		return 0
+	}
+	return levelCounts[level-zapcore.DebugLevel].Load()
+}
+
+// countEntry 作为 zap.Hooks 在日志写入后计数
+func countEntry(entry zapcore.Entry) error {
+	if entry.Level >= zapcore.DebugLevel && entry.Level <= zapcore.FatalLevel {
+		levelCounts[entry.Level-zapcore.DebugLevel].Add(1)
+	}
+	if hook := metricsHook.Load(); hook != nil {
+		(*hook)(entry.Level, entry.LoggerName)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package zap_logger
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMetricsHook(t *testing.T) {
+	var mu sync.Mutex
+	hooked := map[zapcore.Level]int{}
+	SetMetricsHook(func(level zapcore.Level, module string) {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked[level]++
+	})
+	defer SetMetricsHook(nil)
+
+	logger := NewZapLoggerWithConfig(Config{Level: "info", Outputs: []TeeConfig{{Target: filepath.Join(t.TempDir(), "app.log")}}})
+	infoBefore, errorBefore, debugBefore := LogCount(zapcore.InfoLevel), LogCount(zapcore.ErrorLevel), LogCount(zapcore.DebugLevel)
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Error("error message")
+	logger.Error("error message")
+
+	// 低于配置等级的日志不计数
+	if LogCount(zapcore.DebugLevel) != debugBefore || LogCount(zapcore.InfoLevel)-infoBefore != 1 || LogCount(zapcore.ErrorLevel)-errorBefore != 2 {
+		t.Errorf("LogCount() debug/info/error = %d/%d/%d", LogCount(zapcore.DebugLevel)-debugBefore,
+			LogCount(zapcore.InfoLevel)-infoBefore, LogCount(zapcore.ErrorLevel)-errorBefore)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if hooked[zapcore.DebugLevel] != 0 || hooked[zapcore.InfoLevel] != 1 || hooked[zapcore.ErrorLevel] != 2 {
+		t.Errorf("hooked = %v, want info 1 and error 2", hooked)
+	}
+}
@@ -0,0 +1,124 @@
+package zap_logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"github.com/NumberMan1/general/sign"
+)
+
+const (
+	// HeaderTraceId 请求与响应中携带 trace id 的请求头，请求中没有时读取 W3C traceparent
+	HeaderTraceId = "X-Trace-Id"
+	// HeaderSessionId 请求中携带会话 id 的请求头
+	HeaderSessionId   = "X-Session-Id"
+	headerTraceParent = "traceparent"
+)
+
+// NewTraceId 生成 32 位十六进制的 trace id
+func NewTraceId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// traceIdFromHeader 读取 X-Trace-Id 或 W3C traceparent（version-traceid-spanid-flags）中的 trace id
+func traceIdFromHeader(header http.Header) string {
+	if traceId := header.Get(HeaderTraceId); traceId != "" {
+		return traceId
+	}
+	if parts := strings.Split(header.Get(headerTraceParent), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
+
+// ContextWithRequestLogger 创建带 trace_id、method 与 session_id 的请求日志，并按 sign.LOGGER 与 sign.TRACE_ID 存入 ctx，
+// 字段可通过 FieldsFromCtx 读取；traceId 为空时生成新的 trace id，sessionId 为 0 时不输出
+func ContextWithRequestLogger(ctx context.Context, traceId, method string, sessionId uint64) (context.Context, Logger) {
+	if traceId == "" {
+		traceId = NewTraceId()
+	}
+	fields := []field.Field{field.WithTraceId(traceId), field.WithMethod(method)}
+	if sessionId != 0 {
+		fields = append(fields, field.WithSession(sessionId))
+	}
+	logger := MustGetLoggerCtx(ctx).With(fields...)
+	ctx = context.WithValue(ctx, sign.TRACE_ID, traceId)
+	ctx = context.WithValue(ctx, ctxFieldsKey{}, appendFields(ctx, fields))
+	return context.WithValue(ctx, sign.LOGGER, logger), logger
+}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// HTTPMiddleware 为每个请求创建请求日志存入 ctx，并在响应头中返回 trace id；
+// 请求结束后记录状态码与耗时，5xx 为 Error，4xx 为 Warn，其他为 Info
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sessionId, _ := strconv.ParseUint(r.Header.Get(HeaderSessionId), 10, 64)
+		ctx, logger := ContextWithRequestLogger(r.Context(), traceIdFromHeader(r.Header), r.Method+" "+r.URL.Path, sessionId)
+		w.Header().Set(HeaderTraceId, signValue(ctx, sign.TRACE_ID))
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		logger.Debug("http request", field.String("remote", r.RemoteAddr), field.String("query", r.URL.RawQuery))
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		fields := []field.Field{field.Int("status", recorder.status), field.WithCostUS(time.Since(start).Microseconds())}
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			logger.Error("http response", fields...)
+		case recorder.status >= http.StatusBadRequest:
+			logger.Warn("http response", fields...)
+		default:
+			logger.Info("http response", fields...)
+		}
+	})
+}
+
+// UnaryCall 为一次 RPC 调用创建请求日志存入 ctx，记录请求、响应与耗时，返回错误时为 Error；
+// handler 与 grpc.UnaryHandler 签名一致，gRPC 拦截器可直接转发：
+//
+//	func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+//		var traceId string
+//		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("x-trace-id")) > 0 {
+//			traceId = md.Get("x-trace-id")[0]
+//		}
+//		return zaplogger.UnaryCall(ctx, info.FullMethod, traceId, 0, req, handler)
+//	}
+func UnaryCall(ctx context.Context, method, traceId string, sessionId uint64, req any,
+	handler func(ctx context.Context, req any) (any, error)) (any, error) {
+	start := time.Now()
+	ctx, logger := ContextWithRequestLogger(ctx, traceId, method, sessionId)
+	logger.Debug("rpc request", field.WithData(req))
+
+	resp, err := handler(ctx, req)
+
+	cost := field.WithCostUS(time.Since(start).Microseconds())
+	if err != nil {
+		logger.Error("rpc response", cost, field.WithError(err))
+		return resp, err
+	}
+	logger.Info("rpc response", cost)
+	logger.Debug("rpc response data", field.WithData(resp))
+	return resp, nil
+}
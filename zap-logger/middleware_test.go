@@ -0,0 +1,72 @@
+package zap_logger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := zap.New(core)
+	defaultLogger = &ZapLogger{base: base, root: base}
+	defer func() { defaultLogger = nil }()
+
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := GetLoggerCtx(r.Context()); err != nil {
+			t.Errorf("GetLoggerCtx() error = %v", err)
+		}
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(headerTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(HeaderSessionId, "42")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if got := recorder.Header().Get(HeaderTraceId); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("response trace id = %q", got)
+	}
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[1].Level != zapcore.WarnLevel {
+		t.Fatalf("entries = %v, want handling and warn response", entries)
+	}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || fields["method"] != "GET /users" || fields["session_id"] != uint64(42) {
+			t.Errorf("fields = %v", fields)
+		}
+	}
+	if entries[1].ContextMap()["status"] != int64(http.StatusNotFound) {
+		t.Errorf("status = %v, want 404", entries[1].ContextMap()["status"])
+	}
+}
+
+func TestUnaryCall(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := zap.New(core)
+	defaultLogger = &ZapLogger{base: base, root: base}
+	defer func() { defaultLogger = nil }()
+
+	wantErr := errors.New("boom")
+	_, err := UnaryCall(context.Background(), "/user.User/Get", "", 0, "req", func(ctx context.Context, req any) (any, error) {
+		if FromContext(ctx) == DefaultLogger() {
+			t.Errorf("handler ctx has no request logger")
+		}
+		return nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("UnaryCall() error = %v, want %v", err, wantErr)
+	}
+	entries := logs.AllUntimed()
+	if len(entries) != 1 || entries[0].Level != zapcore.ErrorLevel || len(entries[0].ContextMap()["trace_id"].(string)) != 32 {
+		t.Errorf("entries = %v, want error response with generated trace id", entries)
+	}
+}
@@ -0,0 +1,82 @@
+package zap_logger
+
+import (
+	"strings"
+
+	"github.com/NumberMan1/log"
+	"go.uber.org/zap/zapcore"
+)
+
+// moduleLevels 按 Named 模块名配置的等级，未配置的模块使用全局等级
+type moduleLevels struct {
+	global  zapcore.Level
+	modules map[string]zapcore.Level
+}
+
+func newModuleLevels(global log.Level, modules map[string]log.Level) moduleLevels {
+	levels := moduleLevels{global: zapLevel(global), modules: make(map[string]zapcore.Level, len(modules))}
+	for name, level := range modules {
+		levels.modules[name] = zapLevel(level)
+	}
+	return levels
+}
+
+// min 全局与各模块中最低的等级
+func (levels moduleLevels) min() zapcore.Level {
+	level := levels.global
+	for _, moduleLevel := range levels.modules {
+		level = min(level, moduleLevel)
+	}
+	return level
+}
+
+// of 模块的等级：按 storage.redis、storage 的顺序匹配最具体的配置，都未配置时为全局等级
+func (levels moduleLevels) of(name string) zapcore.Level {
+	for name != "" {
+		if level, ok := levels.modules[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return levels.global
+}
+
+// moduleCore 按日志所属模块的等级过滤，内层输出需开启到 levels.min()；
+// requestDebug 时内层输出开启到 debug，DebugTargets 中的日志不受等级限制
+type moduleCore struct {
+	zapcore.Core
+	levels       moduleLevels
+	requestDebug bool
+	ids          debugIds
+}
+
+func (core *moduleCore) Enabled(level zapcore.Level) bool {
+	if level < core.levels.min() && !core.debugging() {
+		return false
+	}
+	return core.Core.Enabled(level)
+}
+
+func (core *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *core
+	clone.Core = core.Core.With(fields)
+	if core.requestDebug {
+		clone.ids = core.ids.with(fields)
+	}
+	return &clone
+}
+
+func (core *moduleCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < core.levels.of(entry.LoggerName) && !core.debugging() {
+		return checked
+	}
+	return core.Core.Check(entry, checked)
+}
+
+func (core *moduleCore) debugging() bool {
+	return core.requestDebug && core.ids.debugging()
+}
@@ -0,0 +1,52 @@
+package zap_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NumberMan1/log"
+)
+
+func TestNamed_ModuleLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := NewZapLoggerWithConfig(Config{
+		Level:   "info",
+		Modules: map[string]log.Level{"storage": "debug", "network": "warn"},
+		Outputs: []TeeConfig{{Target: path}},
+	}).(*ZapLogger)
+
+	tests := []struct {
+		name    string
+		logger  Logger
+		debug   bool
+		info    bool
+		message string
+	}{
+		{name: "未配置的模块使用全局等级", logger: logger, info: true, message: "root"},
+		{name: "storage 为 debug", logger: logger.Named("storage"), debug: true, info: true, message: "storage"},
+		{name: "子模块继承 storage", logger: logger.Named("storage").Named("redis"), debug: true, info: true, message: "redis"},
+		{name: "network 为 warn", logger: logger.Named("network"), message: "network"},
+	}
+	for _, tt := range tests {
+		tt.logger.Debug(tt.message + " debug")
+		tt.logger.Info(tt.message + " info")
+	}
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(string(data), `"`+tt.message+` debug"`); got != tt.debug {
+				t.Errorf("debug written = %v, want %v", got, tt.debug)
+			}
+			if got := strings.Contains(string(data), `"`+tt.message+` info"`); got != tt.info {
+				t.Errorf("info written = %v, want %v", got, tt.info)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package zap_logger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// NewTestLogger 创建记录全部等级日志的 Logger，用于测试中断言日志：
+//
+//	logger, logs := zaplogger.NewTestLogger()
+//	...
+//	if logs.FilterLevelExact(zapcore.WarnLevel).FilterMessage("retry").Len() != 1 { ... }
+//
+// 日志的字段可通过 LoggedEntry.ContextMap 读取，需要稳定的时间时传入 WithClock
+func NewTestLogger(opts ...Option) (Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	base := newZap(core, Config{}, newOptions(opts).zapOptions()...)
+	return &ZapLogger{base: base, root: base}, logs
+}
+
+// SetDefaultLogger 替换默认日志，返回恢复原默认日志的函数，用于测试中捕获依赖 DefaultLogger 的组件的日志
+func SetDefaultLogger(logger Logger) (restore func()) {
+	previous := defaultLogger
+	defaultLogger = logger
+	return func() { defaultLogger = previous }
+}
@@ -0,0 +1,47 @@
+package zap_logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Option NewZapLoggerWithConfig 与 NewTestLogger 的可选项
+type Option func(opts *options)
+
+type options struct {
+	clock func() time.Time
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// zapOptions 可选项对应的 zap 选项
+func (o options) zapOptions() []zap.Option {
+	return []zap.Option{zap.WithClock(funcClock(o.clock))}
+}
+
+// WithClock 以 clock 作为日志时间与去重窗口的时钟，便于测试与回放工具得到稳定的时间戳
+func WithClock(clock func() time.Time) Option {
+	return func(opts *options) {
+		if clock != nil {
+			opts.clock = clock
+		}
+	}
+}
+
+// funcClock 以函数实现 zapcore.Clock
+type funcClock func() time.Time
+
+func (clock funcClock) Now() time.Time {
+	return clock()
+}
+
+func (clock funcClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
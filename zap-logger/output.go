@@ -0,0 +1,301 @@
+package zap_logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NumberMan1/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	fileBufferSize    = 4096
+	fileFlushInterval = 10 * time.Second
+)
+
+// fileLevels 文件输出按等级分目录，error 目录同时收录 dpanic/panic/fatal
+var fileLevels = []zapcore.Level{zapcore.ErrorLevel, zapcore.WarnLevel, zapcore.InfoLevel, zapcore.DebugLevel}
+
+// zapLevel 将配置的等级转换为 zap 等级，无法识别时为 info
+func zapLevel(level log.Level) zapcore.Level {
+	lvl, err := zapcore.ParseLevel(string(level))
+	if err != nil {
+		return zapcore.InfoLevel
+	}
+	return lvl
+}
+
+// outputLevel 输出单独配置的等级，未配置时为全局等级
+func outputLevel(output OutputConfig, level log.Level) zapcore.Level {
+	if output.Level != "" {
+		return zapLevel(output.Level)
+	}
+	return zapLevel(level)
+}
+
+// newEncoder 按编码格式创建编码器，encoding 为空时使用 defaultEncoding
+func newEncoder(encoding, defaultEncoding string) zapcore.Encoder {
+	if encoding == "" {
+		encoding = defaultEncoding
+	}
+	encoderConfig := log.DefaultEncoder()
+	switch strings.ToLower(encoding) {
+	case EncodingJSON:
+		return zapcore.NewJSONEncoder(encoderConfig)
+	case EncodingColorConsole:
+		encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(colorTimeLayout)
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoderConfig.EncodeName = colorNameEncoder
+		encoderConfig.EncodeCaller = colorCallerEncoder
+	}
+	return zapcore.NewConsoleEncoder(encoderConfig)
+}
+
+const (
+	// colorTimeLayout 彩色输出只保留时分秒与毫秒
+	colorTimeLayout = "15:04:05.000"
+	colorCyan       = "\x1b[36m"
+	colorGray       = "\x1b[90m"
+	colorReset      = "\x1b[0m"
+)
+
+// colorNameEncoder 以青色输出 Named 的模块名
+func colorNameEncoder(name string, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(colorCyan + name + colorReset)
+}
+
+// colorCallerEncoder 以灰色输出 文件名:行号，省略目录
+func colorCallerEncoder(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	if !caller.Defined {
+		enc.AppendString("undefined")
+		return
+	}
+	enc.AppendString(colorGray + filepath.Base(caller.File) + ":" + strconv.Itoa(caller.Line) + colorReset)
+}
+
+// outputClosers 输出打开的文件与后台协程，重新加载配置后关闭
+type outputClosers []io.Closer
+
+func (closers outputClosers) Close() error {
+	var err error
+	for _, closer := range closers {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// newCore 按配置组装各输出，按模块等级过滤并脱敏，未开启任何输出时不输出；返回的 io.Closer 关闭各输出
+func newCore(config Config) (zapcore.Core, io.Closer, error) {
+	redactor, err := newRedactor(config.Redact)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers := &outputClosers{}
+	core, err := newModulesCore(config, closers)
+	if err != nil {
+		closers.Close()
+		return nil, nil, err
+	}
+	if redactor != nil {
+		core = &redactCore{Core: core, redactor: redactor}
+	}
+	return core, closers, nil
+}
+
+// newModulesCore 按模块等级过滤的各输出
+func newModulesCore(config Config, closers *outputClosers) (zapcore.Core, error) {
+	if len(config.Modules) == 0 && !config.RequestDebug {
+		return newOutputsCore(config, closers)
+	}
+	levels := newModuleLevels(config.Level, config.Modules)
+	// 未单独配置等级的输出需接收最低的模块等级，再由 moduleCore 按模块过滤
+	config.Level = log.Level(levels.min().String())
+	if config.RequestDebug {
+		config.Level = log.Level(zapcore.DebugLevel.String())
+	}
+	core, err := newOutputsCore(config, closers)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleCore{Core: core, levels: levels, requestDebug: config.RequestDebug}, nil
+}
+
+func newOutputsCore(config Config, closers *outputClosers) (zapcore.Core, error) {
+	cores := []zapcore.Core{&hookCore{}}
+	if config.OutputFile() {
+		fileCores, err := fileoutCores(config, outputLevel(config.FileOutput, config.Level), closers)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, fileCores...)
+	}
+	if config.Stdout {
+		cores = append(cores, stdoutCores(config, outputLevel(config.StdoutOutput, config.Level))...)
+	}
+	if config.ErrorFile != "" {
+		core, err := teeCore(config.errorFileOutput(), config, closers)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+	for _, teeConfig := range config.Outputs {
+		core, err := teeCore(teeConfig, config, closers)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+	for _, sinkConfig := range config.Sinks {
+		factory, ok := LookupSink(sinkConfig.Type)
+		if !ok {
+			return nil, fmt.Errorf("zap-logger: unknown sink type %q", sinkConfig.Type)
+		}
+		sink, err := factory(sinkConfig, config.Name)
+		if err != nil {
+			return nil, err
+		}
+		core := newSinkCore(sink, sinkConfig, outputLevel(OutputConfig{Level: sinkConfig.Level}, config.Level))
+		*closers = append(*closers, core.buffer)
+		cores = append(cores, core)
+	}
+	return zapcore.NewTee(cores...), nil
+}
+
+// newZap 以 core 创建 zap.Logger，按配置设置调用位置、调用栈与 app 字段，extra 在其后应用
+func newZap(core zapcore.Core, config Config, extra ...zap.Option) *zap.Logger {
+	options := []zap.Option{
+		zap.WithCaller(!config.DisableCaller),
+		zap.AddCallerSkip(1),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.Hooks(countEntry),
+	}
+	if config.Development {
+		options = append(options, zap.Development())
+	}
+	switch config.StacktraceLevel {
+	case StacktraceNone:
+	case "":
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
+	default:
+		options = append(options, zap.AddStacktrace(zapLevel(config.StacktraceLevel)))
+	}
+	base := zap.New(core, append(options, extra...)...)
+	if config.Name != "" {
+		base = base.With(zap.String("app", config.Name))
+	}
+	if fields := config.GlobalFields.fields(); len(fields) > 0 {
+		base = base.With(fields...)
+	}
+	return base
+}
+
+func fileoutCores(config Config, level zapcore.Level, closers *outputClosers) ([]zapcore.Core, error) {
+	encoder := newEncoder(config.FileOutput.Encoding, EncodingJSON)
+	cores := make([]zapcore.Core, 0, len(fileLevels))
+	for _, lvl := range fileLevels {
+		writer, err := newFileWriter(config.Name, config.LogFilePath, lvl.String(), config.Rotate)
+		if err != nil {
+			return nil, err
+		}
+		*closers = append(*closers, writer)
+		cores = append(cores, zapcore.NewCore(encoder, writer, priority(lvl, level)))
+	}
+	return cores, nil
+}
+
+// fileWriter 带缓冲的切分文件输出，缓冲超过 4KB 或每 10 秒写入一次
+type fileWriter struct {
+	*zapcore.BufferedWriteSyncer
+	file *rotateWriter
+}
+
+func newBufferedFile(filename string, rotate RotateConfig) (*fileWriter, error) {
+	file, err := newRotateWriter(filename, rotate)
+	if err != nil {
+		return nil, err
+	}
+	return &fileWriter{
+		BufferedWriteSyncer: &zapcore.BufferedWriteSyncer{WS: file, Size: fileBufferSize, FlushInterval: fileFlushInterval},
+		file:                file,
+	}, nil
+}
+
+// Close 写入缓冲后关闭文件
+func (w *fileWriter) Close() error {
+	return errors.Join(w.Stop(), w.file.Close())
+}
+
+// newFileWriter 写入 dir/subDir/app-subDir.log 并按 rotate 切分
+func newFileWriter(app, dir, subDir string, rotate RotateConfig) (*fileWriter, error) {
+	name := subDir
+	if app != "" {
+		name = app + "-" + subDir
+	}
+	return newBufferedFile(filepath.Join(dir, subDir, name+".log"), rotate)
+}
+
+// teeCore 创建 Config.Outputs 中的一个输出
+func teeCore(teeConfig TeeConfig, config Config, closers *outputClosers) (zapcore.Core, error) {
+	var writer zapcore.WriteSyncer
+	defaultEncoding := EncodingConsole
+	switch teeConfig.Target {
+	case "":
+		return nil, fmt.Errorf("zap-logger: output target not configured")
+	case OutputStdout:
+		writer = zapcore.Lock(os.Stdout)
+	case OutputStderr:
+		writer = zapcore.Lock(os.Stderr)
+	default:
+		file, err := newBufferedFile(teeConfig.Target, config.Rotate)
+		if err != nil {
+			return nil, err
+		}
+		*closers = append(*closers, file)
+		writer = file
+		defaultEncoding = EncodingJSON
+	}
+	minLevel := outputLevel(OutputConfig{Level: teeConfig.Level}, config.Level)
+	maxLevel := zapcore.FatalLevel
+	if teeConfig.MaxLevel != "" {
+		maxLevel = zapLevel(teeConfig.MaxLevel)
+	}
+	enabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= minLevel && lvl <= maxLevel
+	})
+	return zapcore.NewCore(newEncoder(teeConfig.Encoding, defaultEncoding), writer, enabler), nil
+}
+
+func stdoutCores(config Config, level zapcore.Level) []zapcore.Core {
+	encoder := newEncoder(config.StdoutOutput.Encoding, config.StdoutTyp)
+	errorEnabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl >= zapcore.ErrorLevel && lvl >= level
+	})
+	otherEnabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return lvl < zapcore.ErrorLevel && lvl >= level
+	})
+	return []zapcore.Core{
+		zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), errorEnabler),
+		zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), otherEnabler),
+	}
+}
+
+// priority 仅输出 fileLevel 等级的日志，error 同时输出更高的等级
+func priority(fileLevel, level zapcore.Level) zap.LevelEnablerFunc {
+	return func(lvl zapcore.Level) bool {
+		if lvl < level {
+			return false
+		}
+		if fileLevel == zapcore.ErrorLevel {
+			return lvl >= zapcore.ErrorLevel
+		}
+		return lvl == fileLevel
+	}
+}
@@ -0,0 +1,123 @@
+package zap_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewZapLoggerWithConfig_FileOutput(t *testing.T) {
+	dir := t.TempDir()
+	logger := NewZapLoggerWithConfig(Config{
+		Name:        "app",
+		Level:       "debug",
+		LogFilePath: dir,
+		FileOutput:  OutputConfig{Encoding: EncodingConsole, Level: "warn"},
+	}).(*ZapLogger)
+	logger.Info("info message")
+	logger.Warn("warn message")
+	logger.Sync()
+
+	// 文件输出的等级高于全局等级时 info 不写入文件
+	if data, _ := os.ReadFile(filepath.Join(dir, "info", "app-info.log")); len(data) != 0 {
+		t.Errorf("info file = %q, want empty", data)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "warn", "app-warn.log"))
+	if err != nil || !strings.Contains(string(data), "warn message") || strings.HasPrefix(string(data), "{") {
+		t.Errorf("warn file = %q, %v, want console encoded warn message", data, err)
+	}
+}
+
+func TestNewZapLoggerWithConfig_Outputs(t *testing.T) {
+	dir := t.TempDir()
+	all, warn, info := filepath.Join(dir, "all.log"), filepath.Join(dir, "warn.log"), filepath.Join(dir, "info.log")
+	logger := NewZapLoggerWithConfig(Config{
+		Level: "debug",
+		Outputs: []TeeConfig{
+			{Target: all},
+			{Target: warn, Level: "warn"},
+			{Target: info, Level: "info", MaxLevel: "info"},
+		},
+	}).(*ZapLogger)
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Error("error message")
+	logger.Sync()
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "全部等级", path: all, want: []string{"debug message", "info message", "error message"}},
+		{name: "warn 及以上", path: warn, want: []string{"error message"}},
+		{name: "仅 info", path: info, want: []string{"info message"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("lines = %q, want %q", lines, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("line %d = %q, want %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewZapLoggerWithConfig_ErrorFile(t *testing.T) {
+	dir := t.TempDir()
+	errorFile := filepath.Join(dir, "app-error.log")
+	logger := NewZapLoggerWithConfig(Config{Name: "app", LogFilePath: dir, ErrorFile: errorFile}).(*ZapLogger)
+	logger.Info("info message")
+	logger.Warn("warn message")
+	logger.Error("error message")
+	logger.Sync()
+
+	data, err := os.ReadFile(errorFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "info message") || !strings.Contains(string(data), "warn message") || !strings.Contains(string(data), "error message") {
+		t.Errorf("error file = %q, want warn and error only", data)
+	}
+	// 主文件输出不受影响
+	if data, _ := os.ReadFile(filepath.Join(dir, "info", "app-info.log")); !strings.Contains(string(data), "info message") {
+		t.Errorf("info file = %q, want info message", data)
+	}
+}
+
+func TestNewEncoder_ColorConsole(t *testing.T) {
+	encoder := newEncoder("", EncodingColorConsole)
+	entry := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2024, 1, 2, 15, 4, 5, 6e6, time.Local),
+		LoggerName: "storage",
+		Message:    "slow",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/storage/redis.go", 42, true),
+	}
+	buf, err := encoder.EncodeEntry(entry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := buf.String()
+	for _, want := range []string{"15:04:05.006", "\x1b[33mWARN\x1b[0m", colorCyan + "storage" + colorReset, colorGray + "redis.go:42" + colorReset, "slow"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("line = %q, want %q", line, want)
+		}
+	}
+	if strings.Contains(line, "2024") || strings.Contains(line, "/src/app") {
+		t.Errorf("line = %q, want short time and trimmed caller", line)
+	}
+}
@@ -0,0 +1,222 @@
+package zap_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultRedactReplacement = "******"
+
+// RedactConfig 脱敏规则，在编码前作用于全部输出，WithData 等以 JSON 记录的结构体同样按字段名脱敏
+type RedactConfig struct {
+	// 需要整体替换的字段名，不区分大小写，支持 path.Match 通配符，如 password、*token*、*secret*
+	Keys []string `json:"keys" yaml:"keys"`
+	// 值中需要替换的正则，作用于日志内容、字符串字段与错误信息，如 Bearer\s+[\w.-]+
+	Patterns []string `json:"patterns" yaml:"patterns"`
+	// 替换文本，为空时为 ******
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// redactor 编译后的脱敏规则
+type redactor struct {
+	keys        []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+// newRedactor 编译脱敏规则，未配置任何规则时返回 nil
+func newRedactor(config RedactConfig) (*redactor, error) {
+	if len(config.Keys) == 0 && len(config.Patterns) == 0 {
+		return nil, nil
+	}
+	r := &redactor{replacement: config.Replacement}
+	if r.replacement == "" {
+		r.replacement = defaultRedactReplacement
+	}
+	for _, key := range config.Keys {
+		key = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("zap-logger: redact key %q: %w", key, err)
+		}
+		r.keys = append(r.keys, key)
+	}
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("zap-logger: redact pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// matchKey 字段名是否需要整体替换
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// value 替换值中匹配正则的部分
+func (r *redactor) value(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// text 脱敏字符串，JSON 对象或数组按字段名与值逐层脱敏
+func (r *redactor) text(s string) string {
+	if trimmed := strings.TrimSpace(s); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if redacted, ok := r.json([]byte(trimmed)); ok {
+			return redacted
+		}
+	}
+	return r.value(s)
+}
+
+// json 解析 JSON 后逐层脱敏再编码，不是合法 JSON 时返回 false
+func (r *redactor) json(data []byte) (string, bool) {
+	v, ok := decodeJSON(data)
+	if !ok {
+		return "", false
+	}
+	out, err := json.Marshal(r.walk(v))
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+// decodeJSON 解析 JSON，数字保留为 json.Number 避免精度丢失
+func decodeJSON(data []byte) (any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil || decoder.More() {
+		return nil, false
+	}
+	return v, true
+}
+
+// walk 逐层脱敏 map、切片与字符串
+func (r *redactor) walk(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			if r.matchKey(key) {
+				out[key] = r.replacement
+				continue
+			}
+			out[key] = r.walk(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = r.walk(value)
+		}
+		return out
+	case string:
+		return r.value(v)
+	}
+	return v
+}
+
+// field 脱敏单个字段
+func (r *redactor) field(f zapcore.Field) zapcore.Field {
+	if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+		return f
+	}
+	if r.matchKey(f.Key) {
+		return zap.String(f.Key, r.replacement)
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = r.text(f.String)
+	case zapcore.ByteStringType:
+		return zap.ByteString(f.Key, []byte(r.text(string(f.Interface.([]byte)))))
+	case zapcore.StringerType:
+		return zap.String(f.Key, r.text(fmt.Sprint(f.Interface)))
+	case zapcore.ErrorType:
+		err, _ := f.Interface.(error)
+		if err == nil {
+			return f
+		}
+		if msg := err.Error(); r.value(msg) != msg {
+			return zap.NamedError(f.Key, errors.New(r.value(msg)))
+		}
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		data, err := json.Marshal(r.marshalable(f))
+		if err != nil {
+			return f
+		}
+		if v, ok := decodeJSON(data); ok {
+			return zap.Any(f.Key, r.walk(v))
+		}
+	}
+	return f
+}
+
+// marshalable 字段的值，ObjectMarshaler 与 ArrayMarshaler 先编码为 map 或切片
+func (r *redactor) marshalable(f zapcore.Field) any {
+	if f.Type == zapcore.ReflectType {
+		return f.Interface
+	}
+	encoder := zapcore.NewMapObjectEncoder()
+	f.AddTo(encoder)
+	return encoder.Fields[f.Key]
+}
+
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = r.field(f)
+	}
+	return out
+}
+
+// redactCore 在编码前对日志内容与字段脱敏
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (core *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: core.Core.With(core.redactor.fields(fields)), redactor: core.redactor}
+}
+
+func (core *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = core.redactor.value(entry.Message)
+	return writeChecked(core.Core, entry, core.redactor.fields(fields))
+}
+
+// writeChecked 经 Check 写入 core，Tee 中各输出按自身等级过滤；直接调用 Tee 的 Write 会写入全部输出
+func writeChecked(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) error {
+	if checked := core.Check(entry, nil); checked != nil {
+		checked.ErrorOutput = zapcore.Lock(os.Stderr)
+		checked.Write(fields...)
+	}
+	return nil
+}
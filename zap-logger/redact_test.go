@@ -0,0 +1,65 @@
+package zap_logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactCore(t *testing.T) {
+	redactor, err := newRedactor(RedactConfig{
+		Keys:     []string{"password", "*token*"},
+		Patterns: []string{`Bearer\s+[\w.-]+`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	observed, logs := observer.New(zapcore.DebugLevel)
+	base := zap.New(&redactCore{Core: observed, redactor: redactor})
+	logger := &ZapLogger{base: base, root: base}
+
+	type login struct {
+		User        string `json:"user"`
+		Password    string `json:"password"`
+		AccessToken string `json:"access_token"`
+	}
+	logger.With(field.String("password", "p1")).Info("auth Bearer abc.def",
+		field.WithData(login{User: "u", Password: "p2", AccessToken: "t"}),
+		zap.Any("req", map[string]any{"header": "Bearer xyz", "Token": "t"}),
+		field.WithError(errors.New("invalid Bearer abc")),
+		field.String("user", "u"),
+	)
+
+	entry := logs.All()[0]
+	if entry.Message != "auth ******" {
+		t.Errorf("message = %q", entry.Message)
+	}
+	fields := entry.ContextMap()
+	if fields["password"] != defaultRedactReplacement || fields["user"] != "u" {
+		t.Errorf("password = %v, user = %v", fields["password"], fields["user"])
+	}
+	data := fields["data"].(string)
+	if strings.Contains(data, "p2") || strings.Contains(data, `"t"`) || !strings.Contains(data, `"user":"u"`) {
+		t.Errorf("data = %s", data)
+	}
+	if req := fields["req"].(map[string]any); req["header"] != defaultRedactReplacement || req["Token"] != defaultRedactReplacement {
+		t.Errorf("req = %s", req)
+	}
+	if fields["error"] != "invalid ******" {
+		t.Errorf("error = %v", fields["error"])
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	if r, err := newRedactor(RedactConfig{}); r != nil || err != nil {
+		t.Errorf("newRedactor(empty) = %v, %v, want nil", r, err)
+	}
+	if _, err := newRedactor(RedactConfig{Patterns: []string{"("}}); err == nil {
+		t.Errorf("newRedactor(invalid pattern) error = nil")
+	}
+}
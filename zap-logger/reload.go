@@ -0,0 +1,267 @@
+package zap_logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NumberMan1/component/filewatch"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// errNotReloadable 日志不是由 NewZapLoggerWithConfig 创建，如 NewTestLogger
+var errNotReloadable = errors.New("zap-logger: logger is not reloadable")
+
+// reloadCloseTimeout 替换输出后等待正在进行的写入结束的最长时间，超时后仍关闭旧输出
+const reloadCloseTimeout = 5 * time.Second
+
+// reloadGen 一次加载的输出
+type reloadGen struct {
+	core   zapcore.Core
+	closer io.Closer
+	// async 开启异步写入时的队列，否则为 nil
+	async *asyncCore
+
+	// active 正在写入的次数，retired 后归零时关闭 idle
+	active   atomic.Int64
+	retired  atomic.Bool
+	idle     chan struct{}
+	idleOnce sync.Once
+}
+
+// newReloadGen 按配置创建输出，开启异步写入时在输出之前加入异步队列，开启去重时再在最外层去重；
+// 关闭时依次输出合并的日志、写完队列、关闭输出。now 为去重窗口的时钟
+func newReloadGen(config Config, now func() time.Time) (*reloadGen, error) {
+	core, closer, err := newCore(config)
+	if err != nil {
+		return nil, err
+	}
+	gen := newGen(core, closer)
+	if config.Async.Enabled {
+		gen.async = newAsyncCore(core, config.Async)
+		gen.core = gen.async
+		gen.closer = outputClosers{gen.async, closer}
+	}
+	if config.Dedup.Enabled {
+		dedup := newDedupCore(gen.core, config.Dedup, now)
+		gen.core = dedup
+		gen.closer = outputClosers{dedup, gen.closer}
+	}
+	return gen, nil
+}
+
+func newGen(core zapcore.Core, closer io.Closer) *reloadGen {
+	return &reloadGen{core: core, closer: closer, idle: make(chan struct{})}
+}
+
+// release 结束一次写入，已被替换且没有其他写入时通知等待关闭的 swap
+func (gen *reloadGen) release() {
+	if gen.active.Add(-1) == 0 && gen.retired.Load() {
+		gen.idleOnce.Do(func() { close(gen.idle) })
+	}
+}
+
+// retire 标记已被替换，等待正在进行的写入结束，最多等待 timeout
+func (gen *reloadGen) retire(timeout time.Duration) {
+	gen.retired.Store(true)
+	if gen.active.Load() == 0 {
+		gen.idleOnce.Do(func() { close(gen.idle) })
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-gen.idle:
+	case <-timer.C:
+	}
+}
+
+// dropped 异步写入时被丢弃的日志条数
+func (gen *reloadGen) dropped() uint64 {
+	if gen.async == nil {
+		return 0
+	}
+	return gen.async.Dropped()
+}
+
+// reloadRoot 可替换的输出，所有 With 出的 reloadCore 共享
+type reloadRoot struct {
+	// now 去重窗口的时钟，重新加载时沿用
+	now     func() time.Time
+	mu      sync.Mutex
+	current atomic.Pointer[reloadGen]
+	// dropped 已替换的输出中被丢弃的日志条数
+	dropped atomic.Uint64
+}
+
+func newReloadRoot(gen *reloadGen, now func() time.Time) *reloadRoot {
+	root := &reloadRoot{now: now}
+	root.current.Store(gen)
+	return root
+}
+
+// acquire 取得当前输出并开始一次写入，写入结束后调用 release；输出替换后不会在写入期间关闭
+func (root *reloadRoot) acquire() *reloadGen {
+	for {
+		gen := root.current.Load()
+		gen.active.Add(1)
+		if !gen.retired.Load() {
+			return gen
+		}
+		// 已被替换，改用新的输出
+		gen.release()
+	}
+}
+
+// swap 替换输出，等待旧输出上正在进行的写入结束后写入缓冲并关闭
+func (root *reloadRoot) swap(gen *reloadGen) error {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	old := root.current.Swap(gen)
+	old.retire(reloadCloseTimeout)
+	err := errors.Join(old.core.Sync(), old.closer.Close())
+	root.dropped.Add(old.dropped())
+	return err
+}
+
+// close 关闭当前输出，之后的日志被丢弃
+func (root *reloadRoot) close() error {
+	return root.swap(newGen(zapcore.NewNopCore(), outputClosers{}))
+}
+
+// Dropped 异步写入时被丢弃的日志条数，包括已替换的输出
+func (root *reloadRoot) Dropped() uint64 {
+	return root.dropped.Load() + root.current.Load().dropped()
+}
+
+// reloadCached 对某次加载的输出附加 With 字段后的结果
+type reloadCached struct {
+	gen  *reloadGen
+	core zapcore.Core
+}
+
+// reloadCore 记录 With 的字段，重新加载后对新输出重新附加
+type reloadCore struct {
+	root   *reloadRoot
+	fields []zapcore.Field
+	cached atomic.Pointer[reloadCached]
+}
+
+func newReloadCore(root *reloadRoot) *reloadCore {
+	return &reloadCore{root: root}
+}
+
+// load 当前输出附加 With 字段后的 core
+func (core *reloadCore) load() zapcore.Core {
+	return core.coreOf(core.root.current.Load())
+}
+
+// coreOf gen 附加 With 字段后的 core
+func (core *reloadCore) coreOf(gen *reloadGen) zapcore.Core {
+	if cached := core.cached.Load(); cached != nil && cached.gen == gen {
+		return cached.core
+	}
+	inner := gen.core
+	if len(core.fields) > 0 {
+		inner = inner.With(core.fields)
+	}
+	core.cached.Store(&reloadCached{gen: gen, core: inner})
+	return inner
+}
+
+func (core *reloadCore) Enabled(level zapcore.Level) bool {
+	return core.load().Enabled(level)
+}
+
+func (core *reloadCore) With(fields []zapcore.Field) zapcore.Core {
+	gen := core.root.current.Load()
+	clone := &reloadCore{root: core.root, fields: append(slices.Clip(core.fields), fields...)}
+	clone.cached.Store(&reloadCached{gen: gen, core: core.load().With(fields)})
+	return clone
+}
+
+// Check 加入自身而非当前输出，写入时再取得输出，避免 CheckedEntry 持有已被替换关闭的输出
+func (core *reloadCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *reloadCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	gen := core.root.acquire()
+	defer gen.release()
+	return writeChecked(core.coreOf(gen), entry, fields)
+}
+
+func (core *reloadCore) Sync() error {
+	gen := core.root.acquire()
+	defer gen.release()
+	return core.coreOf(gen).Sync()
+}
+
+// Reload 按新配置重建输出、等级、模块等级、脱敏规则、额外输出、异步队列与去重并原子替换，已 With 或 Named 出的日志同样生效；
+// 旧的去重输出合并的日志、旧的异步队列写完后停止，旧的输出写入缓冲后关闭。Name、GlobalFields、Development、StacktraceLevel
+// 与 DisableCaller 在创建时确定，不随 Reload 变化
+func (logger *ZapLogger) Reload(config Config) error {
+	if logger.reload == nil {
+		return errNotReloadable
+	}
+	gen, err := newReloadGen(config.developmentDefaults(), logger.reload.now)
+	if err != nil {
+		return err
+	}
+	return logger.reload.swap(gen)
+}
+
+// Reload 重新加载默认日志的配置，InitLogger 创建的默认日志沿用节点名后缀
+func Reload(config Config) error {
+	logger, ok := DefaultLogger().(*ZapLogger)
+	if !ok {
+		return errNotReloadable
+	}
+	config.Name += nodeSuffix
+	return logger.Reload(config)
+}
+
+// LoadConfig 从 YAML 或 JSON 文件加载配置
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("zap-logger: read config %s: %w", path, err)
+	}
+	var config Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	default:
+		return Config{}, fmt.Errorf("zap-logger: unsupported config format %q", ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("zap-logger: parse config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// WatchConfigFile 按 interval 轮询配置文件的修改时间，文件变更后加载并调用 Reload 重新加载默认日志，
+// 加载失败时保留旧配置并通过 onError 回调通知（可为 nil）。ctx 结束时停止监听
+func WatchConfigFile(ctx context.Context, path string, interval time.Duration, onError func(error)) {
+	filewatch.Watch(ctx, path, interval, func() error {
+		config, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		return Reload(config)
+	}, onError)
+}
@@ -0,0 +1,148 @@
+package zap_logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NumberMan1/component/zap-logger/field"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestZapLogger_Reload(t *testing.T) {
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.log"), filepath.Join(dir, "after.log")
+	logger := NewZapLoggerWithConfig(Config{Level: "info", Outputs: []TeeConfig{{Target: before}}}).(*ZapLogger)
+	child := logger.Named("storage").With(field.String("zone", "cn"))
+	child.Debug("debug before")
+	child.Info("info before")
+
+	if err := logger.Reload(Config{Level: "debug", Outputs: []TeeConfig{{Target: after}}}); err != nil {
+		t.Fatal(err)
+	}
+	child.Debug("debug after")
+	logger.Sync()
+
+	// 旧输出在替换时写入缓冲
+	data, _ := os.ReadFile(before)
+	if !strings.Contains(string(data), "info before") || strings.Contains(string(data), "debug") {
+		t.Errorf("before = %q", data)
+	}
+	data, _ = os.ReadFile(after)
+	if !strings.Contains(string(data), "debug after") || !strings.Contains(string(data), `"zone":"cn"`) {
+		t.Errorf("after = %q, want debug entry with With fields", data)
+	}
+
+	if err := logger.Reload(Config{Redact: RedactConfig{Patterns: []string{"("}}}); err == nil {
+		t.Errorf("Reload(invalid) error = nil")
+	}
+	test, _ := NewTestLogger()
+	if err := test.(*ZapLogger).Reload(Config{}); err != errNotReloadable {
+		t.Errorf("Reload(test logger) error = %v, want errNotReloadable", err)
+	}
+}
+
+func TestZapLogger_ReloadPendingEntry(t *testing.T) {
+	dir := t.TempDir()
+	before, after := filepath.Join(dir, "before.log"), filepath.Join(dir, "after.log")
+	logger := NewZapLoggerWithConfig(Config{Level: "info", Outputs: []TeeConfig{{Target: before}}}).(*ZapLogger)
+
+	// Check 与 Write 之间发生 Reload，日志写入新输出而不是已关闭的旧输出
+	checked := logger.base.Check(zapcore.InfoLevel, "pending")
+	if err := logger.Reload(Config{Level: "info", Outputs: []TeeConfig{{Target: after}}}); err != nil {
+		t.Fatal(err)
+	}
+	checked.Write()
+	logger.Sync()
+	data, _ := os.ReadFile(after)
+	if !strings.Contains(string(data), "pending") {
+		t.Errorf("after = %q, want pending entry", data)
+	}
+}
+
+func TestZapLogger_ReloadConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	target := func(i int) string { return filepath.Join(dir, fmt.Sprintf("app-%d.log", i)) }
+	logger := NewZapLoggerWithConfig(Config{Level: "info", Outputs: []TeeConfig{{Target: target(0)}}}).(*ZapLogger)
+
+	const writers, perWriter, reloads = 4, 200, 5
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWriter; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	for i := 1; i <= reloads; i++ {
+		if err := logger.Reload(Config{Level: "info", Outputs: []TeeConfig{{Target: target(i)}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 替换时正在进行的写入不丢失
+	lines := 0
+	for i := 0; i <= reloads; i++ {
+		data, _ := os.ReadFile(target(i))
+		lines += strings.Count(string(data), "message")
+	}
+	if lines != writers*perWriter {
+		t.Errorf("lines = %d, want %d", lines, writers*perWriter)
+	}
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path, output := filepath.Join(dir, "log.yaml"), filepath.Join(dir, "app.log")
+	restore := SetDefaultLogger(NewZapLoggerWithConfig(Config{Level: "info"}))
+	defer restore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	errs := make(chan error, 1)
+	go func() {
+		defer close(done)
+		WatchConfigFile(ctx, path, 10*time.Millisecond, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+	}()
+	// 文件不存在时通过 onError 通知
+	if err := <-errs; err == nil {
+		t.Fatalf("onError(nil)")
+	}
+
+	config := "level: debug\noutputs:\n  - target: " + output + "\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		DefaultLogger().Debug("reloaded")
+		DefaultLogger().(*ZapLogger).Sync()
+		if data, _ := os.ReadFile(output); strings.Contains(string(data), "reloaded") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("config not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,217 @@
+package zap_logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRotateMaxSizeMB  = 100
+	defaultRotateMaxAgeDays = 30
+	rotateBackupTimeFormat  = "2006-01-02T15-04-05.000"
+	rotateCompressSuffix    = ".gz"
+)
+
+// RotateConfig 文件输出的切分配置
+type RotateConfig struct {
+	// 单个文件的最大大小（MB），超过后切分，<=0 时为 100
+	MaxSizeMB int `json:"max_size_mb" yaml:"max-size-mb"`
+	// 切分出的旧文件保留的最大天数，<=0 时为 30
+	MaxAgeDays int `json:"max_age_days" yaml:"max-age-days"`
+	// 保留的旧文件最大个数，<=0 时不限制
+	MaxBackups int `json:"max_backups" yaml:"max-backups"`
+	// 是否以 gzip 压缩切分出的旧文件
+	Compress bool `json:"compress" yaml:"compress"`
+}
+
+func (conf RotateConfig) maxSize() int64 {
+	if conf.MaxSizeMB <= 0 {
+		return defaultRotateMaxSizeMB * 1024 * 1024
+	}
+	return int64(conf.MaxSizeMB) * 1024 * 1024
+}
+
+func (conf RotateConfig) maxAge() time.Duration {
+	if conf.MaxAgeDays <= 0 {
+		return defaultRotateMaxAgeDays * 24 * time.Hour
+	}
+	return time.Duration(conf.MaxAgeDays) * 24 * time.Hour
+}
+
+// rotateWriter 按大小切分的文件输出：当前文件为 filename，切分出的旧文件为 name-时间.log，
+// 切分时按保留天数与个数清理旧文件，并按需压缩
+type rotateWriter struct {
+	filename string
+	config   RotateConfig
+	maxSize  int64
+	timeNow  func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newRotateWriter(filename string, config RotateConfig) (*rotateWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &rotateWriter{filename: filename, config: config, maxSize: config.maxSize(), timeNow: time.Now}, nil
+}
+
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open 以追加方式打开当前文件
+func (w *rotateWriter) open() error {
+	file, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为旧文件后重新打开，并清理旧文件
+func (w *rotateWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := os.Rename(w.filename, w.backupName(w.timeNow())); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.cleanup()
+}
+
+func (w *rotateWriter) backupName(t time.Time) string {
+	ext := filepath.Ext(w.filename)
+	return strings.TrimSuffix(w.filename, ext) + "-" + t.Format(rotateBackupTimeFormat) + ext
+}
+
+// rotateBackup 切分出的旧文件
+type rotateBackup struct {
+	path string
+	time time.Time
+}
+
+// backups 按时间从新到旧列出旧文件
+func (w *rotateWriter) backups() ([]rotateBackup, error) {
+	entries, err := os.ReadDir(filepath.Dir(w.filename))
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(w.filename)
+	prefix := strings.TrimSuffix(filepath.Base(w.filename), ext) + "-"
+	backups := make([]rotateBackup, 0)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), rotateCompressSuffix)
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(rotateBackupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext), time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, rotateBackup{path: filepath.Join(filepath.Dir(w.filename), entry.Name()), time: t})
+	}
+	slices.SortFunc(backups, func(a, b rotateBackup) int { return b.time.Compare(a.time) })
+	return backups, nil
+}
+
+// cleanup 删除超过保留个数或天数的旧文件，开启压缩时压缩其余未压缩的旧文件
+func (w *rotateWriter) cleanup() error {
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+	cutoff := w.timeNow().Add(-w.config.maxAge())
+	for i, backup := range backups {
+		if (w.config.MaxBackups > 0 && i >= w.config.MaxBackups) || backup.time.Before(cutoff) {
+			if err = os.Remove(backup.path); err != nil {
+				return err
+			}
+			continue
+		}
+		if w.config.Compress && !strings.HasSuffix(backup.path, rotateCompressSuffix) {
+			if err = compressFile(backup.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile 将文件压缩为 path.gz 后删除原文件
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+rotateCompressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + rotateCompressSuffix)
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,80 @@
+package zap_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      RotateConfig
+		wantBackups int
+		wantSuffix  string
+	}{
+		{name: "不限制个数", config: RotateConfig{}, wantBackups: 3, wantSuffix: ".log"},
+		{name: "按个数清理", config: RotateConfig{MaxBackups: 2}, wantBackups: 2, wantSuffix: ".log"},
+		{name: "压缩旧文件", config: RotateConfig{MaxBackups: 2, Compress: true}, wantBackups: 2, wantSuffix: ".log.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writer, err := newRotateWriter(filepath.Join(dir, "app-info.log"), tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer writer.Close()
+			writer.maxSize = 10
+			now := time.Date(2025, 3, 20, 10, 0, 0, 0, time.Local)
+			writer.timeNow = func() time.Time { return now }
+			for i := 0; i < 4; i++ {
+				if _, err = writer.Write([]byte("0123456789")); err != nil {
+					t.Fatal(err)
+				}
+				now = now.Add(time.Second)
+			}
+
+			backups, err := writer.backups()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(backups) != tt.wantBackups {
+				t.Fatalf("backups = %d, want %d", len(backups), tt.wantBackups)
+			}
+			for _, backup := range backups {
+				if !strings.HasSuffix(backup.path, tt.wantSuffix) {
+					t.Errorf("backup %s, want suffix %s", backup.path, tt.wantSuffix)
+				}
+			}
+			if info, err := os.Stat(filepath.Join(dir, "app-info.log")); err != nil || info.Size() != 10 {
+				t.Errorf("current file = %v, %v, want 10 bytes", info, err)
+			}
+		})
+	}
+}
+
+func TestRotateWriter_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := newRotateWriter(filepath.Join(dir, "app-info.log"), RotateConfig{MaxAgeDays: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	writer.maxSize = 10
+	now := time.Date(2025, 3, 20, 10, 0, 0, 0, time.Local)
+	writer.timeNow = func() time.Time { return now }
+	writer.Write([]byte("0123456789"))
+	writer.Write([]byte("0123456789"))
+	now = now.Add(48 * time.Hour)
+	writer.Write([]byte("0123456789"))
+
+	// 两天前切分的旧文件已被删除
+	backups, _ := writer.backups()
+	if len(backups) != 1 || !backups[0].time.Equal(now) {
+		t.Errorf("backups = %v, want only the latest", backups)
+	}
+}
@@ -0,0 +1,295 @@
+package zap_logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NumberMan1/log"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSinkBatchSize     = 100
+	defaultSinkFlushInterval = time.Second
+	defaultSinkMaxBuffer     = 10000
+	defaultSinkMaxRetries    = 3
+	defaultSinkRetryBackoff  = 200 * time.Millisecond
+	defaultSinkTimeout       = 5 * time.Second
+)
+
+// SinkEntry 发送到 Sink 的一条日志
+type SinkEntry struct {
+	Time  time.Time
+	Level zapcore.Level
+	// 以 JSON 编码的完整日志，不含换行
+	Line []byte
+}
+
+// Sink 日志的额外输出，如日志聚合服务；日志先写入缓冲，按批发送，失败时重试；
+// 实现了 io.Closer 时在 Reload 替换输出后关闭
+type Sink interface {
+	// Send 发送一批日志，返回错误时按 SinkConfig 重试，重试耗尽后丢弃
+	Send(ctx context.Context, entries []SinkEntry) error
+}
+
+// SinkConfig 日志额外输出的配置
+type SinkConfig struct {
+	// 输出类型：SinkHTTP、SinkLoki、SinkKafka、SinkSyslog 或 SinkJournald，通过 Config.Sinks 创建时生效
+	Type string `json:"type" yaml:"type"`
+	// HTTP 批量接收地址、Loki push 接口地址、Kafka REST Proxy 地址、syslog 地址或 journald socket 路径
+	Url string `json:"url" yaml:"url"`
+	// 附加的请求头，如鉴权信息
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	// Kafka 的 topic
+	Topic string `json:"topic" yaml:"topic"`
+	// Loki 的 stream 标签，为空时为 {app="Config.Name"}；journald 的额外字段
+	Labels map[string]string `json:"labels" yaml:"labels"`
+	// 该输出的最低等级，为空时使用 Config.Level
+	Level log.Level `json:"level" yaml:"level"`
+	// 单批最多的日志条数，<=0 时为 100
+	BatchSize int `json:"batch_size" yaml:"batch-size"`
+	// 未满一批时的发送间隔（毫秒），<=0 时为 1000
+	FlushIntervalMillis int64 `json:"flush_interval_millis" yaml:"flush-interval-millis"`
+	// 缓冲的最多日志条数，超出时丢弃最旧的日志，<=0 时为 10000
+	MaxBufferSize int `json:"max_buffer_size" yaml:"max-buffer-size"`
+	// 发送失败的最大重试次数，<0 时不重试，0 时为 3
+	MaxRetries int `json:"max_retries" yaml:"max-retries"`
+	// 首次重试前的等待时间（毫秒），之后每次翻倍，<=0 时为 200
+	RetryBackoffMillis int64 `json:"retry_backoff_millis" yaml:"retry-backoff-millis"`
+	// 单次发送的超时（毫秒），<=0 时为 5000
+	TimeoutMillis int64 `json:"timeout_millis" yaml:"timeout-millis"`
+}
+
+func (conf SinkConfig) batchSize() int {
+	if conf.BatchSize <= 0 {
+		return defaultSinkBatchSize
+	}
+	return conf.BatchSize
+}
+
+func (conf SinkConfig) flushInterval() time.Duration {
+	if conf.FlushIntervalMillis <= 0 {
+		return defaultSinkFlushInterval
+	}
+	return time.Duration(conf.FlushIntervalMillis) * time.Millisecond
+}
+
+func (conf SinkConfig) maxBuffer() int {
+	if conf.MaxBufferSize <= 0 {
+		return defaultSinkMaxBuffer
+	}
+	return conf.MaxBufferSize
+}
+
+func (conf SinkConfig) maxRetries() int {
+	switch {
+	case conf.MaxRetries < 0:
+		return 0
+	case conf.MaxRetries == 0:
+		return defaultSinkMaxRetries
+	}
+	return conf.MaxRetries
+}
+
+func (conf SinkConfig) retryBackoff() time.Duration {
+	if conf.RetryBackoffMillis <= 0 {
+		return defaultSinkRetryBackoff
+	}
+	return time.Duration(conf.RetryBackoffMillis) * time.Millisecond
+}
+
+func (conf SinkConfig) timeout() time.Duration {
+	if conf.TimeoutMillis <= 0 {
+		return defaultSinkTimeout
+	}
+	return time.Duration(conf.TimeoutMillis) * time.Millisecond
+}
+
+// SinkFactory 根据配置创建 Sink，app 为 Config.Name
+type SinkFactory func(config SinkConfig, app string) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]SinkFactory{}
+)
+
+// RegisterSink 注册输出类型，之后可在 Config.Sinks 中按类型引用；同名注册会覆盖
+func RegisterSink(typ string, factory SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[typ] = factory
+}
+
+// LookupSink 按类型查找已注册的输出
+func LookupSink(typ string) (SinkFactory, bool) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	factory, ok := sinks[typ]
+	return factory, ok
+}
+
+// sinkBuffer 缓冲日志并在后台按批发送到 Sink，所有 With 出的 sinkCore 共享
+type sinkBuffer struct {
+	sink   Sink
+	config SinkConfig
+	sleep  func(wait time.Duration)
+
+	mu      sync.Mutex
+	entries []SinkEntry
+	dropped int
+	notify  chan struct{}
+	stop    chan struct{}
+	// sendMu 保证同一时刻只有一批在发送，Sync 与后台发送不会乱序
+	sendMu sync.Mutex
+}
+
+func newSinkBuffer(sink Sink, config SinkConfig) *sinkBuffer {
+	buffer := &sinkBuffer{
+		sink:   sink,
+		config: config,
+		sleep:  time.Sleep,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	go buffer.run()
+	return buffer
+}
+
+func (buffer *sinkBuffer) add(entry SinkEntry) {
+	buffer.mu.Lock()
+	if len(buffer.entries) >= buffer.config.maxBuffer() {
+		buffer.entries = buffer.entries[1:]
+		buffer.dropped++
+	}
+	buffer.entries = append(buffer.entries, entry)
+	full := len(buffer.entries) >= buffer.config.batchSize()
+	buffer.mu.Unlock()
+	if full {
+		select {
+		case buffer.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (buffer *sinkBuffer) run() {
+	ticker := time.NewTicker(buffer.config.flushInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-buffer.notify:
+		case <-buffer.stop:
+			return
+		}
+		buffer.flush()
+	}
+}
+
+// Close 停止后台发送，发送缓冲中的日志后关闭 Sink（实现了 io.Closer 时）
+func (buffer *sinkBuffer) Close() error {
+	close(buffer.stop)
+	err := buffer.flush()
+	if closer, ok := buffer.sink.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// flush 发送缓冲中的全部日志
+func (buffer *sinkBuffer) flush() error {
+	buffer.sendMu.Lock()
+	defer buffer.sendMu.Unlock()
+	for {
+		buffer.mu.Lock()
+		n := min(len(buffer.entries), buffer.config.batchSize())
+		batch := buffer.entries[:n:n]
+		buffer.entries = buffer.entries[n:]
+		dropped := buffer.dropped
+		buffer.dropped = 0
+		buffer.mu.Unlock()
+		if dropped > 0 {
+			fmt.Fprintf(os.Stderr, "zap-logger: sink buffer full, dropped %d entries\n", dropped)
+		}
+		if n == 0 {
+			return nil
+		}
+		if err := buffer.send(batch); err != nil {
+			fmt.Fprintf(os.Stderr, "zap-logger: sink send failed, dropped %d entries: %v\n", n, err)
+			return err
+		}
+	}
+}
+
+// send 发送一批日志，失败时按指数退避重试
+func (buffer *sinkBuffer) send(batch []SinkEntry) error {
+	backoff := buffer.config.retryBackoff()
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), buffer.config.timeout())
+		err := buffer.sink.Send(ctx, batch)
+		cancel()
+		if err == nil || attempt >= buffer.config.maxRetries() {
+			return err
+		}
+		buffer.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// sinkCore 将日志以 JSON 编码后写入 sinkBuffer
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	buffer  *sinkBuffer
+}
+
+func newSinkCore(sink Sink, config SinkConfig, level zapcore.LevelEnabler) *sinkCore {
+	return &sinkCore{
+		LevelEnabler: level,
+		encoder:      zapcore.NewJSONEncoder(log.DefaultEncoder()),
+		buffer:       newSinkBuffer(sink, config),
+	}
+}
+
+func (core *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := core.encoder.Clone()
+	for _, f := range fields {
+		f.AddTo(encoder)
+	}
+	return &sinkCore{LevelEnabler: core.LevelEnabler, encoder: encoder, buffer: core.buffer}
+}
+
+func (core *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.Enabled(entry.Level) {
+		return checked.AddCore(entry, core)
+	}
+	return checked
+}
+
+func (core *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := core.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	line := make([]byte, 0, buf.Len())
+	line = append(line, buf.Bytes()...)
+	buf.Free()
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	core.buffer.add(SinkEntry{Time: entry.Time, Level: entry.Level, Line: line})
+	if entry.Level > zapcore.ErrorLevel {
+		// 可能即将退出，立即发送
+		return core.Sync()
+	}
+	return nil
+}
+
+func (core *sinkCore) Sync() error {
+	return core.buffer.flush()
+}
@@ -0,0 +1,147 @@
+package zap_logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// SinkHTTP 以 JSON 数组批量 POST 到任意 HTTP 接口
+	SinkHTTP = "http"
+	// SinkLoki 通过 Loki push API 发送
+	SinkLoki = "loki"
+	// SinkKafka 通过 Kafka REST Proxy（v2）写入 topic
+	SinkKafka = "kafka"
+)
+
+func init() {
+	RegisterSink(SinkHTTP, func(config SinkConfig, app string) (Sink, error) {
+		if config.Url == "" {
+			return nil, fmt.Errorf("zap-logger: http sink url not configured")
+		}
+		return NewHTTPSink(config), nil
+	})
+	RegisterSink(SinkLoki, func(config SinkConfig, app string) (Sink, error) {
+		if config.Url == "" {
+			return nil, fmt.Errorf("zap-logger: loki sink url not configured")
+		}
+		return NewLokiSink(config, app), nil
+	})
+	RegisterSink(SinkKafka, func(config SinkConfig, app string) (Sink, error) {
+		if config.Url == "" || config.Topic == "" {
+			return nil, fmt.Errorf("zap-logger: kafka sink url or topic not configured")
+		}
+		return NewKafkaSink(config), nil
+	})
+}
+
+// httpSender 发送 POST 请求，非 2xx 视为失败
+type httpSender struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (sender httpSender) post(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sender.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range sender.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := sender.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("zap-logger: sink http status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HTTPSink 将一批日志以 JSON 数组 POST 到 SinkConfig.Url
+type HTTPSink struct {
+	sender httpSender
+}
+
+func NewHTTPSink(config SinkConfig) *HTTPSink {
+	return &HTTPSink{sender: httpSender{url: config.Url, headers: config.Headers, client: http.DefaultClient}}
+}
+
+func (sink *HTTPSink) Send(ctx context.Context, entries []SinkEntry) error {
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.Line)
+	}
+	body, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+	return sink.sender.post(ctx, "application/json", body)
+}
+
+// LokiSink 通过 Loki push API（/loki/api/v1/push）发送，所有日志写入同一 stream
+type LokiSink struct {
+	sender httpSender
+	labels map[string]string
+}
+
+// NewLokiSink 创建 Loki 输出，SinkConfig.Labels 为空时以 app 作为标签
+func NewLokiSink(config SinkConfig, app string) *LokiSink {
+	labels := config.Labels
+	if len(labels) == 0 {
+		labels = map[string]string{"app": app}
+	}
+	return &LokiSink{sender: httpSender{url: config.Url, headers: config.Headers, client: http.DefaultClient}, labels: labels}
+}
+
+func (sink *LokiSink) Send(ctx context.Context, entries []SinkEntry) error {
+	values := make([][2]string, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, [2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), string(entry.Line)})
+	}
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	body, err := json.Marshal(map[string][]stream{"streams": {{Stream: sink.labels, Values: values}}})
+	if err != nil {
+		return err
+	}
+	return sink.sender.post(ctx, "application/json", body)
+}
+
+// KafkaSink 通过 Kafka REST Proxy v2 的 POST /topics/{topic} 写入，每条日志为一条 JSON 消息
+type KafkaSink struct {
+	sender httpSender
+}
+
+func NewKafkaSink(config SinkConfig) *KafkaSink {
+	reqUrl := config.Url + "/topics/" + url.PathEscape(config.Topic)
+	return &KafkaSink{sender: httpSender{url: reqUrl, headers: config.Headers, client: http.DefaultClient}}
+}
+
+func (sink *KafkaSink) Send(ctx context.Context, entries []SinkEntry) error {
+	type record struct {
+		Value json.RawMessage `json:"value"`
+	}
+	records := make([]record, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, record{Value: entry.Line})
+	}
+	body, err := json.Marshal(map[string][]record{"records": records})
+	if err != nil {
+		return err
+	}
+	return sink.sender.post(ctx, "application/vnd.kafka.json.v2+json", body)
+}
@@ -0,0 +1,177 @@
+//go:build !windows && !plan9
+
+package zap_logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/syslog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// SinkSyslog 写入本机或远程 syslog
+	SinkSyslog = "syslog"
+	// SinkJournald 通过原生协议写入 systemd-journald
+	SinkJournald = "journald"
+
+	defaultJournaldSocket = "/run/systemd/journal/socket"
+)
+
+func init() {
+	RegisterSink(SinkSyslog, func(config SinkConfig, app string) (Sink, error) {
+		return NewSyslogSink(config, app)
+	})
+	RegisterSink(SinkJournald, func(config SinkConfig, app string) (Sink, error) {
+		return NewJournaldSink(config, app), nil
+	})
+}
+
+// syslogPriority 日志等级对应的 syslog 优先级
+func syslogPriority(level zapcore.Level) syslog.Priority {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return syslog.LOG_CRIT
+	case level == zapcore.ErrorLevel:
+		return syslog.LOG_ERR
+	case level == zapcore.WarnLevel:
+		return syslog.LOG_WARNING
+	case level == zapcore.InfoLevel:
+		return syslog.LOG_INFO
+	}
+	return syslog.LOG_DEBUG
+}
+
+// SyslogSink 写入 syslog，SinkConfig.Url 为空时写入本机 syslog，否则为 udp://host:514、tcp://host:514 或 unix:///dev/log
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+func NewSyslogSink(config SinkConfig, app string) (*SyslogSink, error) {
+	var network, addr string
+	if config.Url != "" {
+		u, err := url.Parse(config.Url)
+		if err != nil {
+			return nil, err
+		}
+		network, addr = u.Scheme, u.Host
+		if network == "unix" || network == "unixgram" {
+			addr = u.Path
+		}
+	}
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, app)
+	if err != nil {
+		return nil, fmt.Errorf("zap-logger: dial syslog: %w", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (sink *SyslogSink) Send(ctx context.Context, entries []SinkEntry) error {
+	for _, entry := range entries {
+		var err error
+		line := string(entry.Line)
+		switch syslogPriority(entry.Level) {
+		case syslog.LOG_CRIT:
+			err = sink.writer.Crit(line)
+		case syslog.LOG_ERR:
+			err = sink.writer.Err(line)
+		case syslog.LOG_WARNING:
+			err = sink.writer.Warning(line)
+		case syslog.LOG_INFO:
+			err = sink.writer.Info(line)
+		default:
+			err = sink.writer.Debug(line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JournaldSink 通过 journald 原生协议写入，每条日志为一条记录，MESSAGE 为 JSON 编码的日志；
+// SinkConfig.Url 为空时使用 /run/systemd/journal/socket，Labels 作为额外字段写入（键会转换为大写）
+type JournaldSink struct {
+	socket string
+	fields map[string]string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewJournaldSink(config SinkConfig, app string) *JournaldSink {
+	socket := config.Url
+	if socket == "" {
+		socket = defaultJournaldSocket
+	}
+	fields := map[string]string{"SYSLOG_IDENTIFIER": app}
+	for k, v := range config.Labels {
+		fields[strings.ToUpper(k)] = v
+	}
+	return &JournaldSink{socket: socket, fields: fields}
+}
+
+func (sink *JournaldSink) Send(ctx context.Context, entries []SinkEntry) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.conn == nil {
+		conn, err := net.Dial("unixgram", sink.socket)
+		if err != nil {
+			return fmt.Errorf("zap-logger: dial journald: %w", err)
+		}
+		sink.conn = conn
+	}
+	for _, entry := range entries {
+		if _, err := sink.conn.Write(sink.record(entry)); err != nil {
+			sink.conn.Close()
+			sink.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (sink *JournaldSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.conn == nil {
+		return nil
+	}
+	err := sink.conn.Close()
+	sink.conn = nil
+	return err
+}
+
+// record 编码一条 journald 记录：KEY=value\n，值含换行时为 KEY\n<8 字节小端长度><value>\n
+func (sink *JournaldSink) record(entry SinkEntry) []byte {
+	var buf bytes.Buffer
+	write := func(key, value string) {
+		if !strings.ContainsRune(value, '\n') {
+			buf.WriteString(key + "=" + value + "\n")
+			return
+		}
+		buf.WriteString(key + "\n")
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value + "\n")
+	}
+	write("MESSAGE", string(entry.Line))
+	write("PRIORITY", strconv.Itoa(int(syslogPriority(entry.Level))))
+	for k, v := range sink.fields {
+		if v != "" {
+			write(k, v)
+		}
+	}
+	return buf.Bytes()
+}
+
+func (sink *SyslogSink) Close() error {
+	return sink.writer.Close()
+}
@@ -0,0 +1,56 @@
+//go:build !windows && !plan9
+
+package zap_logger
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogAndJournaldSink(t *testing.T) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	socket := filepath.Join(t.TempDir(), "journal.socket")
+	journal, err := net.ListenPacket("unixgram", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	tests := []struct {
+		name   string
+		config SinkConfig
+		conn   net.PacketConn
+		want   []string
+	}{
+		{name: "syslog", config: SinkConfig{Type: SinkSyslog, Url: "udp://" + udp.LocalAddr().String()}, conn: udp,
+			want: []string{"<11>", "app", `"msg":"sink message"`}},
+		{name: "journald", config: SinkConfig{Type: SinkJournald, Url: socket, Labels: map[string]string{"env": "test"}}, conn: journal,
+			want: []string{"PRIORITY=3\n", "SYSLOG_IDENTIFIER=app\n", "ENV=test\n", `"msg":"sink message"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewZapLoggerWithConfig(Config{Name: "app", Sinks: []SinkConfig{tt.config}}).(*ZapLogger)
+			logger.Error("sink message")
+			logger.Sync()
+
+			buf := make([]byte, 4096)
+			tt.conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := tt.conn.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(buf[:n]), want) {
+					t.Errorf("record = %q, want %q", buf[:n], want)
+				}
+			}
+		})
+	}
+}
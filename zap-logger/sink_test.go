@@ -0,0 +1,86 @@
+package zap_logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestSink(t *testing.T) {
+	tests := []struct {
+		name     string
+		sinkType string
+		path     string
+		count    func(body []byte) int
+	}{
+		{name: "HTTP 批量发送", sinkType: SinkHTTP, path: "/", count: func(body []byte) int {
+			var lines []json.RawMessage
+			json.Unmarshal(body, &lines)
+			return len(lines)
+		}},
+		{name: "Loki push", sinkType: SinkLoki, path: "/", count: func(body []byte) int {
+			var data struct {
+				Streams []struct {
+					Values [][2]string `json:"values"`
+				} `json:"streams"`
+			}
+			json.Unmarshal(body, &data)
+			if len(data.Streams) != 1 {
+				return 0
+			}
+			return len(data.Streams[0].Values)
+		}},
+		{name: "Kafka REST Proxy", sinkType: SinkKafka, path: "/topics/logs", count: func(body []byte) int {
+			var data struct {
+				Records []json.RawMessage `json:"records"`
+			}
+			json.Unmarshal(body, &data)
+			return len(data.Records)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests, received int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests++
+				// 首次请求失败，验证重试
+				if requests == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				if r.URL.Path != tt.path {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.path)
+				}
+				body, _ := io.ReadAll(r.Body)
+				received += tt.count(body)
+			}))
+			defer server.Close()
+
+			logger := NewZapLoggerWithConfig(Config{Name: "app", Sinks: []SinkConfig{{
+				Type:                tt.sinkType,
+				Url:                 server.URL,
+				Topic:               "logs",
+				Level:               "info",
+				FlushIntervalMillis: 60000,
+				RetryBackoffMillis:  1,
+			}}}).(*ZapLogger)
+			logger.Debug("debug message")
+			logger.Info("info message")
+			logger.Warn("warn message")
+			logger.Sync()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if requests != 2 || received != 2 {
+				t.Errorf("requests = %d, received = %d, want 2, 2", requests, received)
+			}
+		})
+	}
+}